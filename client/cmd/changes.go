@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+)
+
+// maxReconnectDelay ограничивает паузу между попытками переподключения к ленте изменений.
+const maxReconnectDelay = 30 * time.Second
+
+// kindNames - названия видов элементов хранилища для вывода.
+var kindNames = map[string]string{
+	domain.KindPassword: "пароль",
+	domain.KindCard:     "карта",
+	domain.KindNote:     "заметка",
+	domain.KindFile:     "файл",
+	domain.KindRecord:   "запись",
+}
+
+// actionNames - названия действий над элементами для вывода.
+var actionNames = map[string]string{
+	domain.ActionCreated: "добавление",
+	domain.ActionUpdated: "изменение",
+	domain.ActionDeleted: "удаление",
+}
+
+// watchChanges показывает изменения хранилища, в том числе сделанные на других устройствах,
+// пока пользователь не нажмет Enter. Сначала выводятся изменения, пропущенные с прошлого просмотра.
+func watchChanges() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		followChanges(ctx)
+	}()
+
+	getUserInputInfo("Изменения хранилища (Enter - вернуться в меню):\n")
+	cancel()
+	<-done
+}
+
+// followChanges читает ленту изменений и после обрыва соединения
+// переподключается с курсора последнего полученного события.
+func followChanges(ctx context.Context) {
+	delay := time.Second
+	for {
+		received, err := readChanges(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			fmt.Println("Лента изменений прервана:", err)
+		}
+		if received {
+			delay = time.Second
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// readChanges подключается к ленте изменений в формате Server-Sent Events и выводит события,
+// пока соединение не оборвется. Возвращает, удалось ли получить хотя бы одно событие.
+func readChanges(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, serverURL+"/events", nil)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при создании запроса: %v", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", "Bearer "+session.Tokens.AccessToken)
+	if session.ChangesCursor != "" {
+		req.Header.Set("Last-Event-ID", session.ChangesCursor)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при выполнении запроса: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		// Токен доступа истек: обновляем его, а подключится следующая попытка
+		return false, refreshTokens()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, statusError(resp)
+	}
+
+	received := false
+	var data string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "data:"):
+			data = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		case line == "" && data != "":
+			var event domain.ChangeEvent
+			if err := json.Unmarshal([]byte(data), &event); err == nil {
+				printChange(event)
+				session.ChangesCursor = event.Cursor
+				received = true
+			}
+			data = ""
+		}
+	}
+	return received, scanner.Err()
+}
+
+// printChange выводит событие изменения хранилища.
+func printChange(event domain.ChangeEvent) {
+	at := event.At.Local().Format("15:04:05")
+	if event.Action == domain.ActionReset {
+		fmt.Printf("[%s] Часть изменений пропущена, обновите списки\n", at)
+		return
+	}
+
+	kind, ok := kindNames[event.Kind]
+	if !ok {
+		kind = event.Kind
+	}
+	action, ok := actionNames[event.Action]
+	if !ok {
+		action = event.Action
+	}
+	if event.NewName != "" {
+		fmt.Printf("[%s] %s «%s»: %s, новое название «%s»\n", at, kind, event.Name, action, event.NewName)
+		return
+	}
+	fmt.Printf("[%s] %s «%s»: %s\n", at, kind, event.Name, action)
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+)
+
+// UpdatePasswordData содержит информацию об изменении пароля.
+type UpdatePasswordData struct {
+	PassName    string `json:"passName"`    // Текущее название пароля
+	NewPassName string `json:"newPassName"` // Новое название пароля
+	Password    string `json:"password"`    // Новый пароль
+}
+
+// UpdateCardData содержит информацию об изменении карты.
+type UpdateCardData struct {
+	CardName       string `json:"cardName"`       // Текущее название карты
+	NewCardName    string `json:"newCardName"`    // Новое название карты
+	NumberCard     string `json:"numberCard"`     // Номер карты
+	ExpiryDateCard string `json:"expiryDateCard"` // Срок карты
+	CvvCard        string `json:"CvvCard"`        // Секретный код карты
+}
+
+// chooseName выводит список названий и возвращает выбранное пользователем.
+// Второе значение равно false, если пользователь решил вернуться назад.
+func chooseName(title string, names []string) (string, bool) {
+	for {
+		fmt.Printf("\n%s:\n", title)
+		for i, name := range names {
+			fmt.Printf("%d. %s\n", i+1, name)
+		}
+		fmt.Println("0. Вернуться назад")
+
+		choice, err := strconv.Atoi(getUserInputInfo("Выберите номер: "))
+		switch {
+		case err != nil:
+			fmt.Println("Ошибка при чтении ввода:", err)
+		case choice == 0:
+			return "", false
+		case choice < 1 || choice > len(names):
+			fmt.Println("Некорректный номер")
+		default:
+			return names[choice-1], true
+		}
+	}
+}
+
+// confirm запрашивает у пользователя подтверждение действия.
+func confirm(prompt string) bool {
+	answer := strings.ToLower(getUserInputInfo(prompt + " (y/n): "))
+	return answer == "y" || answer == "д"
+}
+
+// editPassword запрашивает у пользователя изменения пароля и отправляет их на сервер.
+func editPassword() {
+	passName, ok := findItem(domain.KindPassword, "Список паролей")
+	if !ok {
+		return
+	}
+
+	newPassName := getUserInputInfo("Новое название (Enter - оставить прежнее): ")
+	password := getUserInputInfo("Новый пароль (Enter - оставить прежний): ")
+	if newPassName == "" && password == "" {
+		fmt.Println("Изменений нет")
+		return
+	}
+
+	// Шифротекст привязан к названию, поэтому при переименовании прежние пароль
+	// и ключ одноразовых паролей шифруются заново
+	var otpURI string
+	if password == "" || newPassName != "" {
+		if !unlock() {
+			return
+		}
+		current, uri, err := GetPasswordEntry(passName)
+		if err != nil {
+			fmt.Println("Ошибка при получении пароля:", err)
+			return
+		}
+		if password == "" {
+			password = current
+		}
+		otpURI = uri
+	}
+
+	if err := UpdatePassword(passName, newPassName, password); err != nil {
+		fmt.Println("Ошибка при изменении пароля:", err)
+		return
+	}
+	if newPassName != "" && otpURI != "" {
+		if err := SetPasswordOTP(newPassName, otpURI); err != nil {
+			fmt.Println("Ошибка при переносе ключа одноразовых паролей:", err)
+		}
+	}
+	fmt.Println("Пароль успешно изменен!")
+	indexName(domain.KindPassword, currentName(passName, newPassName))
+}
+
+// UpdatePassword отправляет запрос на сервер для изменения пароля.
+// Пустое новое название оставляет прежнее.
+func UpdatePassword(passName, newPassName, password string) error {
+	name := passName
+	if newPassName != "" {
+		name = newPassName
+	}
+	encrypted, err := encryptField(password, recordID("password", name))
+	if err != nil {
+		return err
+	}
+
+	return fetchJSON(
+		"/password/update", UpdatePasswordData{PassName: passName, NewPassName: newPassName, Password: encrypted}, nil,
+	)
+}
+
+// deletePassword запрашивает подтверждение и удаляет выбранный пароль.
+func deletePassword() {
+	passName, ok := findItem(domain.KindPassword, "Список паролей")
+	if !ok || !confirm(fmt.Sprintf("Переместить пароль '%s' в корзину?", passName)) {
+		return
+	}
+
+	if err := fetchJSON("/password/delete", PassData{PassName: passName}, nil); err != nil {
+		fmt.Println("Ошибка при удалении пароля:", err)
+		return
+	}
+	fmt.Println("Пароль перемещен в корзину")
+}
+
+// editCard запрашивает у пользователя изменения карты и отправляет их на сервер.
+func editCard() {
+	cardName, ok := findItem(domain.KindCard, "Список карт")
+	if !ok {
+		return
+	}
+
+	// Текущие реквизиты нужны, чтобы оставить неизмененные поля и зашифровать их заново
+	if !unlock() {
+		return
+	}
+	number, expiry, cvv, err := GetCard(cardName)
+	if err != nil {
+		fmt.Println("Ошибка при получении карты:", err)
+		return
+	}
+
+	newCardName := getUserInputInfo("Новое название (Enter - оставить прежнее): ")
+	number = inputOrDefault("Номер карты", number)
+	expiry = inputOrDefault("Срок действия", expiry)
+	cvv = inputOrDefault("CVV", cvv)
+
+	if err := UpdateCard(cardName, newCardName, number, expiry, cvv); err != nil {
+		fmt.Println("Ошибка при изменении карты:", err)
+		return
+	}
+	fmt.Println("Карта успешно изменена!")
+	indexName(domain.KindCard, currentName(cardName, newCardName))
+}
+
+// UpdateCard отправляет запрос на сервер для изменения карты.
+// Пустое новое название оставляет прежнее.
+func UpdateCard(cardName, newCardName, numberCard, expiryDateCard, cvvCard string) error {
+	name := cardName
+	if newCardName != "" {
+		name = newCardName
+	}
+
+	encrypted := make([]string, 0, 3)
+	for i, field := range []string{numberCard, expiryDateCard, cvvCard} {
+		value, err := encryptField(field, recordID("card", name, cardFields[i]))
+		if err != nil {
+			return err
+		}
+		encrypted = append(encrypted, value)
+	}
+
+	return fetchJSON(
+		"/card/update", UpdateCardData{
+			CardName:       cardName,
+			NewCardName:    newCardName,
+			NumberCard:     encrypted[0],
+			ExpiryDateCard: encrypted[1],
+			CvvCard:        encrypted[2],
+		}, nil,
+	)
+}
+
+// deleteCard запрашивает подтверждение и удаляет выбранную карту.
+func deleteCard() {
+	cardName, ok := findItem(domain.KindCard, "Список карт")
+	if !ok || !confirm(fmt.Sprintf("Переместить карту '%s' в корзину?", cardName)) {
+		return
+	}
+
+	if err := fetchJSON("/card/delete", CardData{CardName: cardName}, nil); err != nil {
+		fmt.Println("Ошибка при удалении карты:", err)
+		return
+	}
+	fmt.Println("Карта перемещена в корзину")
+}
+
+// inputOrDefault запрашивает значение поля, пустой ввод оставляет текущее значение.
+func inputOrDefault(field, current string) string {
+	value := getUserInputInfo(fmt.Sprintf("%s [%s] (Enter - оставить): ", field, current))
+	if value == "" {
+		return current
+	}
+	return value
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+)
+
+// chunkChecksumHeader - заголовок с SHA-256 зашифрованной части файла в hex.
+const chunkChecksumHeader = "X-Chunk-Checksum"
+
+// chunkAttempts - количество попыток передать одну часть файла.
+const chunkAttempts = 3
+
+// FileData содержит название файла.
+type FileData struct {
+	FileName string `json:"fileName"` // Название файла
+}
+
+// NewFileData содержит информацию о загружаемом файле.
+type NewFileData struct {
+	FileName string `json:"fileName"` // Название файла
+	Size     int64  `json:"size"`     // Размер исходного файла в байтах
+}
+
+// UploadData содержит идентификатор загрузки.
+type UploadData struct {
+	ID int `json:"id"` // Идентификатор файла
+}
+
+// FileInfo описывает файл и разбиение его содержимого на части.
+type FileInfo struct {
+	ID        int       `json:"id"`        // Идентификатор файла
+	FileName  string    `json:"fileName"`  // Название файла
+	Size      int64     `json:"size"`      // Размер исходного файла в байтах
+	ChunkSize int       `json:"chunkSize"` // Размер исходной части в байтах
+	Chunks    int       `json:"chunks"`    // Количество частей
+	Complete  bool      `json:"complete"`  // Загружены ли все части
+	CreatedAt time.Time `json:"createdAt"` // Время начала загрузки
+}
+
+// UploadSession описывает начатую или возобновленную загрузку файла.
+type UploadSession struct {
+	FileInfo
+	Uploaded []int `json:"uploaded"` // Номера уже загруженных частей
+}
+
+// showFilesMenu выводит меню работы с файлами.
+func showFilesMenu() {
+	fmt.Println("\nФайлы:")
+	fmt.Println("1. Загрузить файл")
+	fmt.Println("2. Скачать файл")
+	fmt.Println("3. Удалить файл")
+	switch getUserInputInfo("Выберите подпункт: ") {
+	case "1":
+		uploadFile()
+	case "2":
+		downloadFile()
+	case "3":
+		deleteFile()
+	default:
+		fmt.Println("Некорректный подпункт меню")
+	}
+}
+
+// uploadFile запрашивает путь к файлу и название, под которым он будет сохранен в хранилище.
+func uploadFile() {
+	path := getUserInputInfo("Введите путь к файлу: ")
+	if path == "" {
+		fmt.Println("Путь не может быть пустым")
+		return
+	}
+	name := inputOrDefault("Введите название файла", filepath.Base(path))
+
+	if err := UploadFile(name, path); err != nil {
+		fmt.Println("Ошибка при загрузке файла:", err)
+		fmt.Println("Повторите загрузку, чтобы продолжить с места остановки.")
+		return
+	}
+	fmt.Println("Файл успешно загружен!")
+	indexName(domain.KindFile, name)
+}
+
+// UploadFile загружает файл на сервер по частям, каждая часть шифруется отдельно.
+// Если загрузка файла с тем же названием и размером была прервана, передаются только недостающие части.
+func UploadFile(name, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	var upload UploadSession
+	if err := fetchJSON("/file/upload", NewFileData{FileName: name, Size: stat.Size()}, &upload); err != nil {
+		return err
+	}
+
+	uploaded := make(map[int]bool, len(upload.Uploaded))
+	for _, index := range upload.Uploaded {
+		uploaded[index] = true
+	}
+	if len(uploaded) > 0 {
+		fmt.Printf("Продолжаем загрузку: %d из %d частей уже на сервере\n", len(uploaded), upload.Chunks)
+	}
+
+	buf := make([]byte, upload.ChunkSize)
+	for index := 0; index < upload.Chunks; index++ {
+		if uploaded[index] {
+			continue
+		}
+		n, err := file.ReadAt(buf, int64(index)*int64(upload.ChunkSize))
+		if err != nil && err != io.EOF {
+			return err
+		}
+		chunk, err := encryptBytes(buf[:n], fileChunkRecord(name, index, upload.Chunks))
+		if err != nil {
+			return err
+		}
+		if err := putChunk(upload.ID, index, chunk); err != nil {
+			return fmt.Errorf("часть %d: %v", index+1, err)
+		}
+		fmt.Printf("\rЗагружено частей: %d/%d", index+1, upload.Chunks)
+	}
+	fmt.Println()
+
+	return fetchJSON("/file/complete", UploadData{ID: upload.ID}, nil)
+}
+
+// putChunk отправляет зашифрованную часть файла, повторяя попытку при сетевой ошибке, сбое сервера
+// или несовпадении контрольной суммы.
+func putChunk(fileID, index int, chunk []byte) error {
+	sum := sha256.Sum256(chunk)
+	header := map[string]string{
+		"Content-Type":      "application/octet-stream",
+		chunkChecksumHeader: hex.EncodeToString(sum[:]),
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < chunkAttempts; attempt++ {
+		resp, err := doAuthorized(http.MethodPut, chunkPath(fileID, index), chunk, header)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode == http.StatusOK {
+			resp.Body.Close()
+			return nil
+		}
+		body := readError(resp)
+		resp.Body.Close()
+
+		lastErr = responseError(resp, body)
+		// Часть, поврежденная при передаче, отправляется заново
+		mismatch := body.Code == domain.CodeValidation && body.Details["field"] == "checksum"
+		if resp.StatusCode < http.StatusInternalServerError && !mismatch {
+			return lastErr
+		}
+	}
+	return lastErr
+}
+
+// downloadFile ищет файл по названию и сохраняет выбранный на диск.
+func downloadFile() {
+	name, ok := findItem(domain.KindFile, "Список файлов")
+	if !ok {
+		fmt.Println("Возвращаемся назад...")
+		return
+	}
+	saveFile(name)
+}
+
+// saveFile запрашивает путь и скачивает в него файл.
+func saveFile(name string) {
+	path := inputOrDefault("Введите путь для сохранения", name)
+	if _, err := os.Stat(path); err == nil && !confirm(fmt.Sprintf("Файл '%s' уже существует. Перезаписать?", path)) {
+		fmt.Println("Скачивание отменено")
+		return
+	}
+
+	if err := DownloadFile(name, path); err != nil {
+		fmt.Println("Ошибка при скачивании файла:", err)
+		fmt.Println("Повторите скачивание, чтобы продолжить с места остановки.")
+		return
+	}
+	fmt.Printf("Файл сохранен в '%s'\n", path)
+}
+
+// DownloadFile скачивает файл по частям и записывает его на диск, не загружая целиком в память.
+// Содержимое пишется во временный файл с суффиксом .part; если он остался от прерванного
+// скачивания, скачивание продолжается с первой незаписанной части.
+func DownloadFile(name, path string) error {
+	var info FileInfo
+	if err := fetchJSON("/file/info", FileData{FileName: name}, &info); err != nil {
+		return err
+	}
+
+	partPath := path + ".part"
+	part, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+
+	stat, err := part.Stat()
+	if err != nil {
+		return err
+	}
+	// Последняя часть могла записаться не полностью, поэтому отбрасываем все после последней целой части
+	first := int(stat.Size() / int64(info.ChunkSize))
+	if first > info.Chunks {
+		first = 0
+	}
+	offset := int64(first) * int64(info.ChunkSize)
+	if err := part.Truncate(offset); err != nil {
+		return err
+	}
+	if first > 0 {
+		fmt.Printf("Продолжаем скачивание: %d из %d частей уже на диске\n", first, info.Chunks)
+	}
+
+	for index := first; index < info.Chunks; index++ {
+		// Разрешение может истечь во время скачивания большого файла
+		if !unlock() {
+			return fmt.Errorf("скачивание прервано: пин-код не подтвержден")
+		}
+		chunk, err := getChunk(info.ID, index)
+		if err != nil {
+			return fmt.Errorf("часть %d: %v", index+1, err)
+		}
+		plaintext, err := decryptBytes(chunk, fileChunkRecord(name, index, info.Chunks))
+		if err != nil {
+			return fmt.Errorf("часть %d: %v", index+1, err)
+		}
+		if _, err := part.WriteAt(plaintext, offset); err != nil {
+			return err
+		}
+		offset += int64(len(plaintext))
+		fmt.Printf("\rСкачано частей: %d/%d", index+1, info.Chunks)
+	}
+	fmt.Println()
+
+	if offset != info.Size {
+		return fmt.Errorf("размер скачанного файла %d не совпадает с ожидаемым %d", offset, info.Size)
+	}
+	if err := part.Sync(); err != nil {
+		return err
+	}
+	if err := part.Close(); err != nil {
+		return err
+	}
+	return os.Rename(partPath, path)
+}
+
+// getChunk скачивает зашифрованную часть файла и сверяет ее контрольную сумму,
+// повторяя попытку при сетевой ошибке, сбое сервера или несовпадении суммы.
+func getChunk(fileID, index int) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; attempt < chunkAttempts; attempt++ {
+		resp, err := doAuthorized(http.MethodGet, chunkPath(fileID, index), nil, nil)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		chunk, err := readChunk(resp)
+		if err == nil {
+			return chunk, nil
+		}
+		lastErr = err
+		if resp.StatusCode != http.StatusOK && resp.StatusCode < http.StatusInternalServerError {
+			return nil, err
+		}
+	}
+	return nil, lastErr
+}
+
+// readChunk читает тело ответа с частью файла и проверяет его контрольную сумму.
+func readChunk(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp)
+	}
+
+	var buf bytes.Buffer
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(&buf, hash), resp.Body); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении ответа: %v", err)
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != resp.Header.Get(chunkChecksumHeader) {
+		return nil, fmt.Errorf("контрольная сумма части не совпадает")
+	}
+	return buf.Bytes(), nil
+}
+
+// deleteFile выводит список файлов и удаляет выбранный после подтверждения.
+func deleteFile() {
+	name, ok := findItem(domain.KindFile, "Список файлов")
+	if !ok {
+		fmt.Println("Возвращаемся назад...")
+		return
+	}
+	if !confirm(fmt.Sprintf("Удалить файл '%s' без возможности восстановления?", name)) {
+		fmt.Println("Удаление отменено")
+		return
+	}
+
+	if err := fetchJSON("/file/delete", FileData{FileName: name}, nil); err != nil {
+		fmt.Println("Ошибка при удалении файла:", err)
+		return
+	}
+	fmt.Println("Файл удален")
+}
+
+// chunkPath формирует путь запроса к части файла.
+func chunkPath(fileID, index int) string {
+	return "/file/chunk?id=" + strconv.Itoa(fileID) + "&index=" + strconv.Itoa(index)
+}
+
+// fileChunkRecord формирует идентификатор записи для части файла.
+// В него входят номер части и их количество, поэтому части нельзя переставить или отбросить незаметно.
+func fileChunkRecord(name string, index, chunks int) string {
+	return recordID("file", name, strconv.Itoa(index), strconv.Itoa(chunks))
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Revision описывает одну ревизию записи хранилища.
+type Revision struct {
+	Revision  int       `json:"revision"`  // Номер ревизии
+	Name      string    `json:"name"`      // Название записи в этой ревизии
+	ChangedBy string    `json:"changedBy"` // Логин пользователя, создавшего ревизию
+	CreatedAt time.Time `json:"createdAt"` // Время создания ревизии
+}
+
+// PasswordRevisionData содержит информацию о запрашиваемой ревизии пароля.
+type PasswordRevisionData struct {
+	PassName string `json:"passName"` // Текущее название пароля
+	Revision int    `json:"revision"` // Номер ревизии
+}
+
+// CardRevisionData содержит информацию о запрашиваемой ревизии карты.
+type CardRevisionData struct {
+	CardName string `json:"cardName"` // Текущее название карты
+	Revision int    `json:"revision"` // Номер ревизии
+}
+
+// PasswordRevision содержит ревизию пароля.
+type PasswordRevision struct {
+	Revision
+	Password string `json:"password"` // Зашифрованный пароль
+}
+
+// CardRevision содержит ревизию карты.
+type CardRevision struct {
+	Revision
+	CardInfo
+}
+
+// showPasswordHistory выводит историю изменений пароля и позволяет восстановить одну из ревизий.
+func showPasswordHistory(passName string) {
+	var revisions []Revision
+	if err := fetchJSON("/password/history", PassData{PassName: passName}, &revisions); err != nil {
+		fmt.Println("Ошибка при получении истории пароля:", err)
+		return
+	}
+
+	revision, ok := chooseRevision(revisions)
+	if !ok || !unlock() {
+		return
+	}
+
+	var passRevision PasswordRevision
+	err := fetchJSON("/password/revision", PasswordRevisionData{PassName: passName, Revision: revision}, &passRevision)
+	if err != nil {
+		fmt.Println("Ошибка при получении ревизии пароля:", err)
+		return
+	}
+	// Ревизия зашифрована под названием, которое запись имела в момент изменения
+	password, err := decryptField(passRevision.Password, recordID("password", passRevision.Name))
+	if err != nil {
+		fmt.Println("Ошибка при расшифровке ревизии:", err)
+		return
+	}
+	fmt.Printf("Ревизия %d пароля '%s': %s\n", passRevision.Revision.Revision, passRevision.Name, password)
+
+	if !confirm("Сделать эту ревизию текущей?") {
+		return
+	}
+	err = fetchJSON("/password/restore", PasswordRevisionData{PassName: passName, Revision: revision}, nil)
+	if err != nil {
+		fmt.Println("Ошибка при восстановлении пароля:", err)
+		return
+	}
+	fmt.Println("Пароль восстановлен")
+}
+
+// showCardHistory выводит историю изменений карты и позволяет восстановить одну из ревизий.
+func showCardHistory(cardName string) {
+	var revisions []Revision
+	if err := fetchJSON("/card/history", CardData{CardName: cardName}, &revisions); err != nil {
+		fmt.Println("Ошибка при получении истории карты:", err)
+		return
+	}
+
+	revision, ok := chooseRevision(revisions)
+	if !ok || !unlock() {
+		return
+	}
+
+	var cardRevision CardRevision
+	err := fetchJSON("/card/revision", CardRevisionData{CardName: cardName, Revision: revision}, &cardRevision)
+	if err != nil {
+		fmt.Println("Ошибка при получении ревизии карты:", err)
+		return
+	}
+	fields := []string{cardRevision.Number, cardRevision.ExpiryDate, cardRevision.CVV}
+	for i, field := range fields {
+		fields[i], err = decryptField(field, recordID("card", cardRevision.Name, cardFields[i]))
+		if err != nil {
+			fmt.Println("Ошибка при расшифровке ревизии:", err)
+			return
+		}
+	}
+	fmt.Printf("Ревизия %d карты '%s':\n", cardRevision.Revision.Revision, cardRevision.Name)
+	fmt.Printf("Номер карты: %s\n", fields[0])
+	fmt.Printf("Срок действия: %s\n", fields[1])
+	fmt.Printf("CVV: %s\n", fields[2])
+
+	if !confirm("Сделать эту ревизию текущей?") {
+		return
+	}
+	err = fetchJSON("/card/restore", CardRevisionData{CardName: cardName, Revision: revision}, nil)
+	if err != nil {
+		fmt.Println("Ошибка при восстановлении карты:", err)
+		return
+	}
+	fmt.Println("Карта восстановлена")
+}
+
+// chooseRevision выводит список ревизий и возвращает номер выбранной.
+// Второе значение равно false, если пользователь решил вернуться назад.
+func chooseRevision(revisions []Revision) (int, bool) {
+	for {
+		fmt.Println("\nИстория изменений:")
+		for i, revision := range revisions {
+			fmt.Printf(
+				"%d. Ревизия %d от %s, %s ('%s')\n", i+1, revision.Revision,
+				revision.CreatedAt.Local().Format("02.01.2006 15:04"), revision.ChangedBy, revision.Name,
+			)
+		}
+		fmt.Println("0. Вернуться назад")
+
+		choice, err := strconv.Atoi(getUserInputInfo("Выберите номер: "))
+		switch {
+		case err != nil:
+			fmt.Println("Ошибка при чтении ввода:", err)
+		case choice == 0:
+			return 0, false
+		case choice < 1 || choice > len(revisions):
+			fmt.Println("Некорректный номер")
+		default:
+			return revisions[choice-1].Revision, true
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/egosha7/goph-keeper/internal/blindindex"
+	"github.com/egosha7/goph-keeper/internal/domain"
+)
+
+// reindexPageSize - количество элементов, индексируемых за один запрос списка.
+const reindexPageSize = 100
+
+// indexName обновляет на сервере слепой индекс названия элемента.
+// Ошибка не прерывает операцию, ради которой индекс обновлялся: индекс можно перестроить позже.
+func indexName(kind, name string) {
+	terms := blindindex.NameTerms(session.IndexKey, name)
+	if err := SetBlindIndex(kind, name, domain.IndexName, terms); err != nil {
+		fmt.Println("Не удалось обновить поисковый индекс:", err)
+	}
+}
+
+// indexDomains обновляет на сервере слепой индекс доменов сайтов пароля.
+// Регулярные выражения не индексируются: домен из них не извлечь.
+func indexDomains(passName string, urls []domain.LoginURL) {
+	var addresses []string
+	for _, u := range urls {
+		if u.Match != domain.MatchRegex {
+			addresses = append(addresses, u.URL)
+		}
+	}
+	terms := blindindex.DomainTerms(session.IndexKey, addresses...)
+	if err := SetBlindIndex(domain.KindPassword, passName, domain.IndexDomain, terms); err != nil {
+		fmt.Println("Не удалось обновить поисковый индекс:", err)
+	}
+}
+
+// SetBlindIndex отправляет на сервер термы слепого индекса поля элемента.
+func SetBlindIndex(kind, name, field string, terms []string) error {
+	data := domain.BlindIndexData{Kind: kind, Name: name, Field: field, Terms: terms}
+	return fetchJSON("/index/set", data, nil)
+}
+
+// currentName возвращает название элемента после изменения: пустое новое название оставляет прежнее.
+func currentName(name, newName string) string {
+	if newName == "" {
+		return name
+	}
+	return newName
+}
+
+// reindexVault заново вычисляет слепые индексы всех элементов хранилища.
+// Нужен для элементов, добавленных до появления слепого поиска или с другого клиента.
+func reindexVault() {
+	query := domain.SearchQuery{Limit: reindexPageSize}
+	var indexed int
+	for {
+		var result domain.SearchResult
+		if err := fetchJSON("/search", query, &result); err != nil {
+			fmt.Println("Ошибка при получении списка элементов:", err)
+			return
+		}
+		for _, item := range result.Items {
+			terms := blindindex.NameTerms(session.IndexKey, item.Name)
+			if err := SetBlindIndex(item.Kind, item.Name, domain.IndexName, terms); err != nil {
+				fmt.Printf("Не удалось обновить индекс '%s': %v\n", item.Name, err)
+				continue
+			}
+			indexed++
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		query.Cursor = result.NextCursor
+	}
+
+	passwords, err := fetchPasswordURLs()
+	if err != nil {
+		fmt.Println("Ошибка при получении адресов сайтов:", err)
+		return
+	}
+	for _, password := range passwords {
+		indexDomains(password.PassName, password.URLs)
+	}
+	fmt.Printf("Поисковый индекс обновлен, элементов: %d\n", indexed)
+}
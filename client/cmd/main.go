@@ -3,8 +3,13 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/egosha7/goph-keeper/internal/blindindex"
+	"github.com/egosha7/goph-keeper/internal/crypt"
+	"github.com/egosha7/goph-keeper/internal/domain"
 	"github.com/egosha7/goph-keeper/internal/style"
 	"golang.org/x/crypto/ssh/terminal"
 	"io/ioutil"
@@ -13,8 +18,10 @@ import (
 	"os/signal"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 )
 
 var (
@@ -24,26 +31,18 @@ var (
 	BuildDate = "unknown"
 )
 
-// UserInfo представляет информацию о пользователе.
-type UserInfo struct {
-	Login string `json:"login"` // Логин пользователя
-}
-
-// PinData содержит информацию о пользователе и пин-коде.
+// PinData содержит пин-код пользователя.
 type PinData struct {
-	Login string `json:"login"` // Логин пользователя
-	Pin   string `json:"pin"`   // Пин-код пользователя
+	Pin string `json:"pin"` // Пин-код пользователя
 }
 
-// PassData содержит информацию о пользователе и названии пароля.
+// PassData содержит название пароля.
 type PassData struct {
-	Login    string `json:"login"`    // Логин пользователя
 	PassName string `json:"passName"` // Название пароля
 }
 
-// CardData содержит информацию о пользователе и названии карты.
+// CardData содержит название карты.
 type CardData struct {
-	Login    string `json:"login"`    // Логин пользователя
 	CardName string `json:"cardName"` // Название карты
 }
 
@@ -56,22 +55,28 @@ type CardInfo struct {
 
 // PasswordData содержит информацию о новом пароле.
 type PasswordData struct {
-	Login    string `json:"login"`    // Логин пользователя
 	PassName string `json:"passName"` // Название нового пароля
 	Password string `json:"password"` // Новый пароль
 }
 
 // NewCardData содержит информацию о новой карте.
 type NewCardData struct {
-	Login          string `json:"login"`          // Логин пользователя
 	CardName       string `json:"cardName"`       // Название новой карты
 	NumberCard     string `json:"numberCard"`     // Номер новой карты
 	ExpiryDateCard string `json:"expiryDateCard"` // Срок новой карты
 	CvvCard        string `json:"CvvCard"`        // Секретный код новой карты
 }
 
+// cardFields - названия зашифрованных полей карты в порядке номер, срок, CVV.
+var cardFields = []string{"number", "expiry", "cvv"}
+
 // Функция с которой начинается работа программы
 func main() {
+	if err := setupTransport(); err != nil {
+		fmt.Println("Ошибка настройки подключения к серверу:", err)
+		os.Exit(1)
+	}
+
 	// Вывод информации о версии и дате сборки
 	fmt.Println(string(style.ColorGreen), style.Name, string(style.ColorReset))
 	showStartMenu()
@@ -129,47 +134,92 @@ func showStartMenu() {
 }
 
 // showMenu отображает главное меню приложения и обрабатывает выбор пользователя.
-// Параметр login представляет логин текущего пользователя.
-func showMenu(login string) {
+func showMenu() {
 	for {
 		// Выводим меню
 		fmt.Println("\nМеню:")
 		fmt.Println("1. Просмотреть данные")
 		fmt.Println("2. Внести новый пароль")
 		fmt.Println("3. Внести новую карту")
+		fmt.Println("4. Изменить пароль")
+		fmt.Println("5. Удалить пароль")
+		fmt.Println("6. Изменить карту")
+		fmt.Println("7. Удалить карту")
+		fmt.Println("8. Корзина")
+		fmt.Println("9. Заметки")
+		fmt.Println("10. Файлы")
+		fmt.Println("11. Записи")
+		fmt.Println("12. Теги и папки")
+		fmt.Println("13. Поиск")
+		fmt.Println("14. Обновить поисковый индекс")
+		fmt.Println("15. Сайты")
+		fmt.Println("16. Одноразовые пароли")
+		fmt.Println("17. Двухфакторная аутентификация")
+		fmt.Println("18. Следить за изменениями")
 		fmt.Println("0. Выйти")
 
 		// Получаем выбор пользователя
-		fmt.Print("Выберите пункт меню: ")
-		reader := bufio.NewReader(os.Stdin)
-		choice, _, err := reader.ReadRune()
-		if err != nil {
-			fmt.Println("Ошибка при чтении ввода:", err)
-			continue
-		}
+		choice := getUserInputInfo("Выберите пункт меню: ")
 
 		// Обрабатываем выбор пользователя
 		switch choice {
-		case '1':
+		case "1":
 			fmt.Println("\nПросмотр данных:")
 			fmt.Println("1. Пароль")
 			fmt.Println("2. Карта")
+			fmt.Println("3. Заметка")
 			subChoice := getUserInputInfo("Выберите подпункт: ")
 			switch subChoice {
 			case "1":
-				viewPasswordsName(login)
+				viewPasswordsName()
 			case "2":
-				viewCardsName(login)
+				viewCardsName()
+			case "3":
+				viewNotesName()
 			default:
 				fmt.Println("Некорректный подпункт меню")
 			}
-		case '2':
+		case "2":
 			fmt.Println("Внести новый пароль")
-			addNewPassword(login)
-		case '3':
+			addNewPassword()
+		case "3":
 			fmt.Println("Внести новую карту")
-			addNewCard(login)
-		case '0':
+			addNewCard()
+		case "4":
+			fmt.Println("Изменить пароль")
+			editPassword()
+		case "5":
+			fmt.Println("Удалить пароль")
+			deletePassword()
+		case "6":
+			fmt.Println("Изменить карту")
+			editCard()
+		case "7":
+			fmt.Println("Удалить карту")
+			deleteCard()
+		case "8":
+			showTrash()
+		case "9":
+			showNotesMenu()
+		case "10":
+			showFilesMenu()
+		case "11":
+			showRecordsMenu()
+		case "12":
+			showOrganizeMenu()
+		case "13":
+			showSearch()
+		case "14":
+			reindexVault()
+		case "15":
+			showSitesMenu()
+		case "16":
+			showOTPMenu()
+		case "17":
+			showSecondFactorMenu()
+		case "18":
+			watchChanges()
+		case "0":
 			fmt.Println("До свидания!")
 			return
 		default:
@@ -179,8 +229,7 @@ func showMenu(login string) {
 }
 
 // addNewCard запрашивает у пользователя информацию о новой банковской карте и отправляет ее на сервер для добавления.
-// Параметр login представляет логин текущего пользователя.
-func addNewCard(login string) {
+func addNewCard() {
 	// Получаем информацию о новой карте от пользователя
 	reader := bufio.NewReader(os.Stdin)
 
@@ -213,7 +262,7 @@ func addNewCard(login string) {
 	}
 
 	// Отправляем данные новой карты на сервер
-	success, err := AddNewCard(login, cardName, numberCard, expiryDateCard, CvvCard)
+	success, err := AddNewCard(cardName, numberCard, expiryDateCard, CvvCard)
 	if err != nil {
 		fmt.Println("Ошибка при добавлении карты:", err)
 		return
@@ -221,43 +270,50 @@ func addNewCard(login string) {
 
 	if success {
 		fmt.Println("Новая карта успешно добавлена!")
+		indexName(domain.KindCard, cardName)
+		if confirm("Указать метаданные, теги и папку?") {
+			updateItemMeta(cardItems, cardName, domain.ItemMeta{})
+		}
 	} else {
 		fmt.Println("Не удалось добавить новую карту.")
 	}
 }
 
-func AddNewCard(login, cardName, numberCard, expiryDateCard, CvvCard string) (bool, error) {
-	// Создаем JSON-объект с информацией о пользователе и новом пароле
-	passwordData := NewCardData{
-		Login:          login,
-		CardName:       cardName,
-		NumberCard:     numberCard,
-		ExpiryDateCard: expiryDateCard,
-		CvvCard:        CvvCard,
+func AddNewCard(cardName, numberCard, expiryDateCard, CvvCard string) (bool, error) {
+	// Шифруем реквизиты карты мастер-ключом, сервер получает только шифротекст
+	encrypted := make([]string, 0, 3)
+	for i, field := range []string{numberCard, expiryDateCard, CvvCard} {
+		value, err := encryptField(field, recordID("card", cardName, cardFields[i]))
+		if err != nil {
+			return false, err
+		}
+		encrypted = append(encrypted, value)
 	}
 
-	// Преобразуем информацию в JSON
-	jsonData, err := json.Marshal(passwordData)
-	if err != nil {
-		return false, fmt.Errorf("ошибка при кодировании JSON: %v", err)
+	// Создаем JSON-объект с информацией о новой карте
+	passwordData := NewCardData{
+		CardName:       cardName,
+		NumberCard:     encrypted[0],
+		ExpiryDateCard: encrypted[1],
+		CvvCard:        encrypted[2],
 	}
 
-	// Отправляем POST-запрос на сервер для добавления нового пароля
-	resp, err := http.Post("http://localhost:8080/card/add", "application/json", bytes.NewBuffer(jsonData))
+	// Отправляем POST-запрос на сервер для добавления новой карты
+	resp, err := postJSON("/card/add", passwordData)
 	if err != nil {
-		return false, fmt.Errorf("ошибка при выполнении запроса: %v", err)
+		return false, err
 	}
 	defer resp.Body.Close()
 
 	// Проверяем статус ответа
 	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("ошибка: сервер вернул статус %s", resp.Status)
+		return false, statusError(resp)
 	}
 
 	return true, nil
 }
 
-func addNewPassword(login string) {
+func addNewPassword() {
 	// Получаем информацию о новом пароле от пользователя
 	reader := bufio.NewReader(os.Stdin)
 
@@ -276,7 +332,7 @@ func addNewPassword(login string) {
 	}
 
 	// Отправляем новый пароль на сервер
-	success, err := AddPassword(login, passName, password)
+	success, err := AddPassword(passName, password)
 	if err != nil {
 		fmt.Println("Ошибка при добавлении нового пароля:", err)
 		return
@@ -284,167 +340,99 @@ func addNewPassword(login string) {
 
 	if success {
 		fmt.Println("Новый пароль успешно добавлен!")
+		indexName(domain.KindPassword, passName)
+		if confirm("Указать метаданные, теги и папку?") {
+			updateItemMeta(passwordItems, passName, domain.ItemMeta{})
+		}
+		if confirm("Указать адреса сайтов?") {
+			editPasswordURLs(passName)
+		}
 	} else {
 		fmt.Println("Не удалось добавить новый пароль.")
 	}
 }
 
 // AddPassword отправляет запрос на сервер для добавления нового пароля.
-// Параметр login представляет логин пользователя.
 // Параметр name представляет название нового пароля.
 // Параметр password представляет сам пароль.
 // Функция возвращает true, если операция добавления прошла успешно, иначе возвращает ошибку.
-func AddPassword(login, name, password string) (bool, error) {
-	// Создаем JSON-объект с информацией о пользователе и новом пароле
-	passwordData := PasswordData{
-		Login:    login,
-		PassName: name,
-		Password: password,
+func AddPassword(name, password string) (bool, error) {
+	// Шифруем пароль мастер-ключом, сервер получает только шифротекст
+	encrypted, err := encryptField(password, recordID("password", name))
+	if err != nil {
+		return false, err
 	}
 
-	// Преобразуем информацию в JSON
-	jsonData, err := json.Marshal(passwordData)
-	if err != nil {
-		return false, fmt.Errorf("ошибка при кодировании JSON: %v", err)
+	// Создаем JSON-объект с информацией о новом пароле
+	passwordData := PasswordData{
+		PassName: name,
+		Password: encrypted,
 	}
 
 	// Отправляем POST-запрос на сервер для добавления нового пароля
-	resp, err := http.Post("http://localhost:8080/password/add", "application/json", bytes.NewBuffer(jsonData))
+	resp, err := postJSON("/password/add", passwordData)
 	if err != nil {
-		return false, fmt.Errorf("ошибка при выполнении запроса: %v", err)
+		return false, err
 	}
 	defer resp.Body.Close()
 
 	// Проверяем статус ответа
 	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("ошибка: сервер вернул статус %s", resp.Status)
+		return false, statusError(resp)
 	}
 
 	return true, nil
 }
 
-// viewCardsName отправляет запрос на сервер для получения списка названий карт пользователя и выводит их на экран.
-// Параметр login представляет логин пользователя.
-func viewCardsName(login string) {
-	// Создаем JSON-объект с информацией о пользователе
-	userInfo := UserInfo{
-		Login: login,
-	}
-
-	// Преобразуем информацию о пользователе в JSON
-	jsonData, err := json.Marshal(userInfo)
-	if err != nil {
-		fmt.Println("Ошибка при кодировании JSON:", err)
+// viewCardsName ищет карту по названию и показывает выбранную.
+func viewCardsName() {
+	cardName, ok := findItem(domain.KindCard, "Список карт")
+	if !ok {
+		fmt.Println("Возвращаемся назад...")
 		return
 	}
+	showCard(cardName)
+}
 
-	// Отправляем POST-запрос на сервер
-	resp, err := http.Post("http://localhost:8080/card/namelist", "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		fmt.Println("Ошибка при выполнении запроса:", err)
-		return
-	}
-	defer resp.Body.Close()
-
-	// Проверяем статус ответа
-	if resp.StatusCode != http.StatusOK {
-		fmt.Println("Ошибка: сервер вернул статус", resp.Status)
+// showCard выводит реквизиты карты после проверки пин-кода.
+func showCard(cardName string) {
+	if !unlock() {
 		return
 	}
 
-	// Создаем срез строк для хранения списка карт
-	var namecards []string
-	err = json.NewDecoder(resp.Body).Decode(&namecards)
+	cardNumber, cardExpiry, cardCVV, err := GetCard(cardName)
 	if err != nil {
-		fmt.Println("Ошибка при декодировании JSON:", err)
+		fmt.Println("Ошибка при получении карты:", err)
 		return
 	}
-
-	for {
-		// Выводим список карт
-		fmt.Println("\nСписок кард:")
-		for i, cards := range namecards {
-			fmt.Printf("%d. %s\n", i+1, cards)
-		}
-		fmt.Println("0. Вернуться назад")
-
-		// Получаем выбор пользователя
-		fmt.Print("Выберите номер карты: ")
-		var choice int
-		_, err := fmt.Scanln(&choice)
-		if err != nil {
-			fmt.Println("Ошибка при чтении ввода:", err)
-			continue
-		}
-
-		// Обрабатываем выбор пользователя
-		if choice == 0 {
-			fmt.Println("Возвращаемся назад...")
-			return
-		} else if choice < 1 || choice > len(namecards) {
-			fmt.Println("Некорректный номер карты")
-			continue
-		}
-
-		// Получаем пин-код от пользователя
-		fmt.Print("Введите пин-код: ")
-		var pinCode string
-		_, err = fmt.Scanln(&pinCode)
-		if err != nil {
-			fmt.Println("Ошибка при чтении ввода пин-кода:", err)
-			continue
-		}
-
-		// Проверяем пин-код на сервере
-		valid, err := checkPinCode(login, pinCode)
-		if err != nil {
-			fmt.Println("Ошибка при проверке пин-кода:", err)
-			continue
-		}
-
-		// Если пин-код валиден, выводим данные о карте
-		if valid {
-			// Выводим выбранную карту
-			selectedNameCard := namecards[choice-1]
-			cardNumber, cardExpiry, cardCVV, _ := GetCard(login, selectedNameCard)
-			fmt.Printf("Данные от карты '%s':\n", selectedNameCard)
-			fmt.Printf("Номер карты: %s\n", cardNumber)
-			fmt.Printf("Срок действия: %s\n", cardExpiry)
-			fmt.Printf("CVV: %s\n", cardCVV)
-			return
-		} else {
-			fmt.Println("Неверный пин-код")
-		}
+	fmt.Printf("Данные от карты '%s':\n", cardName)
+	fmt.Printf("Номер карты: %s\n", cardNumber)
+	fmt.Printf("Срок действия: %s\n", cardExpiry)
+	fmt.Printf("CVV: %s\n", cardCVV)
+	if confirm("Показать историю изменений?") {
+		showCardHistory(cardName)
 	}
 }
 
 // GetCard отправляет запрос на сервер для получения данных о карте пользователя.
-// Параметр login представляет логин пользователя.
 // Параметр selectedNameCard представляет название выбранной карты.
 // Возвращает номер карты, срок действия и CVV карты, а также ошибку, если таковая возникла.
-func GetCard(login, selectedNameCard string) (string, string, string, error) {
-	// Создаем JSON-объект с информацией о пользователе и названии карты
+func GetCard(selectedNameCard string) (string, string, string, error) {
+	// Создаем JSON-объект с названием карты
 	cardData := CardData{
-		Login:    login,
 		CardName: selectedNameCard,
 	}
 
-	// Преобразуем информацию о пользователе и названии карты в JSON
-	jsonData, err := json.Marshal(cardData)
-	if err != nil {
-		return "", "", "", fmt.Errorf("ошибка при кодировании JSON: %v", err)
-	}
-
 	// Отправляем POST-запрос на сервер для получения данных о карте
-	resp, err := http.Post("http://localhost:8080/card/get", "application/json", bytes.NewBuffer(jsonData))
+	resp, err := postJSON("/card/get", cardData)
 	if err != nil {
-		return "", "", "", fmt.Errorf("ошибка при выполнении запроса: %v", err)
+		return "", "", "", err
 	}
 	defer resp.Body.Close()
 
 	// Проверяем статус ответа
 	if resp.StatusCode != http.StatusOK {
-		return "", "", "", fmt.Errorf("ошибка: сервер вернул статус %s", resp.Status)
+		return "", "", "", statusError(resp)
 	}
 
 	// Декодируем ответ в структуру CardInfo
@@ -453,174 +441,157 @@ func GetCard(login, selectedNameCard string) (string, string, string, error) {
 		return "", "", "", fmt.Errorf("ошибка при декодировании JSON: %v", err)
 	}
 
-	// Возвращаем информацию о карте
-	return cardInfo.Number, cardInfo.ExpiryDate, cardInfo.CVV, nil
-}
-
-// viewPasswordsName отправляет запрос на сервер для получения списка названий паролей пользователя и их отображения.
-// Параметр login представляет логин пользователя.
-func viewPasswordsName(login string) {
-	// Создаем JSON-объект с информацией о пользователе
-	userInfo := UserInfo{
-		Login: login,
+	// Расшифровываем реквизиты карты мастер-ключом
+	decrypted := make([]string, 0, 3)
+	for i, field := range []string{cardInfo.Number, cardInfo.ExpiryDate, cardInfo.CVV} {
+		value, err := decryptField(field, recordID("card", selectedNameCard, cardFields[i]))
+		if err != nil {
+			return "", "", "", err
+		}
+		decrypted = append(decrypted, value)
 	}
 
-	// Преобразуем информацию о пользователе в JSON
-	jsonData, err := json.Marshal(userInfo)
-	if err != nil {
-		fmt.Println("Ошибка при кодировании JSON:", err)
-		return
-	}
+	// Возвращаем информацию о карте
+	return decrypted[0], decrypted[1], decrypted[2], nil
+}
 
-	// Отправляем POST-запрос на сервер
-	resp, err := http.Post("http://localhost:8080/pass/namelist", "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		fmt.Println("Ошибка при выполнении запроса:", err)
+// viewPasswordsName ищет пароль по названию и показывает выбранный.
+func viewPasswordsName() {
+	passName, ok := findItem(domain.KindPassword, "Список паролей")
+	if !ok {
+		fmt.Println("Возвращаемся назад...")
 		return
 	}
-	defer resp.Body.Close()
+	showPassword(passName)
+}
 
-	// Проверяем статус ответа
-	if resp.StatusCode != http.StatusOK {
-		fmt.Println("Ошибка: сервер вернул статус", resp.Status)
+// showPassword выводит пароль после проверки пин-кода.
+func showPassword(passName string) {
+	if !unlock() {
 		return
 	}
 
-	// Создаем срез строк для хранения списка паролей
-	var namepasswords []string
-	err = json.NewDecoder(resp.Body).Decode(&namepasswords)
+	pass, uri, err := GetPasswordEntry(passName)
 	if err != nil {
-		fmt.Println("Ошибка при декодировании JSON:", err)
+		fmt.Println("Ошибка при получении пароля:", err)
 		return
 	}
-
-	for {
-		// Выводим список паролей
-		fmt.Println("\nСписок паролей:")
-		for i, password := range namepasswords {
-			fmt.Printf("%d. %s\n", i+1, password)
-		}
-		fmt.Println("0. Вернуться назад")
-
-		// Получаем выбор пользователя
-		fmt.Print("Выберите номер пароля: ")
-		var choice int
-		_, err := fmt.Scanln(&choice)
-		if err != nil {
-			fmt.Println("Ошибка при чтении ввода:", err)
-			continue
-		}
-
-		// Обрабатываем выбор пользователя
-		if choice == 0 {
-			fmt.Println("Возвращаемся назад...")
-			return
-		} else if choice < 1 || choice > len(namepasswords) {
-			fmt.Println("Некорректный номер пароля")
-			continue
-		}
-
-		// Получаем пин-код от пользователя
-		fmt.Print("Введите пин-код: ")
-		var pinCode string
-		_, err = fmt.Scanln(&pinCode)
-		if err != nil {
-			fmt.Println("Ошибка при чтении ввода пин-кода:", err)
-			continue
-		}
-
-		// Проверяем пин-код на сервере
-		valid, err := checkPinCode(login, pinCode)
-		if err != nil {
-			fmt.Println("Ошибка при проверке пин-кода:", err)
-			continue
-		}
-
-		// Если пин-код валиден, выводим пароль
-		if valid {
-			// Выводим выбранный пароль
-			selectedNamePassword := namepasswords[choice-1]
-			fmt.Printf("Выбор: %s\n", selectedNamePassword)
-			pass, _ := GetPassword(login, selectedNamePassword)
-			fmt.Printf("Выбранный пароль: %s\n", pass)
-			return
-		} else {
-			fmt.Println("Неверный пин-код")
-		}
+	fmt.Printf("Выбор: %s\n", passName)
+	fmt.Printf("Выбранный пароль: %s\n", pass)
+	if uri != "" {
+		showPasswordCode(passName, uri)
+	}
+	if confirm("Показать историю изменений?") {
+		showPasswordHistory(passName)
 	}
 }
 
 // GetPassword отправляет запрос на сервер для получения пароля пользователя по выбранному названию.
-// Параметр login представляет логин пользователя.
 // Параметр selectedNamePassword представляет название выбранного пароля.
 // Возвращает пароль и ошибку, если таковая возникла.
-func GetPassword(login, selectedNamePassword string) (string, error) {
-	// Создаем JSON-объект с информацией о пользователе и пин-коде
+func GetPassword(selectedNamePassword string) (string, error) {
+	// Создаем JSON-объект с названием пароля
 	passData := PassData{
-		Login:    login,
 		PassName: selectedNamePassword,
 	}
 
-	// Преобразуем информацию о пользователе и пин-коде в JSON
-	jsonData, err := json.Marshal(passData)
-	if err != nil {
-		return "", fmt.Errorf("ошибка при кодировании JSON: %v", err)
-	}
-
-	// Отправляем POST-запрос на сервер для проверки пин-кода
-	resp, err := http.Post("http://localhost:8080/password/get", "application/json", bytes.NewBuffer(jsonData))
+	// Отправляем POST-запрос на сервер для получения пароля
+	resp, err := postJSON("/password/get", passData)
 	if err != nil {
-		return "", fmt.Errorf("ошибка при выполнении запроса: %v", err)
+		return "", err
 	}
 	defer resp.Body.Close()
 
 	// Проверяем статус ответа
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("ошибка: сервер вернул статус %s", resp.Status)
+		return "", statusError(resp)
 	}
 
-	// Читаем ответ и возвращаем полученный пароль
+	// Читаем ответ и расшифровываем полученный пароль
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return "", fmt.Errorf("ошибка при чтении тела ответа: %v", err)
 	}
 
-	return string(body), nil
+	return decryptField(string(body), recordID("password", selectedNamePassword))
+}
+
+// unlockMargin - запас до истечения разрешения, после которого пин-код запрашивается заново,
+// чтобы разрешение не истекло между проверкой и запросом секрета.
+const unlockMargin = 5 * time.Second
+
+// unlock запрашивает пин-код и проверяет его на сервере, пока он не окажется верным.
+// Пока действует выданное сервером разрешение, пин-код повторно не запрашивается.
+// Пустой ввод отменяет проверку. Возвращает true, если пин-код подтвержден.
+func unlock() bool {
+	if time.Now().Add(unlockMargin).Before(session.Unlock.ExpiresAt) {
+		return true
+	}
+	for {
+		pinCode := getUserInputInfo("Введите пин-код (Enter - отмена): ")
+		if pinCode == "" {
+			return false
+		}
+		valid, err := checkPinCode(pinCode)
+		var locked *pinLockedError
+		switch {
+		case errors.As(err, &locked):
+			fmt.Println(locked.Error())
+			return false
+		case err != nil:
+			fmt.Println("Ошибка при проверке пин-кода:", err)
+		case !valid:
+			fmt.Println("Неверный пин-код")
+		default:
+			return true
+		}
+	}
+}
+
+// pinLockedError возвращается, если сервер временно заблокировал проверку пин-кода после неудачных попыток.
+type pinLockedError struct {
+	retryAfter time.Duration // Через сколько можно повторить попытку
+}
+
+// Error возвращает описание ошибки.
+func (e *pinLockedError) Error() string {
+	return fmt.Sprintf("слишком много неудачных попыток, проверка пин-кода заблокирована на %s", e.retryAfter)
 }
 
 // checkPinCode отправляет запрос на сервер для проверки пин-кода пользователя.
-// Параметр login представляет логин пользователя.
 // Параметр pinCode представляет введенный пин-код.
 // Возвращает true, если пин-код верен, и ошибку, если таковая возникла.
-func checkPinCode(login, pinCode string) (bool, error) {
-	// Создаем JSON-объект с информацией о пользователе и пин-коде
+// Выданное сервером разрешение на чтение секретов сохраняется в сеансе.
+// Если проверка заблокирована, возвращает *pinLockedError.
+func checkPinCode(pinCode string) (bool, error) {
+	// Создаем JSON-объект с пин-кодом
 	pinData := PinData{
-		Login: login,
-		Pin:   pinCode,
-	}
-
-	// Преобразуем информацию о пользователе и пин-коде в JSON
-	jsonData, err := json.Marshal(pinData)
-	if err != nil {
-		return false, fmt.Errorf("ошибка при кодировании JSON: %v", err)
+		Pin: pinCode,
 	}
 
 	// Отправляем POST-запрос на сервер для проверки пин-кода
-	resp, err := http.Post("http://localhost:8080/pincheck", "application/json", bytes.NewBuffer(jsonData))
+	resp, err := postJSON("/pincheck", pinData)
 	if err != nil {
-		return false, fmt.Errorf("ошибка при выполнении запроса: %v", err)
+		return false, err
 	}
 	defer resp.Body.Close()
 
 	// Проверяем статус ответа
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusUnauthorized {
-			return false, fmt.Errorf("неверный пин-код")
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var grant domain.UnlockGrant
+		if err := json.NewDecoder(resp.Body).Decode(&grant); err != nil {
+			return false, fmt.Errorf("ошибка при декодировании JSON: %v", err)
 		}
-		return false, fmt.Errorf("Oшибка: сервер вернул статус %s", resp.Status)
-	}
-
-	return true, nil
+		session.Unlock = grant
+		return true, nil
+	case http.StatusForbidden:
+		return false, nil
+	case http.StatusLocked:
+		seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return false, &pinLockedError{retryAfter: time.Duration(seconds) * time.Second}
+	}
+	return false, statusError(resp)
 }
 
 // registerUser регистрирует нового пользователя.
@@ -651,11 +622,31 @@ func registerUser() {
 	fmt.Println("Введите ваш новый пин-код:")
 	pin := getUserInput()
 
+	// Мастер-пароль не передается на сервер: из него клиент получает ключ шифрования данных
+	fmt.Println("Придумайте мастер-пароль для шифрования данных:")
+	masterPassword1 := getHiddenUserInput()
+
+	fmt.Println("Повторите мастер-пароль:")
+	masterPassword2 := getHiddenUserInput()
+
+	if masterPassword1 != masterPassword2 {
+		fmt.Println("Мастер-пароли не совпадают. Пожалуйста, попробуйте снова.")
+		registerUser()
+		return
+	}
+
+	salt, err := crypt.NewSalt()
+	if err != nil {
+		fmt.Println("Ошибка при генерации соли:", err)
+		return
+	}
+
 	// Создаем данные для отправки в формате JSON
 	data := map[string]string{
 		"login":    email,
 		"password": password2,
 		"pin":      pin,
+		"salt":     base64.StdEncoding.EncodeToString(salt),
 	}
 
 	// Кодируем данные в формат JSON
@@ -666,7 +657,7 @@ func registerUser() {
 	}
 
 	// Отправляем POST запрос на сервер
-	resp, err := http.Post("http://localhost:8080/auth/registration", "application/json", bytes.NewBuffer(jsonData))
+	resp, err := httpClient.Post(serverURL+"/auth/registration", "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
 		fmt.Println("Ошибка при отправке запроса:", err)
 		return
@@ -675,14 +666,21 @@ func registerUser() {
 
 	// Проверяем код статуса ответа
 	if resp.StatusCode == http.StatusOK {
+		if err := startSession(email, resp); err != nil {
+			fmt.Println("Ошибка при регистрации пользователя:", err)
+			return
+		}
+		session.MasterKey = crypt.DeriveKey(masterPassword2, salt)
+		session.IndexKey = blindindex.DeriveKey(session.MasterKey)
 		fmt.Println("Пользователь успешно зарегистрирован")
-		showMenu(email)
+		showMenu()
 	} else if resp.StatusCode != http.StatusOK {
 		if resp.StatusCode == http.StatusConflict {
 			fmt.Println("Ошибка при регистрации пользователя: данный логин уже зарегистрирован")
 			registerUser()
+			return
 		}
-		fmt.Println("Ошибка при регистрации пользователя:", resp.Status)
+		fmt.Println("Ошибка при регистрации пользователя:", statusError(resp))
 		registerUser()
 	}
 }
@@ -712,7 +710,7 @@ func loginUser() {
 	}
 
 	// Отправляем POST запрос на сервер
-	resp, err := http.Post("http://localhost:8080/auth", "application/json", bytes.NewBuffer(jsonData))
+	resp, err := httpClient.Post(serverURL+"/auth", "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
 		fmt.Println("Ошибка при отправке запроса:", err)
 		return
@@ -721,8 +719,18 @@ func loginUser() {
 
 	// Проверяем код статуса ответа
 	if resp.StatusCode == http.StatusOK {
+		if err := completeLogin(email, resp); err != nil {
+			fmt.Println("Ошибка при авторизации пользователя:", err)
+			return
+		}
+
+		fmt.Println("Введите мастер-пароль:")
+		if err := unlockVault(getHiddenUserInput()); err != nil {
+			fmt.Println("Ошибка при получении ключа шифрования:", err)
+			return
+		}
 		fmt.Println("Пользователь успешно авторизован")
-		showMenu(email)
+		showMenu()
 	} else if resp.StatusCode != http.StatusOK {
 		if resp.StatusCode == http.StatusUnauthorized {
 			fmt.Println("Ошибка при авторизации пользователя: неправильно введен e-mail или пароль")
@@ -762,6 +770,27 @@ func getUserInputInfo(prompt string) string {
 	return input
 }
 
+// getMultilineInput читает многострочный текст до строки из одной точки или конца ввода.
+func getMultilineInput(prompt string) string {
+	fmt.Println(prompt)
+	reader := bufio.NewReader(os.Stdin)
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line == "." {
+			break
+		}
+		if line != "" || err == nil {
+			lines = append(lines, line)
+		}
+		if err != nil {
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // Функция для получения скрытого ввода пароля
 func getHiddenUserInput() string {
 	bytePassword, err := terminal.ReadPassword(int(syscall.Stdin))
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+)
+
+// itemKind описывает вид элементов хранилища, у которых есть метаданные, теги и папка.
+type itemKind struct {
+	title  string // Заголовок списка
+	prefix string // Префикс маршрутов на сервере
+}
+
+var (
+	passwordItems = itemKind{title: "Пароли", prefix: "/password"}
+	cardItems     = itemKind{title: "Карты", prefix: "/card"}
+)
+
+// showOrganizeMenu выводит меню работы с метаданными, тегами и папками.
+func showOrganizeMenu() {
+	fmt.Println("\nТеги и папки:")
+	fmt.Println("1. Пароли по тегу и папке")
+	fmt.Println("2. Карты по тегу и папке")
+	fmt.Println("3. Изменить метаданные пароля")
+	fmt.Println("4. Изменить метаданные карты")
+	switch getUserInputInfo("Выберите подпункт: ") {
+	case "1":
+		showItems(passwordItems)
+	case "2":
+		showItems(cardItems)
+	case "3":
+		editItemMeta(passwordItems)
+	case "4":
+		editItemMeta(cardItems)
+	default:
+		fmt.Println("Некорректный подпункт меню")
+	}
+}
+
+// showItems запрашивает фильтр и выводит подходящие элементы с их метаданными.
+func showItems(kind itemKind) {
+	filter := domain.ItemFilter{
+		Tag:    getUserInputInfo("Тег (Enter - любой): "),
+		Folder: getUserInputInfo("Папка, например Работа/Почта (Enter - любая): "),
+	}
+	items, err := fetchItems(kind, filter)
+	if err != nil {
+		fmt.Println("Ошибка при получении списка:", err)
+		return
+	}
+	if len(items) == 0 {
+		fmt.Println("Ничего не найдено")
+		return
+	}
+
+	fmt.Printf("\n%s:\n", kind.title)
+	for _, item := range items {
+		fmt.Println(formatItem(item))
+	}
+}
+
+// editItemMeta изменяет метаданные, теги и папку выбранного элемента.
+func editItemMeta(kind itemKind) {
+	items, err := fetchItems(kind, domain.ItemFilter{})
+	if err != nil {
+		fmt.Println("Ошибка при получении списка:", err)
+		return
+	}
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = formatItem(item)
+	}
+
+	choice, ok := chooseName(kind.title, names)
+	if !ok {
+		fmt.Println("Возвращаемся назад...")
+		return
+	}
+	for i, name := range names {
+		if name == choice {
+			updateItemMeta(kind, items[i].Name, items[i].ItemMeta)
+			return
+		}
+	}
+}
+
+// updateItemMeta запрашивает новые метаданные, теги и папку элемента и отправляет их на сервер.
+func updateItemMeta(kind itemKind, name string, current domain.ItemMeta) {
+	meta := domain.ItemMeta{
+		Metadata: inputMetadata(current.Metadata),
+		Tags:     inputTags(current.Tags),
+		Folder:   inputFolder(current.Folder),
+	}
+	if err := SetItemMeta(kind, name, meta); err != nil {
+		fmt.Println("Ошибка при изменении метаданных:", err)
+		return
+	}
+	fmt.Println("Метаданные сохранены")
+}
+
+// SetItemMeta отправляет на сервер новые метаданные, теги и папку элемента.
+func SetItemMeta(kind itemKind, name string, meta domain.ItemMeta) error {
+	if kind == cardItems {
+		return fetchJSON(kind.prefix+"/meta", domain.CardMetaData{CardName: name, ItemMeta: meta}, nil)
+	}
+	return fetchJSON(kind.prefix+"/meta", domain.PasswordMetaData{PassName: name, ItemMeta: meta}, nil)
+}
+
+// fetchItems получает с сервера элементы, подходящие под фильтр.
+func fetchItems(kind itemKind, filter domain.ItemFilter) ([]domain.ItemSummary, error) {
+	var items []domain.ItemSummary
+	if err := fetchJSON(kind.prefix+"/list", filter, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// inputTags запрашивает теги через запятую. Пустой ввод оставляет текущие теги, "-" удаляет все.
+func inputTags(current []string) []string {
+	input := getUserInputInfo(fmt.Sprintf("Теги через запятую [%s] (Enter - оставить, \"-\" - очистить): ", strings.Join(current, ", ")))
+	switch input {
+	case "":
+		return current
+	case "-":
+		return nil
+	}
+
+	var tags []string
+	for _, tag := range strings.Split(input, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// inputFolder запрашивает путь папки. Пустой ввод оставляет текущую папку, "/" переносит в корень.
+func inputFolder(current string) string {
+	input := getUserInputInfo(fmt.Sprintf("Папка [%s] (Enter - оставить, \"/\" - корень): ", current))
+	if input == "" {
+		return current
+	}
+	return strings.Trim(input, "/")
+}
+
+// formatItem формирует строку элемента списка: папка, название, теги и метаданные.
+func formatItem(item domain.ItemSummary) string {
+	var b strings.Builder
+	if item.Folder != "" {
+		b.WriteString(item.Folder + "/")
+	}
+	b.WriteString(strings.TrimSpace(item.Name))
+	for _, tag := range item.Tags {
+		b.WriteString(" #" + tag)
+	}
+
+	keys := make([]string, 0, len(item.Metadata))
+	for key := range item.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%s", key, item.Metadata[key])
+	}
+	return b.String()
+}
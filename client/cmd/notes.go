@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+)
+
+// NoteData содержит заголовок заметки.
+type NoteData struct {
+	Title string `json:"title"` // Заголовок заметки
+}
+
+// NewNoteData содержит информацию о новой заметке.
+type NewNoteData struct {
+	Title string `json:"title"` // Заголовок новой заметки
+	Body  string `json:"body"`  // Зашифрованный текст заметки
+}
+
+// UpdateNoteData содержит информацию об изменении заметки.
+type UpdateNoteData struct {
+	Title    string `json:"title"`    // Текущий заголовок заметки
+	NewTitle string `json:"newTitle"` // Новый заголовок заметки
+	Body     string `json:"body"`     // Зашифрованный текст заметки
+}
+
+// NoteRevisionData содержит информацию о запрашиваемой ревизии заметки.
+type NoteRevisionData struct {
+	Title    string `json:"title"`    // Текущий заголовок заметки
+	Revision int    `json:"revision"` // Номер ревизии
+}
+
+// NoteRevision содержит ревизию заметки.
+type NoteRevision struct {
+	Revision
+	Body string `json:"body"` // Зашифрованный текст заметки
+}
+
+// noteBodyPrompt - подсказка для ввода многострочного текста заметки.
+const noteBodyPrompt = "Введите текст заметки (для завершения введите строку из одной точки):"
+
+// showNotesMenu выводит меню работы с заметками.
+func showNotesMenu() {
+	fmt.Println("\nЗаметки:")
+	fmt.Println("1. Новая заметка")
+	fmt.Println("2. Изменить заметку")
+	fmt.Println("3. Удалить заметку")
+	switch getUserInputInfo("Выберите подпункт: ") {
+	case "1":
+		addNewNote()
+	case "2":
+		editNote()
+	case "3":
+		deleteNote()
+	default:
+		fmt.Println("Некорректный подпункт меню")
+	}
+}
+
+// addNewNote запрашивает у пользователя заголовок и текст заметки и отправляет их на сервер.
+func addNewNote() {
+	title := getUserInputInfo("Введите заголовок заметки: ")
+	if title == "" {
+		fmt.Println("Заголовок не может быть пустым")
+		return
+	}
+	body := getMultilineInput(noteBodyPrompt)
+
+	if err := AddNote(title, body); err != nil {
+		fmt.Println("Ошибка при добавлении заметки:", err)
+		return
+	}
+	fmt.Println("Заметка успешно добавлена!")
+	indexName(domain.KindNote, title)
+}
+
+// AddNote шифрует текст заметки и отправляет ее на сервер.
+func AddNote(title, body string) error {
+	encrypted, err := encryptField(body, recordID("note", title))
+	if err != nil {
+		return err
+	}
+	return fetchJSON("/note/add", NewNoteData{Title: title, Body: encrypted}, nil)
+}
+
+// viewNotesName ищет заметку по заголовку и показывает выбранную.
+func viewNotesName() {
+	title, ok := findItem(domain.KindNote, "Список заметок")
+	if !ok {
+		fmt.Println("Возвращаемся назад...")
+		return
+	}
+	showNote(title)
+}
+
+// showNote выводит заметку после проверки пин-кода.
+func showNote(title string) {
+	if !unlock() {
+		return
+	}
+
+	body, err := GetNote(title)
+	if err != nil {
+		fmt.Println("Ошибка при получении заметки:", err)
+		return
+	}
+	fmt.Printf("Заметка '%s':\n%s\n", title, body)
+	if confirm("Показать историю изменений?") {
+		showNoteHistory(title)
+	}
+}
+
+// GetNote получает заметку с сервера и расшифровывает ее текст.
+func GetNote(title string) (string, error) {
+	resp, err := postJSON("/note/get", NoteData{Title: title})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("ошибка при чтении тела ответа: %v", err)
+	}
+	return decryptField(string(body), recordID("note", title))
+}
+
+// editNote запрашивает у пользователя изменения заметки и отправляет их на сервер.
+func editNote() {
+	title, ok := findItem(domain.KindNote, "Список заметок")
+	if !ok {
+		return
+	}
+
+	newTitle := getUserInputInfo("Новый заголовок (Enter - оставить прежний): ")
+	var body string
+	if confirm("Изменить текст заметки?") {
+		body = getMultilineInput(noteBodyPrompt)
+	} else if newTitle == "" {
+		fmt.Println("Изменений нет")
+		return
+	} else {
+		// Текст привязан к заголовку, поэтому при переименовании прежний текст шифруется заново
+		var err error
+		if body, err = GetNote(title); err != nil {
+			fmt.Println("Ошибка при получении заметки:", err)
+			return
+		}
+	}
+
+	if err := UpdateNote(title, newTitle, body); err != nil {
+		fmt.Println("Ошибка при изменении заметки:", err)
+		return
+	}
+	fmt.Println("Заметка успешно изменена!")
+	indexName(domain.KindNote, currentName(title, newTitle))
+}
+
+// UpdateNote отправляет запрос на сервер для изменения заметки.
+// Пустой новый заголовок оставляет прежний.
+func UpdateNote(title, newTitle, body string) error {
+	name := title
+	if newTitle != "" {
+		name = newTitle
+	}
+	encrypted, err := encryptField(body, recordID("note", name))
+	if err != nil {
+		return err
+	}
+	return fetchJSON("/note/update", UpdateNoteData{Title: title, NewTitle: newTitle, Body: encrypted}, nil)
+}
+
+// deleteNote запрашивает подтверждение и перемещает выбранную заметку в корзину.
+func deleteNote() {
+	title, ok := findItem(domain.KindNote, "Список заметок")
+	if !ok || !confirm(fmt.Sprintf("Переместить заметку '%s' в корзину?", title)) {
+		return
+	}
+
+	if err := fetchJSON("/note/delete", NoteData{Title: title}, nil); err != nil {
+		fmt.Println("Ошибка при удалении заметки:", err)
+		return
+	}
+	fmt.Println("Заметка перемещена в корзину")
+}
+
+// showNoteHistory выводит историю изменений заметки и позволяет восстановить одну из ревизий.
+func showNoteHistory(title string) {
+	var revisions []Revision
+	if err := fetchJSON("/note/history", NoteData{Title: title}, &revisions); err != nil {
+		fmt.Println("Ошибка при получении истории заметки:", err)
+		return
+	}
+
+	revision, ok := chooseRevision(revisions)
+	if !ok || !unlock() {
+		return
+	}
+
+	var noteRevision NoteRevision
+	err := fetchJSON("/note/revision", NoteRevisionData{Title: title, Revision: revision}, &noteRevision)
+	if err != nil {
+		fmt.Println("Ошибка при получении ревизии заметки:", err)
+		return
+	}
+	body, err := decryptField(noteRevision.Body, recordID("note", noteRevision.Name))
+	if err != nil {
+		fmt.Println("Ошибка при расшифровке ревизии:", err)
+		return
+	}
+	fmt.Printf("Ревизия %d заметки '%s':\n%s\n", noteRevision.Revision.Revision, noteRevision.Name, body)
+
+	if !confirm("Сделать эту ревизию текущей?") {
+		return
+	}
+	if err := fetchJSON("/note/restore", NoteRevisionData{Title: title, Revision: revision}, nil); err != nil {
+		fmt.Println("Ошибка при восстановлении заметки:", err)
+		return
+	}
+	fmt.Println("Заметка восстановлена")
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/otp"
+)
+
+// otpTemplate - встроенный шаблон записей с ключом одноразовых паролей.
+const otpTemplate = "otp"
+
+// showOTPMenu выводит меню работы с одноразовыми паролями.
+func showOTPMenu() {
+	fmt.Println("\nОдноразовые пароли:")
+	fmt.Println("1. Новый ключ")
+	fmt.Println("2. Показать код")
+	fmt.Println("3. Привязать ключ к паролю")
+	fmt.Println("4. Отвязать ключ от пароля")
+	switch getUserInputInfo("Выберите подпункт: ") {
+	case "1":
+		addOTPKey()
+	case "2":
+		showCode()
+	case "3":
+		attachOTP()
+	case "4":
+		detachOTP()
+	default:
+		fmt.Println("Некорректный подпункт меню")
+	}
+}
+
+// addOTPKey сохраняет ключ одноразовых паролей отдельной записью.
+func addOTPKey() {
+	key, ok := inputOTPKey()
+	if !ok {
+		return
+	}
+	defaultName := strings.TrimPrefix(key.Issuer+":"+key.Account, ":")
+	name := inputOrDefault("Название", defaultName)
+	if name == "" {
+		fmt.Println("Название не может быть пустым")
+		return
+	}
+
+	fields := []domain.Field{{Name: "secret", Type: domain.FieldOTPSeed, Value: key.URI()}}
+	if err := AddRecord(otpTemplate, name, fields, nil); err != nil {
+		fmt.Println("Ошибка при сохранении ключа:", err)
+		return
+	}
+	indexName(domain.KindRecord, name)
+	fmt.Println("Ключ сохранен")
+	printCode(key)
+}
+
+// showCode ищет пароль или запись с ключом одноразовых паролей и выводит текущий код.
+func showCode() {
+	item, ok := searchItems("Пароли и записи", domain.SearchQuery{
+		Kinds: []string{domain.KindPassword, domain.KindRecord},
+	}, searchSubstring)
+	if !ok {
+		fmt.Println("Возвращаемся назад...")
+		return
+	}
+	if !unlock() {
+		return
+	}
+
+	if item.Kind == domain.KindPassword {
+		_, uri, err := GetPasswordEntry(item.Name)
+		if err != nil {
+			fmt.Println("Ошибка при получении пароля:", err)
+			return
+		}
+		if uri == "" {
+			fmt.Println("К паролю не привязан ключ одноразовых паролей")
+			return
+		}
+		showPasswordCode(item.Name, uri)
+		return
+	}
+
+	record, err := GetRecord(item.Name)
+	if err != nil {
+		fmt.Println("Ошибка при получении записи:", err)
+		return
+	}
+	if !showRecordCodes(record) {
+		fmt.Println("В записи нет ключей одноразовых паролей")
+	}
+}
+
+// attachOTP привязывает ключ одноразовых паролей к выбранному паролю.
+func attachOTP() {
+	passName, ok := findItem(domain.KindPassword, "Список паролей")
+	if !ok {
+		fmt.Println("Возвращаемся назад...")
+		return
+	}
+	key, ok := inputOTPKey()
+	if !ok {
+		return
+	}
+	if err := SetPasswordOTP(passName, key.URI()); err != nil {
+		fmt.Println("Ошибка при привязке ключа:", err)
+		return
+	}
+	fmt.Println("Ключ привязан к паролю")
+	printCode(key)
+}
+
+// detachOTP отвязывает ключ одноразовых паролей от выбранного пароля.
+func detachOTP() {
+	passName, ok := findItem(domain.KindPassword, "Список паролей")
+	if !ok {
+		fmt.Println("Возвращаемся назад...")
+		return
+	}
+	if !confirm(fmt.Sprintf("Отвязать ключ от пароля '%s'?", strings.TrimSpace(passName))) {
+		return
+	}
+	if err := SetPasswordOTP(passName, ""); err != nil {
+		fmt.Println("Ошибка при отвязке ключа:", err)
+		return
+	}
+	fmt.Println("Ключ отвязан")
+}
+
+// inputOTPKey запрашивает ключ otpauth:// или секрет в base32.
+func inputOTPKey() (*otp.Key, bool) {
+	key, err := otp.Parse(getUserInputInfo("Ключ otpauth:// или секрет в base32: "))
+	if err != nil {
+		fmt.Println("Некорректный ключ:", err)
+		return nil, false
+	}
+	return key, true
+}
+
+// showPasswordCode выводит код по ключу, привязанному к паролю.
+// Для HOTP счетчик увеличивается и сохраняется, чтобы следующий код был новым.
+func showPasswordCode(passName, uri string) {
+	key, err := otp.Parse(uri)
+	if err != nil {
+		fmt.Println("Некорректный ключ одноразовых паролей:", err)
+		return
+	}
+	printCode(key)
+	if key.Type == otp.TypeHOTP {
+		key.Counter++
+		if err := SetPasswordOTP(passName, key.URI()); err != nil {
+			fmt.Println("Не удалось сохранить счетчик HOTP:", err)
+		}
+	}
+}
+
+// showRecordCodes выводит коды по всем ключам одноразовых паролей записи.
+// Возвращает false, если ключей в записи нет.
+func showRecordCodes(record *domain.Record) bool {
+	var found, advanced bool
+	for i, field := range record.Fields {
+		if field.Type != domain.FieldOTPSeed || field.Value == "" {
+			continue
+		}
+		key, err := otp.Parse(field.Value)
+		if err != nil {
+			fmt.Printf("%s: некорректный ключ: %v\n", field.Name, err)
+			continue
+		}
+		found = true
+		fmt.Printf("%s: ", field.Name)
+		printCode(key)
+		if key.Type == otp.TypeHOTP {
+			key.Counter++
+			record.Fields[i].Value = key.URI()
+			advanced = true
+		}
+	}
+	if advanced {
+		if err := UpdateRecord(record.Name, record.Name, record.Fields, record.Metadata); err != nil {
+			fmt.Println("Не удалось сохранить счетчик HOTP:", err)
+		}
+	}
+	return found
+}
+
+// printCode выводит текущий код и, для TOTP, сколько секунд он еще действует.
+func printCode(key *otp.Key) {
+	code, remaining, err := key.Code(time.Now())
+	if err != nil {
+		fmt.Println("Ошибка при вычислении кода:", err)
+		return
+	}
+	if key.Type == otp.TypeHOTP {
+		fmt.Printf("Код: %s (HOTP, счетчик %d)\n", code, key.Counter)
+		return
+	}
+	fmt.Printf("Код: %s (действует еще %d с)\n", code, int(remaining.Seconds()))
+}
+
+// GetPasswordEntry получает пароль вместе с привязанным ключом одноразовых паролей и расшифровывает их.
+// Если ключ не привязан, возвращается пустая строка.
+func GetPasswordEntry(passName string) (string, string, error) {
+	var entry domain.PasswordEntry
+	if err := fetchJSON("/password/entry", domain.PassData{PassName: passName}, &entry); err != nil {
+		return "", "", err
+	}
+	password, err := decryptField(entry.Password, recordID("password", passName))
+	if err != nil {
+		return "", "", err
+	}
+	if entry.OTP == "" {
+		return password, "", nil
+	}
+	uri, err := decryptField(entry.OTP, recordID("password", passName, "otp"))
+	if err != nil {
+		return "", "", err
+	}
+	return password, uri, nil
+}
+
+// SetPasswordOTP шифрует ключ одноразовых паролей и привязывает его к паролю.
+// Пустой ключ отвязывает одноразовые пароли.
+func SetPasswordOTP(passName, uri string) error {
+	var encrypted string
+	if uri != "" {
+		var err error
+		if encrypted, err = encryptField(uri, recordID("password", passName, "otp")); err != nil {
+			return err
+		}
+	}
+	return fetchJSON("/password/otp", domain.PasswordOTPData{PassName: passName, OTP: encrypted}, nil)
+}
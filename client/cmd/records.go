@@ -0,0 +1,538 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/schema"
+)
+
+// fieldTypes - типы собственных полей, которые пользователь может добавить к записи.
+var fieldTypes = []domain.FieldType{
+	domain.FieldText, domain.FieldHidden, domain.FieldURL, domain.FieldEmail, domain.FieldDate, domain.FieldOTPSeed,
+}
+
+// showRecordsMenu выводит меню работы с записями произвольного типа.
+func showRecordsMenu() {
+	fmt.Println("\nЗаписи:")
+	fmt.Println("1. Новая запись")
+	fmt.Println("2. Просмотреть запись")
+	fmt.Println("3. Изменить запись")
+	fmt.Println("4. Удалить запись")
+	fmt.Println("5. Шаблоны")
+	fmt.Println("6. Новый шаблон")
+	fmt.Println("7. Удалить шаблон")
+	switch getUserInputInfo("Выберите подпункт: ") {
+	case "1":
+		addNewRecord()
+	case "2":
+		viewRecord()
+	case "3":
+		editRecord()
+	case "4":
+		deleteRecord()
+	case "5":
+		showTemplates()
+	case "6":
+		addNewTemplate()
+	case "7":
+		deleteTemplate()
+	default:
+		fmt.Println("Некорректный подпункт меню")
+	}
+}
+
+// addNewRecord запрашивает тип, название и поля новой записи и отправляет ее на сервер.
+func addNewRecord() {
+	template, ok := chooseTemplate()
+	if !ok {
+		fmt.Println("Возвращаемся назад...")
+		return
+	}
+	name := getUserInputInfo("Введите название записи: ")
+	if name == "" {
+		fmt.Println("Название не может быть пустым")
+		return
+	}
+
+	values := inputTemplateFields(template, nil)
+	values = append(values, inputCustomFields(values)...)
+	metadata := inputMetadata(nil)
+
+	if err := AddRecord(template.Name, name, values, metadata); err != nil {
+		fmt.Println("Ошибка при добавлении записи:", err)
+		return
+	}
+	fmt.Println("Запись успешно добавлена!")
+	indexName(domain.KindRecord, name)
+}
+
+// AddRecord шифрует значения полей и отправляет новую запись на сервер.
+func AddRecord(recordType, name string, values []domain.Field, metadata map[string]string) error {
+	fields, err := encryptRecordFields(name, values)
+	if err != nil {
+		return err
+	}
+	record := domain.Record{Type: recordType, Name: name, Fields: fields, Metadata: metadata}
+	return fetchJSON("/record/add", record, nil)
+}
+
+// GetRecord получает запись с сервера и расшифровывает значения ее полей.
+func GetRecord(name string) (*domain.Record, error) {
+	var record domain.Record
+	if err := fetchJSON("/record/get", domain.RecordData{Name: name}, &record); err != nil {
+		return nil, err
+	}
+	fields, err := decryptRecordFields(name, record.Fields)
+	if err != nil {
+		return nil, err
+	}
+	record.Fields = fields
+	return &record, nil
+}
+
+// UpdateRecord шифрует значения полей под новым названием и отправляет изменения на сервер.
+func UpdateRecord(name, newName string, values []domain.Field, metadata map[string]string) error {
+	fields, err := encryptRecordFields(newName, values)
+	if err != nil {
+		return err
+	}
+	data := domain.UpdateRecordData{Name: name, NewName: newName, Fields: fields, Metadata: metadata}
+	return fetchJSON("/record/update", data, nil)
+}
+
+// viewRecord ищет запись по названию и показывает выбранную после проверки пин-кода.
+func viewRecord() {
+	name, ok := chooseRecord()
+	if !ok {
+		return
+	}
+
+	showRecord(name)
+}
+
+// showRecord выводит запись после проверки пин-кода.
+func showRecord(name string) {
+	if !unlock() {
+		return
+	}
+
+	record, err := GetRecord(name)
+	if err != nil {
+		fmt.Println("Ошибка при получении записи:", err)
+		return
+	}
+	printRecord(record.Name, record.Type, record.Fields, record.Metadata)
+	showRecordCodes(record)
+	if confirm("Показать историю изменений?") {
+		showRecordHistory(name)
+	}
+}
+
+// editRecord изменяет поля, метаданные и название выбранной записи.
+func editRecord() {
+	name, ok := chooseRecord()
+	if !ok {
+		return
+	}
+	record, err := GetRecord(name)
+	if err != nil {
+		fmt.Println("Ошибка при получении записи:", err)
+		return
+	}
+	template, ok := findTemplate(record.Type)
+	if !ok {
+		fmt.Printf("Шаблон '%s' не найден, запись нельзя изменить\n", record.Type)
+		return
+	}
+
+	newName := inputOrDefault("Название", record.Name)
+	values := inputTemplateFields(template, record.Fields)
+	// Собственные поля пользователя изменяются так же, как поля шаблона
+	for _, field := range record.Fields {
+		if _, ok := findSpec(template, field.Name); !ok {
+			field.Value = inputFieldValue(domain.FieldSpec{Name: field.Name, Type: field.Type}, field.Value)
+			values = append(values, field)
+		}
+	}
+	values = append(values, inputCustomFields(values)...)
+	metadata := inputMetadata(record.Metadata)
+
+	if err := UpdateRecord(name, newName, values, metadata); err != nil {
+		fmt.Println("Ошибка при изменении записи:", err)
+		return
+	}
+	fmt.Println("Запись успешно изменена!")
+	indexName(domain.KindRecord, currentName(name, newName))
+}
+
+// deleteRecord перемещает выбранную запись в корзину после подтверждения.
+func deleteRecord() {
+	name, ok := chooseRecord()
+	if !ok {
+		return
+	}
+	if !confirm(fmt.Sprintf("Переместить запись '%s' в корзину?", name)) {
+		fmt.Println("Удаление отменено")
+		return
+	}
+	if err := fetchJSON("/record/delete", domain.RecordData{Name: name}, nil); err != nil {
+		fmt.Println("Ошибка при удалении записи:", err)
+		return
+	}
+	fmt.Println("Запись перемещена в корзину")
+}
+
+// showRecordHistory выводит ревизии записи и позволяет восстановить выбранную.
+func showRecordHistory(name string) {
+	var revisions []Revision
+	if err := fetchJSON("/record/history", domain.RecordData{Name: name}, &revisions); err != nil {
+		fmt.Println("Ошибка при получении истории записи:", err)
+		return
+	}
+
+	revision, ok := chooseRevision(revisions)
+	if !ok || !unlock() {
+		return
+	}
+
+	var recordRevision domain.RecordRevision
+	data := domain.RecordRevisionData{Name: name, Revision: revision}
+	if err := fetchJSON("/record/revision", data, &recordRevision); err != nil {
+		fmt.Println("Ошибка при получении ревизии записи:", err)
+		return
+	}
+	fields, err := decryptRecordFields(recordRevision.Name, recordRevision.Fields)
+	if err != nil {
+		fmt.Println("Ошибка при расшифровке ревизии:", err)
+		return
+	}
+	fmt.Printf("Ревизия %d:\n", recordRevision.Revision.Revision)
+	printRecord(recordRevision.Name, recordRevision.Type, fields, recordRevision.Metadata)
+
+	if !confirm("Сделать эту ревизию текущей?") {
+		return
+	}
+	if err := fetchJSON("/record/restore", data, nil); err != nil {
+		fmt.Println("Ошибка при восстановлении записи:", err)
+		return
+	}
+	fmt.Println("Запись восстановлена")
+}
+
+// showTemplates выводит встроенные и пользовательские шаблоны с их полями.
+func showTemplates() {
+	templates, err := fetchTemplates()
+	if err != nil {
+		fmt.Println("Ошибка при получении шаблонов:", err)
+		return
+	}
+	for _, template := range templates {
+		kind := "пользовательский"
+		if template.BuiltIn {
+			kind = "встроенный"
+		}
+		fmt.Printf("\n%s (%s) - %s\n", template.Name, kind, template.Description)
+		for _, spec := range template.Fields {
+			required := ""
+			if spec.Required {
+				required = ", обязательное"
+			}
+			fmt.Printf("  %s: %s%s\n", spec.Name, spec.Type, required)
+		}
+	}
+}
+
+// addNewTemplate запрашивает название и поля пользовательского шаблона и отправляет его на сервер.
+func addNewTemplate() {
+	template := domain.Template{
+		Name:        getUserInputInfo("Введите название шаблона (латиница, цифры и дефис): "),
+		Description: getUserInputInfo("Введите описание шаблона: "),
+	}
+	for {
+		name := getUserInputInfo("Введите название поля (Enter - завершить): ")
+		if name == "" {
+			break
+		}
+		fieldType, ok := chooseFieldType()
+		if !ok {
+			continue
+		}
+		template.Fields = append(template.Fields, domain.FieldSpec{
+			Name:      name,
+			Type:      fieldType,
+			Required:  confirm("Поле обязательное?"),
+			Multiline: fieldType == domain.FieldText && confirm("Значение может занимать несколько строк?"),
+		})
+	}
+
+	if err := schema.ValidateTemplate(template); err != nil {
+		fmt.Println("Некорректный шаблон:", err)
+		return
+	}
+	if err := fetchJSON("/template/add", template, nil); err != nil {
+		fmt.Println("Ошибка при добавлении шаблона:", err)
+		return
+	}
+	fmt.Println("Шаблон успешно добавлен!")
+}
+
+// deleteTemplate удаляет выбранный пользовательский шаблон.
+func deleteTemplate() {
+	templates, err := fetchTemplates()
+	if err != nil {
+		fmt.Println("Ошибка при получении шаблонов:", err)
+		return
+	}
+	var names []string
+	for _, template := range templates {
+		if !template.BuiltIn {
+			names = append(names, template.Name)
+		}
+	}
+	if len(names) == 0 {
+		fmt.Println("Пользовательских шаблонов нет")
+		return
+	}
+
+	name, ok := chooseName("Пользовательские шаблоны", names)
+	if !ok {
+		fmt.Println("Возвращаемся назад...")
+		return
+	}
+	if err := fetchJSON("/template/delete", domain.TemplateData{Name: name}, nil); err != nil {
+		fmt.Println("Ошибка при удалении шаблона:", err)
+		return
+	}
+	fmt.Println("Шаблон удален")
+}
+
+// fetchTemplates получает с сервера встроенные и пользовательские шаблоны.
+func fetchTemplates() ([]domain.Template, error) {
+	var templates []domain.Template
+	if err := fetchJSON("/template/list", nil, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// findTemplate находит шаблон по названию.
+func findTemplate(name string) (domain.Template, bool) {
+	templates, err := fetchTemplates()
+	if err != nil {
+		fmt.Println("Ошибка при получении шаблонов:", err)
+		return domain.Template{}, false
+	}
+	for _, template := range templates {
+		if template.Name == name {
+			return template, true
+		}
+	}
+	return domain.Template{}, false
+}
+
+// chooseTemplate предлагает выбрать шаблон новой записи.
+func chooseTemplate() (domain.Template, bool) {
+	templates, err := fetchTemplates()
+	if err != nil {
+		fmt.Println("Ошибка при получении шаблонов:", err)
+		return domain.Template{}, false
+	}
+	names := make([]string, len(templates))
+	for i, template := range templates {
+		names[i] = template.Name + " - " + template.Description
+	}
+
+	choice, ok := chooseName("Тип записи", names)
+	if !ok {
+		return domain.Template{}, false
+	}
+	for i, name := range names {
+		if name == choice {
+			return templates[i], true
+		}
+	}
+	return domain.Template{}, false
+}
+
+// chooseRecord ищет запись по названию и возвращает название выбранной.
+func chooseRecord() (string, bool) {
+	name, ok := findItem(domain.KindRecord, "Список записей")
+	if !ok {
+		fmt.Println("Возвращаемся назад...")
+	}
+	return name, ok
+}
+
+// chooseFieldType предлагает выбрать тип поля.
+func chooseFieldType() (domain.FieldType, bool) {
+	names := make([]string, len(fieldTypes))
+	for i, fieldType := range fieldTypes {
+		names[i] = string(fieldType)
+	}
+	choice, ok := chooseName("Тип поля", names)
+	return domain.FieldType(choice), ok
+}
+
+// findSpec находит поле шаблона по названию.
+func findSpec(template domain.Template, name string) (domain.FieldSpec, bool) {
+	for _, spec := range template.Fields {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return domain.FieldSpec{}, false
+}
+
+// inputTemplateFields запрашивает значения полей шаблона. Если заданы текущие поля,
+// их значения предлагаются по умолчанию.
+func inputTemplateFields(template domain.Template, current []domain.Field) []domain.Field {
+	currentValues := make(map[string]string, len(current))
+	for _, field := range current {
+		currentValues[field.Name] = field.Value
+	}
+
+	var fields []domain.Field
+	for _, spec := range template.Fields {
+		value := inputFieldValue(spec, currentValues[spec.Name])
+		if value != "" {
+			fields = append(fields, domain.Field{Name: spec.Name, Type: spec.Type, Value: value})
+		}
+	}
+	return fields
+}
+
+// inputFieldValue запрашивает значение поля, пока оно не пройдет проверку формата.
+func inputFieldValue(spec domain.FieldSpec, current string) string {
+	for {
+		var value string
+		switch {
+		case spec.Multiline && current != "" && !confirm(fmt.Sprintf("Изменить поле '%s'?", spec.Name)):
+			return current
+		case spec.Multiline:
+			value = getMultilineInput(fmt.Sprintf("Введите %s (%s, для завершения введите строку из одной точки):", spec.Name, spec.Type))
+		case current != "":
+			value = inputOrDefault(spec.Name, current)
+		default:
+			value = getUserInputInfo(fmt.Sprintf("Введите %s (%s): ", spec.Name, spec.Type))
+		}
+
+		if spec.Required && value == "" {
+			fmt.Println("Поле обязательное")
+			continue
+		}
+		if err := schema.ValidateValue(spec.Type, value); err != nil {
+			fmt.Println("Некорректное значение:", err)
+			continue
+		}
+		return value
+	}
+}
+
+// inputCustomFields предлагает добавить к записи собственные поля.
+func inputCustomFields(existing []domain.Field) []domain.Field {
+	taken := make(map[string]bool, len(existing))
+	for _, field := range existing {
+		taken[field.Name] = true
+	}
+
+	var fields []domain.Field
+	for confirm("Добавить собственное поле?") {
+		name := getUserInputInfo("Введите название поля: ")
+		if name == "" || taken[name] {
+			fmt.Println("Название поля пустое или уже используется")
+			continue
+		}
+		fieldType, ok := chooseFieldType()
+		if !ok {
+			continue
+		}
+		value := inputFieldValue(domain.FieldSpec{Name: name, Type: fieldType}, "")
+		fields = append(fields, domain.Field{Name: name, Type: fieldType, Value: value})
+		taken[name] = true
+	}
+	return fields
+}
+
+// inputMetadata запрашивает метаданные записи в виде строк "ключ=значение".
+// Текущие метаданные сохраняются, пустое значение удаляет ключ.
+func inputMetadata(current map[string]string) map[string]string {
+	metadata := make(map[string]string, len(current))
+	for key, value := range current {
+		metadata[key] = value
+	}
+	if !confirm("Изменить метаданные (ключ=значение, открыты серверу)?") {
+		return metadata
+	}
+
+	for {
+		line := getUserInputInfo("Введите ключ=значение (Enter - завершить): ")
+		if line == "" {
+			return metadata
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			fmt.Println("Ожидается строка вида ключ=значение")
+			continue
+		}
+		if value = strings.TrimSpace(value); value == "" {
+			delete(metadata, key)
+		} else {
+			metadata[key] = value
+		}
+	}
+}
+
+// encryptRecordFields шифрует значения полей, привязывая каждое к записи и названию поля.
+func encryptRecordFields(name string, values []domain.Field) ([]domain.Field, error) {
+	fields := make([]domain.Field, len(values))
+	for i, field := range values {
+		encrypted, err := encryptField(field.Value, recordID("record", name, field.Name))
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = domain.Field{Name: field.Name, Type: field.Type, Value: encrypted}
+	}
+	return fields, nil
+}
+
+// decryptRecordFields расшифровывает значения полей записи с указанным названием.
+func decryptRecordFields(name string, encrypted []domain.Field) ([]domain.Field, error) {
+	fields := make([]domain.Field, len(encrypted))
+	for i, field := range encrypted {
+		value, err := decryptField(field.Value, recordID("record", name, field.Name))
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = domain.Field{Name: field.Name, Type: field.Type, Value: value}
+	}
+	return fields, nil
+}
+
+// printRecord выводит расшифрованную запись.
+func printRecord(name, recordType string, fields []domain.Field, metadata map[string]string) {
+	fmt.Printf("Запись '%s' (%s):\n", name, recordType)
+	for _, field := range fields {
+		if strings.Contains(field.Value, "\n") {
+			fmt.Printf("%s:\n%s\n", field.Name, field.Value)
+		} else {
+			fmt.Printf("%s: %s\n", field.Name, field.Value)
+		}
+	}
+	if len(metadata) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Println("Метаданные:")
+	for _, key := range keys {
+		fmt.Printf("  %s = %s\n", key, metadata[key])
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/egosha7/goph-keeper/internal/blindindex"
+	"github.com/egosha7/goph-keeper/internal/domain"
+)
+
+// searchPageSize - количество результатов поиска на одной странице.
+const searchPageSize = 10
+
+// Режимы сопоставления строки поиска.
+const (
+	searchSubstring = iota // По подстроке названия; строку поиска видит сервер
+	searchWords            // По словам названия через слепой индекс
+	searchExact            // По названию целиком через слепой индекс
+	searchDomain           // По домену сайта пароля через слепой индекс
+)
+
+// kindTitles содержит названия видов элементов хранилища для вывода.
+var kindTitles = map[string]string{
+	domain.KindPassword: "пароль",
+	domain.KindCard:     "карта",
+	domain.KindNote:     "заметка",
+	domain.KindFile:     "файл",
+	domain.KindRecord:   "запись",
+}
+
+// findItem ищет элементы указанного вида по названию и возвращает название выбранного.
+// Второе значение равно false, если пользователь решил вернуться назад.
+func findItem(kind, title string) (string, bool) {
+	item, ok := searchItems(title, domain.SearchQuery{Kinds: []string{kind}}, searchSubstring)
+	return item.Name, ok
+}
+
+// showSearch ищет по всем элементам хранилища с фильтрами и открывает выбранный элемент.
+func showSearch() {
+	mode := inputSearchMode()
+	query := domain.SearchQuery{
+		Kinds:  inputKinds(),
+		Tag:    getUserInputInfo("Тег (Enter - любой): "),
+		Folder: getUserInputInfo("Папка, например Работа/Почта (Enter - любая): "),
+		Sort:   inputSort(),
+	}
+	item, ok := searchItems("Результаты поиска", query, mode)
+	if !ok {
+		fmt.Println("Возвращаемся назад...")
+		return
+	}
+
+	switch item.Kind {
+	case domain.KindPassword:
+		showPassword(item.Name)
+	case domain.KindCard:
+		showCard(item.Name)
+	case domain.KindNote:
+		showNote(item.Name)
+	case domain.KindRecord:
+		showRecord(item.Name)
+	case domain.KindFile:
+		saveFile(item.Name)
+	}
+}
+
+// searchItems запрашивает строку поиска и выводит результаты постранично.
+// Пользователь может выбрать элемент по номеру, перейти на следующую страницу или начать новый поиск.
+func searchItems(title string, query domain.SearchQuery, mode int) (domain.SearchItem, bool) {
+	query.Limit = searchPageSize
+	for {
+		setSearchInput(&query, mode, getUserInputInfo("Поиск (Enter - все): "))
+		query.Cursor = ""
+
+	pages:
+		for {
+			var result domain.SearchResult
+			if err := fetchJSON("/search", query, &result); err != nil {
+				fmt.Println("Ошибка при поиске:", err)
+				return domain.SearchItem{}, false
+			}
+
+			fmt.Printf("\n%s:\n", title)
+			if len(result.Items) == 0 {
+				fmt.Println("Ничего не найдено")
+			}
+			for i, item := range result.Items {
+				fmt.Printf("%d. %s\n", i+1, formatSearchItem(item))
+			}
+			if result.NextCursor != "" {
+				fmt.Println("n. Следующая страница")
+			}
+			fmt.Println("s. Новый поиск")
+			fmt.Println("0. Вернуться назад")
+
+			for {
+				choice := strings.ToLower(getUserInputInfo("Выберите пункт: "))
+				if choice == "s" {
+					break pages
+				}
+				if choice == "n" && result.NextCursor != "" {
+					query.Cursor = result.NextCursor
+					continue pages
+				}
+
+				number, err := strconv.Atoi(choice)
+				switch {
+				case err != nil || number < 0 || number > len(result.Items):
+					fmt.Println("Некорректный пункт")
+				case number == 0:
+					return domain.SearchItem{}, false
+				default:
+					return result.Items[number-1], true
+				}
+			}
+		}
+	}
+}
+
+// setSearchInput заполняет запрос строкой поиска в выбранном режиме.
+// В режимах слепого поиска на сервер уходят только термы индекса, вычисленные на ключе пользователя.
+func setSearchInput(query *domain.SearchQuery, mode int, input string) {
+	query.Query, query.Terms = "", nil
+	switch mode {
+	case searchWords:
+		query.Terms = blindindex.QueryTerms(session.IndexKey, input, false)
+	case searchExact:
+		query.Terms = blindindex.QueryTerms(session.IndexKey, input, true)
+	case searchDomain:
+		if host := blindindex.Host(input); host != "" {
+			query.Kinds = []string{domain.KindPassword}
+			query.Terms = []string{blindindex.Term(session.IndexKey, blindindex.ScopeDomain, host)}
+		}
+	default:
+		query.Query = input
+	}
+}
+
+// inputSearchMode запрашивает режим сопоставления строки поиска.
+func inputSearchMode() int {
+	fmt.Println("\nРежим поиска:")
+	fmt.Println("1. По части названия")
+	fmt.Println("2. По словам названия (слепой индекс)")
+	fmt.Println("3. По названию целиком (слепой индекс)")
+	fmt.Println("4. Пароли по домену сайта (слепой индекс)")
+	switch getUserInputInfo("Выберите режим (Enter - по части названия): ") {
+	case "2":
+		return searchWords
+	case "3":
+		return searchExact
+	case "4":
+		return searchDomain
+	default:
+		return searchSubstring
+	}
+}
+
+// inputKinds запрашивает виды элементов для поиска. Пустой ввод означает все виды.
+func inputKinds() []string {
+	fmt.Println("\nВиды элементов:")
+	fmt.Println("1. Пароли")
+	fmt.Println("2. Карты")
+	fmt.Println("3. Заметки")
+	fmt.Println("4. Файлы")
+	fmt.Println("5. Записи")
+	input := getUserInputInfo("Номера через запятую (Enter - все): ")
+
+	kinds := []string{domain.KindPassword, domain.KindCard, domain.KindNote, domain.KindFile, domain.KindRecord}
+	var selected []string
+	for _, part := range strings.Split(input, ",") {
+		number, err := strconv.Atoi(strings.TrimSpace(part))
+		if err == nil && number >= 1 && number <= len(kinds) {
+			selected = append(selected, kinds[number-1])
+		}
+	}
+	return selected
+}
+
+// inputSort запрашивает порядок сортировки результатов поиска.
+func inputSort() string {
+	fmt.Println("\nСортировка:")
+	fmt.Println("1. По названию")
+	fmt.Println("2. По названию в обратном порядке")
+	fmt.Println("3. Сначала старые")
+	fmt.Println("4. Сначала новые")
+	switch getUserInputInfo("Выберите порядок (Enter - по названию): ") {
+	case "2":
+		return domain.SortNameDesc
+	case "3":
+		return domain.SortUpdated
+	case "4":
+		return domain.SortUpdatedDesc
+	default:
+		return domain.SortName
+	}
+}
+
+// formatSearchItem формирует строку результата поиска: папка, название, вид, теги и время изменения.
+func formatSearchItem(item domain.SearchItem) string {
+	var b strings.Builder
+	if item.Folder != "" {
+		b.WriteString(item.Folder + "/")
+	}
+	b.WriteString(strings.TrimSpace(item.Name))
+
+	kind := kindTitles[item.Kind]
+	if item.Kind == domain.KindRecord {
+		kind += ": " + item.Type
+	}
+	fmt.Fprintf(&b, " (%s)", kind)
+	for _, tag := range item.Tags {
+		b.WriteString(" #" + tag)
+	}
+	if !item.UpdatedAt.IsZero() && item.UpdatedAt.Unix() > 0 {
+		b.WriteString(" " + item.UpdatedAt.Local().Format("02.01.2006 15:04"))
+	}
+	return b.String()
+}
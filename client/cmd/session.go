@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+)
+
+// Tokens содержит пару токенов, выданную сервером.
+type Tokens struct {
+	AccessToken  string `json:"accessToken"`  // Токен доступа
+	RefreshToken string `json:"refreshToken"` // Токен обновления
+}
+
+// Session хранит данные текущего сеанса пользователя.
+type Session struct {
+	Login     string // Логин пользователя
+	Tokens    Tokens // Токены, выданные сервером при входе
+	MasterKey []byte // Мастер-ключ, которым шифруются данные хранилища
+	IndexKey  []byte // Ключ слепого индекса названий, получается из мастер-ключа
+
+	Unlock domain.UnlockGrant // Разрешение на чтение секретов, выданное после проверки пин-кода
+
+	ChangesCursor string // Курсор последнего полученного события ленты изменений
+}
+
+// session - текущий сеанс пользователя.
+var session Session
+
+// startSession разбирает ответ сервера с токенами и сохраняет их в текущем сеансе.
+func startSession(login string, resp *http.Response) error {
+	var tokens Tokens
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return fmt.Errorf("ошибка при декодировании токенов: %v", err)
+	}
+	session.Login = login
+	session.Tokens = tokens
+	return nil
+}
+
+// postJSON отправляет аутентифицированный POST-запрос с JSON-телом на сервер.
+func postJSON(path string, payload interface{}) (*http.Response, error) {
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при кодировании JSON: %v", err)
+		}
+	}
+	return doAuthorized(http.MethodPost, path, body, map[string]string{"Content-Type": "application/json"})
+}
+
+// doAuthorized отправляет аутентифицированный запрос на сервер.
+// Если токен доступа истек, обновляет пару токенов и повторяет запрос один раз.
+func doAuthorized(method, path string, body []byte, header map[string]string) (*http.Response, error) {
+	resp, err := sendAuthorized(method, path, body, header)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	resp.Body.Close()
+
+	if err := refreshTokens(); err != nil {
+		return nil, err
+	}
+	return sendAuthorized(method, path, body, header)
+}
+
+// sendAuthorized отправляет запрос с токеном доступа в заголовке Authorization.
+func sendAuthorized(method, path string, body []byte, header map[string]string) (*http.Response, error) {
+	req, err := http.NewRequest(method, serverURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании запроса: %v", err)
+	}
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Authorization", "Bearer "+session.Tokens.AccessToken)
+	if session.Unlock.Token != "" {
+		req.Header.Set(domain.UnlockHeader, session.Unlock.Token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при выполнении запроса: %v", err)
+	}
+	return resp, nil
+}
+
+// refreshTokens обновляет пару токенов текущего сеанса по токену обновления.
+func refreshTokens() error {
+	jsonData, err := json.Marshal(map[string]string{"refreshToken": session.Tokens.RefreshToken})
+	if err != nil {
+		return fmt.Errorf("ошибка при кодировании JSON: %v", err)
+	}
+
+	resp, err := httpClient.Post(serverURL+"/auth/refresh", "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении запроса: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("сеанс истек, выполните вход заново")
+	}
+	return startSession(session.Login, resp)
+}
+
+// fetchJSON отправляет запрос на сервер и, если result не nil, декодирует в него ответ.
+func fetchJSON(path string, payload, result interface{}) error {
+	resp, err := postJSON(path, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("ошибка при декодировании JSON: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/urlmatch"
+)
+
+// matchTitles содержит описания правил сопоставления адресов в порядке вывода.
+var matchTitles = []struct {
+	match string
+	title string
+}{
+	{domain.MatchDomain, "по базовому домену (example.com подходит для accounts.example.com)"},
+	{domain.MatchHost, "по точному имени хоста"},
+	{domain.MatchStarts, "адрес страницы начинается с указанного"},
+	{domain.MatchRegex, "по регулярному выражению"},
+}
+
+// showSitesMenu выводит меню работы с адресами сайтов паролей.
+func showSitesMenu() {
+	fmt.Println("\nСайты:")
+	fmt.Println("1. Адреса сайтов пароля")
+	fmt.Println("2. Подобрать пароль для страницы")
+	switch getUserInputInfo("Выберите подпункт: ") {
+	case "1":
+		passName, ok := findItem(domain.KindPassword, "Список паролей")
+		if !ok {
+			fmt.Println("Возвращаемся назад...")
+			return
+		}
+		editPasswordURLs(passName)
+	case "2":
+		matchPassword()
+	default:
+		fmt.Println("Некорректный подпункт меню")
+	}
+}
+
+// editPasswordURLs выводит адреса сайтов пароля и позволяет добавить или удалить адрес.
+func editPasswordURLs(passName string) {
+	urls, err := passwordURLs(passName)
+	if err != nil {
+		fmt.Println("Ошибка при получении адресов сайтов:", err)
+		return
+	}
+
+	for {
+		fmt.Printf("\nАдреса сайтов пароля '%s':\n", strings.TrimSpace(passName))
+		for i, u := range urls {
+			fmt.Printf("%d. %s (%s)\n", i+1, u.URL, u.Match)
+		}
+		fmt.Println("+. Добавить адрес")
+		fmt.Println("0. Сохранить и вернуться")
+
+		choice := getUserInputInfo("Выберите адрес для удаления или пункт: ")
+		switch choice {
+		case "0":
+			if err := SetPasswordURLs(passName, urls); err != nil {
+				fmt.Println("Ошибка при сохранении адресов сайтов:", err)
+				return
+			}
+			indexDomains(passName, urls)
+			fmt.Println("Адреса сайтов сохранены")
+			return
+		case "+":
+			if u, ok := inputLoginURL(); ok {
+				urls = append(urls, u)
+			}
+		default:
+			number, err := strconv.Atoi(choice)
+			if err != nil || number < 1 || number > len(urls) {
+				fmt.Println("Некорректный пункт")
+				continue
+			}
+			urls = append(urls[:number-1], urls[number:]...)
+		}
+	}
+}
+
+// inputLoginURL запрашивает адрес сайта и правило сопоставления.
+func inputLoginURL() (domain.LoginURL, bool) {
+	fmt.Println("\nПравило сопоставления:")
+	for i, m := range matchTitles {
+		fmt.Printf("%d. %s\n", i+1, m.title)
+	}
+	number, err := strconv.Atoi(getUserInputInfo("Выберите правило (Enter - по базовому домену): "))
+	if err != nil || number < 1 || number > len(matchTitles) {
+		number = 1
+	}
+
+	u, err := urlmatch.Normalize(domain.LoginURL{
+		URL:   getUserInputInfo("Адрес сайта или регулярное выражение: "),
+		Match: matchTitles[number-1].match,
+	})
+	if err != nil {
+		fmt.Println("Некорректный адрес:", err)
+		return domain.LoginURL{}, false
+	}
+	return u, true
+}
+
+// matchPassword подбирает пароли для адреса страницы и показывает выбранный.
+func matchPassword() {
+	page := getUserInputInfo("Адрес страницы: ")
+	var matches []domain.LoginMatch
+	if err := fetchJSON("/password/match", domain.MatchData{URL: page}, &matches); err != nil {
+		fmt.Println("Ошибка при подборе паролей:", err)
+		return
+	}
+	if len(matches) == 0 {
+		fmt.Println("Подходящих паролей нет")
+		return
+	}
+
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = fmt.Sprintf("%s (%s: %s)", strings.TrimSpace(m.PassName), m.Rule.Match, m.Rule.URL)
+	}
+	choice, ok := chooseName("Подходящие пароли", names)
+	if !ok {
+		fmt.Println("Возвращаемся назад...")
+		return
+	}
+	for i, name := range names {
+		if name == choice {
+			showPassword(matches[i].PassName)
+			return
+		}
+	}
+}
+
+// SetPasswordURLs отправляет на сервер новые адреса сайтов пароля.
+func SetPasswordURLs(passName string, urls []domain.LoginURL) error {
+	return fetchJSON("/password/urls", domain.PasswordURLsData{PassName: passName, URLs: urls}, nil)
+}
+
+// passwordURLs возвращает адреса сайтов пароля.
+func passwordURLs(passName string) ([]domain.LoginURL, error) {
+	passwords, err := fetchPasswordURLs()
+	if err != nil {
+		return nil, err
+	}
+	for _, password := range passwords {
+		if password.PassName == passName {
+			return password.URLs, nil
+		}
+	}
+	return nil, nil
+}
+
+// fetchPasswordURLs получает с сервера адреса сайтов всех паролей.
+func fetchPasswordURLs() ([]domain.PasswordURLsData, error) {
+	var passwords []domain.PasswordURLsData
+	if err := fetchJSON("/password/urls/list", nil, &passwords); err != nil {
+		return nil, err
+	}
+	return passwords, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/tlsconfig"
+)
+
+// serverURL - адрес сервера GophKeeper.
+var serverURL = "http://localhost:8080"
+
+// httpClient - клиент для запросов к серверу с настройками TLS из флагов командной строки.
+var httpClient = http.DefaultClient
+
+// setupTransport читает адрес сервера и параметры TLS из флагов командной строки.
+// Самоподписанному сертификату сервера можно доверять, закрепив его отпечаток или указав свой УЦ.
+func setupTransport() error {
+	var opts tlsconfig.ClientOptions
+	flag.StringVar(&serverURL, "server", serverURL, "Адрес сервера GophKeeper")
+	flag.StringVar(&opts.CAFile, "ca-file", "", "Сертификаты УЦ в PEM, которым доверяет клиент")
+	flag.StringVar(&opts.Pin, "pin-sha256", "", "Отпечаток SHA-256 сертификата сервера")
+	flag.StringVar(&opts.CertFile, "cert", "", "Сертификат устройства для mTLS")
+	flag.StringVar(&opts.KeyFile, "key", "", "Ключ сертификата устройства")
+	flag.Parse()
+	serverURL = strings.TrimRight(serverURL, "/")
+
+	u, err := url.Parse(serverURL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid server address %q", serverURL)
+	}
+	if u.Scheme != "https" {
+		if opts != (tlsconfig.ClientOptions{}) {
+			return fmt.Errorf("TLS options require an https server address")
+		}
+		if ip := net.ParseIP(u.Hostname()); u.Hostname() != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			fmt.Println("Внимание: соединение с сервером не шифруется, используйте https")
+		}
+		return nil
+	}
+
+	config, err := tlsconfig.NewClientConfig(opts)
+	if err != nil {
+		return err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	httpClient = &http.Client{Transport: transport}
+	return nil
+}
+
+// statusError возвращает ошибку с описанием из тела ответа сервера, если оно есть.
+func statusError(resp *http.Response) error {
+	return responseError(resp, readError(resp))
+}
+
+// readError читает тело ответа с ошибкой. Если тело не в формате API, возвращает пустой ответ.
+func readError(resp *http.Response) domain.ErrorResponse {
+	var body domain.ErrorResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&body); err != nil {
+		return domain.ErrorResponse{}
+	}
+	return body
+}
+
+// responseError возвращает ошибку для статуса ответа и прочитанного тела ошибки.
+func responseError(resp *http.Response, body domain.ErrorResponse) error {
+	if body.Message == "" {
+		return fmt.Errorf("ошибка: сервер вернул статус %s", resp.Status)
+	}
+	return fmt.Errorf("ошибка: сервер вернул статус %s: %s", resp.Status, body.Message)
+}
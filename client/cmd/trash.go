@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TrashItem описывает запись, находящуюся в корзине.
+type TrashItem struct {
+	ID        int       `json:"id"`        // Идентификатор записи
+	Name      string    `json:"name"`      // Название записи
+	DeletedAt time.Time `json:"deletedAt"` // Время удаления
+}
+
+// TrashData содержит информацию о записи в корзине.
+type TrashData struct {
+	ID int `json:"id"` // Идентификатор записи
+}
+
+// showTrash выводит содержимое корзины и позволяет восстановить удаленную запись.
+func showTrash() {
+	fmt.Println("\nКорзина:")
+	fmt.Println("1. Пароли")
+	fmt.Println("2. Карты")
+	fmt.Println("3. Заметки")
+	fmt.Println("4. Записи")
+
+	var prefix, title string
+	switch getUserInputInfo("Выберите подпункт: ") {
+	case "1":
+		prefix, title = "/password", "Удаленные пароли"
+	case "2":
+		prefix, title = "/card", "Удаленные карты"
+	case "3":
+		prefix, title = "/note", "Удаленные заметки"
+	case "4":
+		prefix, title = "/record", "Удаленные записи"
+	default:
+		fmt.Println("Некорректный подпункт меню")
+		return
+	}
+
+	var items []TrashItem
+	if err := fetchJSON(prefix+"/trash", nil, &items); err != nil {
+		fmt.Println("Ошибка при получении корзины:", err)
+		return
+	}
+	if len(items) == 0 {
+		fmt.Println("Корзина пуста")
+		return
+	}
+
+	for {
+		fmt.Printf("\n%s:\n", title)
+		for i, item := range items {
+			fmt.Printf("%d. %s (удалено %s)\n", i+1, item.Name, item.DeletedAt.Local().Format("02.01.2006 15:04"))
+		}
+		fmt.Println("0. Вернуться назад")
+
+		choice, err := strconv.Atoi(getUserInputInfo("Выберите запись для восстановления: "))
+		switch {
+		case err != nil:
+			fmt.Println("Ошибка при чтении ввода:", err)
+			continue
+		case choice == 0:
+			return
+		case choice < 1 || choice > len(items):
+			fmt.Println("Некорректный номер")
+			continue
+		}
+
+		item := items[choice-1]
+		if err := fetchJSON(prefix+"/trash/restore", TrashData{ID: item.ID}, nil); err != nil {
+			fmt.Println("Ошибка при восстановлении:", err)
+			return
+		}
+		fmt.Printf("Запись '%s' восстановлена\n", item.Name)
+		return
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/otp"
+)
+
+// secondFactorAttempts - сколько раз можно ввести код второго фактора при входе.
+const secondFactorAttempts = 3
+
+// completeLogin разбирает ответ сервера на вход. Если подключен второй фактор,
+// запрашивает код и завершает вход, иначе сразу сохраняет выданные токены.
+func completeLogin(login string, resp *http.Response) error {
+	var result struct {
+		Tokens
+		domain.SecondFactorChallenge
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("ошибка при декодировании ответа: %v", err)
+	}
+	if !result.SecondFactorRequired {
+		session.Login = login
+		session.Tokens = result.Tokens
+		return nil
+	}
+
+	fmt.Println("Включена двухфакторная аутентификация")
+	for attempt := 0; attempt < secondFactorAttempts; attempt++ {
+		code := getUserInputInfo("Код из приложения-аутентификатора или код восстановления: ")
+		ok, err := sendSecondFactor(login, domain.SecondFactorData{Challenge: result.Challenge, Code: code})
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		fmt.Println("Неверный код, попробуйте еще раз")
+	}
+	return fmt.Errorf("превышено количество попыток ввода кода")
+}
+
+// sendSecondFactor отправляет код второго фактора и при успехе начинает сеанс.
+// Возвращает false, если код не подошел.
+func sendSecondFactor(login string, data domain.SecondFactorData) (bool, error) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при кодировании JSON: %v", err)
+	}
+
+	resp, err := httpClient.Post(serverURL+"/auth/2fa", "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return false, fmt.Errorf("ошибка при отправке запроса: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, startSession(login, resp)
+	case http.StatusUnauthorized:
+		return false, nil
+	}
+	return false, statusError(resp)
+}
+
+// showSecondFactorMenu выводит меню подключения и отключения второго фактора.
+func showSecondFactorMenu() {
+	fmt.Println("\nДвухфакторная аутентификация:")
+	fmt.Println("1. Подключить")
+	fmt.Println("2. Отключить")
+	switch getUserInputInfo("Выберите подпункт: ") {
+	case "1":
+		enrollSecondFactor()
+	case "2":
+		disableSecondFactor()
+	default:
+		fmt.Println("Некорректный подпункт меню")
+	}
+}
+
+// enrollSecondFactor получает ключ TOTP, подтверждает его кодом и выводит коды восстановления.
+func enrollSecondFactor() {
+	var enrollment domain.SecondFactorEnrollment
+	if err := fetchJSON("/auth/2fa/enroll", nil, &enrollment); err != nil {
+		fmt.Println("Ошибка при подключении второго фактора:", err)
+		return
+	}
+	key, err := otp.Parse(enrollment.URI)
+	if err != nil {
+		fmt.Println("Сервер вернул некорректный ключ:", err)
+		return
+	}
+
+	fmt.Println("Добавьте ключ в приложение-аутентификатор:")
+	fmt.Println(enrollment.URI)
+	fmt.Println("Секрет для ручного ввода:", secretOf(key))
+
+	code := getUserInputInfo("Код из приложения для подтверждения: ")
+	var recovery domain.RecoveryCodes
+	if err := fetchJSON("/auth/2fa/confirm", domain.SecondFactorCodeData{Code: code}, &recovery); err != nil {
+		fmt.Println("Ошибка при подтверждении второго фактора:", err)
+		return
+	}
+
+	fmt.Println("Двухфакторная аутентификация подключена")
+	fmt.Println("Коды восстановления - сохраните их, каждый действует один раз и больше показан не будет:")
+	for _, code := range recovery.Codes {
+		fmt.Println("  " + code)
+	}
+}
+
+// disableSecondFactor отключает второй фактор после ввода кода.
+func disableSecondFactor() {
+	code := getUserInputInfo("Код из приложения-аутентификатора или код восстановления: ")
+	if err := fetchJSON("/auth/2fa/disable", domain.SecondFactorCodeData{Code: code}, nil); err != nil {
+		fmt.Println("Ошибка при отключении второго фактора:", err)
+		return
+	}
+	fmt.Println("Двухфакторная аутентификация отключена")
+}
+
+// secretOf возвращает секрет ключа в base32, разбитый на группы по четыре символа.
+func secretOf(key *otp.Key) string {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key.Secret)
+	var groups []string
+	for len(secret) > 4 {
+		groups = append(groups, secret[:4])
+		secret = secret[4:]
+	}
+	return strings.Join(append(groups, secret), " ")
+}
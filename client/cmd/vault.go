@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/egosha7/goph-keeper/internal/blindindex"
+	"github.com/egosha7/goph-keeper/internal/crypt"
+)
+
+// SaltData содержит соль мастер-ключа пользователя.
+type SaltData struct {
+	Salt string `json:"salt"` // Соль в base64
+}
+
+// unlockVault получает мастер-ключ из мастер-пароля и соли пользователя, хранящейся на сервере.
+func unlockVault(masterPassword string) error {
+	resp, err := postJSON("/auth/salt", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+
+	var saltData SaltData
+	if err := json.NewDecoder(resp.Body).Decode(&saltData); err != nil {
+		return fmt.Errorf("ошибка при декодировании JSON: %v", err)
+	}
+	salt, err := base64.StdEncoding.DecodeString(saltData.Salt)
+	if err != nil || len(salt) == 0 {
+		return fmt.Errorf("сервер вернул некорректную соль")
+	}
+
+	session.MasterKey = crypt.DeriveKey(masterPassword, salt)
+	session.IndexKey = blindindex.DeriveKey(session.MasterKey)
+	return nil
+}
+
+// recordID формирует идентификатор поля записи, к которому привязывается шифротекст.
+// Например, "card/Visa/cvv": такой шифротекст не расшифруется в другом поле или записи.
+func recordID(parts ...string) string {
+	return strings.Join(parts, "/")
+}
+
+// encryptField шифрует значение мастер-ключом и возвращает конверт в base64.
+// Конверт привязан к текущему пользователю и указанной записи.
+func encryptField(value, record string) (string, error) {
+	ciphertext, err := encryptBytes([]byte(value), record)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// decryptField расшифровывает значение, зашифрованное функцией encryptField для той же записи.
+func decryptField(value, record string) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", fmt.Errorf("сервер вернул некорректный шифротекст: %v", err)
+	}
+	plaintext, err := decryptBytes(ciphertext, record)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// encryptBytes шифрует данные мастер-ключом и возвращает конверт, привязанный к пользователю и записи.
+func encryptBytes(plaintext []byte, record string) ([]byte, error) {
+	ad := crypt.AssociatedData{Owner: session.Login, Record: record}
+	ciphertext, err := crypt.Encrypt(crypt.XChaCha20Poly1305, session.MasterKey, plaintext, ad)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при шифровании: %v", err)
+	}
+	return ciphertext, nil
+}
+
+// decryptBytes расшифровывает конверт, созданный функцией encryptBytes для той же записи.
+func decryptBytes(ciphertext []byte, record string) ([]byte, error) {
+	ad := crypt.AssociatedData{Owner: session.Login, Record: record}
+	plaintext, err := crypt.Decrypt(session.MasterKey, ciphertext, ad)
+	switch {
+	case errors.Is(err, crypt.ErrWrongKey):
+		return nil, fmt.Errorf("не удалось расшифровать данные: неверный мастер-пароль")
+	case errors.Is(err, crypt.ErrTampered):
+		return nil, fmt.Errorf("не удалось расшифровать данные: данные повреждены или подменены")
+	case err != nil:
+		return nil, fmt.Errorf("не удалось расшифровать данные: %v", err)
+	}
+	return plaintext, nil
+}
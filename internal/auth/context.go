@@ -0,0 +1,21 @@
+package auth
+
+import "context"
+
+// contextKey - тип ключей контекста пакета, исключающий пересечения с другими пакетами.
+type contextKey struct{}
+
+// loginKey - ключ, под которым в контексте запроса хранится логин аутентифицированного пользователя.
+var loginKey = contextKey{}
+
+// WithLogin возвращает копию контекста с логином аутентифицированного пользователя.
+func WithLogin(ctx context.Context, login string) context.Context {
+	return context.WithValue(ctx, loginKey, login)
+}
+
+// LoginFromContext возвращает логин аутентифицированного пользователя из контекста.
+// Второе значение равно false, если запрос не прошел аутентификацию.
+func LoginFromContext(ctx context.Context) (string, bool) {
+	login, ok := ctx.Value(loginKey).(string)
+	return login, ok && login != ""
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Типы токенов, записываемые в поле typ.
+const (
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
+	challengeType    = "2fa"
+	unlockTokenType  = "unlock"
+)
+
+// ScopeSecrets - область разрешения на разблокировку: чтение паролей и реквизитов карт.
+const ScopeSecrets = "secrets:read"
+
+// ChallengeTTL - время, за которое нужно подтвердить вход вторым фактором.
+const ChallengeTTL = 5 * time.Minute
+
+// ErrInvalidToken возвращается, если токен не прошел проверку подписи, истек или имеет неверный тип.
+// Относится к виду domain.ErrUnauthorized.
+var ErrInvalidToken = domain.Unauthorized("invalid token")
+
+// claims описывает полезную нагрузку токена.
+type claims struct {
+	jwt.RegisteredClaims
+	Type  string `json:"typ"`             // Тип токена: access, refresh, 2fa или unlock
+	Scope string `json:"scope,omitempty"` // Область разрешения на разблокировку
+}
+
+// TokenManager выпускает и проверяет подписанные токены доступа, обновления и разблокировки.
+type TokenManager struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	unlockTTL  time.Duration
+}
+
+// NewTokenManager создает новый экземпляр TokenManager.
+func NewTokenManager(secret string, accessTTL, refreshTTL, unlockTTL time.Duration) *TokenManager {
+	return &TokenManager{
+		secret:     []byte(secret),
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		unlockTTL:  unlockTTL,
+	}
+}
+
+// Issue выпускает пару токенов (доступа и обновления) для указанного пользователя.
+func (m *TokenManager) Issue(login string) (accessToken, refreshToken string, err error) {
+	accessToken, err = m.sign(login, accessTokenType, "", m.accessTTL)
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken, err = m.sign(login, refreshTokenType, "", m.refreshTTL)
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+// ParseAccess проверяет токен доступа и возвращает логин его владельца.
+func (m *TokenManager) ParseAccess(token string) (string, error) {
+	c, err := m.parse(token, accessTokenType)
+	return c.Subject, err
+}
+
+// ParseRefresh проверяет токен обновления и возвращает логин его владельца.
+func (m *TokenManager) ParseRefresh(token string) (string, error) {
+	c, err := m.parse(token, refreshTokenType)
+	return c.Subject, err
+}
+
+// IssueChallenge выпускает токен входа, ожидающего подтверждения вторым фактором.
+// Токен подтверждает только правильный пароль и не дает доступа к хранилищу.
+func (m *TokenManager) IssueChallenge(login string) (string, error) {
+	return m.sign(login, challengeType, "", ChallengeTTL)
+}
+
+// ParseChallenge проверяет токен входа, ожидающего второй фактор, и возвращает логин его владельца.
+func (m *TokenManager) ParseChallenge(token string) (string, error) {
+	c, err := m.parse(token, challengeType)
+	return c.Subject, err
+}
+
+// IssueUnlock выпускает токен разблокировки с указанной областью и возвращает его вместе со сроком действия.
+// Токен выдается после проверки пин-кода и дополняет токен доступа, а не заменяет его.
+func (m *TokenManager) IssueUnlock(login, scope string) (string, time.Time, error) {
+	token, err := m.sign(login, unlockTokenType, scope, m.unlockTTL)
+	return token, time.Now().Add(m.unlockTTL), err
+}
+
+// ParseUnlock проверяет токен разблокировки и его область и возвращает логин его владельца.
+func (m *TokenManager) ParseUnlock(token, scope string) (string, error) {
+	c, err := m.parse(token, unlockTokenType)
+	if err != nil {
+		return "", err
+	}
+	if c.Scope != scope {
+		return "", ErrInvalidToken
+	}
+	return c.Subject, nil
+}
+
+// sign формирует и подписывает токен указанного типа.
+func (m *TokenManager) sign(login, tokenType, scope string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(
+		jwt.SigningMethodHS256, claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   login,
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			},
+			Type:  tokenType,
+			Scope: scope,
+		},
+	)
+	signed, err := token.SignedString(m.secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// parse проверяет подпись, срок действия и тип токена и возвращает его полезную нагрузку.
+func (m *TokenManager) parse(token, tokenType string) (claims, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(
+		token, &c, func(t *jwt.Token) (interface{}, error) {
+			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+			}
+			return m.secret, nil
+		},
+	)
+	if err != nil {
+		return claims{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if c.Type != tokenType || c.Subject == "" {
+		return claims{}, ErrInvalidToken
+	}
+	return c, nil
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenManager_IssueAndParse(t *testing.T) {
+	m := NewTokenManager("secret", time.Minute, time.Hour, time.Minute)
+
+	accessToken, refreshToken, err := m.Issue("Egor")
+	require.NoError(t, err)
+
+	login, err := m.ParseAccess(accessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "Egor", login)
+
+	login, err = m.ParseRefresh(refreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, "Egor", login)
+
+	// Токены разных типов не взаимозаменяемы
+	_, err = m.ParseAccess(refreshToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = m.ParseRefresh(accessToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestTokenManager_Rejects(t *testing.T) {
+	m := NewTokenManager("secret", -time.Minute, time.Hour, time.Minute)
+	expired, _, err := m.Issue("Egor")
+	require.NoError(t, err)
+
+	_, err = m.ParseAccess(expired)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	other := NewTokenManager("other", time.Minute, time.Hour, time.Minute)
+	forged, _, err := other.Issue("Egor")
+	require.NoError(t, err)
+
+	_, err = m.ParseAccess(forged)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestTokenManager_Challenge(t *testing.T) {
+	m := NewTokenManager("secret", time.Minute, time.Hour, time.Minute)
+
+	challenge, err := m.IssueChallenge("Egor")
+	require.NoError(t, err)
+
+	login, err := m.ParseChallenge(challenge)
+	require.NoError(t, err)
+	assert.Equal(t, "Egor", login)
+
+	// Токен незавершенного входа не дает доступа
+	_, err = m.ParseAccess(challenge)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	accessToken, _, err := m.Issue("Egor")
+	require.NoError(t, err)
+	_, err = m.ParseChallenge(accessToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestTokenManager_Unlock(t *testing.T) {
+	m := NewTokenManager("secret", time.Minute, time.Hour, 5*time.Minute)
+
+	token, expiresAt, err := m.IssueUnlock("Egor", ScopeSecrets)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), expiresAt, time.Second)
+
+	login, err := m.ParseUnlock(token, ScopeSecrets)
+	require.NoError(t, err)
+	assert.Equal(t, "Egor", login)
+
+	// Разрешение действует только в своей области и не заменяет токен доступа
+	_, err = m.ParseUnlock(token, "secrets:write")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = m.ParseAccess(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	accessToken, _, err := m.Issue("Egor")
+	require.NoError(t, err)
+	_, err = m.ParseUnlock(accessToken, ScopeSecrets)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	expired := NewTokenManager("secret", time.Minute, time.Hour, -time.Minute)
+	token, _, err = expired.IssueUnlock("Egor", ScopeSecrets)
+	require.NoError(t, err)
+	_, err = m.ParseUnlock(token, ScopeSecrets)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
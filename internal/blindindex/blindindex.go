@@ -0,0 +1,159 @@
+// Package blindindex вычисляет слепые индексы названий элементов хранилища.
+//
+// Слепой индекс - это HMAC нормализованного значения на ключе, который знает только клиент.
+// Сервер хранит индексы рядом с данными и сравнивает их на равенство, поэтому поиск
+// по точному названию, по словам названия и по домену сайта работает без значений.
+// Сами названия и адреса сайтов пока хранятся открыто: индекс скрывает их от сервера,
+// только если клиент не передает их в других запросах.
+package blindindex
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// Области индекса. Одно и то же значение в разных областях дает разные термы.
+const (
+	ScopeName   = "name"   // Название целиком
+	ScopeToken  = "token"  // Отдельное слово названия
+	ScopeDomain = "domain" // Домен сайта
+)
+
+// TermLength - длина терма индекса в hex: первые 16 байт HMAC-SHA256.
+const TermLength = 32
+
+// keyLabel отделяет ключ индекса от других ключей, получаемых из мастер-ключа.
+const keyLabel = "goph-keeper/blind-index/v1"
+
+// DeriveKey получает ключ слепого индекса из мастер-ключа пользователя.
+func DeriveKey(masterKey []byte) []byte {
+	mac := hmac.New(sha256.New, masterKey)
+	mac.Write([]byte(keyLabel))
+	return mac.Sum(nil)
+}
+
+// Term вычисляет терм индекса значения в указанной области.
+func Term(key []byte, scope, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(scope))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:TermLength]
+}
+
+// ValidTerm проверяет, что строка похожа на терм индекса.
+func ValidTerm(term string) bool {
+	if len(term) != TermLength {
+		return false
+	}
+	for _, c := range term {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Tokens разбивает значение на слова без повторов.
+func Tokens(value string) []string {
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, word := range words(value) {
+		if !seen[word] {
+			seen[word] = true
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens
+}
+
+// Normalize приводит название к виду, в котором сравниваются точные совпадения:
+// слова через один пробел, без знаков препинания.
+func Normalize(value string) string {
+	return strings.Join(words(value), " ")
+}
+
+// NameTerms вычисляет термы названия: терм названия целиком и термы каждого слова.
+func NameTerms(key []byte, name string) []string {
+	normalized := Normalize(name)
+	if normalized == "" {
+		return nil
+	}
+	terms := []string{Term(key, ScopeName, normalized)}
+	for _, token := range Tokens(name) {
+		terms = append(terms, Term(key, ScopeToken, token))
+	}
+	return terms
+}
+
+// QueryTerms вычисляет термы строки поиска. При exact элемент должен совпасть с названием целиком,
+// иначе название должно содержать все слова строки поиска.
+func QueryTerms(key []byte, query string, exact bool) []string {
+	if exact {
+		normalized := Normalize(query)
+		if normalized == "" {
+			return nil
+		}
+		return []string{Term(key, ScopeName, normalized)}
+	}
+	var terms []string
+	for _, token := range Tokens(query) {
+		terms = append(terms, Term(key, ScopeToken, token))
+	}
+	return terms
+}
+
+// words разбивает значение на слова в нижнем регистре.
+// Словом считается последовательность букв и цифр, "ё" приравнивается к "е".
+func words(value string) []string {
+	value = strings.ReplaceAll(strings.ToLower(value), "ё", "е")
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Host извлекает из адреса сайта имя хоста в нижнем регистре без "www.".
+// Адрес без схемы считается адресом https.
+func Host(address string) string {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return ""
+	}
+	if !strings.Contains(address, "://") {
+		address = "https://" + address
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	return strings.TrimPrefix(host, "www.")
+}
+
+// DomainTerms вычисляет термы доменов сайтов: хоста каждого адреса и всех его родительских доменов
+// из двух и более частей, чтобы поиск по google.com находил mail.google.com.
+func DomainTerms(key []byte, addresses ...string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, address := range addresses {
+		host := Host(address)
+		if host == "" {
+			continue
+		}
+		// Сам хост индексируется всегда, даже если он из одной части, например localhost
+		labels := strings.Split(host, ".")
+		for i := 0; i == 0 || i+2 <= len(labels); i++ {
+			domain := strings.Join(labels[i:], ".")
+			if seen[domain] {
+				continue
+			}
+			seen[domain] = true
+			terms = append(terms, Term(key, ScopeDomain, domain))
+		}
+	}
+	return terms
+}
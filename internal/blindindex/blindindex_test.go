@@ -0,0 +1,57 @@
+package blindindex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testKey = DeriveKey([]byte("0123456789abcdef0123456789abcdef"))
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "почта работа gmail", Normalize("  Почта, Работа — Gmail! "))
+	assert.Equal(t, "елка", Normalize("Ёлка"))
+	assert.Equal(t, []string{"work", "mail"}, Tokens("Work mail, WORK"))
+}
+
+func TestNameTerms(t *testing.T) {
+	terms := NameTerms(testKey, "Work Mail")
+	assert.Len(t, terms, 3)
+	for _, term := range terms {
+		assert.True(t, ValidTerm(term))
+	}
+
+	// Точное совпадение не зависит от регистра и знаков препинания
+	assert.Equal(t, terms[:1], QueryTerms(testKey, "work, MAIL", true))
+	// Поиск по словам находит название, содержащее все слова в любом порядке
+	assert.Subset(t, terms, QueryTerms(testKey, "mail work", false))
+	assert.NotSubset(t, terms, QueryTerms(testKey, "mail home", false))
+
+	assert.Nil(t, NameTerms(testKey, " ,. "))
+	assert.Nil(t, QueryTerms(testKey, "", true))
+}
+
+func TestTermDependsOnKeyAndScope(t *testing.T) {
+	otherKey := DeriveKey([]byte("another master key"))
+	assert.NotEqual(t, Term(testKey, ScopeToken, "mail"), Term(otherKey, ScopeToken, "mail"))
+	assert.NotEqual(t, Term(testKey, ScopeToken, "mail"), Term(testKey, ScopeName, "mail"))
+}
+
+func TestDomainTerms(t *testing.T) {
+	assert.Equal(t, "mail.google.com", Host("https://www.Mail.Google.com./inbox"))
+	assert.Equal(t, "example.org", Host("example.org:8080/login"))
+	assert.Equal(t, "", Host("  "))
+
+	terms := DomainTerms(testKey, "https://mail.google.com/", "localhost")
+	assert.Equal(t, []string{
+		Term(testKey, ScopeDomain, "mail.google.com"),
+		Term(testKey, ScopeDomain, "google.com"),
+		Term(testKey, ScopeDomain, "localhost"),
+	}, terms)
+}
+
+func TestValidTerm(t *testing.T) {
+	assert.True(t, ValidTerm(Term(testKey, ScopeName, "x")))
+	assert.False(t, ValidTerm("ABCDEF"))
+	assert.False(t, ValidTerm("zz"+Term(testKey, ScopeName, "x")[2:]))
+}
@@ -0,0 +1,41 @@
+// Package blob хранит содержимое двоичных секретов вне базы данных.
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/egosha7/goph-keeper/internal/config"
+	"github.com/egosha7/goph-keeper/internal/domain"
+)
+
+// Типы хранилищ, задаваемые в конфигурации.
+const (
+	StoreLocal = "local"
+)
+
+// ErrNotFound возвращается, если объекта с указанным ключом нет.
+var ErrNotFound = domain.NotFound("blob not found")
+
+// Store хранит двоичные объекты по ключу.
+// Ключ состоит из сегментов, разделенных "/", и не содержит "." и ".." в качестве сегментов.
+type Store interface {
+	// Put сохраняет содержимое r под ключом key и возвращает количество записанных байт.
+	// Если чтение из r завершилось ошибкой, объект не сохраняется, а прежнее содержимое ключа не меняется.
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Get открывает объект для потокового чтения.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет объект. Отсутствие объекта ошибкой не считается.
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStore создает хранилище объектов по конфигурации сервера.
+func NewStore(cfg *config.Config) (Store, error) {
+	switch cfg.BlobStore {
+	case StoreLocal:
+		return NewLocalStore(cfg.BlobDir)
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", cfg.BlobStore)
+	}
+}
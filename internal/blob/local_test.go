@@ -0,0 +1,75 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingReader отдает часть данных и завершается ошибкой, как оборванное соединение.
+type failingReader struct {
+	data io.Reader
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.data.Read(p)
+	if err == io.EOF {
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func TestLocalStore_PutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	n, err := store.Put(ctx, "1/0", strings.NewReader("chunk"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+
+	rc, err := store.Get(ctx, "1/0")
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.Equal(t, "chunk", string(data))
+
+	require.NoError(t, store.Delete(ctx, "1/0"))
+	_, err = store.Get(ctx, "1/0")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Delete(ctx, "1/0"))
+}
+
+func TestLocalStore_FailedPutKeepsPrevious(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = store.Put(ctx, "1/0", strings.NewReader("old"))
+	require.NoError(t, err)
+	_, err = store.Put(ctx, "1/0", &failingReader{data: strings.NewReader("new")})
+	require.Error(t, err)
+
+	rc, err := store.Get(ctx, "1/0")
+	require.NoError(t, err)
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(data))
+}
+
+func TestLocalStore_InvalidKey(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../etc/passwd", "1//0", "1/./0", "/abs"} {
+		_, err := store.Put(context.Background(), key, strings.NewReader("x"))
+		assert.Error(t, err, key)
+	}
+}
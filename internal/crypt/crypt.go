@@ -1,67 +1,182 @@
-// Данный пакет не реализован в программе
-
+// Package crypt реализует шифрование данных хранилища.
+// Клиент шифрует каждый элемент мастер-ключом до отправки на сервер,
+// поэтому сервер хранит только шифротекст.
 package crypt
 
 import (
-	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Algorithm - идентификатор алгоритма AEAD, записываемый в заголовок конверта.
+type Algorithm byte
+
+// Поддерживаемые алгоритмы шифрования.
+const (
+	AES256GCM         Algorithm = 1
+	XChaCha20Poly1305 Algorithm = 2
 )
 
-// encrypt используется для шифрования данных
-func encrypt(data []byte, key []byte) ([]byte, error) {
-	block, err := aes.NewCipher(key)
+// Version1 - текущая версия формата конверта.
+const Version1 byte = 1
+
+// headerSize - размер заголовка без nonce: версия, алгоритм и идентификатор ключа.
+const headerSize = 1 + 1 + 4
+
+// Ошибки расшифровки.
+var (
+	ErrUnknownVersion   = errors.New("unknown envelope version")
+	ErrUnknownAlgorithm = errors.New("unknown encryption algorithm")
+	ErrMalformed        = errors.New("malformed envelope")
+	ErrWrongKey         = errors.New("envelope was encrypted with another key")
+	ErrTampered         = errors.New("envelope authentication failed")
+)
+
+// AssociatedData привязывает шифротекст к владельцу и записи.
+// Конверт, перенесенный в другую запись или к другому пользователю, не расшифруется.
+type AssociatedData struct {
+	Owner  string // Владелец записи
+	Record string // Идентификатор записи
+}
+
+// Envelope представляет разобранный конверт с шифротекстом.
+//
+// Формат: версия (1 байт) | алгоритм (1 байт) | идентификатор ключа (4 байта, big-endian) | nonce | шифротекст с тегом.
+// Заголовок целиком входит в аутентифицируемые данные.
+type Envelope struct {
+	Version    byte
+	Algorithm  Algorithm
+	KeyID      uint32
+	Nonce      []byte
+	Ciphertext []byte
+
+	header []byte
+}
+
+// KeyID вычисляет идентификатор ключа, по которому при расшифровке распознается неверный ключ.
+func KeyID(key []byte) uint32 {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("goph-keeper key id"))
+	return binary.BigEndian.Uint32(mac.Sum(nil))
+}
+
+// Encrypt шифрует данные ключом указанным алгоритмом и упаковывает результат в конверт.
+func Encrypt(alg Algorithm, key, plaintext []byte, ad AssociatedData) ([]byte, error) {
+	aead, err := newAEAD(alg, key)
 	if err != nil {
 		return nil, err
 	}
 
-	// Создаем генератор случайных чисел
-	iv := make([]byte, aes.BlockSize)
-	_, err = rand.Read(iv)
-	if err != nil {
+	out := make([]byte, headerSize+aead.NonceSize(), headerSize+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	out[0] = Version1
+	out[1] = byte(alg)
+	binary.BigEndian.PutUint32(out[2:headerSize], KeyID(key))
+
+	nonce := out[headerSize:]
+	if _, err := rand.Read(nonce); err != nil {
 		return nil, err
 	}
 
-	// Реализуем шифрование данных
-	mode := cipher.NewCBCEncrypter(block, iv)
-	paddedData := pkcs7Padding(data, aes.BlockSize)
-	encryptedData := make([]byte, len(paddedData))
-	mode.CryptBlocks(encryptedData, paddedData)
-
-	// Возвращаем зашифрованные данные с вектором инициализации
-	return append(iv, encryptedData...), nil
+	return aead.Seal(out, nonce, plaintext, additionalData(out, ad)), nil
 }
 
-// decrypt используется для дешифрования данных
-func decrypt(encryptedData []byte, key []byte) ([]byte, error) {
-	block, err := aes.NewCipher(key)
+// Decrypt проверяет и расшифровывает конверт, созданный функцией Encrypt.
+func Decrypt(key, data []byte, ad AssociatedData) ([]byte, error) {
+	env, err := ParseEnvelope(data)
 	if err != nil {
 		return nil, err
 	}
+	if env.KeyID != KeyID(key) {
+		return nil, ErrWrongKey
+	}
 
-	// Извлекаем вектор инициализации из зашифрованных данных
-	iv := encryptedData[:aes.BlockSize]
-	encryptedData = encryptedData[aes.BlockSize:]
+	aead, err := newAEAD(env.Algorithm, key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, additionalData(env.header, ad))
+	if err != nil {
+		return nil, ErrTampered
+	}
+	return plaintext, nil
+}
 
-	// Реализуем дешифрование данных
-	mode := cipher.NewCBCDecrypter(block, iv)
-	decryptedData := make([]byte, len(encryptedData))
-	mode.CryptBlocks(decryptedData, encryptedData)
+// ParseEnvelope разбирает заголовок конверта без расшифровки.
+// Позволяет узнать, каким ключом зашифрованы данные.
+func ParseEnvelope(data []byte) (*Envelope, error) {
+	if len(data) < headerSize {
+		return nil, ErrMalformed
+	}
+	if data[0] != Version1 {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, data[0])
+	}
 
-	// Убираем дополнительные байты padding'а
-	return pkcs7Unpadding(decryptedData), nil
+	alg := Algorithm(data[1])
+	nonceSize, overhead, err := sizes(alg)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerSize+nonceSize+overhead {
+		return nil, ErrMalformed
+	}
+
+	return &Envelope{
+		Version:    data[0],
+		Algorithm:  alg,
+		KeyID:      binary.BigEndian.Uint32(data[2:headerSize]),
+		Nonce:      data[headerSize : headerSize+nonceSize],
+		Ciphertext: data[headerSize+nonceSize:],
+		header:     data[:headerSize+nonceSize],
+	}, nil
 }
 
-// pkcs7Padding используется для дополнения данных до размера блока
-func pkcs7Padding(data []byte, blockSize int) []byte {
-	padding := blockSize - len(data)%blockSize
-	padText := bytes.Repeat([]byte{byte(padding)}, padding)
-	return append(data, padText...)
+// additionalData формирует аутентифицируемые данные из заголовка конверта и привязки к записи.
+// Поля предваряются длиной, чтобы исключить неоднозначность при конкатенации.
+func additionalData(header []byte, ad AssociatedData) []byte {
+	out := make([]byte, 0, len(header)+8+len(ad.Owner)+len(ad.Record))
+	out = append(out, header...)
+	for _, field := range []string{ad.Owner, ad.Record} {
+		out = binary.BigEndian.AppendUint32(out, uint32(len(field)))
+		out = append(out, field...)
+	}
+	return out
 }
 
-// pkcs7Unpadding используется для удаления padding'а из данных
-func pkcs7Unpadding(data []byte) []byte {
-	padding := int(data[len(data)-1])
-	return data[:len(data)-padding]
+// newAEAD создает AEAD указанного алгоритма для ключа.
+func newAEAD(alg Algorithm, key []byte) (cipher.AEAD, error) {
+	switch alg {
+	case AES256GCM:
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("invalid key size %d", len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case XChaCha20Poly1305:
+		return chacha20poly1305.NewX(key)
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownAlgorithm, alg)
+	}
+}
+
+// sizes возвращает размеры nonce и тега аутентификации алгоритма.
+func sizes(alg Algorithm) (nonceSize, overhead int, err error) {
+	switch alg {
+	case AES256GCM:
+		return 12, 16, nil
+	case XChaCha20Poly1305:
+		return chacha20poly1305.NonceSizeX, chacha20poly1305.Overhead, nil
+	default:
+		return 0, 0, fmt.Errorf("%w: %d", ErrUnknownAlgorithm, alg)
+	}
 }
@@ -0,0 +1,123 @@
+package crypt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKey(t *testing.T, password string) []byte {
+	t.Helper()
+	salt, err := NewSalt()
+	require.NoError(t, err)
+	return DeriveKey(password, salt)
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := newTestKey(t, "master")
+	ad := AssociatedData{Owner: "Egor", Record: "password/Email"}
+
+	for _, alg := range []Algorithm{AES256GCM, XChaCha20Poly1305} {
+		ciphertext, err := Encrypt(alg, key, []byte("password123"), ad)
+		require.NoError(t, err)
+		assert.NotContains(t, string(ciphertext), "password123")
+
+		env, err := ParseEnvelope(ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, Version1, env.Version)
+		assert.Equal(t, alg, env.Algorithm)
+		assert.Equal(t, KeyID(key), env.KeyID)
+
+		plaintext, err := Decrypt(key, ciphertext, ad)
+		require.NoError(t, err)
+		assert.Equal(t, "password123", string(plaintext))
+	}
+}
+
+func TestDecrypt_Errors(t *testing.T) {
+	key := newTestKey(t, "master")
+	ad := AssociatedData{Owner: "Egor", Record: "password/Email"}
+
+	ciphertext, err := Encrypt(XChaCha20Poly1305, key, []byte("password123"), ad)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		key  []byte
+		data func() []byte
+		ad   AssociatedData
+		err  error
+	}{
+		{
+			name: "Wrong Key",
+			key:  newTestKey(t, "wrong"),
+			data: func() []byte { return ciphertext },
+			ad:   ad,
+			err:  ErrWrongKey,
+		},
+		{
+			name: "Tampered Ciphertext",
+			key:  key,
+			data: func() []byte {
+				data := append([]byte(nil), ciphertext...)
+				data[len(data)-1] ^= 0xff
+				return data
+			},
+			ad:  ad,
+			err: ErrTampered,
+		},
+		{
+			name: "Swapped Record",
+			key:  key,
+			data: func() []byte { return ciphertext },
+			ad:   AssociatedData{Owner: "Egor", Record: "password/Bank"},
+			err:  ErrTampered,
+		},
+		{
+			name: "Swapped Owner",
+			key:  key,
+			data: func() []byte { return ciphertext },
+			ad:   AssociatedData{Owner: "Ivan", Record: "password/Email"},
+			err:  ErrTampered,
+		},
+		{
+			name: "Tampered Algorithm",
+			key:  key,
+			data: func() []byte {
+				data := append([]byte(nil), ciphertext...)
+				data[1] = 0x7f
+				return data
+			},
+			ad:  ad,
+			err: ErrUnknownAlgorithm,
+		},
+		{
+			name: "Unknown Version",
+			key:  key,
+			data: func() []byte {
+				data := append([]byte(nil), ciphertext...)
+				data[0] = 2
+				return data
+			},
+			ad:  ad,
+			err: ErrUnknownVersion,
+		},
+		{
+			name: "Truncated",
+			key:  key,
+			data: func() []byte { return ciphertext[:10] },
+			ad:   ad,
+			err:  ErrMalformed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				_, err := Decrypt(tc.key, tc.data(), tc.ad)
+				assert.ErrorIs(t, err, tc.err)
+			},
+		)
+	}
+}
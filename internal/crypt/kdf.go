@@ -0,0 +1,33 @@
+package crypt
+
+import (
+	"crypto/rand"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Параметры Argon2id для получения мастер-ключа (рекомендация RFC 9106).
+const (
+	argonTime    = 1
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+
+	// KeySize - длина мастер-ключа в байтах.
+	KeySize = 32
+	// SaltSize - длина соли пользователя в байтах.
+	SaltSize = 16
+)
+
+// NewSalt генерирует случайную соль для получения мастер-ключа.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// DeriveKey получает мастер-ключ из мастер-пароля пользователя и его соли с помощью Argon2id.
+func DeriveKey(masterPassword string, salt []byte) []byte {
+	return argon2.IDKey([]byte(masterPassword), salt, argonTime, argonMemory, argonThreads, KeySize)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/egosha7/goph-keeper/internal/config"
 	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 	"net/http"
 )
 
@@ -31,6 +32,21 @@ func ConnectToDB(cfg *config.Config) (*pgx.Conn, error) {
 	return conn, nil
 }
 
+// NewPool создает пул подключений к базе данных на основе конфигурации.
+func NewPool(cfg *config.Config) (*pgxpool.Pool, error) {
+	// Парсинг конфигурации для пула подключений
+	config, err := pgxpool.ParseConfig(cfg.DataBase)
+	if err != nil {
+		return nil, err
+	}
+
+	// Установка максимального количества соединений в пуле
+	config.MaxConns = 1000
+
+	// Создание пула подключений
+	return pgxpool.ConnectConfig(context.Background(), config)
+}
+
 // PingDB выполняет пинг базы данных и отправляет статус в HTTP-ответ.
 func PingDB(w http.ResponseWriter, r *http.Request, conn *pgx.Conn) {
 	err := conn.Ping(context.Background())
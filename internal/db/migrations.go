@@ -0,0 +1,303 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// migrations содержит идемпотентные инструкции для создания и обновления схемы базы данных.
+// Инструкции выполняются по порядку при каждом запуске сервера, новые добавляются в конец.
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS users (
+		id       SERIAL PRIMARY KEY,
+		login    TEXT NOT NULL UNIQUE,
+		password TEXT NOT NULL,
+		pin      TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS passwords (
+		id       SERIAL PRIMARY KEY,
+		id_user  INTEGER NOT NULL REFERENCES users (id),
+		name     TEXT NOT NULL,
+		password TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS cards (
+		id         SERIAL PRIMARY KEY,
+		id_user    INTEGER NOT NULL REFERENCES users (id),
+		name       TEXT NOT NULL,
+		number     TEXT NOT NULL,
+		expirydate TEXT NOT NULL,
+		cvv        TEXT NOT NULL
+	)`,
+	// Соль для получения мастер-ключа на стороне клиента
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS salt TEXT NOT NULL DEFAULT ''`,
+	// Ключи данных пользователей, обернутые ключом шифрования ключей указанной версии
+	`CREATE TABLE IF NOT EXISTS user_keys (
+		id_user     INTEGER PRIMARY KEY REFERENCES users (id),
+		wrapped_key BYTEA NOT NULL,
+		kek_version INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS user_keys_kek_version_idx ON user_keys (kek_version)`,
+	// История изменений: каждая ревизия хранит полный снимок записи, последняя совпадает с текущей
+	`CREATE TABLE IF NOT EXISTS password_revisions (
+		id_password INTEGER NOT NULL REFERENCES passwords (id) ON DELETE CASCADE,
+		revision    INTEGER NOT NULL,
+		name        TEXT NOT NULL,
+		password    TEXT NOT NULL,
+		changed_by  TEXT NOT NULL,
+		created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (id_password, revision)
+	)`,
+	`CREATE TABLE IF NOT EXISTS card_revisions (
+		id_card    INTEGER NOT NULL REFERENCES cards (id) ON DELETE CASCADE,
+		revision   INTEGER NOT NULL,
+		name       TEXT NOT NULL,
+		number     TEXT NOT NULL,
+		expirydate TEXT NOT NULL,
+		cvv        TEXT NOT NULL,
+		changed_by TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (id_card, revision)
+	)`,
+	// Записи, созданные до появления истории, получают первую ревизию из текущего значения
+	`INSERT INTO password_revisions (id_password, revision, name, password, changed_by)
+		SELECT p.id, 1, p.name, p.password, u.login FROM passwords p JOIN users u ON u.id = p.id_user
+		WHERE NOT EXISTS (SELECT 1 FROM password_revisions r WHERE r.id_password = p.id)`,
+	`INSERT INTO card_revisions (id_card, revision, name, number, expirydate, cvv, changed_by)
+		SELECT c.id, 1, c.name, c.number, c.expirydate, c.cvv, u.login FROM cards c JOIN users u ON u.id = c.id_user
+		WHERE NOT EXISTS (SELECT 1 FROM card_revisions r WHERE r.id_card = c.id)`,
+	// Корзина: удаленные записи помечаются временем удаления и окончательно удаляются по истечении срока хранения
+	`ALTER TABLE passwords ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ`,
+	`ALTER TABLE cards ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ`,
+	`CREATE INDEX IF NOT EXISTS passwords_deleted_at_idx ON passwords (deleted_at) WHERE deleted_at IS NOT NULL`,
+	`CREATE INDEX IF NOT EXISTS cards_deleted_at_idx ON cards (deleted_at) WHERE deleted_at IS NOT NULL`,
+	// Текстовые заметки
+	`CREATE TABLE IF NOT EXISTS notes (
+		id         SERIAL PRIMARY KEY,
+		id_user    INTEGER NOT NULL REFERENCES users (id),
+		name       TEXT NOT NULL,
+		body       TEXT NOT NULL,
+		deleted_at TIMESTAMPTZ
+	)`,
+	`CREATE INDEX IF NOT EXISTS notes_deleted_at_idx ON notes (deleted_at) WHERE deleted_at IS NOT NULL`,
+	`CREATE TABLE IF NOT EXISTS note_revisions (
+		id_note    INTEGER NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+		revision   INTEGER NOT NULL,
+		name       TEXT NOT NULL,
+		body       TEXT NOT NULL,
+		changed_by TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (id_note, revision)
+	)`,
+	// Двоичные файлы: метаданные в базе, содержимое частями в хранилище объектов
+	`CREATE TABLE IF NOT EXISTS files (
+		id         SERIAL PRIMARY KEY,
+		id_user    INTEGER NOT NULL REFERENCES users (id),
+		name       TEXT NOT NULL,
+		size       BIGINT NOT NULL,
+		chunk_size INTEGER NOT NULL,
+		chunks     INTEGER NOT NULL,
+		complete   BOOLEAN NOT NULL DEFAULT false,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE TABLE IF NOT EXISTS file_chunks (
+		id_file  INTEGER NOT NULL REFERENCES files (id) ON DELETE CASCADE,
+		chunk    INTEGER NOT NULL,
+		size     INTEGER NOT NULL,
+		checksum TEXT NOT NULL,
+		PRIMARY KEY (id_file, chunk)
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS files_user_name_idx ON files (id_user, name)`,
+	// Записи произвольного типа: поля зашифрованы одним конвертом, тип задается встроенным или пользовательским шаблоном
+	`CREATE TABLE IF NOT EXISTS record_templates (
+		id          SERIAL PRIMARY KEY,
+		id_user     INTEGER NOT NULL REFERENCES users (id),
+		name        TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		fields      JSONB NOT NULL,
+		UNIQUE (id_user, name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS records (
+		id         SERIAL PRIMARY KEY,
+		id_user    INTEGER NOT NULL REFERENCES users (id),
+		type       TEXT NOT NULL,
+		name       TEXT NOT NULL,
+		fields     TEXT NOT NULL,
+		metadata   JSONB NOT NULL DEFAULT '{}',
+		deleted_at TIMESTAMPTZ
+	)`,
+	`CREATE INDEX IF NOT EXISTS records_user_type_idx ON records (id_user, type) WHERE deleted_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS records_deleted_at_idx ON records (deleted_at) WHERE deleted_at IS NOT NULL`,
+	`CREATE TABLE IF NOT EXISTS record_revisions (
+		id_record  INTEGER NOT NULL REFERENCES records (id) ON DELETE CASCADE,
+		revision   INTEGER NOT NULL,
+		type       TEXT NOT NULL,
+		name       TEXT NOT NULL,
+		fields     TEXT NOT NULL,
+		metadata   JSONB NOT NULL,
+		changed_by TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (id_record, revision)
+	)`,
+	// Открытые метаданные, теги и папка паролей и карт, в том числе в их ревизиях
+	`ALTER TABLE passwords
+		ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}',
+		ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}',
+		ADD COLUMN IF NOT EXISTS folder TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE cards
+		ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}',
+		ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}',
+		ADD COLUMN IF NOT EXISTS folder TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE password_revisions
+		ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}',
+		ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}',
+		ADD COLUMN IF NOT EXISTS folder TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE card_revisions
+		ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}',
+		ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}',
+		ADD COLUMN IF NOT EXISTS folder TEXT NOT NULL DEFAULT ''`,
+	`CREATE INDEX IF NOT EXISTS passwords_tags_idx ON passwords USING GIN (tags)`,
+	`CREATE INDEX IF NOT EXISTS passwords_folder_idx ON passwords (id_user, folder)`,
+	`CREATE INDEX IF NOT EXISTS cards_tags_idx ON cards USING GIN (tags)`,
+	`CREATE INDEX IF NOT EXISTS cards_folder_idx ON cards (id_user, folder)`,
+	// Поиск: единое представление активных элементов хранилища и индексы по названию без учета регистра
+	`CREATE OR REPLACE VIEW vault_items AS
+		SELECT 'password' AS kind, p.id, p.id_user, 'password' AS type, p.name, p.tags, p.folder,
+			COALESCE((SELECT MAX(r.created_at) FROM password_revisions r WHERE r.id_password = p.id), 'epoch') AS updated_at
+		FROM passwords p WHERE p.deleted_at IS NULL
+		UNION ALL
+		SELECT 'card', c.id, c.id_user, 'card', c.name, c.tags, c.folder,
+			COALESCE((SELECT MAX(r.created_at) FROM card_revisions r WHERE r.id_card = c.id), 'epoch')
+		FROM cards c WHERE c.deleted_at IS NULL
+		UNION ALL
+		SELECT 'note', n.id, n.id_user, 'note', n.name, '{}'::TEXT[], '',
+			COALESCE((SELECT MAX(r.created_at) FROM note_revisions r WHERE r.id_note = n.id), 'epoch')
+		FROM notes n WHERE n.deleted_at IS NULL
+		UNION ALL
+		SELECT 'record', rc.id, rc.id_user, rc.type, rc.name, '{}'::TEXT[], '',
+			COALESCE((SELECT MAX(r.created_at) FROM record_revisions r WHERE r.id_record = rc.id), 'epoch')
+		FROM records rc WHERE rc.deleted_at IS NULL
+		UNION ALL
+		SELECT 'file', f.id, f.id_user, 'file', f.name, '{}'::TEXT[], '', f.created_at
+		FROM files f WHERE f.complete`,
+	`CREATE INDEX IF NOT EXISTS passwords_search_idx ON passwords (id_user, lower(name) text_pattern_ops) WHERE deleted_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS cards_search_idx ON cards (id_user, lower(name) text_pattern_ops) WHERE deleted_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS notes_search_idx ON notes (id_user, lower(name) text_pattern_ops) WHERE deleted_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS records_search_idx ON records (id_user, lower(name) text_pattern_ops) WHERE deleted_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS files_search_idx ON files (id_user, lower(name) text_pattern_ops) WHERE complete`,
+	// Триграммные индексы ускоряют поиск по подстроке; если расширение pg_trgm недоступно, поиск работает без них
+	`DO $$ BEGIN
+		CREATE EXTENSION IF NOT EXISTS pg_trgm;
+	EXCEPTION WHEN OTHERS THEN
+		RAISE NOTICE 'pg_trgm is not available: %', SQLERRM;
+	END $$`,
+	`DO $$ BEGIN
+		IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm') THEN
+			CREATE INDEX IF NOT EXISTS passwords_name_trgm_idx ON passwords USING GIN (lower(name) gin_trgm_ops) WHERE deleted_at IS NULL;
+			CREATE INDEX IF NOT EXISTS cards_name_trgm_idx ON cards USING GIN (lower(name) gin_trgm_ops) WHERE deleted_at IS NULL;
+			CREATE INDEX IF NOT EXISTS notes_name_trgm_idx ON notes USING GIN (lower(name) gin_trgm_ops) WHERE deleted_at IS NULL;
+			CREATE INDEX IF NOT EXISTS records_name_trgm_idx ON records USING GIN (lower(name) gin_trgm_ops) WHERE deleted_at IS NULL;
+			CREATE INDEX IF NOT EXISTS files_name_trgm_idx ON files USING GIN (lower(name) gin_trgm_ops) WHERE complete;
+		END IF;
+	END $$`,
+	// Адреса сайтов паролей с правилами сопоставления
+	`ALTER TABLE passwords ADD COLUMN IF NOT EXISTS urls JSONB NOT NULL DEFAULT '[]'`,
+	`ALTER TABLE password_revisions ADD COLUMN IF NOT EXISTS urls JSONB NOT NULL DEFAULT '[]'`,
+	// Ключ одноразовых паролей, привязанный к паролю; пустая строка - ключа нет
+	`ALTER TABLE passwords ADD COLUMN IF NOT EXISTS otp TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE password_revisions ADD COLUMN IF NOT EXISTS otp TEXT NOT NULL DEFAULT ''`,
+	// Второй фактор входа: ключ TOTP, ожидающий подтверждения ключ и номер периода последнего принятого кода
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT false`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_key TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_pending TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT NOT NULL DEFAULT 0`,
+	`CREATE TABLE IF NOT EXISTS recovery_codes (
+		id_user   INTEGER NOT NULL REFERENCES users (id),
+		code_hash TEXT NOT NULL,
+		PRIMARY KEY (id_user, code_hash)
+	)`,
+	// Счетчик неудачных проверок пин-кода и время, до которого проверка заблокирована
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS pin_failures INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS pin_locked_until TIMESTAMPTZ NOT NULL DEFAULT 'epoch'`,
+	// Название элемента уникально среди элементов пользователя вне корзины. Дубликаты, добавленные до появления
+	// ограничения, перемещаются в корзину: остается самый ранний элемент, остальные можно восстановить после переименования
+	`UPDATE passwords d SET deleted_at = now() WHERE d.deleted_at IS NULL AND EXISTS (
+		SELECT 1 FROM passwords o WHERE o.id_user = d.id_user AND o.name = d.name AND o.deleted_at IS NULL AND o.id < d.id
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS passwords_user_name_idx ON passwords (id_user, name) WHERE deleted_at IS NULL`,
+	`UPDATE cards d SET deleted_at = now() WHERE d.deleted_at IS NULL AND EXISTS (
+		SELECT 1 FROM cards o WHERE o.id_user = d.id_user AND o.name = d.name AND o.deleted_at IS NULL AND o.id < d.id
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS cards_user_name_idx ON cards (id_user, name) WHERE deleted_at IS NULL`,
+	`UPDATE notes d SET deleted_at = now() WHERE d.deleted_at IS NULL AND EXISTS (
+		SELECT 1 FROM notes o WHERE o.id_user = d.id_user AND o.name = d.name AND o.deleted_at IS NULL AND o.id < d.id
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS notes_user_name_idx ON notes (id_user, name) WHERE deleted_at IS NULL`,
+	`UPDATE records d SET deleted_at = now() WHERE d.deleted_at IS NULL AND EXISTS (
+		SELECT 1 FROM records o WHERE o.id_user = d.id_user AND o.name = d.name AND o.deleted_at IS NULL AND o.id < d.id
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS records_user_name_idx ON records (id_user, name) WHERE deleted_at IS NULL`,
+	// Счетчик неудачных проверок второго фактора и время, до которого проверка заблокирована
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_failures INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_locked_until TIMESTAMPTZ NOT NULL DEFAULT 'epoch'`,
+	// Отметка паролей и карт, которые могли остаться открытыми со времен до шифрования на сервере. Строки, существовавшие
+	// при добавлении столбца, отмечаются, новые строки всегда зашифрованы. Отметку снимает команда encrypt-legacy
+	`ALTER TABLE passwords ADD COLUMN IF NOT EXISTS legacy BOOLEAN NOT NULL DEFAULT true`,
+	`ALTER TABLE passwords ALTER COLUMN legacy SET DEFAULT false`,
+	`ALTER TABLE password_revisions ADD COLUMN IF NOT EXISTS legacy BOOLEAN NOT NULL DEFAULT true`,
+	`ALTER TABLE password_revisions ALTER COLUMN legacy SET DEFAULT false`,
+	`ALTER TABLE cards ADD COLUMN IF NOT EXISTS legacy BOOLEAN NOT NULL DEFAULT true`,
+	`ALTER TABLE cards ALTER COLUMN legacy SET DEFAULT false`,
+	`ALTER TABLE card_revisions ADD COLUMN IF NOT EXISTS legacy BOOLEAN NOT NULL DEFAULT true`,
+	`ALTER TABLE card_revisions ALTER COLUMN legacy SET DEFAULT false`,
+	// Слепой индекс: термы HMAC названий и доменов сайтов паролей, вычисленные клиентом на своем ключе
+	`ALTER TABLE passwords ADD COLUMN IF NOT EXISTS name_terms TEXT[] NOT NULL DEFAULT '{}',
+		ADD COLUMN IF NOT EXISTS domain_terms TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE password_revisions ADD COLUMN IF NOT EXISTS name_terms TEXT[] NOT NULL DEFAULT '{}',
+		ADD COLUMN IF NOT EXISTS domain_terms TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE cards ADD COLUMN IF NOT EXISTS name_terms TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE card_revisions ADD COLUMN IF NOT EXISTS name_terms TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE notes ADD COLUMN IF NOT EXISTS name_terms TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE note_revisions ADD COLUMN IF NOT EXISTS name_terms TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE records ADD COLUMN IF NOT EXISTS name_terms TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE record_revisions ADD COLUMN IF NOT EXISTS name_terms TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE files ADD COLUMN IF NOT EXISTS name_terms TEXT[] NOT NULL DEFAULT '{}'`,
+	`CREATE INDEX IF NOT EXISTS passwords_blind_idx ON passwords USING GIN ((name_terms || domain_terms)) WHERE deleted_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS cards_blind_idx ON cards USING GIN (name_terms) WHERE deleted_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS notes_blind_idx ON notes USING GIN (name_terms) WHERE deleted_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS records_blind_idx ON records USING GIN (name_terms) WHERE deleted_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS files_blind_idx ON files USING GIN (name_terms) WHERE complete`,
+	// Представление для поиска с термами слепого индекса. vault_items не заменяется: инструкции выполняются
+	// при каждом запуске, и ее прежнее определение не может удалить добавленный в конец столбец
+	`CREATE OR REPLACE VIEW vault_search_items AS
+		SELECT 'password' AS kind, p.id, p.id_user, 'password' AS type, p.name, p.tags, p.folder,
+			COALESCE((SELECT MAX(r.created_at) FROM password_revisions r WHERE r.id_password = p.id), 'epoch') AS updated_at,
+			p.name_terms || p.domain_terms AS blind_terms
+		FROM passwords p WHERE p.deleted_at IS NULL
+		UNION ALL
+		SELECT 'card', c.id, c.id_user, 'card', c.name, c.tags, c.folder,
+			COALESCE((SELECT MAX(r.created_at) FROM card_revisions r WHERE r.id_card = c.id), 'epoch'), c.name_terms
+		FROM cards c WHERE c.deleted_at IS NULL
+		UNION ALL
+		SELECT 'note', n.id, n.id_user, 'note', n.name, '{}'::TEXT[], '',
+			COALESCE((SELECT MAX(r.created_at) FROM note_revisions r WHERE r.id_note = n.id), 'epoch'), n.name_terms
+		FROM notes n WHERE n.deleted_at IS NULL
+		UNION ALL
+		SELECT 'record', rc.id, rc.id_user, rc.type, rc.name, '{}'::TEXT[], '',
+			COALESCE((SELECT MAX(r.created_at) FROM record_revisions r WHERE r.id_record = rc.id), 'epoch'), rc.name_terms
+		FROM records rc WHERE rc.deleted_at IS NULL
+		UNION ALL
+		SELECT 'file', f.id, f.id_user, 'file', f.name, '{}'::TEXT[], '', f.created_at, f.name_terms
+		FROM files f WHERE f.complete`,
+}
+
+// Migrate приводит схему базы данных к актуальному состоянию.
+func Migrate(ctx context.Context, conn *pgx.Conn) error {
+	for i, migration := range migrations {
+		if _, err := conn.Exec(ctx, migration); err != nil {
+			return fmt.Errorf("migration %d failed: %w", i, err)
+		}
+	}
+	return nil
+}
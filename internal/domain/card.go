@@ -2,20 +2,29 @@ package domain
 
 // CardData содержит информацию о карте.
 type CardData struct {
-	Login    string `json:"login"`    // Логин пользователя
 	CardName string `json:"cardName"` // Название карты
 }
 
 // NewCardData содержит информацию о новой карте.
+// Реквизиты шифруются клиентом, сервер получает и хранит только шифротекст в base64.
 type NewCardData struct {
-	Login          string `json:"login"`          // Логин пользователя
 	CardName       string `json:"cardName"`       // Название новой карты
-	NumberCard     string `json:"numberCard"`     // Номер новой карты
-	ExpiryDateCard string `json:"expiryDateCard"` // Срок новой карты
-	CvvCard        string `json:"CvvCard"`        // Секретный код новой карты
+	NumberCard     string `json:"numberCard"`     // Зашифрованный номер новой карты
+	ExpiryDateCard string `json:"expiryDateCard"` // Зашифрованный срок новой карты
+	CvvCard        string `json:"CvvCard"`        // Зашифрованный секретный код новой карты
 }
 
-// CardInfo содержит информацию о банковской карте.
+// UpdateCardData содержит информацию об изменении карты.
+// При переименовании клиент заново шифрует реквизиты под новым названием.
+type UpdateCardData struct {
+	CardName       string `json:"cardName"`       // Текущее название карты
+	NewCardName    string `json:"newCardName"`    // Новое название карты, пустое - без переименования
+	NumberCard     string `json:"numberCard"`     // Зашифрованный номер карты
+	ExpiryDateCard string `json:"expiryDateCard"` // Зашифрованный срок карты
+	CvvCard        string `json:"CvvCard"`        // Зашифрованный секретный код карты
+}
+
+// CardInfo содержит зашифрованные реквизиты банковской карты.
 type CardInfo struct {
 	Number     string `json:"number"`     // Номер карты
 	ExpiryDate string `json:"expiryDate"` // Срок действия карты
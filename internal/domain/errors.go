@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Виды ошибок, по которым определяется ответ клиенту. Проверяются через errors.Is.
+// Ошибки, не относящиеся ни к одному виду, считаются внутренними ошибками сервера.
+var (
+	ErrNotFound     = errors.New("not found")         // Запрошенный объект не существует
+	ErrConflict     = errors.New("conflict")          // Объект уже существует или находится в несовместимом состоянии
+	ErrUnauthorized = errors.New("unauthorized")      // Неверные учетные данные
+	ErrForbidden    = errors.New("forbidden")         // Действие запрещено для пользователя
+	ErrLocked       = errors.New("locked")            // Действие временно заблокировано
+	ErrValidation   = errors.New("validation failed") // Некорректные данные запроса
+)
+
+// Error - ошибка одного из видов с описанием, которое можно показать клиенту.
+type Error struct {
+	Kind    error             // Вид ошибки, например ErrNotFound
+	Message string            // Описание ошибки
+	Details map[string]string // Дополнительные сведения, например название поля
+}
+
+// Error возвращает описание ошибки.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap возвращает вид ошибки, чтобы его можно было проверить через errors.Is.
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// NotFound возвращает ошибку вида ErrNotFound.
+func NotFound(format string, args ...interface{}) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// Conflict возвращает ошибку вида ErrConflict.
+func Conflict(format string, args ...interface{}) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// Unauthorized возвращает ошибку вида ErrUnauthorized.
+func Unauthorized(format string, args ...interface{}) error {
+	return &Error{Kind: ErrUnauthorized, Message: fmt.Sprintf(format, args...)}
+}
+
+// Forbidden возвращает ошибку вида ErrForbidden.
+func Forbidden(format string, args ...interface{}) error {
+	return &Error{Kind: ErrForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+// Invalid возвращает ошибку вида ErrValidation.
+func Invalid(format string, args ...interface{}) error {
+	return &Error{Kind: ErrValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// InvalidField возвращает ошибку вида ErrValidation с названием некорректного поля в подробностях.
+func InvalidField(field, format string, args ...interface{}) error {
+	return &Error{Kind: ErrValidation, Message: fmt.Sprintf(format, args...), Details: map[string]string{"field": field}}
+}
+
+// Коды ошибок в ответах API, по которым клиент выбирает, как реагировать.
+const (
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"
+	CodeUnauthorized    = "unauthorized"
+	CodeForbidden       = "forbidden"
+	CodeLocked          = "locked"
+	CodeValidation      = "validation"
+	CodeTooManyRequests = "too_many_requests"
+	CodeInternal        = "internal"
+)
+
+// ErrorResponse - тело ответа API с ошибкой.
+type ErrorResponse struct {
+	Code    string            `json:"code"`              // Код ошибки, например not_found
+	Message string            `json:"message"`           // Описание ошибки для пользователя
+	Details map[string]string `json:"details,omitempty"` // Дополнительные сведения
+}
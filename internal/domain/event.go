@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// Действия над элементами хранилища в ленте изменений.
+const (
+	ActionCreated = "created" // Элемент добавлен или восстановлен из корзины
+	ActionUpdated = "updated" // Элемент изменен или переименован
+	ActionDeleted = "deleted" // Элемент удален или перемещен в корзину
+	ActionReset   = "reset"   // События до курсора утрачены: клиенту нужно заново загрузить списки
+)
+
+// ChangeEvent описывает изменение элемента хранилища пользователя.
+type ChangeEvent struct {
+	Cursor  string    `json:"cursor"`            // Позиция события в ленте; с нее лента возобновляется после переподключения
+	Action  string    `json:"action"`            // Действие
+	Kind    string    `json:"kind,omitempty"`    // Вид элемента: password, card, note, file, record
+	Name    string    `json:"name,omitempty"`    // Название элемента
+	NewName string    `json:"newName,omitempty"` // Новое название, если элемент переименован
+	At      time.Time `json:"at"`                // Время изменения
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// FileData содержит информацию о файле.
+type FileData struct {
+	FileName string `json:"fileName"` // Название файла
+}
+
+// NewFileData содержит информацию о загружаемом файле.
+type NewFileData struct {
+	FileName string `json:"fileName"` // Название файла
+	Size     int64  `json:"size"`     // Размер исходного файла в байтах
+}
+
+// UploadData содержит идентификатор загрузки.
+type UploadData struct {
+	ID int `json:"id"` // Идентификатор файла
+}
+
+// FileInfo описывает файл и разбиение его содержимого на части.
+// Каждая часть шифруется клиентом отдельно, поэтому часть в хранилище больше исходной на размер конверта.
+type FileInfo struct {
+	ID        int       `json:"id"`        // Идентификатор файла
+	FileName  string    `json:"fileName"`  // Название файла
+	Size      int64     `json:"size"`      // Размер исходного файла в байтах
+	ChunkSize int       `json:"chunkSize"` // Размер исходной части в байтах, последняя часть может быть меньше
+	Chunks    int       `json:"chunks"`    // Количество частей
+	Complete  bool      `json:"complete"`  // Загружены ли все части
+	CreatedAt time.Time `json:"createdAt"` // Время начала загрузки
+}
+
+// UploadSession описывает начатую или возобновленную загрузку файла.
+type UploadSession struct {
+	FileInfo
+	Uploaded []int `json:"uploaded"` // Номера уже загруженных частей
+}
+
+// FileChunk описывает загруженную часть файла.
+type FileChunk struct {
+	Index    int    // Номер части, начиная с 0
+	Size     int64  // Размер сохраненной части в байтах
+	Checksum string // SHA-256 сохраненной части в hex
+}
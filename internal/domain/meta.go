@@ -0,0 +1,64 @@
+package domain
+
+// ItemMeta содержит открытые сведения об элементе хранилища: метаданные, теги и папку.
+// В отличие от секретов эти сведения не шифруются клиентом, чтобы сервер мог фильтровать по ним.
+type ItemMeta struct {
+	Metadata map[string]string `json:"metadata"` // Метаданные ключ-значение, например сайт или банк
+	Tags     []string          `json:"tags"`     // Произвольные теги
+	Folder   string            `json:"folder"`   // Путь папки через "/", пустой - корень
+}
+
+// ItemFilter содержит фильтр списка элементов хранилища.
+type ItemFilter struct {
+	Tag    string `json:"tag"`    // Тег, пустой - без фильтра по тегу
+	Folder string `json:"folder"` // Папка вместе с вложенными, пустая - без фильтра по папке
+}
+
+// ItemSummary описывает элемент хранилища в списке.
+type ItemSummary struct {
+	Name string `json:"name"` // Название элемента
+	ItemMeta
+}
+
+// PasswordMetaData содержит новые метаданные, теги и папку пароля.
+type PasswordMetaData struct {
+	PassName string `json:"passName"` // Название пароля
+	ItemMeta
+}
+
+// CardMetaData содержит новые метаданные, теги и папку карты.
+type CardMetaData struct {
+	CardName string `json:"cardName"` // Название карты
+	ItemMeta
+}
+
+// Правила сопоставления адреса сайта пароля с адресом страницы.
+const (
+	MatchDomain = "domain" // Совпадает базовый домен по списку публичных суффиксов, например example.co.uk
+	MatchHost   = "host"   // Совпадает имя хоста и порт, если он указан
+	MatchStarts = "starts" // Адрес страницы начинается с адреса сайта
+	MatchRegex  = "regex"  // Адрес страницы соответствует регулярному выражению
+)
+
+// LoginURL описывает адрес сайта, для которого подходит пароль.
+type LoginURL struct {
+	URL   string `json:"url"`   // Адрес сайта или регулярное выражение
+	Match string `json:"match"` // Правило сопоставления, по умолчанию domain
+}
+
+// PasswordURLsData содержит адреса сайтов пароля.
+type PasswordURLsData struct {
+	PassName string     `json:"passName"` // Название пароля
+	URLs     []LoginURL `json:"urls"`     // Адреса сайтов
+}
+
+// MatchData содержит адрес страницы, для которой подбираются пароли.
+type MatchData struct {
+	URL string `json:"url"` // Адрес страницы
+}
+
+// LoginMatch описывает пароль, подходящий для адреса страницы.
+type LoginMatch struct {
+	PassName string   `json:"passName"` // Название пароля
+	Rule     LoginURL `json:"rule"`     // Сработавшее правило
+}
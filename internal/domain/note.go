@@ -0,0 +1,34 @@
+package domain
+
+// NoteData содержит информацию о заметке.
+type NoteData struct {
+	Title string `json:"title"` // Заголовок заметки
+}
+
+// NewNoteData содержит информацию о новой заметке.
+// Текст шифруется клиентом, сервер получает и хранит только шифротекст в base64.
+type NewNoteData struct {
+	Title string `json:"title"` // Заголовок новой заметки
+	Body  string `json:"body"`  // Зашифрованный текст заметки
+}
+
+// UpdateNoteData содержит информацию об изменении заметки.
+// При переименовании клиент заново шифрует текст под новым заголовком.
+type UpdateNoteData struct {
+	Title    string `json:"title"`    // Текущий заголовок заметки
+	NewTitle string `json:"newTitle"` // Новый заголовок заметки, пустой - без переименования
+	Body     string `json:"body"`     // Зашифрованный текст заметки
+}
+
+// NoteRevisionData содержит информацию о запрашиваемой ревизии заметки.
+type NoteRevisionData struct {
+	Title    string `json:"title"`    // Текущий заголовок заметки
+	Revision int    `json:"revision"` // Номер ревизии
+}
+
+// NoteRevision содержит ревизию заметки.
+// Текст зашифрован клиентом под заголовком, указанным в ревизии.
+type NoteRevision struct {
+	Revision
+	Body string `json:"body"` // Зашифрованный текст заметки
+}
@@ -2,13 +2,34 @@ package domain
 
 // PassData содержит информацию о пароле.
 type PassData struct {
-	Login    string `json:"login"`    // Логин пользователя
 	PassName string `json:"passName"` // Название пароля
 }
 
 // PasswordData содержит информацию о новом пароле.
+// Пароль шифруется клиентом, сервер получает и хранит только шифротекст в base64.
 type PasswordData struct {
-	Login    string `json:"login"`    // Логин пользователя
 	PassName string `json:"passName"` // Название нового пароля
-	Password string `json:"password"` // Новый пароль
+	Password string `json:"password"` // Зашифрованный пароль
+}
+
+// UpdatePasswordData содержит информацию об изменении пароля.
+// При переименовании клиент заново шифрует пароль под новым названием.
+type UpdatePasswordData struct {
+	PassName    string `json:"passName"`    // Текущее название пароля
+	NewPassName string `json:"newPassName"` // Новое название пароля, пустое - без переименования
+	Password    string `json:"password"`    // Зашифрованный пароль
+}
+
+// PasswordOTPData содержит ключ одноразовых паролей, привязываемый к паролю.
+// Ключ в формате otpauth:// шифруется клиентом; пустой ключ отвязывает одноразовые пароли.
+type PasswordOTPData struct {
+	PassName string `json:"passName"` // Название пароля
+	OTP      string `json:"otp"`      // Зашифрованный ключ одноразовых паролей
+}
+
+// PasswordEntry содержит пароль вместе с привязанным ключом одноразовых паролей.
+type PasswordEntry struct {
+	PassName string `json:"passName"`      // Название пароля
+	Password string `json:"password"`      // Зашифрованный пароль
+	OTP      string `json:"otp,omitempty"` // Зашифрованный ключ одноразовых паролей
 }
@@ -0,0 +1,95 @@
+package domain
+
+import "time"
+
+// FieldType - тип поля записи. Определяет, как клиент проверяет и показывает значение.
+type FieldType string
+
+// Поддерживаемые типы полей.
+const (
+	FieldText    FieldType = "text"     // Произвольный текст
+	FieldHidden  FieldType = "hidden"   // Секрет, который клиент показывает только по запросу
+	FieldURL     FieldType = "url"      // Адрес сайта
+	FieldEmail   FieldType = "email"    // Адрес электронной почты
+	FieldDate    FieldType = "date"     // Дата в формате ГГГГ-ММ-ДД
+	FieldOTPSeed FieldType = "otp-seed" // Ключ одноразовых паролей: otpauth:// или секрет в base32
+)
+
+// FieldSpec описывает поле шаблона записи.
+type FieldSpec struct {
+	Name      string    `json:"name"`                // Название поля
+	Type      FieldType `json:"type"`                // Тип поля
+	Required  bool      `json:"required,omitempty"`  // Обязательно ли поле
+	Multiline bool      `json:"multiline,omitempty"` // Может ли значение занимать несколько строк
+}
+
+// Template описывает тип записи: набор полей, которые ожидаются в записях этого типа.
+// Встроенные шаблоны общие для всех пользователей, пользовательские видны только владельцу.
+type Template struct {
+	Name        string      `json:"name"`        // Название шаблона, оно же тип записи
+	Description string      `json:"description"` // Описание шаблона
+	Fields      []FieldSpec `json:"fields"`      // Поля шаблона
+	BuiltIn     bool        `json:"builtIn"`     // Встроенный ли шаблон
+}
+
+// TemplateData содержит название шаблона.
+type TemplateData struct {
+	Name string `json:"name"` // Название шаблона
+}
+
+// Field - поле записи. Значение шифруется клиентом, сервер хранит только шифротекст в base64.
+type Field struct {
+	Name  string    `json:"name"`  // Название поля
+	Type  FieldType `json:"type"`  // Тип поля
+	Value string    `json:"value"` // Зашифрованное значение поля
+}
+
+// Record - запись хранилища произвольного типа.
+// Помимо полей шаблона запись может содержать собственные поля пользователя.
+type Record struct {
+	Type     string            `json:"type"`               // Тип записи - название шаблона
+	Name     string            `json:"name"`               // Название записи
+	Fields   []Field           `json:"fields"`             // Поля записи
+	Metadata map[string]string `json:"metadata,omitempty"` // Открытые метаданные записи
+}
+
+// RecordData содержит название записи.
+type RecordData struct {
+	Name string `json:"name"` // Название записи
+}
+
+// RecordListData содержит фильтр списка записей.
+type RecordListData struct {
+	Type string `json:"type"` // Тип записей, пустой - все типы
+}
+
+// RecordSummary описывает запись в списке без ее полей.
+type RecordSummary struct {
+	Name      string    `json:"name"`      // Название записи
+	Type      string    `json:"type"`      // Тип записи
+	UpdatedAt time.Time `json:"updatedAt"` // Время последнего изменения
+}
+
+// UpdateRecordData содержит информацию об изменении записи.
+// При переименовании клиент заново шифрует значения полей под новым названием.
+type UpdateRecordData struct {
+	Name     string            `json:"name"`               // Текущее название записи
+	NewName  string            `json:"newName"`            // Новое название записи, пустое - без переименования
+	Fields   []Field           `json:"fields"`             // Поля записи
+	Metadata map[string]string `json:"metadata,omitempty"` // Открытые метаданные записи
+}
+
+// RecordRevisionData содержит информацию о запрашиваемой ревизии записи.
+type RecordRevisionData struct {
+	Name     string `json:"name"`     // Текущее название записи
+	Revision int    `json:"revision"` // Номер ревизии
+}
+
+// RecordRevision содержит ревизию записи.
+// Значения полей зашифрованы клиентом под названием, указанным в ревизии.
+type RecordRevision struct {
+	Revision
+	Type     string            `json:"type"`               // Тип записи
+	Fields   []Field           `json:"fields"`             // Поля записи
+	Metadata map[string]string `json:"metadata,omitempty"` // Открытые метаданные записи
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// Revision описывает одну ревизию записи хранилища.
+type Revision struct {
+	Revision  int       `json:"revision"`  // Номер ревизии, начиная с 1
+	Name      string    `json:"name"`      // Название записи в этой ревизии
+	ChangedBy string    `json:"changedBy"` // Логин пользователя, создавшего ревизию
+	CreatedAt time.Time `json:"createdAt"` // Время создания ревизии
+}
+
+// PasswordRevisionData содержит информацию о запрашиваемой ревизии пароля.
+type PasswordRevisionData struct {
+	PassName string `json:"passName"` // Текущее название пароля
+	Revision int    `json:"revision"` // Номер ревизии
+}
+
+// CardRevisionData содержит информацию о запрашиваемой ревизии карты.
+type CardRevisionData struct {
+	CardName string `json:"cardName"` // Текущее название карты
+	Revision int    `json:"revision"` // Номер ревизии
+}
+
+// PasswordRevision содержит ревизию пароля.
+// Пароль зашифрован клиентом под названием, указанным в ревизии.
+type PasswordRevision struct {
+	Revision
+	Password string `json:"password"` // Зашифрованный пароль
+}
+
+// CardRevision содержит ревизию карты.
+// Реквизиты зашифрованы клиентом под названием, указанным в ревизии.
+type CardRevision struct {
+	Revision
+	CardInfo
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+// Виды элементов хранилища, по которым выполняется поиск.
+const (
+	KindPassword = "password"
+	KindCard     = "card"
+	KindNote     = "note"
+	KindFile     = "file"
+	KindRecord   = "record"
+)
+
+// Режимы сопоставления строки поиска с названием.
+const (
+	MatchSubstring = "substring" // Название содержит строку поиска
+	MatchPrefix    = "prefix"    // Название начинается со строки поиска
+)
+
+// Порядок сортировки результатов поиска. Префикс "-" означает обратный порядок.
+const (
+	SortName        = "name"
+	SortNameDesc    = "-name"
+	SortUpdated     = "updated"
+	SortUpdatedDesc = "-updated"
+)
+
+// SearchQuery содержит параметры поиска по элементам хранилища.
+// Пустые поля не ограничивают результат.
+type SearchQuery struct {
+	Query  string   `json:"query"`  // Строка поиска по названию без учета регистра
+	Match  string   `json:"match"`  // Режим сопоставления, по умолчанию substring
+	Kinds  []string `json:"kinds"`  // Виды элементов: password, card, note, file, record
+	Types  []string `json:"types"`  // Типы элементов; у записей - название шаблона, у остальных совпадает с видом
+	Tag    string   `json:"tag"`    // Тег
+	Folder string   `json:"folder"` // Папка вместе с вложенными
+	Sort   string   `json:"sort"`   // Порядок сортировки, по умолчанию name
+	Limit  int      `json:"limit"`  // Размер страницы, по умолчанию 20
+	Terms  []string `json:"terms"`  // Термы слепого индекса; элемент должен содержать все
+	Cursor string   `json:"cursor"` // Курсор следующей страницы из предыдущего ответа
+}
+
+// SearchItem описывает найденный элемент хранилища.
+type SearchItem struct {
+	Kind      string    `json:"kind"`      // Вид элемента
+	Type      string    `json:"type"`      // Тип элемента
+	Name      string    `json:"name"`      // Название
+	Tags      []string  `json:"tags"`      // Теги
+	Folder    string    `json:"folder"`    // Папка
+	UpdatedAt time.Time `json:"updatedAt"` // Время последнего изменения
+}
+
+// SearchResult содержит страницу результатов поиска.
+type SearchResult struct {
+	Items      []SearchItem `json:"items"`                // Найденные элементы
+	NextCursor string       `json:"nextCursor,omitempty"` // Курсор следующей страницы, пустой - страница последняя
+}
+
+// Поля элемента, по которым строится слепой индекс.
+const (
+	IndexName   = "name"   // Название элемента
+	IndexDomain = "domain" // Домены сайтов пароля
+)
+
+// BlindIndexData содержит термы слепого индекса элемента, вычисленные клиентом.
+// Термы заменяют прежние термы того же поля.
+type BlindIndexData struct {
+	Kind  string   `json:"kind"`  // Вид элемента
+	Name  string   `json:"name"`  // Название элемента
+	Field string   `json:"field"` // Индексируемое поле, по умолчанию name
+	Terms []string `json:"terms"` // Термы индекса в hex
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// TrashItem описывает запись, находящуюся в корзине.
+type TrashItem struct {
+	ID        int       `json:"id"`        // Идентификатор записи
+	Name      string    `json:"name"`      // Название записи
+	DeletedAt time.Time `json:"deletedAt"` // Время удаления
+}
+
+// TrashData содержит информацию о записи в корзине.
+// Название не уникально среди удаленных записей, поэтому запись выбирается по идентификатору.
+type TrashData struct {
+	ID int `json:"id"` // Идентификатор записи
+}
@@ -1,24 +1,90 @@
 package domain
 
+import "time"
+
 // User структура для представления пользователя
 type User struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
 	Pin      string `json:"pin"`
+	Salt     string `json:"salt"` // Соль мастер-ключа в base64, генерируется клиентом
+}
+
+// SaltData представляет соль, из которой клиент получает мастер-ключ.
+type SaltData struct {
+	Salt string `json:"salt"` // Соль в base64
 }
 
-// UserInfo представляет информацию о пользователе.
-type UserInfo struct {
-	Login string `json:"Login"` // Логин пользователя
+// Tokens представляет пару токенов, выдаваемую после аутентификации.
+type Tokens struct {
+	AccessToken  string `json:"accessToken"`  // Токен доступа
+	RefreshToken string `json:"refreshToken"` // Токен обновления
+}
+
+// RefreshData представляет данные для обновления пары токенов.
+type RefreshData struct {
+	RefreshToken string `json:"refreshToken"` // Токен обновления
 }
 
 // CheckPinData представляет данные для проверки пин-кода.
 type CheckPinData struct {
-	Login string `json:"login"` // Логин пользователя
-	Pin   string `json:"pin"`   // Пин-код
+	Pin string `json:"pin"` // Пин-код
+}
+
+// UnlockHeader - заголовок запроса, в котором передается токен разблокировки.
+const UnlockHeader = "X-Unlock-Token"
+
+// UnlockGrant представляет разрешение на чтение секретов, выданное после проверки пин-кода.
+type UnlockGrant struct {
+	Token     string    `json:"unlockToken"` // Токен разблокировки
+	ExpiresAt time.Time `json:"expiresAt"`   // Время, после которого нужно снова ввести пин-код
 }
 
 // CheckPinResponse представляет ответ о результате проверки пин-кода.
 type CheckPinResponse struct {
 	Valid bool `json:"valid"` // Результат проверки пин-кода
 }
+
+// SecondFactorChallenge представляет ответ на вход, который нужно подтвердить вторым фактором.
+type SecondFactorChallenge struct {
+	SecondFactorRequired bool   `json:"secondFactorRequired"` // Всегда true: нужен код второго фактора
+	Challenge            string `json:"challenge"`            // Токен незавершенного входа
+}
+
+// SecondFactorData представляет данные для завершения входа вторым фактором.
+type SecondFactorData struct {
+	Challenge string `json:"challenge"` // Токен незавершенного входа
+	Code      string `json:"code"`      // Код TOTP или код восстановления
+}
+
+// SecondFactorCodeData представляет код второго фактора для подтверждения подключения или отключения.
+type SecondFactorCodeData struct {
+	Code string `json:"code"` // Код TOTP или, при отключении, код восстановления
+}
+
+// SecondFactorEnrollment представляет ключ TOTP, выданный при подключении второго фактора.
+type SecondFactorEnrollment struct {
+	URI string `json:"uri"` // Ключ в формате otpauth:// для приложения-аутентификатора
+}
+
+// RecoveryCodes представляет одноразовые коды восстановления доступа без аутентификатора.
+type RecoveryCodes struct {
+	Codes []string `json:"codes"`
+}
+
+// SecondFactor описывает состояние второго фактора пользователя.
+type SecondFactor struct {
+	Enabled     bool      // Второй фактор подключен
+	Key         string    // Ключ TOTP в формате otpauth://
+	Pending     string    // Ключ, выданный при подключении и еще не подтвержденный кодом
+	LastStep    int64     // Номер периода последнего принятого кода
+	Failures    int       // Количество неудачных проверок кода подряд
+	LockedUntil time.Time // Время, до которого проверка кода заблокирована
+}
+
+// PinState описывает сохраненный пин-код пользователя и состояние защиты от перебора.
+type PinState struct {
+	Hash        string    // Хеш пин-кода; у пользователей, зарегистрированных до хеширования, - открытый пин-код
+	Failures    int       // Количество неудачных проверок подряд
+	LockedUntil time.Time // Время, до которого проверка пин-кода заблокирована
+}
@@ -0,0 +1,188 @@
+// Package events - шина событий изменения хранилища.
+// Сервисный слой публикует в шину изменения элементов, а транспорты (gRPC и Server-Sent Events)
+// передают их подписчикам - клиентам того же пользователя.
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+)
+
+// DefaultHistorySize - сколько последних событий каждого пользователя хранится для возобновления ленты.
+const DefaultHistorySize = 1000
+
+// subscriptionBuffer - сколько событий может ждать отправки одному подписчику.
+// Подписчик, отставший сильнее, отключается и возобновляет ленту с последнего курсора.
+const subscriptionBuffer = 64
+
+// Bus - шина событий в памяти процесса.
+// Каждое событие получает курсор вида "<эпоха>-<номер>": эпоха меняется при перезапуске сервера,
+// поэтому курсор, выданный до перезапуска, распознается как устаревший.
+type Bus struct {
+	mu          sync.Mutex
+	epoch       string
+	seq         uint64
+	historySize int
+	users       map[string]*userFeed
+	now         func() time.Time
+}
+
+// userFeed - лента одного пользователя.
+type userFeed struct {
+	history []domain.ChangeEvent
+	dropped uint64 // Номер последнего вытесненного из истории события
+	subs    map[*Subscription]struct{}
+}
+
+// NewBus создает шину, хранящую до historySize последних событий каждого пользователя.
+func NewBus(historySize int) *Bus {
+	if historySize < 1 {
+		historySize = DefaultHistorySize
+	}
+	epoch := make([]byte, 4)
+	if _, err := rand.Read(epoch); err != nil {
+		panic(err)
+	}
+	return &Bus{
+		epoch:       hex.EncodeToString(epoch),
+		historySize: historySize,
+		users:       make(map[string]*userFeed),
+		now:         time.Now,
+	}
+}
+
+// Publish присваивает событию курсор и время и передает его подписчикам пользователя.
+func (b *Bus) Publish(login string, event domain.ChangeEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	event.Cursor = b.cursor(b.seq)
+	event.At = b.now()
+
+	feed := b.feed(login)
+	if len(feed.history) == b.historySize {
+		feed.dropped = b.seqOf(feed.history[0])
+		feed.history = append(feed.history[:0], feed.history[1:]...)
+	}
+	feed.history = append(feed.history, event)
+
+	for sub := range feed.subs {
+		select {
+		case sub.ch <- event:
+		default:
+			// Подписчик не успевает забирать события: отключаем его, чтобы не задерживать остальных
+			b.unsubscribe(login, sub)
+		}
+	}
+}
+
+// Subscribe подписывает на события пользователя и возвращает события после курсора,
+// которые подписчик пропустил. Пустой курсор означает подписку только на новые события.
+// Если события после курсора уже вытеснены из истории или курсор выдан до перезапуска сервера,
+// вместо них возвращается одно событие reset.
+func (b *Bus) Subscribe(login, cursor string) (*Subscription, []domain.ChangeEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	feed := b.feed(login)
+	backlog := b.backlog(feed, cursor)
+
+	sub := &Subscription{bus: b, login: login, ch: make(chan domain.ChangeEvent, subscriptionBuffer)}
+	feed.subs[sub] = struct{}{}
+	return sub, backlog
+}
+
+// backlog возвращает события ленты после курсора.
+func (b *Bus) backlog(feed *userFeed, cursor string) []domain.ChangeEvent {
+	if cursor == "" {
+		return nil
+	}
+
+	seq, ok := b.parseCursor(cursor)
+	if !ok || seq < feed.dropped || seq > b.seq {
+		return []domain.ChangeEvent{{Cursor: b.cursor(b.seq), Action: domain.ActionReset, At: b.now()}}
+	}
+
+	var events []domain.ChangeEvent
+	for _, event := range feed.history {
+		if b.seqOf(event) > seq {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// feed возвращает ленту пользователя, создавая ее при необходимости.
+func (b *Bus) feed(login string) *userFeed {
+	feed, ok := b.users[login]
+	if !ok {
+		feed = &userFeed{subs: make(map[*Subscription]struct{})}
+		b.users[login] = feed
+	}
+	return feed
+}
+
+// unsubscribe отключает подписчика и закрывает его канал.
+func (b *Bus) unsubscribe(login string, sub *Subscription) {
+	feed, ok := b.users[login]
+	if !ok {
+		return
+	}
+	if _, ok := feed.subs[sub]; !ok {
+		return
+	}
+	delete(feed.subs, sub)
+	close(sub.ch)
+}
+
+// cursor формирует курсор события с номером seq.
+func (b *Bus) cursor(seq uint64) string {
+	return fmt.Sprintf("%s-%d", b.epoch, seq)
+}
+
+// parseCursor возвращает номер события из курсора текущей эпохи.
+func (b *Bus) parseCursor(cursor string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(cursor, "-")
+	if !ok || epoch != b.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// seqOf возвращает номер события, опубликованного шиной.
+func (b *Bus) seqOf(event domain.ChangeEvent) uint64 {
+	seq, _ := b.parseCursor(event.Cursor)
+	return seq
+}
+
+// Subscription - подписка на события пользователя.
+type Subscription struct {
+	bus   *Bus
+	login string
+	ch    chan domain.ChangeEvent
+}
+
+// Events возвращает канал событий. Канал закрывается после Close
+// или если подписчик отстал и был отключен - тогда ленту нужно возобновить с последнего курсора.
+func (s *Subscription) Events() <-chan domain.ChangeEvent {
+	return s.ch
+}
+
+// Close отменяет подписку. Повторный вызов ничего не делает.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.unsubscribe(s.login, s)
+}
@@ -0,0 +1,84 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus_PublishSubscribe(t *testing.T) {
+	bus := NewBus(10)
+	sub, backlog := bus.Subscribe("Egor", "")
+	defer sub.Close()
+	assert.Empty(t, backlog)
+
+	bus.Publish("Anna", domain.ChangeEvent{Action: domain.ActionCreated, Kind: domain.KindCard, Name: "Visa"})
+	bus.Publish("Egor", domain.ChangeEvent{Action: domain.ActionCreated, Kind: domain.KindPassword, Name: "Email"})
+
+	event := <-sub.Events()
+	assert.Equal(t, "Email", event.Name)
+	assert.NotEmpty(t, event.Cursor)
+	assert.False(t, event.At.IsZero())
+	assert.Empty(t, sub.Events(), "события другого пользователя не доставляются")
+}
+
+func TestBus_Resume(t *testing.T) {
+	bus := NewBus(3)
+	bus.Publish("Egor", domain.ChangeEvent{Action: domain.ActionCreated, Name: "a"})
+
+	first, _ := bus.Subscribe("Egor", "")
+	bus.Publish("Egor", domain.ChangeEvent{Action: domain.ActionCreated, Name: "b"})
+	cursor := (<-first.Events()).Cursor
+	first.Close()
+
+	bus.Publish("Egor", domain.ChangeEvent{Action: domain.ActionUpdated, Name: "b"})
+	bus.Publish("Egor", domain.ChangeEvent{Action: domain.ActionDeleted, Name: "b"})
+
+	testCases := []struct {
+		name    string
+		cursor  string
+		actions []string
+	}{
+		{name: "From cursor", cursor: cursor, actions: []string{domain.ActionUpdated, domain.ActionDeleted}},
+		{name: "Other epoch", cursor: "00000000-1", actions: []string{domain.ActionReset}},
+		{name: "Garbage", cursor: "cursor", actions: []string{domain.ActionReset}},
+		{name: "Evicted", cursor: bus.cursor(0), actions: []string{domain.ActionReset}},
+		{name: "Future", cursor: bus.cursor(100), actions: []string{domain.ActionReset}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				sub, backlog := bus.Subscribe("Egor", tc.cursor)
+				defer sub.Close()
+
+				var actions []string
+				for _, event := range backlog {
+					actions = append(actions, event.Action)
+				}
+				assert.Equal(t, tc.actions, actions)
+			},
+		)
+	}
+}
+
+func TestBus_SlowSubscriber(t *testing.T) {
+	bus := NewBus(DefaultHistorySize)
+	sub, _ := bus.Subscribe("Egor", "")
+
+	for i := 0; i <= subscriptionBuffer; i++ {
+		bus.Publish("Egor", domain.ChangeEvent{Action: domain.ActionUpdated, Name: "Email"})
+	}
+
+	// Отставший подписчик получает накопленные события, после чего канал закрывается
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+	require.Equal(t, subscriptionBuffer, received)
+
+	// Повторная отмена подписки безопасна
+	sub.Close()
+}
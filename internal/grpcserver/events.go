@@ -0,0 +1,57 @@
+package grpcserver
+
+import (
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/keeperpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// WatchChanges передает изменения хранилища текущего пользователя, начиная с событий после курсора.
+func (s *Server) WatchChanges(req *keeperpb.WatchChangesRequest, stream keeperpb.Keeper_WatchChangesServer) error {
+	ctx := stream.Context()
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return err
+	}
+
+	sub, backlog, err := s.services.WatchChanges(login, req.GetCursor())
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	for _, event := range backlog {
+		if err := stream.Send(eventToProto(event)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Клиент отстал и был отключен: он переподключится с последним курсором
+				return status.Error(codes.Unavailable, "Лента изменений прервана, повторите вызов с последним курсором")
+			}
+			if err := stream.Send(eventToProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// eventToProto преобразует событие изменения в сообщение.
+func eventToProto(event domain.ChangeEvent) *keeperpb.ChangeEvent {
+	return &keeperpb.ChangeEvent{
+		Cursor:  event.Cursor,
+		Action:  event.Action,
+		Kind:    event.Kind,
+		Name:    event.Name,
+		NewName: event.NewName,
+		At:      timestamppb.New(event.At),
+	}
+}
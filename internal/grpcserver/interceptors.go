@@ -0,0 +1,287 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/auth"
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/keeperpb"
+	"github.com/egosha7/goph-keeper/internal/ratelimit"
+	"github.com/egosha7/goph-keeper/internal/service"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods - методы, доступные без токена доступа.
+var publicMethods = map[string]bool{
+	keeperpb.Keeper_Register_FullMethodName:      true,
+	keeperpb.Keeper_Login_FullMethodName:         true,
+	keeperpb.Keeper_CompleteLogin_FullMethodName: true,
+	keeperpb.Keeper_RefreshTokens_FullMethodName: true,
+}
+
+// retryAfterKey - ключ трейлера с числом секунд до повторной попытки.
+const retryAfterKey = "retry-after"
+
+// LoggingInterceptor логирует вызовы методов с кодом результата и длительностью.
+func LoggingInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(logger, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamLoggingInterceptor логирует потоковые вызовы после их завершения.
+func StreamLoggingInterceptor(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(logger, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// logCall записывает в журнал завершенный вызов.
+func logCall(logger *zap.Logger, method string, start time.Time, err error) {
+	logger.Info(
+		"Request completed",
+		zap.String("Method", method),
+		zap.String("Code", status.Code(err).String()),
+		zap.Duration("Duration", time.Since(start)),
+	)
+}
+
+// ErrorInterceptor переводит ошибки сервисного слоя в статусы gRPC.
+// Ошибки, для которых статус не определен, скрываются от клиента за кодом Internal.
+func ErrorInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, toStatus(ctx, logger, info.FullMethod, err)
+		}
+		return resp, nil
+	}
+}
+
+// StreamErrorInterceptor переводит ошибки потоковых вызовов в статусы gRPC.
+func StreamErrorInterceptor(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return toStatus(ss.Context(), logger, info.FullMethod, err)
+		}
+		return nil
+	}
+}
+
+// toStatus возвращает статус gRPC для ошибки. Ошибки, уже являющиеся статусом, не меняются,
+// ошибки сервисного слоя переводятся по виду из domain, остальные считаются внутренними.
+func toStatus(ctx context.Context, logger *zap.Logger, method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var (
+		locked             *service.PinLockedError
+		secondFactorLocked *service.SecondFactorLockedError
+	)
+	switch {
+	case errors.As(err, &locked):
+		setRetryAfter(ctx, time.Until(locked.Until))
+		return status.Error(codes.ResourceExhausted, "Проверка пин-кода временно заблокирована")
+	case errors.As(err, &secondFactorLocked):
+		setRetryAfter(ctx, time.Until(secondFactorLocked.Until))
+		return status.Error(codes.ResourceExhausted, "Проверка второго фактора временно заблокирована")
+	case errors.Is(err, auth.ErrInvalidToken):
+		return status.Error(codes.Unauthenticated, "Недействительный токен")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "Запрос отменен")
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "Истекло время ожидания")
+	case errors.Is(err, domain.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domain.ErrUnauthorized):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, domain.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domain.ErrLocked):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrValidation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	logger.Error("Ошибка при выполнении запроса", zap.String("Method", method), zap.Error(err))
+	return status.Error(codes.Internal, "Внутренняя ошибка сервера")
+}
+
+// AuthInterceptor определяет пользователя по токену доступа из метаданных authorization
+// и сохраняет его логин в контексте. Открытые методы пропускаются без проверки.
+func AuthInterceptor(services *service.Service, logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, services, logger, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor определяет пользователя потокового вызова так же, как AuthInterceptor.
+func StreamAuthInterceptor(services *service.Service, logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), services, logger, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate возвращает контекст с логином владельца токена доступа.
+func authenticate(ctx context.Context, services *service.Service, logger *zap.Logger, method string) (context.Context, error) {
+	if publicMethods[method] {
+		return ctx, nil
+	}
+
+	// Ожидаем метаданные вида `authorization: Bearer <token>`.
+	token, found := strings.CutPrefix(metadataValue(ctx, "authorization"), "Bearer ")
+	if !found || token == "" {
+		return nil, status.Error(codes.Unauthenticated, "Требуется токен доступа")
+	}
+
+	login, err := services.ParseAccessToken(token)
+	if err != nil {
+		logger.Info("Недействительный токен доступа", zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "Недействительный токен доступа")
+	}
+	return auth.WithLogin(ctx, login), nil
+}
+
+// serverStream - поток с контекстом, дополненным перехватчиком.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context возвращает контекст потока.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// RateLimit - ограничение частоты вызовов группы методов для IP-адреса и для учетной записи.
+// Группы совпадают с группами маршрутов REST API, поэтому корзины общие для обоих протоколов.
+type RateLimit struct {
+	Group   string           // Название группы
+	IP      ratelimit.Policy // Ограничение для одного IP-адреса
+	Account ratelimit.Policy // Ограничение для одной учетной записи
+}
+
+// RateLimitInterceptor ограничивает частоту вызовов. Группа выбирается функцией limitOf по имени метода.
+// Учетная запись берется из контекста, а для открытых методов - из поля login запроса.
+// При недоступности хранилища состояния вызов пропускается, чтобы сбой не останавливал сервис.
+func RateLimitInterceptor(
+	limiter ratelimit.Limiter, limitOf func(method string) RateLimit, logger *zap.Logger,
+) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := allow(ctx, limiter, limitOf(info.FullMethod), accountOf(ctx, req), logger); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamRateLimitInterceptor ограничивает частоту открытия потоков так же, как RateLimitInterceptor.
+func StreamRateLimitInterceptor(
+	limiter ratelimit.Limiter, limitOf func(method string) RateLimit, logger *zap.Logger,
+) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		if err := allow(ctx, limiter, limitOf(info.FullMethod), accountOf(ctx, nil), logger); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// allow расходует маркеры корзин IP-адреса и учетной записи.
+// Если лимит исчерпан, возвращает статус ResourceExhausted.
+func allow(ctx context.Context, limiter ratelimit.Limiter, limit RateLimit, login string, logger *zap.Logger) error {
+	keys := []string{"ip:" + peerIP(ctx)}
+	policies := []ratelimit.Policy{limit.IP}
+	if login != "" && limit.Account.Enabled() {
+		keys = append(keys, ratelimit.AccountKey(login))
+		policies = append(policies, limit.Account)
+	}
+
+	for i, key := range keys {
+		ok, retryAfter, err := limiter.Allow(ctx, limit.Group+":"+key, policies[i])
+		if err != nil {
+			logger.Warn("Ошибка хранилища ограничений частоты запросов", zap.Error(err))
+			continue
+		}
+		if !ok {
+			logger.Info("Превышено ограничение частоты запросов", zap.String("group", limit.Group), zap.String("key", key))
+			setRetryAfter(ctx, retryAfter)
+			return status.Error(codes.ResourceExhausted, "Слишком много запросов, повторите позже")
+		}
+	}
+	return nil
+}
+
+// setRetryAfter передает клиенту в трейлере, через сколько секунд можно повторить вызов.
+func setRetryAfter(ctx context.Context, d time.Duration) {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	_ = grpc.SetTrailer(ctx, metadata.Pairs(retryAfterKey, strconv.Itoa(seconds)))
+}
+
+// metadataValue возвращает первое значение входящих метаданных по ключу.
+func metadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// peerIP возвращает IP-адрес клиента из адреса соединения.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// accountOf возвращает логин, к которому относится вызов.
+func accountOf(ctx context.Context, req interface{}) string {
+	if login, ok := auth.LoginFromContext(ctx); ok {
+		return login
+	}
+	if r, ok := req.(interface{ GetLogin() string }); ok {
+		return r.GetLogin()
+	}
+	return ""
+}
@@ -0,0 +1,278 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/keeperpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AddPassword добавляет пароль.
+func (s *Server) AddPassword(ctx context.Context, req *keeperpb.Password) (*emptypb.Empty, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.services.AddPassword(login, req.GetName(), req.GetPassword()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// GetPassword возвращает пароль вместе с ключом одноразовых паролей.
+func (s *Server) GetPassword(ctx context.Context, req *keeperpb.ItemRequest) (*keeperpb.Password, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireUnlock(ctx, login); err != nil {
+		return nil, err
+	}
+
+	entry, err := s.services.GetPasswordEntry(login, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return &keeperpb.Password{Name: entry.PassName, Password: entry.Password, Otp: entry.OTP}, nil
+}
+
+// ListPasswords возвращает названия паролей.
+func (s *Server) ListPasswords(ctx context.Context, _ *emptypb.Empty) (*keeperpb.NameList, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := s.services.GetPasswordNameList(login)
+	if err != nil {
+		return nil, err
+	}
+	return &keeperpb.NameList{Names: names}, nil
+}
+
+// UpdatePassword изменяет или переименовывает пароль.
+func (s *Server) UpdatePassword(ctx context.Context, req *keeperpb.UpdatePasswordRequest) (*emptypb.Empty, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.services.UpdatePassword(login, req.GetName(), req.GetNewName(), req.GetPassword()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// DeletePassword перемещает пароль в корзину.
+func (s *Server) DeletePassword(ctx context.Context, req *keeperpb.ItemRequest) (*emptypb.Empty, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.services.DeletePassword(login, req.GetName()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// AddCard добавляет карту.
+func (s *Server) AddCard(ctx context.Context, req *keeperpb.Card) (*emptypb.Empty, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.services.AddCard(login, req.GetName(), req.GetNumber(), req.GetExpiryDate(), req.GetCvv()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// GetCard возвращает реквизиты карты.
+func (s *Server) GetCard(ctx context.Context, req *keeperpb.ItemRequest) (*keeperpb.Card, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireUnlock(ctx, login); err != nil {
+		return nil, err
+	}
+
+	number, expiryDate, cvv, err := s.services.GetCard(login, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return &keeperpb.Card{Name: req.GetName(), Number: number, ExpiryDate: expiryDate, Cvv: cvv}, nil
+}
+
+// ListCards возвращает названия карт.
+func (s *Server) ListCards(ctx context.Context, _ *emptypb.Empty) (*keeperpb.NameList, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := s.services.GetCardNameList(login)
+	if err != nil {
+		return nil, err
+	}
+	return &keeperpb.NameList{Names: names}, nil
+}
+
+// UpdateCard изменяет или переименовывает карту.
+func (s *Server) UpdateCard(ctx context.Context, req *keeperpb.UpdateCardRequest) (*emptypb.Empty, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.services.UpdateCard(login, req.GetName(), req.GetNewName(), req.GetNumber(), req.GetExpiryDate(), req.GetCvv())
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// DeleteCard перемещает карту в корзину.
+func (s *Server) DeleteCard(ctx context.Context, req *keeperpb.ItemRequest) (*emptypb.Empty, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.services.DeleteCard(login, req.GetName()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// Search ищет элементы хранилища любого вида.
+func (s *Server) Search(ctx context.Context, req *keeperpb.SearchRequest) (*keeperpb.SearchResponse, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.services.SearchItems(
+		login, domain.SearchQuery{
+			Query:  req.GetQuery(),
+			Match:  req.GetMatch(),
+			Kinds:  req.GetKinds(),
+			Types:  req.GetTypes(),
+			Tag:    req.GetTag(),
+			Folder: req.GetFolder(),
+			Sort:   req.GetSort(),
+			Limit:  int(req.GetLimit()),
+			Terms:  req.GetTerms(),
+			Cursor: req.GetCursor(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &keeperpb.SearchResponse{NextCursor: result.NextCursor}
+	for _, item := range result.Items {
+		resp.Items = append(
+			resp.Items, &keeperpb.SearchItem{
+				Kind:      item.Kind,
+				Type:      item.Type,
+				Name:      item.Name,
+				Tags:      item.Tags,
+				Folder:    item.Folder,
+				UpdatedAt: timestamppb.New(item.UpdatedAt),
+			},
+		)
+	}
+	return resp, nil
+}
+
+// AddRecord добавляет запись произвольного типа.
+func (s *Server) AddRecord(ctx context.Context, req *keeperpb.Record) (*emptypb.Empty, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	record := domain.Record{
+		Type:     req.GetType(),
+		Name:     req.GetName(),
+		Fields:   fieldsFromProto(req.GetFields()),
+		Metadata: req.GetMetadata(),
+	}
+	if err := s.services.AddRecord(login, record); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// GetRecord возвращает запись.
+func (s *Server) GetRecord(ctx context.Context, req *keeperpb.ItemRequest) (*keeperpb.Record, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireUnlock(ctx, login); err != nil {
+		return nil, err
+	}
+
+	record, err := s.services.GetRecord(login, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return &keeperpb.Record{
+		Type:     record.Type,
+		Name:     record.Name,
+		Fields:   fieldsToProto(record.Fields),
+		Metadata: record.Metadata,
+	}, nil
+}
+
+// UpdateRecord изменяет или переименовывает запись.
+func (s *Server) UpdateRecord(ctx context.Context, req *keeperpb.UpdateRecordRequest) (*emptypb.Empty, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.services.UpdateRecord(login, req.GetName(), req.GetNewName(), fieldsFromProto(req.GetFields()), req.GetMetadata())
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// DeleteRecord перемещает запись в корзину.
+func (s *Server) DeleteRecord(ctx context.Context, req *keeperpb.ItemRequest) (*emptypb.Empty, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.services.DeleteRecord(login, req.GetName()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// fieldsFromProto преобразует поля записи из сообщений.
+func fieldsFromProto(fields []*keeperpb.Field) []domain.Field {
+	result := make([]domain.Field, 0, len(fields))
+	for _, f := range fields {
+		result = append(result, domain.Field{Name: f.GetName(), Type: domain.FieldType(f.GetType()), Value: f.GetValue()})
+	}
+	return result
+}
+
+// fieldsToProto преобразует поля записи в сообщения.
+func fieldsToProto(fields []domain.Field) []*keeperpb.Field {
+	result := make([]*keeperpb.Field, 0, len(fields))
+	for _, f := range fields {
+		result = append(result, &keeperpb.Field{Name: f.Name, Type: string(f.Type), Value: f.Value})
+	}
+	return result
+}
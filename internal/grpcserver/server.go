@@ -0,0 +1,197 @@
+// Package grpcserver реализует gRPC-сервис Keeper поверх сервисного слоя.
+// Сервис работает рядом с REST API и использует те же сервисы, токены и ограничения частоты запросов.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/egosha7/goph-keeper/internal/auth"
+	"github.com/egosha7/goph-keeper/internal/config"
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/keeperpb"
+	"github.com/egosha7/goph-keeper/internal/ratelimit"
+	"github.com/egosha7/goph-keeper/internal/service"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server - реализация keeperpb.KeeperServer.
+type Server struct {
+	keeperpb.UnimplementedKeeperServer
+	services *service.Service
+	logger   *zap.Logger
+}
+
+// NewServer создает реализацию сервиса Keeper.
+func NewServer(services *service.Service, logger *zap.Logger) *Server {
+	return &Server{services: services, logger: logger}
+}
+
+// New создает gRPC-сервер с зарегистрированным сервисом Keeper и цепочкой перехватчиков:
+// логирование, перевод ошибок в статусы, аутентификация и ограничение частоты вызовов.
+// Дополнительные параметры, например учетные данные TLS, передаются в opts.
+func New(
+	cfg *config.Config, services *service.Service, limiter ratelimit.Limiter, logger *zap.Logger, opts ...grpc.ServerOption,
+) *grpc.Server {
+	limitOf := func(method string) RateLimit {
+		switch {
+		case publicMethods[method]:
+			return RateLimit{Group: "auth", IP: cfg.AuthRateIP, Account: cfg.AuthRateAccount}
+		case method == keeperpb.Keeper_CheckPin_FullMethodName:
+			return RateLimit{Group: "pin", IP: cfg.PinRateIP, Account: cfg.PinRateAccount}
+		default:
+			return RateLimit{Group: "api", IP: cfg.APIRateIP, Account: cfg.APIRateAccount}
+		}
+	}
+
+	opts = append(
+		opts,
+		grpc.ChainUnaryInterceptor(
+			LoggingInterceptor(logger),
+			ErrorInterceptor(logger),
+			AuthInterceptor(services, logger),
+			RateLimitInterceptor(limiter, limitOf, logger),
+		),
+		grpc.ChainStreamInterceptor(
+			StreamLoggingInterceptor(logger),
+			StreamErrorInterceptor(logger),
+			StreamAuthInterceptor(services, logger),
+			StreamRateLimitInterceptor(limiter, limitOf, logger),
+		),
+	)
+	s := grpc.NewServer(opts...)
+	keeperpb.RegisterKeeperServer(s, NewServer(services, logger))
+	return s
+}
+
+// currentLogin возвращает логин пользователя, определенный AuthInterceptor.
+func currentLogin(ctx context.Context) (string, error) {
+	login, ok := auth.LoginFromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "Пользователь не аутентифицирован")
+	}
+	return login, nil
+}
+
+// requireUnlock проверяет токен разблокировки из метаданных x-unlock-token.
+// Без действующего токена секреты не выдаются, даже если токен доступа верен.
+func (s *Server) requireUnlock(ctx context.Context, login string) error {
+	token := metadataValue(ctx, strings.ToLower(domain.UnlockHeader))
+	if token == "" {
+		return status.Error(codes.PermissionDenied, "Требуется ввести пин-код")
+	}
+	owner, err := s.services.ParseUnlockToken(token)
+	if err != nil || owner != login {
+		return status.Error(codes.PermissionDenied, "Требуется ввести пин-код")
+	}
+	return nil
+}
+
+// Register регистрирует пользователя и выдает пару токенов.
+func (s *Server) Register(_ context.Context, req *keeperpb.RegisterRequest) (*keeperpb.LoginResponse, error) {
+	user := &domain.User{Login: req.GetLogin(), Password: req.GetPassword(), Pin: req.GetPin(), Salt: req.GetSalt()}
+	if err := s.services.RegisterUser(user); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.services.IssueTokens(user.Login)
+	if err != nil {
+		return nil, err
+	}
+	return &keeperpb.LoginResponse{Tokens: tokensToProto(tokens)}, nil
+}
+
+// Login проверяет логин и пароль.
+// С подключенным вторым фактором вместо токенов выдается токен незавершенного входа.
+func (s *Server) Login(_ context.Context, req *keeperpb.LoginRequest) (*keeperpb.LoginResponse, error) {
+	user := &domain.User{Login: req.GetLogin(), Password: req.GetPassword()}
+	if err := s.services.AuthenticateUser(user); err != nil {
+		s.logger.Info("Failed to check user validity", zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "Неверная пара логин/пароль")
+	}
+
+	challenge, err := s.services.LoginChallenge(user.Login)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != "" {
+		return &keeperpb.LoginResponse{SecondFactorRequired: true, Challenge: challenge}, nil
+	}
+
+	tokens, err := s.services.IssueTokens(user.Login)
+	if err != nil {
+		return nil, err
+	}
+	return &keeperpb.LoginResponse{Tokens: tokensToProto(tokens)}, nil
+}
+
+// CompleteLogin завершает вход кодом второго фактора.
+func (s *Server) CompleteLogin(_ context.Context, req *keeperpb.CompleteLoginRequest) (*keeperpb.Tokens, error) {
+	tokens, err := s.services.CompleteLogin(req.GetChallenge(), req.GetCode())
+	if errors.Is(err, domain.ErrLocked) {
+		// Проверка заблокирована после неудачных попыток: статус и время повтора задает toStatus
+		return nil, err
+	}
+	if err != nil {
+		s.logger.Info("Failed to verify second factor", zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "Неверный код второго фактора")
+	}
+	return tokensToProto(tokens), nil
+}
+
+// RefreshTokens выпускает новую пару токенов по токену обновления.
+func (s *Server) RefreshTokens(_ context.Context, req *keeperpb.RefreshTokensRequest) (*keeperpb.Tokens, error) {
+	tokens, err := s.services.RefreshTokens(req.GetRefreshToken())
+	if err != nil {
+		s.logger.Info("Недействительный токен обновления", zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "Недействительный токен обновления")
+	}
+	return tokensToProto(tokens), nil
+}
+
+// GetSalt возвращает соль мастер-ключа текущего пользователя.
+func (s *Server) GetSalt(ctx context.Context, _ *emptypb.Empty) (*keeperpb.SaltResponse, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	salt, err := s.services.GetSalt(login)
+	if err != nil {
+		return nil, err
+	}
+	return &keeperpb.SaltResponse{Salt: salt}, nil
+}
+
+// CheckPin проверяет пин-код и выдает разрешение на чтение секретов.
+func (s *Server) CheckPin(ctx context.Context, req *keeperpb.CheckPinRequest) (*keeperpb.UnlockGrant, error) {
+	login, err := currentLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, err := s.services.CheckPinCode(login, req.GetPin())
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, status.Error(codes.PermissionDenied, "Неверный пин-код")
+	}
+
+	grant, err := s.services.IssueUnlock(login)
+	if err != nil {
+		return nil, err
+	}
+	return &keeperpb.UnlockGrant{UnlockToken: grant.Token, ExpiresAt: timestamppb.New(grant.ExpiresAt)}, nil
+}
+
+// tokensToProto преобразует пару токенов в сообщение.
+func tokensToProto(tokens *domain.Tokens) *keeperpb.Tokens {
+	return &keeperpb.Tokens{AccessToken: tokens.AccessToken, RefreshToken: tokens.RefreshToken}
+}
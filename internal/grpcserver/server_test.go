@@ -0,0 +1,336 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/config"
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/events"
+	"github.com/egosha7/goph-keeper/internal/keeperpb"
+	"github.com/egosha7/goph-keeper/internal/ratelimit"
+	"github.com/egosha7/goph-keeper/internal/service"
+	mock_service "github.com/egosha7/goph-keeper/internal/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newClient запускает сервер Keeper в памяти и возвращает клиента к нему.
+func newClient(t *testing.T, s *mock_service.MockServices, cfg *config.Config) keeperpb.KeeperClient {
+	listener := bufconn.Listen(1 << 20)
+	server := New(cfg, &service.Service{Services: s}, ratelimit.NewMemory(), zap.NewNop())
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(
+			func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			},
+		),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return keeperpb.NewKeeperClient(conn)
+}
+
+// withToken возвращает контекст с токеном доступа в метаданных.
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+func TestServer_Login(t *testing.T) {
+	testCases := []struct {
+		name         string
+		mockBehavior func(s *mock_service.MockServices)
+		expectedCode codes.Code
+		expected     *keeperpb.LoginResponse
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockServices) {
+				s.EXPECT().AuthenticateUser(&domain.User{Login: "Egor", Password: "secret"}).Return(nil)
+				s.EXPECT().LoginChallenge("Egor").Return("", nil)
+				s.EXPECT().IssueTokens("Egor").Return(&domain.Tokens{AccessToken: "access", RefreshToken: "refresh"}, nil)
+			},
+			expectedCode: codes.OK,
+			expected: &keeperpb.LoginResponse{
+				Tokens: &keeperpb.Tokens{AccessToken: "access", RefreshToken: "refresh"},
+			},
+		},
+		{
+			name: "Second factor",
+			mockBehavior: func(s *mock_service.MockServices) {
+				s.EXPECT().AuthenticateUser(&domain.User{Login: "Egor", Password: "secret"}).Return(nil)
+				s.EXPECT().LoginChallenge("Egor").Return("challenge", nil)
+			},
+			expectedCode: codes.OK,
+			expected:     &keeperpb.LoginResponse{SecondFactorRequired: true, Challenge: "challenge"},
+		},
+		{
+			name: "Wrong password",
+			mockBehavior: func(s *mock_service.MockServices) {
+				s.EXPECT().AuthenticateUser(&domain.User{Login: "Egor", Password: "secret"}).Return(errors.New("mismatch"))
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name: "Service error",
+			mockBehavior: func(s *mock_service.MockServices) {
+				s.EXPECT().AuthenticateUser(&domain.User{Login: "Egor", Password: "secret"}).Return(nil)
+				s.EXPECT().LoginChallenge("Egor").Return("", errors.New("db down"))
+			},
+			expectedCode: codes.Internal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				s := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(s)
+				client := newClient(t, s, config.Default())
+
+				resp, err := client.Login(context.Background(), &keeperpb.LoginRequest{Login: "Egor", Password: "secret"})
+				assert.Equal(t, tc.expectedCode, status.Code(err))
+				if tc.expected != nil {
+					assert.Equal(t, tc.expected.GetTokens().GetAccessToken(), resp.GetTokens().GetAccessToken())
+					assert.Equal(t, tc.expected.GetTokens().GetRefreshToken(), resp.GetTokens().GetRefreshToken())
+					assert.Equal(t, tc.expected.GetSecondFactorRequired(), resp.GetSecondFactorRequired())
+					assert.Equal(t, tc.expected.GetChallenge(), resp.GetChallenge())
+				}
+			},
+		)
+	}
+}
+
+func TestServer_GetPassword(t *testing.T) {
+	testCases := []struct {
+		name         string
+		token        string
+		unlock       string
+		mockBehavior func(s *mock_service.MockServices)
+		expectedCode codes.Code
+	}{
+		{
+			name:   "OK",
+			token:  "access",
+			unlock: "unlock",
+			mockBehavior: func(s *mock_service.MockServices) {
+				s.EXPECT().ParseAccessToken("access").Return("Egor", nil)
+				s.EXPECT().ParseUnlockToken("unlock").Return("Egor", nil)
+				s.EXPECT().GetPasswordEntry("Egor", "Email").Return(
+					&domain.PasswordEntry{PassName: "Email", Password: "cipher", OTP: "otp"}, nil,
+				)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:   "Not found",
+			token:  "access",
+			unlock: "unlock",
+			mockBehavior: func(s *mock_service.MockServices) {
+				s.EXPECT().ParseAccessToken("access").Return("Egor", nil)
+				s.EXPECT().ParseUnlockToken("unlock").Return("Egor", nil)
+				s.EXPECT().GetPasswordEntry("Egor", "Email").Return(nil, domain.NotFound("password %q not found", "Email"))
+			},
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "No token",
+			mockBehavior: func(s *mock_service.MockServices) {},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:  "Invalid token",
+			token: "expired",
+			mockBehavior: func(s *mock_service.MockServices) {
+				s.EXPECT().ParseAccessToken("expired").Return("", errors.New("invalid token"))
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:  "No unlock token",
+			token: "access",
+			mockBehavior: func(s *mock_service.MockServices) {
+				s.EXPECT().ParseAccessToken("access").Return("Egor", nil)
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:   "Foreign unlock token",
+			token:  "access",
+			unlock: "unlock",
+			mockBehavior: func(s *mock_service.MockServices) {
+				s.EXPECT().ParseAccessToken("access").Return("Egor", nil)
+				s.EXPECT().ParseUnlockToken("unlock").Return("Anna", nil)
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				s := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(s)
+				client := newClient(t, s, config.Default())
+
+				ctx := context.Background()
+				if tc.token != "" {
+					ctx = withToken(ctx, tc.token)
+				}
+				if tc.unlock != "" {
+					ctx = metadata.AppendToOutgoingContext(ctx, "x-unlock-token", tc.unlock)
+				}
+
+				resp, err := client.GetPassword(ctx, &keeperpb.ItemRequest{Name: "Email"})
+				assert.Equal(t, tc.expectedCode, status.Code(err))
+				if tc.expectedCode == codes.OK {
+					assert.Equal(t, "cipher", resp.GetPassword())
+					assert.Equal(t, "otp", resp.GetOtp())
+				}
+			},
+		)
+	}
+}
+
+func TestServer_GetRecordNotUnlocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := mock_service.NewMockServices(ctrl)
+	s.EXPECT().ParseAccessToken("access").Return("Egor", nil)
+	client := newClient(t, s, config.Default())
+
+	// Без токена разблокировки запись не запрашивается у сервиса
+	_, err := client.GetRecord(withToken(context.Background(), "access"), &keeperpb.ItemRequest{Name: "Deploy key"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestServer_CheckPin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := mock_service.NewMockServices(ctrl)
+	s.EXPECT().ParseAccessToken("access").Return("Egor", nil)
+	s.EXPECT().CheckPinCode("Egor", "0000").Return(false, &service.PinLockedError{Until: time.Now().Add(time.Minute)})
+	client := newClient(t, s, config.Default())
+
+	var trailer metadata.MD
+	_, err := client.CheckPin(
+		withToken(context.Background(), "access"), &keeperpb.CheckPinRequest{Pin: "0000"}, grpc.Trailer(&trailer),
+	)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.NotEmpty(t, trailer.Get(retryAfterKey))
+}
+
+func TestServer_CompleteLoginLocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := mock_service.NewMockServices(ctrl)
+	s.EXPECT().CompleteLogin("challenge", "000000").Return(nil, errors.New("invalid code"))
+	s.EXPECT().CompleteLogin("challenge", "111111").Return(
+		nil, &service.SecondFactorLockedError{Until: time.Now().Add(time.Minute)},
+	)
+	client := newClient(t, s, config.Default())
+
+	_, err := client.CompleteLogin(
+		context.Background(), &keeperpb.CompleteLoginRequest{Challenge: "challenge", Code: "000000"},
+	)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Заблокированный вход отличается от неверного кода и сообщает, когда можно повторить
+	var trailer metadata.MD
+	_, err = client.CompleteLogin(
+		context.Background(), &keeperpb.CompleteLoginRequest{Challenge: "challenge", Code: "111111"}, grpc.Trailer(&trailer),
+	)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.NotEmpty(t, trailer.Get(retryAfterKey))
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := config.Default()
+	cfg.AuthRateAccount = ratelimit.Policy{Requests: 1, Per: time.Hour}
+
+	s := mock_service.NewMockServices(ctrl)
+	s.EXPECT().AuthenticateUser(gomock.Any()).Return(errors.New("mismatch"))
+	client := newClient(t, s, cfg)
+
+	_, err := client.Login(context.Background(), &keeperpb.LoginRequest{Login: "Egor", Password: "guess"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Вторая попытка для той же учетной записи отклоняется до обращения к сервису
+	_, err = client.Login(context.Background(), &keeperpb.LoginRequest{Login: "Egor", Password: "guess"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestServer_WatchChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bus := events.NewBus(10)
+	sub, _ := bus.Subscribe("Egor", "")
+	backlog := []domain.ChangeEvent{{Cursor: "c-1", Action: domain.ActionReset}}
+
+	s := mock_service.NewMockServices(ctrl)
+	s.EXPECT().ParseAccessToken("access").Return("Egor", nil)
+	s.EXPECT().WatchChanges("Egor", "stale").Return(sub, backlog, nil)
+	client := newClient(t, s, config.Default())
+
+	ctx, cancel := context.WithCancel(withToken(context.Background(), "access"))
+	defer cancel()
+	stream, err := client.WatchChanges(ctx, &keeperpb.WatchChangesRequest{Cursor: "stale"})
+	require.NoError(t, err)
+
+	// Сначала приходят пропущенные события, затем новые
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, domain.ActionReset, event.GetAction())
+
+	bus.Publish("Egor", domain.ChangeEvent{Action: domain.ActionUpdated, Kind: domain.KindCard, Name: "Visa", NewName: "Mir"})
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, domain.ActionUpdated, event.GetAction())
+	assert.Equal(t, "Mir", event.GetNewName())
+	assert.NotEmpty(t, event.GetCursor())
+
+	// Отключенный подписчик получает Unavailable и должен переподключиться
+	sub.Close()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestServer_WatchChangesUnauthenticated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := newClient(t, mock_service.NewMockServices(ctrl), config.Default())
+	stream, err := client.WatchChanges(context.Background(), &keeperpb.WatchChangesRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
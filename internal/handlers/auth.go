@@ -1,71 +1,212 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"github.com/egosha7/goph-keeper/internal/domain"
-	"go.uber.org/zap"
 	"net/http"
 )
 
 // CheckPinCodeHandler обработчик запроса на проверку пин-кода.
 func (h *Handler) CheckPinCodeHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
 	var requestData domain.CheckPinData
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		h.logger.Error("Ошибка при разборе JSON", zap.Error(err))
-		http.Error(w, "Ошибка при разборе JSON", http.StatusBadRequest)
+	if !h.decodeJSON(w, r, &requestData) {
 		return
 	}
 
-	valid, err := h.Services.CheckPinCode(requestData.Login, requestData.Pin)
+	valid, err := h.Services.CheckPinCode(login, requestData.Pin)
+	if errors.Is(err, domain.ErrLocked) {
+		// Проверка заблокирована после неудачных попыток: в ответе сообщается, когда можно повторить
+		h.writeError(w, err, "Проверка пин-кода временно заблокирована")
+		return
+	}
 	if err != nil {
-		h.logger.Error("Ошибка при проверке пин-кода", zap.Error(err))
-		http.Error(w, "Ошибка при проверке пин-кода", http.StatusInternalServerError)
+		h.writeError(w, err, "Ошибка при проверке пин-кода")
 		return
 	}
 
 	if valid {
-		// Если пин-коды совпадают, отправляем статус OK
-		w.WriteHeader(http.StatusOK)
+		// Если пин-коды совпадают, выдаем разрешение на чтение секретов
+		grant, err := h.Services.IssueUnlock(login)
+		if err != nil {
+			h.writeError(w, err, "Ошибка при выдаче разрешения")
+			return
+		}
+		h.writeJSON(w, grant)
 		return
 	}
 
-	// Если пин-коды не совпадают, отправляем статус Unauthorized
-	http.Error(w, "Неверный пин-код", http.StatusUnauthorized)
+	// Если пин-коды не совпадают, отправляем статус Forbidden:
+	// Unauthorized клиент воспринимает как истекший токен и повторил бы запрос, засчитав лишнюю попытку
+	h.writeError(w, domain.Forbidden("invalid pin"), "Неверный пин-код")
 }
 
 // RegisterUser обрабатывает запрос на регистрацию нового пользователя.
 func (h *Handler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	var user domain.User
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		h.logger.Error("Ошибка при разборе JSON", zap.Error(err))
-		http.Error(w, "Ошибка при разборе JSON", http.StatusBadRequest)
+	if !h.decodeJSON(w, r, &user) {
 		return
 	}
 
 	if err := h.Services.RegisterUser(&user); err != nil {
-		h.logger.Error("Ошибка при регистрации пользователя", zap.Error(err))
-		http.Error(w, "Ошибка при регистрации пользователя", http.StatusInternalServerError)
+		h.writeError(w, err, "Ошибка при регистрации пользователя")
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	h.issueTokens(w, user.Login)
 }
 
 // AuthUser обрабатывает запрос аутентификации пользователя.
 func (h *Handler) AuthUser(w http.ResponseWriter, r *http.Request) {
 	var user *domain.User
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		http.Error(w, "Ошибка при разборе JSON", http.StatusBadRequest)
+	if !h.decodeJSON(w, r, &user) {
 		return
 	}
 
-	h.logger.Info(user.Password + user.Login + user.Pin)
-
 	if err := h.Services.AuthenticateUser(user); err != nil {
-		http.Error(w, "Неверная пара логин/пароль", http.StatusUnauthorized)
-		h.logger.Error("Failed to check user validity", zap.Error(err))
+		message := "Ошибка при проверке пользователя"
+		if errors.Is(err, domain.ErrUnauthorized) {
+			message = "Неверная пара логин/пароль"
+		}
+		h.writeError(w, err, message)
+		return
+	}
+
+	// С подключенным вторым фактором вместо токенов выдается токен незавершенного входа
+	challenge, err := h.Services.LoginChallenge(user.Login)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при проверке второго фактора")
+		return
+	}
+	if challenge != "" {
+		h.writeJSON(w, domain.SecondFactorChallenge{SecondFactorRequired: true, Challenge: challenge})
+		return
+	}
+
+	h.issueTokens(w, user.Login)
+}
+
+// CompleteLoginHandler обрабатывает запрос на завершение входа кодом второго фактора.
+func (h *Handler) CompleteLoginHandler(w http.ResponseWriter, r *http.Request) {
+	var requestData domain.SecondFactorData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	tokens, err := h.Services.CompleteLogin(requestData.Challenge, requestData.Code)
+	if errors.Is(err, domain.ErrLocked) {
+		// Проверка заблокирована после неудачных попыток: в ответе сообщается, когда можно повторить
+		h.writeError(w, err, "Проверка второго фактора временно заблокирована")
+		return
+	}
+	if err != nil {
+		h.writeError(w, err, "Неверный код второго фактора")
+		return
+	}
+
+	h.writeJSON(w, tokens)
+}
+
+// EnrollSecondFactorHandler обрабатывает запрос на подключение второго фактора и возвращает ключ TOTP.
+func (h *Handler) EnrollSecondFactorHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	uri, err := h.Services.EnrollSecondFactor(login)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при подключении второго фактора")
+		return
+	}
+
+	h.writeJSON(w, domain.SecondFactorEnrollment{URI: uri})
+}
+
+// ConfirmSecondFactorHandler обрабатывает запрос на подтверждение второго фактора и возвращает коды восстановления.
+func (h *Handler) ConfirmSecondFactorHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain.SecondFactorCodeData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	codes, err := h.Services.ConfirmSecondFactor(login, requestData.Code)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при подтверждении второго фактора")
+		return
+	}
+
+	h.writeJSON(w, domain.RecoveryCodes{Codes: codes})
+}
+
+// DisableSecondFactorHandler обрабатывает запрос на отключение второго фактора.
+func (h *Handler) DisableSecondFactorHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain.SecondFactorCodeData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.DisableSecondFactor(login, requestData.Code); err != nil {
+		h.writeError(w, err, "Ошибка при отключении второго фактора")
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// RefreshTokens обрабатывает запрос на обновление пары токенов.
+func (h *Handler) RefreshTokens(w http.ResponseWriter, r *http.Request) {
+	var requestData domain.RefreshData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	tokens, err := h.Services.RefreshTokens(requestData.RefreshToken)
+	if err != nil {
+		h.writeError(w, err, "Недействительный токен обновления")
+		return
+	}
+
+	h.writeJSON(w, tokens)
+}
+
+// GetSaltHandler обрабатывает запрос на получение соли мастер-ключа текущего пользователя.
+func (h *Handler) GetSaltHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	salt, err := h.Services.GetSalt(login)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении соли")
+		return
+	}
+
+	h.writeJSON(w, domain.SaltData{Salt: salt})
+}
+
+// issueTokens выпускает пару токенов для пользователя и отправляет их в ответе.
+func (h *Handler) issueTokens(w http.ResponseWriter, login string) {
+	tokens, err := h.Services.IssueTokens(login)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при выпуске токенов")
+		return
+	}
+
+	h.writeJSON(w, tokens)
+}
@@ -6,7 +6,9 @@ import (
 	"github.com/egosha7/goph-keeper/internal/domain"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/egosha7/goph-keeper/internal/service"
 	mock_service "github.com/egosha7/goph-keeper/internal/service/mocks"
@@ -36,6 +38,7 @@ func TestHandler_AuthUser(t *testing.T) {
 			},
 			mockBehavior: func(s *mock_service.MockServices, user *domain.User) {
 				s.EXPECT().RegisterUser(user).Return(nil)
+				s.EXPECT().IssueTokens(user.Login).Return(&domain.Tokens{AccessToken: "a", RefreshToken: "r"}, nil)
 			},
 			expectedStatusCode: 200,
 		},
@@ -78,11 +81,12 @@ func TestHandler_AuthenticateUser(t *testing.T) {
 	type mockBehavior func(s *mock_service.MockServices, user *domain.User)
 
 	testTable := []struct {
-		name               string
-		inputBody          string
-		inputUser          *domain.User
-		mockBehavior       mockBehavior
-		expectedStatusCode int
+		name                 string
+		inputBody            string
+		inputUser            *domain.User
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
 	}{
 		{
 			name:      "OK",
@@ -93,8 +97,37 @@ func TestHandler_AuthenticateUser(t *testing.T) {
 			},
 			mockBehavior: func(s *mock_service.MockServices, user *domain.User) {
 				s.EXPECT().AuthenticateUser(user).Return(nil)
+				s.EXPECT().LoginChallenge(user.Login).Return("", nil)
+				s.EXPECT().IssueTokens(user.Login).Return(&domain.Tokens{AccessToken: "a", RefreshToken: "r"}, nil)
 			},
-			expectedStatusCode: http.StatusOK,
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"accessToken":"a","refreshToken":"r"}`,
+		},
+		{
+			name:      "Second Factor Required",
+			inputBody: `{"login":"Egor","password":"parol"}`,
+			inputUser: &domain.User{
+				Login:    "Egor",
+				Password: "parol",
+			},
+			mockBehavior: func(s *mock_service.MockServices, user *domain.User) {
+				s.EXPECT().AuthenticateUser(user).Return(nil)
+				s.EXPECT().LoginChallenge(user.Login).Return("c", nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"secondFactorRequired":true,"challenge":"c"}`,
+		},
+		{
+			name:      "Wrong Password",
+			inputBody: `{"login":"Egor","password":"wrong"}`,
+			inputUser: &domain.User{
+				Login:    "Egor",
+				Password: "wrong",
+			},
+			mockBehavior: func(s *mock_service.MockServices, user *domain.User) {
+				s.EXPECT().AuthenticateUser(user).Return(domain.Unauthorized("invalid login or password"))
+			},
+			expectedStatusCode: http.StatusUnauthorized,
 		},
 	}
 
@@ -126,6 +159,9 @@ func TestHandler_AuthenticateUser(t *testing.T) {
 				r.ServeHTTP(w, req)
 
 				assert.Equal(t, testCase.expectedStatusCode, w.Code)
+				if testCase.expectedResponseBody != "" {
+					assert.Equal(t, testCase.expectedResponseBody, w.Body.String())
+				}
 			},
 		)
 	}
@@ -140,6 +176,7 @@ func TestHandler_CheckPinCodeHandler(t *testing.T) {
 		inputPin           string
 		mockBehavior       mockBehavior
 		expectedStatusCode int
+		expectedRetryAfter string
 	}{
 		{
 			name:       "Valid Pin",
@@ -147,6 +184,7 @@ func TestHandler_CheckPinCodeHandler(t *testing.T) {
 			inputPin:   "1234",
 			mockBehavior: func(s *mock_service.MockServices, login, pin string) {
 				s.EXPECT().CheckPinCode(login, pin).Return(true, nil)
+				s.EXPECT().IssueUnlock(login).Return(&domain.UnlockGrant{Token: "u"}, nil)
 			},
 			expectedStatusCode: http.StatusOK,
 		},
@@ -157,7 +195,17 @@ func TestHandler_CheckPinCodeHandler(t *testing.T) {
 			mockBehavior: func(s *mock_service.MockServices, login, pin string) {
 				s.EXPECT().CheckPinCode(login, pin).Return(false, nil)
 			},
-			expectedStatusCode: http.StatusUnauthorized,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Locked",
+			inputLogin: "Egor",
+			inputPin:   "0000",
+			mockBehavior: func(s *mock_service.MockServices, login, pin string) {
+				s.EXPECT().CheckPinCode(login, pin).Return(false, &service.PinLockedError{Until: time.Now().Add(time.Minute)})
+			},
+			expectedStatusCode: http.StatusLocked,
+			expectedRetryAfter: "60",
 		},
 	}
 
@@ -183,8 +231,7 @@ func TestHandler_CheckPinCodeHandler(t *testing.T) {
 
 				// Создаем JSON для запроса
 				requestData := domain.CheckPinData{
-					Login: tc.inputLogin,
-					Pin:   tc.inputPin,
+					Pin: tc.inputPin,
 				}
 				requestBody, err := json.Marshal(requestData)
 				if err != nil {
@@ -192,10 +239,192 @@ func TestHandler_CheckPinCodeHandler(t *testing.T) {
 				}
 
 				w := httptest.NewRecorder()
-				req := httptest.NewRequest("POST", "/check-pin", bytes.NewBuffer(requestBody))
+				req := withLogin(httptest.NewRequest("POST", "/check-pin", bytes.NewBuffer(requestBody)), tc.inputLogin)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedRetryAfter, w.Header().Get("Retry-After"))
+			},
+		)
+	}
+}
+
+func TestHandler_GetSaltHandler(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		login                string
+		salt                 string
+		err                  error
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "OK",
+			login:                "Egor",
+			salt:                 "c2FsdHNhbHRzYWx0c2FsdA==",
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"salt":"c2FsdHNhbHRzYWx0c2FsdA=="}`,
+		},
+		{
+			name:                 "User Not Found",
+			login:                "NonExistentUser",
+			err:                  domain.NotFound("user not found"),
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"code":"not_found","message":"Ошибка при получении соли","details":{"reason":"user not found"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				auth.EXPECT().GetSalt(tc.login).Return(tc.salt, tc.err)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/auth/salt", func(w http.ResponseWriter, r *http.Request) {
+						handlers.GetSaltHandler(w, r)
+					},
+				)
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/auth/salt", nil), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+			},
+		)
+	}
+}
+
+func TestHandler_CompleteLoginHandler(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		inputBody            string
+		tokens               *domain.Tokens
+		err                  error
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "OK",
+			inputBody:            `{"challenge":"c","code":"123456"}`,
+			tokens:               &domain.Tokens{AccessToken: "a", RefreshToken: "r"},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"accessToken":"a","refreshToken":"r"}`,
+		},
+		{
+			name:                 "Invalid Code",
+			inputBody:            `{"challenge":"c","code":"000000"}`,
+			err:                  domain.Unauthorized("invalid second factor code"),
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedResponseBody: `{"code":"unauthorized","message":"Неверный код второго фактора","details":{"reason":"invalid second factor code"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				var requestData domain.SecondFactorData
+				if err := json.Unmarshal([]byte(tc.inputBody), &requestData); err != nil {
+					t.Fatal(err)
+				}
+
+				auth := mock_service.NewMockServices(ctrl)
+				auth.EXPECT().CompleteLogin(requestData.Challenge, requestData.Code).Return(tc.tokens, tc.err)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/auth/2fa", func(w http.ResponseWriter, r *http.Request) {
+						handlers.CompleteLoginHandler(w, r)
+					},
+				)
+
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest("POST", "/auth/2fa", bytes.NewBufferString(tc.inputBody))
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+			},
+		)
+	}
+}
+
+func TestHandler_ConfirmSecondFactorHandler(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		login                string
+		code                 string
+		codes                []string
+		err                  error
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "OK",
+			login:                "Egor",
+			code:                 "123456",
+			codes:                []string{"AAAA-BBBB-CCCC-DDDD"},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"codes":["AAAA-BBBB-CCCC-DDDD"]}`,
+		},
+		{
+			name:                 "Invalid Code",
+			login:                "Egor",
+			code:                 "000000",
+			err:                  domain.InvalidField("code", "invalid second factor code"),
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"code":"validation","message":"Ошибка при подтверждении второго фактора","details":{"field":"code","reason":"invalid second factor code"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				auth.EXPECT().ConfirmSecondFactor(tc.login, tc.code).Return(tc.codes, tc.err)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/auth/2fa/confirm", func(w http.ResponseWriter, r *http.Request) {
+						handlers.ConfirmSecondFactorHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(domain.SecondFactorCodeData{Code: tc.code})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/auth/2fa/confirm", bytes.NewBuffer(requestBody)), tc.login)
 				r.ServeHTTP(w, req)
 
 				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
 			},
 		)
 	}
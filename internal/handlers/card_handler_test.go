@@ -15,46 +15,48 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestHandler_AddCardHandler(t *testing.T) {
-	type mockBehavior func(s *mock_service.MockServices, requestData domain2.NewCardData)
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.NewCardData)
 
 	testCases := []struct {
 		name               string
+		login              string
 		requestData        domain2.NewCardData
 		mockBehavior       mockBehavior
 		expectedStatusCode int
 	}{
 		{
-			name: "Valid Card",
+			name:  "Valid Card",
+			login: "Egor",
 			requestData: domain2.NewCardData{
-				Login:          "Egor",
 				CardName:       "Visa",
 				NumberCard:     "1234567890123456",
 				ExpiryDateCard: "12/24",
 				CvvCard:        "123",
 			},
-			mockBehavior: func(s *mock_service.MockServices, requestData domain2.NewCardData) {
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.NewCardData) {
 				s.EXPECT().AddCard(
-					requestData.Login, requestData.CardName, requestData.NumberCard, requestData.ExpiryDateCard,
+					login, requestData.CardName, requestData.NumberCard, requestData.ExpiryDateCard,
 					requestData.CvvCard,
 				).Return(nil)
 			},
 			expectedStatusCode: http.StatusOK,
 		},
 		{
-			name: "Invalid Card",
+			name:  "Invalid Card",
+			login: "Egor",
 			requestData: domain2.NewCardData{
-				Login:          "Egor",
 				CardName:       "Mastercard",
 				NumberCard:     "123456789012345",
 				ExpiryDateCard: "12/25",
 				CvvCard:        "1234",
 			},
-			mockBehavior: func(s *mock_service.MockServices, requestData domain2.NewCardData) {
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.NewCardData) {
 				s.EXPECT().AddCard(
-					requestData.Login, requestData.CardName, requestData.NumberCard, requestData.ExpiryDateCard,
+					login, requestData.CardName, requestData.NumberCard, requestData.ExpiryDateCard,
 					requestData.CvvCard,
 				).Return(errors.New("error adding card"))
 			},
@@ -69,7 +71,7 @@ func TestHandler_AddCardHandler(t *testing.T) {
 				defer ctrl.Finish()
 
 				auth := mock_service.NewMockServices(ctrl)
-				tc.mockBehavior(auth, tc.requestData)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
 				logger := zap.NewExample()
 				userService := &service.Service{Services: auth}
 
@@ -88,7 +90,7 @@ func TestHandler_AddCardHandler(t *testing.T) {
 				}
 
 				w := httptest.NewRecorder()
-				req := httptest.NewRequest("POST", "/add-card", bytes.NewBuffer(requestBody))
+				req := withLogin(httptest.NewRequest("POST", "/add-card", bytes.NewBuffer(requestBody)), tc.login)
 				r.ServeHTTP(w, req)
 
 				assert.Equal(t, tc.expectedStatusCode, w.Code)
@@ -98,23 +100,25 @@ func TestHandler_AddCardHandler(t *testing.T) {
 }
 
 func TestHandler_GetCardHandler(t *testing.T) {
-	type mockBehavior func(s *mock_service.MockServices, requestData domain2.CardData)
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.CardData)
 
 	testCases := []struct {
 		name                 string
+		login                string
 		requestData          domain2.CardData
 		mockBehavior         mockBehavior
 		expectedStatusCode   int
 		expectedResponseBody string
 	}{
 		{
-			name: "Valid Card",
+			name:  "Valid Card",
+			login: "Egor",
 			requestData: domain2.CardData{
-				Login:    "Egor",
 				CardName: "Visa",
 			},
-			mockBehavior: func(s *mock_service.MockServices, requestData domain2.CardData) {
-				s.EXPECT().GetCard(requestData.Login, requestData.CardName).Return(
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.CardData) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().GetCard(login, requestData.CardName).Return(
 					"1234567890123456", "12/24", "123", nil,
 				)
 			},
@@ -122,18 +126,31 @@ func TestHandler_GetCardHandler(t *testing.T) {
 			expectedResponseBody: `{"number":"1234567890123456","expiryDate":"12/24","cvv":"123"}`,
 		},
 		{
-			name: "Invalid Card",
+			name:  "Invalid Card",
+			login: "Egor",
 			requestData: domain2.CardData{
-				Login:    "Egor",
 				CardName: "Mastercard",
 			},
-			mockBehavior: func(s *mock_service.MockServices, requestData domain2.CardData) {
-				s.EXPECT().GetCard(requestData.Login, requestData.CardName).Return(
-					"", "", "", errors.New("card not found"),
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.CardData) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().GetCard(login, requestData.CardName).Return(
+					"", "", "", domain2.NotFound("card not found"),
 				)
 			},
-			expectedStatusCode:   http.StatusInternalServerError,
-			expectedResponseBody: `Ошибка при получении информации о карте`,
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"code":"not_found","message":"Ошибка при получении информации о карте","details":{"reason":"card not found"}}`,
+		},
+		{
+			name:  "Not Unlocked",
+			login: "Egor",
+			requestData: domain2.CardData{
+				CardName: "Visa",
+			},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.CardData) {
+				s.EXPECT().ParseUnlockToken("u").Return("", errors.New("invalid token"))
+			},
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"code":"forbidden","message":"Требуется ввести пин-код","details":{"reason":"invalid unlock token"}}`,
 		},
 	}
 
@@ -144,7 +161,7 @@ func TestHandler_GetCardHandler(t *testing.T) {
 				defer ctrl.Finish()
 
 				auth := mock_service.NewMockServices(ctrl)
-				tc.mockBehavior(auth, tc.requestData)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
 				logger := zap.NewExample()
 				userService := &service.Service{Services: auth}
 
@@ -163,7 +180,7 @@ func TestHandler_GetCardHandler(t *testing.T) {
 				}
 
 				w := httptest.NewRecorder()
-				req := httptest.NewRequest("POST", "/get-card", bytes.NewBuffer(requestBody))
+				req := withUnlock(withLogin(httptest.NewRequest("POST", "/get-card", bytes.NewBuffer(requestBody)), tc.login), "u")
 				r.ServeHTTP(w, req)
 
 				assert.Equal(t, tc.expectedStatusCode, w.Code)
@@ -174,36 +191,32 @@ func TestHandler_GetCardHandler(t *testing.T) {
 }
 
 func TestHandler_GetCardList(t *testing.T) {
-	type mockBehavior func(s *mock_service.MockServices, userInfo domain2.UserInfo)
+	type mockBehavior func(s *mock_service.MockServices, login string)
 
 	testCases := []struct {
 		name                 string
-		requestData          domain2.UserInfo
+		login                string
 		mockBehavior         mockBehavior
 		expectedStatusCode   int
 		expectedResponseBody string
 	}{
 		{
-			name: "Valid User",
-			requestData: domain2.UserInfo{
-				Login: "Egor",
-			},
-			mockBehavior: func(s *mock_service.MockServices, userInfo domain2.UserInfo) {
-				s.EXPECT().GetCardNameList(userInfo.Login).Return([]string{"Visa", "Mastercard"}, nil)
+			name:  "Valid User",
+			login: "Egor",
+			mockBehavior: func(s *mock_service.MockServices, login string) {
+				s.EXPECT().GetCardNameList(login).Return([]string{"Visa", "Mastercard"}, nil)
 			},
 			expectedStatusCode:   http.StatusOK,
 			expectedResponseBody: `["Visa","Mastercard"]`,
 		},
 		{
-			name: "Invalid User",
-			requestData: domain2.UserInfo{
-				Login: "NonExistentUser",
-			},
-			mockBehavior: func(s *mock_service.MockServices, userInfo domain2.UserInfo) {
-				s.EXPECT().GetCardNameList(userInfo.Login).Return(nil, errors.New("user not found"))
+			name:  "Invalid User",
+			login: "NonExistentUser",
+			mockBehavior: func(s *mock_service.MockServices, login string) {
+				s.EXPECT().GetCardNameList(login).Return(nil, domain2.NotFound("user not found"))
 			},
-			expectedStatusCode:   http.StatusInternalServerError,
-			expectedResponseBody: `Ошибка при получении списка названий карт`,
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"code":"not_found","message":"Ошибка при получении списка названий карт","details":{"reason":"user not found"}}`,
 		},
 	}
 
@@ -214,7 +227,7 @@ func TestHandler_GetCardList(t *testing.T) {
 				defer ctrl.Finish()
 
 				auth := mock_service.NewMockServices(ctrl)
-				tc.mockBehavior(auth, tc.requestData)
+				tc.mockBehavior(auth, tc.login)
 				logger := zap.NewExample()
 				userService := &service.Service{Services: auth}
 
@@ -227,19 +240,386 @@ func TestHandler_GetCardList(t *testing.T) {
 					},
 				)
 
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/get-card-list", nil), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(
+					t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()),
+				) // Убираем пробельные символы
+			},
+		)
+	}
+}
+
+func TestHandler_UpdateCardHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.UpdateCardData)
+
+	testCases := []struct {
+		name               string
+		login              string
+		requestData        domain2.UpdateCardData
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:  "Updated",
+			login: "Egor",
+			requestData: domain2.UpdateCardData{
+				CardName:       "Visa",
+				NewCardName:    "Visa Gold",
+				NumberCard:     "1234567890123456",
+				ExpiryDateCard: "12/28",
+				CvvCard:        "321",
+			},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.UpdateCardData) {
+				s.EXPECT().UpdateCard(
+					login, requestData.CardName, requestData.NewCardName, requestData.NumberCard,
+					requestData.ExpiryDateCard, requestData.CvvCard,
+				).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:  "Name Taken",
+			login: "Egor",
+			requestData: domain2.UpdateCardData{
+				CardName:    "Visa",
+				NewCardName: "Mastercard",
+			},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.UpdateCardData) {
+				s.EXPECT().UpdateCard(
+					login, requestData.CardName, requestData.NewCardName, "", "", "",
+				).Return(domain2.Conflict("card already exists"))
+			},
+			expectedStatusCode: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/update-card", func(w http.ResponseWriter, r *http.Request) {
+						handlers.UpdateCardHandler(w, r)
+					},
+				)
+
 				requestBody, err := json.Marshal(tc.requestData)
 				if err != nil {
 					t.Fatal(err)
 				}
 
 				w := httptest.NewRecorder()
-				req := httptest.NewRequest("POST", "/get-card-list", bytes.NewBuffer(requestBody))
+				req := withLogin(httptest.NewRequest("POST", "/update-card", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+			},
+		)
+	}
+}
+
+func TestHandler_DeleteCardHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.CardData)
+
+	testCases := []struct {
+		name               string
+		login              string
+		requestData        domain2.CardData
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:        "Deleted",
+			login:       "Egor",
+			requestData: domain2.CardData{CardName: "Visa"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.CardData) {
+				s.EXPECT().DeleteCard(login, requestData.CardName).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:        "Not Found",
+			login:       "Egor",
+			requestData: domain2.CardData{CardName: "Unknown"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.CardData) {
+				s.EXPECT().DeleteCard(login, requestData.CardName).Return(domain2.NotFound("card not found"))
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/delete-card", func(w http.ResponseWriter, r *http.Request) {
+						handlers.DeleteCardHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/delete-card", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+			},
+		)
+	}
+}
+
+func TestHandler_GetCardRevisionHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.CardRevisionData)
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		name                 string
+		login                string
+		requestData          domain2.CardRevisionData
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:        "Revision",
+			login:       "Egor",
+			requestData: domain2.CardRevisionData{CardName: "Visa", Revision: 1},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.CardRevisionData) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().GetCardRevision(login, requestData.CardName, requestData.Revision).Return(
+					&domain2.CardRevision{
+						Revision: domain2.Revision{Revision: 1, Name: "Visa", ChangedBy: login, CreatedAt: createdAt},
+						CardInfo: domain2.CardInfo{Number: "1234567890123456", ExpiryDate: "12/24", CVV: "123"},
+					}, nil,
+				)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{"revision":1,"name":"Visa","changedBy":"Egor","createdAt":"2024-01-02T03:04:05Z",` +
+				`"number":"1234567890123456","expiryDate":"12/24","cvv":"123"}`,
+		},
+		{
+			name:        "Revision Not Found",
+			login:       "Egor",
+			requestData: domain2.CardRevisionData{CardName: "Visa", Revision: 42},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.CardRevisionData) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().GetCardRevision(
+					login, requestData.CardName, requestData.Revision,
+				).Return(nil, domain2.NotFound("revision not found"))
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"code":"not_found","message":"Ошибка при получении ревизии карты","details":{"reason":"revision not found"}}`,
+		},
+		{
+			name:        "Not Unlocked",
+			login:       "Egor",
+			requestData: domain2.CardRevisionData{CardName: "Visa", Revision: 1},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.CardRevisionData) {
+				s.EXPECT().ParseUnlockToken("u").Return("Anna", nil)
+			},
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"code":"forbidden","message":"Требуется ввести пин-код","details":{"reason":"invalid unlock token"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/card-revision", func(w http.ResponseWriter, r *http.Request) {
+						handlers.GetCardRevisionHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withUnlock(withLogin(httptest.NewRequest("POST", "/card-revision", bytes.NewBuffer(requestBody)), tc.login), "u")
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+			},
+		)
+	}
+}
+
+func TestHandler_RestoreCardFromTrashHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.TrashData)
+
+	testCases := []struct {
+		name               string
+		login              string
+		requestData        domain2.TrashData
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:        "Restored",
+			login:       "Egor",
+			requestData: domain2.TrashData{ID: 3},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.TrashData) {
+				s.EXPECT().RestoreCardFromTrash(login, requestData.ID).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:        "Name Taken",
+			login:       "Egor",
+			requestData: domain2.TrashData{ID: 3},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.TrashData) {
+				s.EXPECT().RestoreCardFromTrash(login, requestData.ID).Return(domain2.Conflict("card already exists"))
+			},
+			expectedStatusCode: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/restore-card", func(w http.ResponseWriter, r *http.Request) {
+						handlers.RestoreCardFromTrashHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/restore-card", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+			},
+		)
+	}
+}
+
+func TestHandler_SetCardMetaHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.CardMetaData)
+
+	testCases := []struct {
+		name               string
+		login              string
+		requestData        domain2.CardMetaData
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:  "Valid Meta",
+			login: "Egor",
+			requestData: domain2.CardMetaData{
+				CardName: "Visa",
+				ItemMeta: domain2.ItemMeta{
+					Metadata: map[string]string{"bank": "Tinkoff"},
+					Tags:     []string{"travel"},
+					Folder:   "Семья",
+				},
+			},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.CardMetaData) {
+				s.EXPECT().SetCardMeta(login, requestData.CardName, requestData.ItemMeta).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:        "Not Found",
+			login:       "Egor",
+			requestData: domain2.CardMetaData{CardName: "Unknown"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.CardMetaData) {
+				s.EXPECT().SetCardMeta(login, requestData.CardName, requestData.ItemMeta).Return(domain2.NotFound("card not found"))
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/card-meta", func(w http.ResponseWriter, r *http.Request) {
+						handlers.SetCardMetaHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/card-meta", bytes.NewBuffer(requestBody)), tc.login)
 				r.ServeHTTP(w, req)
 
 				assert.Equal(t, tc.expectedStatusCode, w.Code)
-				assert.Equal(
-					t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()),
-				) // Убираем пробельные символы
 			},
 		)
 	}
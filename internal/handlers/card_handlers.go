@@ -3,26 +3,27 @@ package handlers
 import (
 	"encoding/json"
 	domain2 "github.com/egosha7/goph-keeper/internal/domain"
-	"go.uber.org/zap"
 	"net/http"
 )
 
 // AddCardHandler обрабатывает запрос на добавление новой карты.
 func (h *Handler) AddCardHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
 	var requestData domain2.NewCardData
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		h.logger.Error("Ошибка при разборе JSON", zap.Error(err))
-		http.Error(w, "Ошибка при разборе JSON", http.StatusBadRequest)
+	if !h.decodeJSON(w, r, &requestData) {
 		return
 	}
 
 	err := h.Services.AddCard(
-		requestData.Login, requestData.CardName, requestData.NumberCard, requestData.ExpiryDateCard,
+		login, requestData.CardName, requestData.NumberCard, requestData.ExpiryDateCard,
 		requestData.CvvCard,
 	)
 	if err != nil {
-		h.logger.Error("Ошибка при добавлении новой карты", zap.Error(err))
-		http.Error(w, "Ошибка при добавлении новой карты", http.StatusInternalServerError)
+		h.writeError(w, err, "Ошибка при добавлении новой карты")
 		return
 	}
 
@@ -31,17 +32,19 @@ func (h *Handler) AddCardHandler(w http.ResponseWriter, r *http.Request) {
 
 // GetCardHandler обрабатывает запрос на получение информации о карте.
 func (h *Handler) GetCardHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok || !h.requireUnlock(w, r, login) {
+		return
+	}
+
 	var requestData domain2.CardData
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		h.logger.Error("Ошибка при разборе JSON", zap.Error(err))
-		http.Error(w, "Ошибка при разборе JSON", http.StatusBadRequest)
+	if !h.decodeJSON(w, r, &requestData) {
 		return
 	}
 
-	cardNumber, cardExpiryDate, cardCVV, err := h.Services.GetCard(requestData.Login, requestData.CardName)
+	cardNumber, cardExpiryDate, cardCVV, err := h.Services.GetCard(login, requestData.CardName)
 	if err != nil {
-		h.logger.Error("Ошибка при получении информации о карте", zap.Error(err))
-		http.Error(w, "Ошибка при получении информации о карте", http.StatusInternalServerError)
+		h.writeError(w, err, "Ошибка при получении информации о карте")
 		return
 	}
 
@@ -59,8 +62,7 @@ func (h *Handler) GetCardHandler(w http.ResponseWriter, r *http.Request) {
 
 	response, err := json.Marshal(cardInfo)
 	if err != nil {
-		h.logger.Error("Ошибка при кодировании данных в JSON", zap.Error(err))
-		http.Error(w, "Ошибка при кодировании данных в JSON", http.StatusInternalServerError)
+		h.writeError(w, err, "Ошибка при кодировании данных в JSON")
 		return
 	}
 
@@ -71,25 +73,204 @@ func (h *Handler) GetCardHandler(w http.ResponseWriter, r *http.Request) {
 
 // GetCardList обрабатывает запрос на получение списка названий карт для указанного пользователя.
 func (h *Handler) GetCardList(w http.ResponseWriter, r *http.Request) {
-	var userInfo domain2.UserInfo
-	if err := json.NewDecoder(r.Body).Decode(&userInfo); err != nil {
-		h.logger.Error("Ошибка при разборе JSON", zap.Error(err))
-		http.Error(w, "Ошибка при разборе JSON", http.StatusBadRequest)
+	login, ok := h.currentLogin(w, r)
+	if !ok {
 		return
 	}
 
-	cards, err := h.Services.GetCardNameList(userInfo.Login)
+	cards, err := h.Services.GetCardNameList(login)
 	if err != nil {
-		h.logger.Error("Ошибка при получении списка названий карт", zap.Error(err))
-		http.Error(w, "Ошибка при получении списка названий карт", http.StatusInternalServerError)
+		h.writeError(w, err, "Ошибка при получении списка названий карт")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(cards); err != nil {
-		h.logger.Error("Ошибка при кодировании данных в JSON", zap.Error(err))
-		http.Error(w, "Ошибка при кодировании данных в JSON", http.StatusInternalServerError)
+		h.writeError(w, err, "Ошибка при кодировании данных в JSON")
+		return
+	}
+}
+
+// UpdateCardHandler обрабатывает запрос на изменение или переименование карты.
+func (h *Handler) UpdateCardHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.UpdateCardData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	err := h.Services.UpdateCard(
+		login, requestData.CardName, requestData.NewCardName, requestData.NumberCard, requestData.ExpiryDateCard,
+		requestData.CvvCard,
+	)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при изменении карты")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// DeleteCardHandler обрабатывает запрос на удаление карты.
+func (h *Handler) DeleteCardHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.CardData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.DeleteCard(login, requestData.CardName); err != nil {
+		h.writeError(w, err, "Ошибка при удалении карты")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetCardHistoryHandler обрабатывает запрос на получение списка ревизий карты.
+func (h *Handler) GetCardHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.CardData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	revisions, err := h.Services.GetCardHistory(login, requestData.CardName)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении истории карты")
+		return
+	}
+
+	h.writeJSON(w, revisions)
+}
+
+// GetCardRevisionHandler обрабатывает запрос на получение реквизитов карты в указанной ревизии.
+func (h *Handler) GetCardRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok || !h.requireUnlock(w, r, login) {
+		return
+	}
+
+	var requestData domain2.CardRevisionData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	revision, err := h.Services.GetCardRevision(login, requestData.CardName, requestData.Revision)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении ревизии карты")
 		return
 	}
+
+	h.writeJSON(w, revision)
+}
+
+// RestoreCardHandler обрабатывает запрос на восстановление карты из ревизии.
+func (h *Handler) RestoreCardHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.CardRevisionData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.RestoreCard(login, requestData.CardName, requestData.Revision); err != nil {
+		h.writeError(w, err, "Ошибка при восстановлении карты")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetCardTrashHandler обрабатывает запрос на получение карт из корзины.
+func (h *Handler) GetCardTrashHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.Services.GetCardTrash(login)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении корзины карт")
+		return
+	}
+
+	h.writeJSON(w, items)
+}
+
+// RestoreCardFromTrashHandler обрабатывает запрос на восстановление карты из корзины.
+func (h *Handler) RestoreCardFromTrashHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.TrashData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.RestoreCardFromTrash(login, requestData.ID); err != nil {
+		h.writeError(w, err, "Ошибка при восстановлении карты из корзины")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetCardListHandler обрабатывает запрос на получение списка карт с метаданными, отфильтрованного по тегу и папке.
+func (h *Handler) GetCardListHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.ItemFilter
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	items, err := h.Services.GetCardList(login, requestData)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении списка карт")
+		return
+	}
+
+	h.writeJSON(w, items)
+}
+
+// SetCardMetaHandler обрабатывает запрос на изменение метаданных, тегов и папки карты.
+func (h *Handler) SetCardMetaHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.CardMetaData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.SetCardMeta(login, requestData.CardName, requestData.ItemMeta); err != nil {
+		h.writeError(w, err, "Ошибка при изменении метаданных карты")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
 }
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+)
+
+// sseHeartbeat - интервал комментариев, которые не дают прокси закрыть простаивающее соединение.
+const sseHeartbeat = 15 * time.Second
+
+// WatchChangesHandler передает изменения хранилища пользователя в формате Server-Sent Events.
+// Лента возобновляется с курсора из заголовка Last-Event-ID, который браузер отправляет сам
+// при переподключении, или из параметра cursor.
+func (h *Handler) WatchChangesHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.writeError(w, fmt.Errorf("response writer does not support flushing"), "Потоковая передача не поддерживается")
+		return
+	}
+
+	cursor := r.Header.Get("Last-Event-ID")
+	if cursor == "" {
+		cursor = r.URL.Query().Get("cursor")
+	}
+
+	sub, backlog, err := h.Services.WatchChanges(login, cursor)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при подписке на изменения")
+		return
+	}
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range backlog {
+		if err := writeEvent(w, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Клиент отстал и был отключен: он переподключится с последним курсором
+				return
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent записывает событие в формате Server-Sent Events. Курсор передается как id события.
+func writeEvent(w io.Writer, event domain.ChangeEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.Cursor, event.Action, data)
+	return err
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	domain2 "github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/events"
+	"github.com/egosha7/goph-keeper/internal/service"
+	mock_service "github.com/egosha7/goph-keeper/internal/service/mocks"
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_WatchChangesHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string)
+
+	testCases := []struct {
+		name               string
+		login              string
+		lastEventID        string
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+		expectedEvents     []string
+	}{
+		{
+			name:        "Resume",
+			login:       "Egor",
+			lastEventID: "cursor",
+			mockBehavior: func(s *mock_service.MockServices, login string) {
+				bus := events.NewBus(10)
+				bus.Publish(login, domain2.ChangeEvent{Action: domain2.ActionCreated, Kind: domain2.KindPassword, Name: "Email"})
+				sub, _ := bus.Subscribe(login, "")
+				bus.Publish(login, domain2.ChangeEvent{Action: domain2.ActionDeleted, Kind: domain2.KindPassword, Name: "Email"})
+				// Закрытая подписка завершает поток после доставки накопленных событий
+				sub.Close()
+
+				backlog := []domain2.ChangeEvent{{Cursor: "c-1", Action: domain2.ActionUpdated, Name: "Email"}}
+				s.EXPECT().WatchChanges(login, "cursor").Return(sub, backlog, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedEvents:     []string{"event: updated", "event: deleted"},
+		},
+		{
+			name:  "Service Error",
+			login: "Egor",
+			mockBehavior: func(s *mock_service.MockServices, login string) {
+				s.EXPECT().WatchChanges(login, "").Return(nil, nil, errors.New("change feed is disabled"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Get(
+					"/events", func(w http.ResponseWriter, r *http.Request) {
+						handlers.WatchChangesHandler(w, r)
+					},
+				)
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("GET", "/events", nil), tc.login)
+				if tc.lastEventID != "" {
+					req.Header.Set("Last-Event-ID", tc.lastEventID)
+				}
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				body := w.Body.String()
+				last := -1
+				for _, event := range tc.expectedEvents {
+					i := strings.Index(body, event)
+					assert.Greater(t, i, last, "событие %q отсутствует или нарушен порядок", event)
+					last = i
+				}
+				if tc.expectedStatusCode == http.StatusOK {
+					assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+					assert.Contains(t, body, "id: c-1\n")
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	domain2 "github.com/egosha7/goph-keeper/internal/domain"
+	"go.uber.org/zap"
+)
+
+// ChunkChecksumHeader - заголовок с SHA-256 части файла в hex.
+const ChunkChecksumHeader = "X-Chunk-Checksum"
+
+// StartUploadHandler обрабатывает запрос на начало или возобновление загрузки файла.
+func (h *Handler) StartUploadHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.NewFileData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	session, err := h.Services.StartUpload(login, requestData.FileName, requestData.Size)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при начале загрузки файла")
+		return
+	}
+
+	h.writeJSON(w, session)
+}
+
+// UploadChunkHandler обрабатывает потоковую загрузку части файла.
+// Идентификатор файла и номер части передаются параметрами id и index, контрольная сумма - заголовком.
+func (h *Handler) UploadChunkHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	fileID, index, ok := h.chunkParams(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.Services.UploadChunk(login, fileID, index, r.Header.Get(ChunkChecksumHeader), r.Body)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при загрузке части файла")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// CompleteUploadHandler обрабатывает запрос на завершение загрузки файла.
+func (h *Handler) CompleteUploadHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.UploadData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.CompleteUpload(login, requestData.ID); err != nil {
+		h.writeError(w, err, "Ошибка при завершении загрузки файла")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetFileInfoHandler обрабатывает запрос на получение метаданных файла.
+func (h *Handler) GetFileInfoHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.FileData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	info, err := h.Services.GetFileInfo(login, requestData.FileName)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении файла")
+		return
+	}
+
+	h.writeJSON(w, info)
+}
+
+// DownloadChunkHandler обрабатывает потоковое скачивание части файла.
+// Контрольная сумма части передается заголовком, чтобы клиент мог проверить полученные данные.
+func (h *Handler) DownloadChunkHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok || !h.requireUnlock(w, r, login) {
+		return
+	}
+
+	fileID, index, ok := h.chunkParams(w, r)
+	if !ok {
+		return
+	}
+
+	body, chunk, err := h.Services.OpenFileChunk(login, fileID, index)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении части файла")
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(chunk.Size, 10))
+	w.Header().Set(ChunkChecksumHeader, chunk.Checksum)
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, body); err != nil {
+		h.logger.Error("Ошибка при отправке части файла", zap.Error(err))
+	}
+}
+
+// GetFileNameList обрабатывает запрос на получение списка названий файлов.
+func (h *Handler) GetFileNameList(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	fileNames, err := h.Services.GetFileNameList(login)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении списка файлов")
+		return
+	}
+
+	h.writeJSON(w, fileNames)
+}
+
+// DeleteFileHandler обрабатывает запрос на удаление файла.
+func (h *Handler) DeleteFileHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.FileData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.DeleteFile(login, requestData.FileName); err != nil {
+		h.writeError(w, err, "Ошибка при удалении файла")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// chunkParams разбирает идентификатор файла и номер части из параметров запроса.
+// При ошибке отправляет статус Bad Request.
+func (h *Handler) chunkParams(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	fileID, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		h.writeError(w, domain2.InvalidField("id", "invalid file id"), "Некорректный идентификатор файла")
+		return 0, 0, false
+	}
+	index, err := strconv.Atoi(r.URL.Query().Get("index"))
+	if err != nil {
+		h.writeError(w, domain2.InvalidField("index", "invalid chunk index"), "Некорректный номер части файла")
+		return 0, 0, false
+	}
+	return fileID, index, true
+}
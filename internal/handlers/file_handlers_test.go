@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/egosha7/goph-keeper/internal/blob"
+	domain2 "github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/service"
+	mock_service "github.com/egosha7/goph-keeper/internal/service/mocks"
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const testChecksum = "3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7"
+
+func TestHandler_UploadChunkHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string)
+
+	testCases := []struct {
+		name               string
+		login              string
+		target             string
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:   "Uploaded",
+			login:  "Egor",
+			target: "/file/chunk?id=5&index=2",
+			mockBehavior: func(s *mock_service.MockServices, login string) {
+				s.EXPECT().UploadChunk(login, 5, 2, testChecksum, gomock.Any()).DoAndReturn(
+					func(_ string, _, _ int, _ string, r io.Reader) error {
+						data, err := io.ReadAll(r)
+						assert.NoError(t, err)
+						assert.Equal(t, "chunk data", string(data))
+						return nil
+					},
+				)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Invalid Index",
+			login:              "Egor",
+			target:             "/file/chunk?id=5&index=last",
+			mockBehavior:       func(s *mock_service.MockServices, login string) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Checksum Mismatch",
+			login:  "Egor",
+			target: "/file/chunk?id=5&index=2",
+			mockBehavior: func(s *mock_service.MockServices, login string) {
+				s.EXPECT().UploadChunk(login, 5, 2, testChecksum, gomock.Any()).Return(service.ErrChecksumMismatch)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Too Large",
+			login:  "Egor",
+			target: "/file/chunk?id=5&index=2",
+			mockBehavior: func(s *mock_service.MockServices, login string) {
+				s.EXPECT().UploadChunk(login, 5, 2, testChecksum, gomock.Any()).Return(service.ErrChunkTooLarge)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Put(
+					"/file/chunk", func(w http.ResponseWriter, r *http.Request) {
+						handlers.UploadChunkHandler(w, r)
+					},
+				)
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("PUT", tc.target, strings.NewReader("chunk data")), tc.login)
+				req.Header.Set(ChunkChecksumHeader, testChecksum)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+			},
+		)
+	}
+}
+
+func TestHandler_DownloadChunkHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string)
+
+	testCases := []struct {
+		name                 string
+		login                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedChecksum     string
+		expectedResponseBody string
+	}{
+		{
+			name:  "Downloaded",
+			login: "Egor",
+			mockBehavior: func(s *mock_service.MockServices, login string) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().OpenFileChunk(login, 5, 0).Return(
+					io.NopCloser(strings.NewReader("chunk data")),
+					&domain2.FileChunk{Index: 0, Size: 10, Checksum: testChecksum}, nil,
+				)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedChecksum:     testChecksum,
+			expectedResponseBody: "chunk data",
+		},
+		{
+			name:  "Not Found",
+			login: "Egor",
+			mockBehavior: func(s *mock_service.MockServices, login string) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().OpenFileChunk(login, 5, 0).Return(nil, nil, domain2.NotFound("chunk not found"))
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"code":"not_found","message":"Ошибка при получении части файла","details":{"reason":"chunk not found"}}`,
+		},
+		{
+			name:  "Blob Missing",
+			login: "Egor",
+			mockBehavior: func(s *mock_service.MockServices, login string) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().OpenFileChunk(login, 5, 0).Return(nil, nil, blob.ErrNotFound)
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"code":"not_found","message":"Ошибка при получении части файла","details":{"reason":"blob not found"}}`,
+		},
+		{
+			name:  "Not Unlocked",
+			login: "Egor",
+			mockBehavior: func(s *mock_service.MockServices, login string) {
+				s.EXPECT().ParseUnlockToken("u").Return("Anna", nil)
+			},
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"code":"forbidden","message":"Требуется ввести пин-код","details":{"reason":"invalid unlock token"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Get(
+					"/file/chunk", func(w http.ResponseWriter, r *http.Request) {
+						handlers.DownloadChunkHandler(w, r)
+					},
+				)
+
+				w := httptest.NewRecorder()
+				req := withUnlock(withLogin(httptest.NewRequest("GET", "/file/chunk?id=5&index=0", nil), tc.login), "u")
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedChecksum, w.Header().Get(ChunkChecksumHeader))
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+			},
+		)
+	}
+}
@@ -1,8 +1,16 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"github.com/egosha7/goph-keeper/internal/auth"
+	"github.com/egosha7/goph-keeper/internal/domain"
 	"github.com/egosha7/goph-keeper/internal/service"
 	"go.uber.org/zap"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
 )
 
 // Handler представляет обработчик HTTP-запросов.
@@ -18,3 +26,120 @@ func NewHandler(services *service.Service, logger *zap.Logger) *Handler {
 		logger:   logger,
 	}
 }
+
+// currentLogin возвращает логин пользователя, установленный промежуточным ПО аутентификации.
+// Если пользователь не аутентифицирован, отправляет статус Unauthorized.
+func (h *Handler) currentLogin(w http.ResponseWriter, r *http.Request) (string, bool) {
+	login, ok := auth.LoginFromContext(r.Context())
+	if !ok {
+		h.writeError(w, domain.Unauthorized("no login in request context"), "Пользователь не аутентифицирован")
+	}
+	return login, ok
+}
+
+// requireUnlock проверяет разрешение на чтение секретов, выданное пользователю после проверки пин-кода.
+// Если разрешения нет или оно истекло, отправляет статус Forbidden.
+func (h *Handler) requireUnlock(w http.ResponseWriter, r *http.Request, login string) bool {
+	token := r.Header.Get(domain.UnlockHeader)
+	if token == "" {
+		h.writeError(w, domain.Forbidden("unlock token required"), "Требуется ввести пин-код")
+		return false
+	}
+	owner, err := h.Services.ParseUnlockToken(token)
+	if err != nil || owner != login {
+		h.logger.Info("Недействительный токен разблокировки", zap.Error(err))
+		h.writeError(w, domain.Forbidden("invalid unlock token"), "Требуется ввести пин-код")
+		return false
+	}
+	return true
+}
+
+// writeJSON кодирует значение в JSON и отправляет его со статусом OK.
+func (h *Handler) writeJSON(w http.ResponseWriter, v interface{}) {
+	response, err := json.Marshal(v)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при кодировании данных в JSON")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// decodeJSON разбирает JSON из тела запроса. Если тело не разбирается, отправляет статус BadRequest.
+func (h *Handler) decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		h.writeError(w, domain.Invalid("malformed json: %v", err), "Ошибка при разборе JSON")
+		return false
+	}
+	return true
+}
+
+// writeError отправляет ошибку в формате domain.ErrorResponse с описанием message.
+// Статус и код ответа определяются видом ошибки, а ее текст передается в подробностях.
+// Ошибки без вида логируются и отправляются со статусом 500 без подробностей, чтобы не раскрывать внутреннее устройство.
+func (h *Handler) writeError(w http.ResponseWriter, err error, message string) {
+	status, code := statusOf(err)
+	response := domain.ErrorResponse{Code: code, Message: message}
+	if status == http.StatusInternalServerError {
+		h.logger.Error(message, zap.Error(err))
+		WriteError(w, status, response)
+		return
+	}
+
+	h.logger.Info(message, zap.Error(err))
+	response.Details = map[string]string{"reason": err.Error()}
+	var typed *domain.Error
+	if errors.As(err, &typed) {
+		for key, value := range typed.Details {
+			response.Details[key] = value
+		}
+	}
+	// Клиент узнает, когда можно повторить заблокированное действие
+	var (
+		pinLocked          *service.PinLockedError
+		secondFactorLocked *service.SecondFactorLockedError
+		until              time.Time
+	)
+	switch {
+	case errors.As(err, &pinLocked):
+		until = pinLocked.Until
+	case errors.As(err, &secondFactorLocked):
+		until = secondFactorLocked.Until
+	}
+	if !until.IsZero() {
+		seconds := strconv.Itoa(int(math.Ceil(time.Until(until).Seconds())))
+		w.Header().Set("Retry-After", seconds)
+		response.Details["retryAfter"] = seconds
+	}
+	WriteError(w, status, response)
+}
+
+// WriteError отправляет тело ошибки в JSON с указанным статусом.
+func WriteError(w http.ResponseWriter, status int, response domain.ErrorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// statusOf возвращает статус HTTP и код ответа для вида ошибки.
+func statusOf(err error) (int, string) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound, domain.CodeNotFound
+	case errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict, domain.CodeConflict
+	case errors.Is(err, domain.ErrUnauthorized):
+		return http.StatusUnauthorized, domain.CodeUnauthorized
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden, domain.CodeForbidden
+	case errors.Is(err, domain.ErrLocked):
+		return http.StatusLocked, domain.CodeLocked
+	case errors.Is(err, domain.ErrValidation):
+		return http.StatusBadRequest, domain.CodeValidation
+	default:
+		return http.StatusInternalServerError, domain.CodeInternal
+	}
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/egosha7/goph-keeper/internal/auth"
+	"github.com/egosha7/goph-keeper/internal/domain"
+)
+
+// withLogin возвращает копию запроса с логином аутентифицированного пользователя в контексте.
+func withLogin(req *http.Request, login string) *http.Request {
+	return req.WithContext(auth.WithLogin(req.Context(), login))
+}
+
+// withUnlock возвращает копию запроса с токеном разблокировки в заголовке.
+func withUnlock(req *http.Request, token string) *http.Request {
+	req.Header.Set(domain.UnlockHeader, token)
+	return req
+}
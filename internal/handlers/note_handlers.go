@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	domain2 "github.com/egosha7/goph-keeper/internal/domain"
+	"net/http"
+)
+
+// AddNoteHandler обрабатывает запрос на добавление новой заметки.
+func (h *Handler) AddNoteHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.NewNoteData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.AddNote(login, requestData.Title, requestData.Body); err != nil {
+		h.writeError(w, err, "Ошибка при добавлении новой заметки")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetNoteHandler обрабатывает запрос на получение заметки.
+func (h *Handler) GetNoteHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok || !h.requireUnlock(w, r, login) {
+		return
+	}
+
+	var requestData domain2.NoteData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	body, err := h.Services.GetNote(login, requestData.Title)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении заметки")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(body))
+}
+
+// GetNoteNameList обрабатывает запрос на получение списка заголовков заметок.
+func (h *Handler) GetNoteNameList(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	titles, err := h.Services.GetNoteNameList(login)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении списка заметок")
+		return
+	}
+
+	h.writeJSON(w, titles)
+}
+
+// UpdateNoteHandler обрабатывает запрос на изменение или переименование заметки.
+func (h *Handler) UpdateNoteHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.UpdateNoteData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	err := h.Services.UpdateNote(login, requestData.Title, requestData.NewTitle, requestData.Body)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при изменении заметки")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// DeleteNoteHandler обрабатывает запрос на удаление заметки.
+func (h *Handler) DeleteNoteHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.NoteData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.DeleteNote(login, requestData.Title); err != nil {
+		h.writeError(w, err, "Ошибка при удалении заметки")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetNoteHistoryHandler обрабатывает запрос на получение списка ревизий заметки.
+func (h *Handler) GetNoteHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.NoteData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	revisions, err := h.Services.GetNoteHistory(login, requestData.Title)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении истории заметки")
+		return
+	}
+
+	h.writeJSON(w, revisions)
+}
+
+// GetNoteRevisionHandler обрабатывает запрос на получение заметки в указанной ревизии.
+func (h *Handler) GetNoteRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok || !h.requireUnlock(w, r, login) {
+		return
+	}
+
+	var requestData domain2.NoteRevisionData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	revision, err := h.Services.GetNoteRevision(login, requestData.Title, requestData.Revision)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении ревизии заметки")
+		return
+	}
+
+	h.writeJSON(w, revision)
+}
+
+// RestoreNoteHandler обрабатывает запрос на восстановление заметки из ревизии.
+func (h *Handler) RestoreNoteHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.NoteRevisionData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.RestoreNote(login, requestData.Title, requestData.Revision); err != nil {
+		h.writeError(w, err, "Ошибка при восстановлении заметки")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetNoteTrashHandler обрабатывает запрос на получение заметок из корзины.
+func (h *Handler) GetNoteTrashHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.Services.GetNoteTrash(login)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении корзины заметок")
+		return
+	}
+
+	h.writeJSON(w, items)
+}
+
+// RestoreNoteFromTrashHandler обрабатывает запрос на восстановление заметки из корзины.
+func (h *Handler) RestoreNoteFromTrashHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.TrashData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.RestoreNoteFromTrash(login, requestData.ID); err != nil {
+		h.writeError(w, err, "Ошибка при восстановлении заметки из корзины")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
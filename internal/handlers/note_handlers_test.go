@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	domain2 "github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/service"
+	mock_service "github.com/egosha7/goph-keeper/internal/service/mocks"
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_AddNoteHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.NewNoteData)
+
+	testCases := []struct {
+		name                 string
+		login                string
+		requestData          domain2.NewNoteData
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:        "Valid Note",
+			login:       "Egor",
+			requestData: domain2.NewNoteData{Title: "Recovery codes", Body: "c2VjcmV0"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.NewNoteData) {
+				s.EXPECT().AddNote(login, requestData.Title, requestData.Body).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:        "Duplicate Note",
+			login:       "Egor",
+			requestData: domain2.NewNoteData{Title: "Recovery codes", Body: "c2VjcmV0"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.NewNoteData) {
+				s.EXPECT().AddNote(login, requestData.Title, requestData.Body).Return(domain2.Conflict("note already exists"))
+			},
+			expectedStatusCode:   http.StatusConflict,
+			expectedResponseBody: `{"code":"conflict","message":"Ошибка при добавлении новой заметки","details":{"reason":"note already exists"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/add-note", func(w http.ResponseWriter, r *http.Request) {
+						handlers.AddNoteHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/add-note", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+			},
+		)
+	}
+}
+
+func TestHandler_GetNoteHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.NoteData)
+
+	testCases := []struct {
+		name                 string
+		login                string
+		requestData          domain2.NoteData
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:        "Valid Note",
+			login:       "Egor",
+			requestData: domain2.NoteData{Title: "Recovery codes"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.NoteData) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().GetNote(login, requestData.Title).Return("c2VjcmV0", nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `c2VjcmV0`,
+		},
+		{
+			name:        "Not Found",
+			login:       "Egor",
+			requestData: domain2.NoteData{Title: "Unknown"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.NoteData) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().GetNote(login, requestData.Title).Return("", domain2.NotFound("note not found"))
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"code":"not_found","message":"Ошибка при получении заметки","details":{"reason":"note not found"}}`,
+		},
+		{
+			name:        "Not Unlocked",
+			login:       "Egor",
+			requestData: domain2.NoteData{Title: "Recovery codes"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.NoteData) {
+				s.EXPECT().ParseUnlockToken("u").Return("Anna", nil)
+			},
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"code":"forbidden","message":"Требуется ввести пин-код","details":{"reason":"invalid unlock token"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/get-note", func(w http.ResponseWriter, r *http.Request) {
+						handlers.GetNoteHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withUnlock(withLogin(httptest.NewRequest("POST", "/get-note", bytes.NewBuffer(requestBody)), tc.login), "u")
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+			},
+		)
+	}
+}
+
+func TestHandler_UpdateNoteHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.UpdateNoteData)
+
+	testCases := []struct {
+		name               string
+		login              string
+		requestData        domain2.UpdateNoteData
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:        "Renamed",
+			login:       "Egor",
+			requestData: domain2.UpdateNoteData{Title: "Codes", NewTitle: "Recovery codes", Body: "c2VjcmV0"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.UpdateNoteData) {
+				s.EXPECT().UpdateNote(login, requestData.Title, requestData.NewTitle, requestData.Body).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:        "Not Found",
+			login:       "Egor",
+			requestData: domain2.UpdateNoteData{Title: "Unknown", Body: "c2VjcmV0"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.UpdateNoteData) {
+				s.EXPECT().UpdateNote(
+					login, requestData.Title, requestData.NewTitle, requestData.Body,
+				).Return(domain2.NotFound("note not found"))
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/update-note", func(w http.ResponseWriter, r *http.Request) {
+						handlers.UpdateNoteHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/update-note", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+			},
+		)
+	}
+}
+
+func TestHandler_DeleteNoteHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.NoteData)
+
+	testCases := []struct {
+		name               string
+		login              string
+		requestData        domain2.NoteData
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:        "Deleted",
+			login:       "Egor",
+			requestData: domain2.NoteData{Title: "Recovery codes"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.NoteData) {
+				s.EXPECT().DeleteNote(login, requestData.Title).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:        "Not Found",
+			login:       "Egor",
+			requestData: domain2.NoteData{Title: "Unknown"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.NoteData) {
+				s.EXPECT().DeleteNote(login, requestData.Title).Return(domain2.NotFound("note not found"))
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/delete-note", func(w http.ResponseWriter, r *http.Request) {
+						handlers.DeleteNoteHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/delete-note", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+			},
+		)
+	}
+}
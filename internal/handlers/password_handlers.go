@@ -3,23 +3,24 @@ package handlers
 import (
 	"encoding/json"
 	domain2 "github.com/egosha7/goph-keeper/internal/domain"
-	"go.uber.org/zap"
 	"net/http"
 )
 
 // AddPasswordHandler обрабатывает запрос на добавление нового пароля.
 func (h *Handler) AddPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
 	var requestData domain2.PasswordData
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		h.logger.Error("Ошибка при разборе JSON", zap.Error(err))
-		http.Error(w, "Ошибка при разборе JSON", http.StatusBadRequest)
+	if !h.decodeJSON(w, r, &requestData) {
 		return
 	}
 
-	err := h.Services.AddPassword(requestData.Login, requestData.PassName, requestData.Password)
+	err := h.Services.AddPassword(login, requestData.PassName, requestData.Password)
 	if err != nil {
-		h.logger.Error("Ошибка при добавлении нового пароля", zap.Error(err))
-		http.Error(w, "Ошибка при добавлении нового пароля", http.StatusInternalServerError)
+		h.writeError(w, err, "Ошибка при добавлении нового пароля")
 		return
 	}
 
@@ -28,17 +29,19 @@ func (h *Handler) AddPasswordHandler(w http.ResponseWriter, r *http.Request) {
 
 // GetPasswordHandler обрабатывает запрос на получение пароля.
 func (h *Handler) GetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok || !h.requireUnlock(w, r, login) {
+		return
+	}
+
 	var requestData domain2.PassData
-	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		h.logger.Error("Ошибка при разборе JSON", zap.Error(err))
-		http.Error(w, "Ошибка при разборе JSON", http.StatusBadRequest)
+	if !h.decodeJSON(w, r, &requestData) {
 		return
 	}
 
-	password, err := h.Services.GetPassword(requestData.Login, requestData.PassName)
+	password, err := h.Services.GetPassword(login, requestData.PassName)
 	if err != nil {
-		h.logger.Error("Ошибка при получении пароля", zap.Error(err))
-		http.Error(w, "Ошибка при получении пароля", http.StatusInternalServerError)
+		h.writeError(w, err, "Ошибка при получении пароля")
 		return
 	}
 
@@ -49,25 +52,299 @@ func (h *Handler) GetPasswordHandler(w http.ResponseWriter, r *http.Request) {
 
 // GetPasswordNameList обрабатывает запрос на получение списка названий паролей для указанного пользователя.
 func (h *Handler) GetPasswordNameList(w http.ResponseWriter, r *http.Request) {
-	var userInfo domain2.UserInfo
-	if err := json.NewDecoder(r.Body).Decode(&userInfo); err != nil {
-		h.logger.Error("Ошибка при разборе JSON", zap.Error(err))
-		http.Error(w, "Ошибка при разборе JSON", http.StatusBadRequest)
+	login, ok := h.currentLogin(w, r)
+	if !ok {
 		return
 	}
 
-	passwords, err := h.Services.GetPasswordNameList(userInfo.Login)
+	passwords, err := h.Services.GetPasswordNameList(login)
 	if err != nil {
-		h.logger.Error("Ошибка при получении списка названий паролей", zap.Error(err))
-		http.Error(w, "Ошибка при получении списка названий паролей", http.StatusInternalServerError)
+		h.writeError(w, err, "Ошибка при получении списка названий паролей")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(passwords); err != nil {
-		h.logger.Error("Ошибка при кодировании данных в JSON", zap.Error(err))
-		http.Error(w, "Ошибка при кодировании данных в JSON", http.StatusInternalServerError)
+		h.writeError(w, err, "Ошибка при кодировании данных в JSON")
+		return
+	}
+}
+
+// UpdatePasswordHandler обрабатывает запрос на изменение или переименование пароля.
+func (h *Handler) UpdatePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.UpdatePasswordData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	err := h.Services.UpdatePassword(login, requestData.PassName, requestData.NewPassName, requestData.Password)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при изменении пароля")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// DeletePasswordHandler обрабатывает запрос на удаление пароля.
+func (h *Handler) DeletePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.PassData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.DeletePassword(login, requestData.PassName); err != nil {
+		h.writeError(w, err, "Ошибка при удалении пароля")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetPasswordHistoryHandler обрабатывает запрос на получение списка ревизий пароля.
+func (h *Handler) GetPasswordHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.PassData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	revisions, err := h.Services.GetPasswordHistory(login, requestData.PassName)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении истории пароля")
+		return
+	}
+
+	h.writeJSON(w, revisions)
+}
+
+// GetPasswordRevisionHandler обрабатывает запрос на получение пароля в указанной ревизии.
+func (h *Handler) GetPasswordRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok || !h.requireUnlock(w, r, login) {
+		return
+	}
+
+	var requestData domain2.PasswordRevisionData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	revision, err := h.Services.GetPasswordRevision(login, requestData.PassName, requestData.Revision)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении ревизии пароля")
+		return
+	}
+
+	h.writeJSON(w, revision)
+}
+
+// RestorePasswordHandler обрабатывает запрос на восстановление пароля из ревизии.
+func (h *Handler) RestorePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.PasswordRevisionData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.RestorePassword(login, requestData.PassName, requestData.Revision); err != nil {
+		h.writeError(w, err, "Ошибка при восстановлении пароля")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetPasswordTrashHandler обрабатывает запрос на получение паролей из корзины.
+func (h *Handler) GetPasswordTrashHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.Services.GetPasswordTrash(login)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении корзины паролей")
+		return
+	}
+
+	h.writeJSON(w, items)
+}
+
+// RestorePasswordFromTrashHandler обрабатывает запрос на восстановление пароля из корзины.
+func (h *Handler) RestorePasswordFromTrashHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.TrashData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.RestorePasswordFromTrash(login, requestData.ID); err != nil {
+		h.writeError(w, err, "Ошибка при восстановлении пароля из корзины")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetPasswordListHandler обрабатывает запрос на получение списка паролей с метаданными, отфильтрованного по тегу и папке.
+func (h *Handler) GetPasswordListHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.ItemFilter
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	items, err := h.Services.GetPasswordList(login, requestData)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении списка паролей")
+		return
+	}
+
+	h.writeJSON(w, items)
+}
+
+// SetPasswordMetaHandler обрабатывает запрос на изменение метаданных, тегов и папки пароля.
+func (h *Handler) SetPasswordMetaHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.PasswordMetaData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.SetPasswordMeta(login, requestData.PassName, requestData.ItemMeta); err != nil {
+		h.writeError(w, err, "Ошибка при изменении метаданных пароля")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// SetPasswordURLsHandler обрабатывает запрос на замену адресов сайтов пароля.
+func (h *Handler) SetPasswordURLsHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.PasswordURLsData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.SetPasswordURLs(login, requestData.PassName, requestData.URLs); err != nil {
+		h.writeError(w, err, "Ошибка при изменении адресов сайтов пароля")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetPasswordURLsHandler обрабатывает запрос на получение адресов сайтов паролей.
+func (h *Handler) GetPasswordURLsHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	passwords, err := h.Services.GetPasswordURLs(login)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении адресов сайтов паролей")
+		return
+	}
+
+	h.writeJSON(w, passwords)
+}
+
+// MatchPasswordsHandler обрабатывает запрос на подбор паролей для адреса страницы.
+func (h *Handler) MatchPasswordsHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.MatchData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	matches, err := h.Services.MatchPasswords(login, requestData.URL)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при подборе паролей для сайта")
+		return
+	}
+
+	h.writeJSON(w, matches)
+}
+
+// SetPasswordOTPHandler обрабатывает запрос на привязку к паролю ключа одноразовых паролей.
+func (h *Handler) SetPasswordOTPHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
 		return
 	}
+
+	var requestData domain2.PasswordOTPData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.SetPasswordOTP(login, requestData.PassName, requestData.OTP); err != nil {
+		h.writeError(w, err, "Ошибка при привязке одноразовых паролей")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetPasswordEntryHandler обрабатывает запрос на получение пароля вместе с ключом одноразовых паролей.
+func (h *Handler) GetPasswordEntryHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok || !h.requireUnlock(w, r, login) {
+		return
+	}
+
+	var requestData domain2.PassData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	entry, err := h.Services.GetPasswordEntry(login, requestData.PassName)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении пароля")
+		return
+	}
+
+	h.writeJSON(w, entry)
 }
@@ -15,44 +15,46 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestHandler_AddPasswordHandler(t *testing.T) {
-	type mockBehavior func(s *mock_service.MockServices, requestData domain2.PasswordData)
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.PasswordData)
 
 	testCases := []struct {
 		name                 string
+		login                string
 		requestData          domain2.PasswordData
 		mockBehavior         mockBehavior
 		expectedStatusCode   int
 		expectedResponseBody string
 	}{
 		{
-			name: "Valid Password",
+			name:  "Valid Password",
+			login: "Egor",
 			requestData: domain2.PasswordData{
-				Login:    "Egor",
 				PassName: "Email",
 				Password: "password123",
 			},
-			mockBehavior: func(s *mock_service.MockServices, requestData domain2.PasswordData) {
-				s.EXPECT().AddPassword(requestData.Login, requestData.PassName, requestData.Password).Return(nil)
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PasswordData) {
+				s.EXPECT().AddPassword(login, requestData.PassName, requestData.Password).Return(nil)
 			},
 			expectedStatusCode: http.StatusOK,
 		},
 		{
-			name: "Invalid Password",
+			name:  "Invalid Password",
+			login: "NonExistentUser",
 			requestData: domain2.PasswordData{
-				Login:    "NonExistentUser",
 				PassName: "Email",
 				Password: "password123",
 			},
-			mockBehavior: func(s *mock_service.MockServices, requestData domain2.PasswordData) {
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PasswordData) {
 				s.EXPECT().AddPassword(
-					requestData.Login, requestData.PassName, requestData.Password,
-				).Return(errors.New("user not found"))
+					login, requestData.PassName, requestData.Password,
+				).Return(domain2.NotFound("user not found"))
 			},
-			expectedStatusCode:   http.StatusInternalServerError,
-			expectedResponseBody: `Ошибка при добавлении нового пароля`,
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"code":"not_found","message":"Ошибка при добавлении нового пароля","details":{"reason":"user not found"}}`,
 		},
 	}
 
@@ -63,7 +65,7 @@ func TestHandler_AddPasswordHandler(t *testing.T) {
 				defer ctrl.Finish()
 
 				auth := mock_service.NewMockServices(ctrl)
-				tc.mockBehavior(auth, tc.requestData)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
 				logger := zap.NewExample()
 				userService := &service.Service{Services: auth}
 
@@ -82,7 +84,7 @@ func TestHandler_AddPasswordHandler(t *testing.T) {
 				}
 
 				w := httptest.NewRecorder()
-				req := httptest.NewRequest("POST", "/add-password", bytes.NewBuffer(requestBody))
+				req := withLogin(httptest.NewRequest("POST", "/add-password", bytes.NewBuffer(requestBody)), tc.login)
 				r.ServeHTTP(w, req)
 
 				assert.Equal(t, tc.expectedStatusCode, w.Code)
@@ -95,38 +97,53 @@ func TestHandler_AddPasswordHandler(t *testing.T) {
 }
 
 func TestHandler_GetPasswordHandler(t *testing.T) {
-	type mockBehavior func(s *mock_service.MockServices, requestData domain2.PassData)
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.PassData)
 
 	testCases := []struct {
 		name                 string
+		login                string
 		requestData          domain2.PassData
 		mockBehavior         mockBehavior
 		expectedStatusCode   int
 		expectedResponseBody string
 	}{
 		{
-			name: "Valid Password",
+			name:  "Valid Password",
+			login: "Egor",
 			requestData: domain2.PassData{
-				Login:    "Egor",
 				PassName: "Email",
 			},
-			mockBehavior: func(s *mock_service.MockServices, requestData domain2.PassData) {
-				s.EXPECT().GetPassword(requestData.Login, requestData.PassName).Return("password123", nil)
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PassData) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().GetPassword(login, requestData.PassName).Return("password123", nil)
 			},
 			expectedStatusCode:   http.StatusOK,
 			expectedResponseBody: "password123",
 		},
 		{
-			name: "Invalid Password",
+			name:  "Invalid Password",
+			login: "NonExistentUser",
 			requestData: domain2.PassData{
-				Login:    "NonExistentUser",
 				PassName: "Email",
 			},
-			mockBehavior: func(s *mock_service.MockServices, requestData domain2.PassData) {
-				s.EXPECT().GetPassword(requestData.Login, requestData.PassName).Return("", errors.New("user not found"))
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PassData) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().GetPassword(login, requestData.PassName).Return("", domain2.NotFound("user not found"))
 			},
-			expectedStatusCode:   http.StatusInternalServerError,
-			expectedResponseBody: `Ошибка при получении пароля`,
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"code":"not_found","message":"Ошибка при получении пароля","details":{"reason":"user not found"}}`,
+		},
+		{
+			name:  "Not Unlocked",
+			login: "Egor",
+			requestData: domain2.PassData{
+				PassName: "Email",
+			},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PassData) {
+				s.EXPECT().ParseUnlockToken("u").Return("", errors.New("invalid token"))
+			},
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"code":"forbidden","message":"Требуется ввести пин-код","details":{"reason":"invalid unlock token"}}`,
 		},
 	}
 
@@ -137,7 +154,7 @@ func TestHandler_GetPasswordHandler(t *testing.T) {
 				defer ctrl.Finish()
 
 				auth := mock_service.NewMockServices(ctrl)
-				tc.mockBehavior(auth, tc.requestData)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
 				logger := zap.NewExample()
 				userService := &service.Service{Services: auth}
 
@@ -156,7 +173,7 @@ func TestHandler_GetPasswordHandler(t *testing.T) {
 				}
 
 				w := httptest.NewRecorder()
-				req := httptest.NewRequest("POST", "/get-password", bytes.NewBuffer(requestBody))
+				req := withUnlock(withLogin(httptest.NewRequest("POST", "/get-password", bytes.NewBuffer(requestBody)), tc.login), "u")
 				r.ServeHTTP(w, req)
 
 				assert.Equal(t, tc.expectedStatusCode, w.Code)
@@ -169,36 +186,32 @@ func TestHandler_GetPasswordHandler(t *testing.T) {
 }
 
 func TestHandler_GetPasswordNameList(t *testing.T) {
-	type mockBehavior func(s *mock_service.MockServices, userInfo domain2.UserInfo)
+	type mockBehavior func(s *mock_service.MockServices, login string)
 
 	testCases := []struct {
 		name                 string
-		userInfo             domain2.UserInfo
+		login                string
 		mockBehavior         mockBehavior
 		expectedStatusCode   int
 		expectedResponseBody string
 	}{
 		{
-			name: "Valid User",
-			userInfo: domain2.UserInfo{
-				Login: "Egor",
-			},
-			mockBehavior: func(s *mock_service.MockServices, userInfo domain2.UserInfo) {
-				s.EXPECT().GetPasswordNameList(userInfo.Login).Return([]string{"Email", "SocialMedia"}, nil)
+			name:  "Valid User",
+			login: "Egor",
+			mockBehavior: func(s *mock_service.MockServices, login string) {
+				s.EXPECT().GetPasswordNameList(login).Return([]string{"Email", "SocialMedia"}, nil)
 			},
 			expectedStatusCode:   http.StatusOK,
 			expectedResponseBody: `["Email","SocialMedia"]`,
 		},
 		{
-			name: "Invalid User",
-			userInfo: domain2.UserInfo{
-				Login: "NonExistentUser",
-			},
-			mockBehavior: func(s *mock_service.MockServices, userInfo domain2.UserInfo) {
-				s.EXPECT().GetPasswordNameList(userInfo.Login).Return(nil, errors.New("user not found"))
+			name:  "Invalid User",
+			login: "NonExistentUser",
+			mockBehavior: func(s *mock_service.MockServices, login string) {
+				s.EXPECT().GetPasswordNameList(login).Return(nil, domain2.NotFound("user not found"))
 			},
-			expectedStatusCode:   http.StatusInternalServerError,
-			expectedResponseBody: `Ошибка при получении списка названий паролей`,
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"code":"not_found","message":"Ошибка при получении списка названий паролей","details":{"reason":"user not found"}}`,
 		},
 	}
 
@@ -209,7 +222,7 @@ func TestHandler_GetPasswordNameList(t *testing.T) {
 				defer ctrl.Finish()
 
 				auth := mock_service.NewMockServices(ctrl)
-				tc.mockBehavior(auth, tc.userInfo)
+				tc.mockBehavior(auth, tc.login)
 				logger := zap.NewExample()
 				userService := &service.Service{Services: auth}
 
@@ -222,13 +235,89 @@ func TestHandler_GetPasswordNameList(t *testing.T) {
 					},
 				)
 
-				requestBody, err := json.Marshal(tc.userInfo)
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/get-password-name-list", nil), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				if tc.expectedResponseBody != "" {
+					assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+				}
+			},
+		)
+	}
+}
+
+func TestHandler_UpdatePasswordHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.UpdatePasswordData)
+
+	testCases := []struct {
+		name                 string
+		login                string
+		requestData          domain2.UpdatePasswordData
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "Rename And Update",
+			login: "Egor",
+			requestData: domain2.UpdatePasswordData{
+				PassName:    "Email",
+				NewPassName: "Gmail",
+				Password:    "newpassword",
+			},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.UpdatePasswordData) {
+				s.EXPECT().UpdatePassword(
+					login, requestData.PassName, requestData.NewPassName, requestData.Password,
+				).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:  "Not Found",
+			login: "Egor",
+			requestData: domain2.UpdatePasswordData{
+				PassName: "Unknown",
+				Password: "newpassword",
+			},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.UpdatePasswordData) {
+				s.EXPECT().UpdatePassword(
+					login, requestData.PassName, "", requestData.Password,
+				).Return(domain2.NotFound("password not found"))
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"code":"not_found","message":"Ошибка при изменении пароля","details":{"reason":"password not found"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/update-password", func(w http.ResponseWriter, r *http.Request) {
+						handlers.UpdatePasswordHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
 				if err != nil {
 					t.Fatal(err)
 				}
 
 				w := httptest.NewRecorder()
-				req := httptest.NewRequest("POST", "/get-password-name-list", bytes.NewBuffer(requestBody))
+				req := withLogin(httptest.NewRequest("POST", "/update-password", bytes.NewBuffer(requestBody)), tc.login)
 				r.ServeHTTP(w, req)
 
 				assert.Equal(t, tc.expectedStatusCode, w.Code)
@@ -239,3 +328,736 @@ func TestHandler_GetPasswordNameList(t *testing.T) {
 		)
 	}
 }
+
+func TestHandler_DeletePasswordHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.PassData)
+
+	testCases := []struct {
+		name               string
+		login              string
+		requestData        domain2.PassData
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:        "Deleted",
+			login:       "Egor",
+			requestData: domain2.PassData{PassName: "Email"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PassData) {
+				s.EXPECT().DeletePassword(login, requestData.PassName).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:        "Not Found",
+			login:       "Egor",
+			requestData: domain2.PassData{PassName: "Unknown"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PassData) {
+				s.EXPECT().DeletePassword(login, requestData.PassName).Return(domain2.NotFound("password not found"))
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/delete-password", func(w http.ResponseWriter, r *http.Request) {
+						handlers.DeletePasswordHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/delete-password", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+			},
+		)
+	}
+}
+
+func TestHandler_GetPasswordHistoryHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.PassData)
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		name                 string
+		login                string
+		requestData          domain2.PassData
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:        "History",
+			login:       "Egor",
+			requestData: domain2.PassData{PassName: "Email"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PassData) {
+				s.EXPECT().GetPasswordHistory(login, requestData.PassName).Return(
+					[]domain2.Revision{{Revision: 2, Name: "Email", ChangedBy: login, CreatedAt: createdAt}}, nil,
+				)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `[{"revision":2,"name":"Email","changedBy":"Egor","createdAt":"2024-01-02T03:04:05Z"}]`,
+		},
+		{
+			name:        "Not Found",
+			login:       "Egor",
+			requestData: domain2.PassData{PassName: "Unknown"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PassData) {
+				s.EXPECT().GetPasswordHistory(login, requestData.PassName).Return(nil, domain2.NotFound("password not found"))
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"code":"not_found","message":"Ошибка при получении истории пароля","details":{"reason":"password not found"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/password-history", func(w http.ResponseWriter, r *http.Request) {
+						handlers.GetPasswordHistoryHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/password-history", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+			},
+		)
+	}
+}
+
+func TestHandler_GetPasswordRevisionHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.PasswordRevisionData)
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		name                 string
+		login                string
+		requestData          domain2.PasswordRevisionData
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:        "Revision",
+			login:       "Egor",
+			requestData: domain2.PasswordRevisionData{PassName: "Email", Revision: 1},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PasswordRevisionData) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().GetPasswordRevision(login, requestData.PassName, requestData.Revision).Return(
+					&domain2.PasswordRevision{
+						Revision: domain2.Revision{Revision: 1, Name: "Email", ChangedBy: login, CreatedAt: createdAt},
+						Password: "cipher",
+					}, nil,
+				)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"revision":1,"name":"Email","changedBy":"Egor","createdAt":"2024-01-02T03:04:05Z","password":"cipher"}`,
+		},
+		{
+			name:        "Revision Not Found",
+			login:       "Egor",
+			requestData: domain2.PasswordRevisionData{PassName: "Email", Revision: 42},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PasswordRevisionData) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().GetPasswordRevision(
+					login, requestData.PassName, requestData.Revision,
+				).Return(nil, domain2.NotFound("revision not found"))
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"code":"not_found","message":"Ошибка при получении ревизии пароля","details":{"reason":"revision not found"}}`,
+		},
+		{
+			name:        "Not Unlocked",
+			login:       "Egor",
+			requestData: domain2.PasswordRevisionData{PassName: "Email", Revision: 1},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PasswordRevisionData) {
+				s.EXPECT().ParseUnlockToken("u").Return("Anna", nil)
+			},
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"code":"forbidden","message":"Требуется ввести пин-код","details":{"reason":"invalid unlock token"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/password-revision", func(w http.ResponseWriter, r *http.Request) {
+						handlers.GetPasswordRevisionHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withUnlock(withLogin(httptest.NewRequest("POST", "/password-revision", bytes.NewBuffer(requestBody)), tc.login), "u")
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+			},
+		)
+	}
+}
+
+func TestHandler_RestorePasswordHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.PasswordRevisionData)
+
+	testCases := []struct {
+		name               string
+		login              string
+		requestData        domain2.PasswordRevisionData
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:        "Restored",
+			login:       "Egor",
+			requestData: domain2.PasswordRevisionData{PassName: "Email", Revision: 1},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PasswordRevisionData) {
+				s.EXPECT().RestorePassword(login, requestData.PassName, requestData.Revision).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:        "Revision Not Found",
+			login:       "Egor",
+			requestData: domain2.PasswordRevisionData{PassName: "Email", Revision: 42},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PasswordRevisionData) {
+				s.EXPECT().RestorePassword(
+					login, requestData.PassName, requestData.Revision,
+				).Return(domain2.NotFound("revision not found"))
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/restore-password", func(w http.ResponseWriter, r *http.Request) {
+						handlers.RestorePasswordHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/restore-password", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+			},
+		)
+	}
+}
+
+func TestHandler_GetPasswordTrashHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string)
+
+	deletedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		name                 string
+		login                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "Trash",
+			login: "Egor",
+			mockBehavior: func(s *mock_service.MockServices, login string) {
+				s.EXPECT().GetPasswordTrash(login).Return(
+					[]domain2.TrashItem{{ID: 7, Name: "Email", DeletedAt: deletedAt}}, nil,
+				)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `[{"id":7,"name":"Email","deletedAt":"2024-01-02T03:04:05Z"}]`,
+		},
+		{
+			name:  "Error",
+			login: "Egor",
+			mockBehavior: func(s *mock_service.MockServices, login string) {
+				s.EXPECT().GetPasswordTrash(login).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"code":"internal","message":"Ошибка при получении корзины паролей"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/password-trash", func(w http.ResponseWriter, r *http.Request) {
+						handlers.GetPasswordTrashHandler(w, r)
+					},
+				)
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/password-trash", nil), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+			},
+		)
+	}
+}
+
+func TestHandler_GetPasswordListHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.ItemFilter)
+
+	testCases := []struct {
+		name                 string
+		login                string
+		requestData          domain2.ItemFilter
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:        "Filtered",
+			login:       "Egor",
+			requestData: domain2.ItemFilter{Tag: "work", Folder: "Почта"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.ItemFilter) {
+				s.EXPECT().GetPasswordList(login, requestData).Return(
+					[]domain2.ItemSummary{
+						{
+							Name: "Email",
+							ItemMeta: domain2.ItemMeta{
+								Metadata: map[string]string{"site": "mail.example.com"},
+								Tags:     []string{"work"},
+								Folder:   "Почта/Рабочая",
+							},
+						},
+					}, nil,
+				)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `[{"name":"Email","metadata":{"site":"mail.example.com"},` +
+				`"tags":["work"],"folder":"Почта/Рабочая"}]`,
+		},
+		{
+			name:        "Error",
+			login:       "Egor",
+			requestData: domain2.ItemFilter{},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.ItemFilter) {
+				s.EXPECT().GetPasswordList(login, requestData).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"code":"internal","message":"Ошибка при получении списка паролей"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/password-list", func(w http.ResponseWriter, r *http.Request) {
+						handlers.GetPasswordListHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/password-list", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+			},
+		)
+	}
+}
+
+func TestHandler_SetPasswordURLsHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.PasswordURLsData)
+
+	testCases := []struct {
+		name               string
+		login              string
+		requestData        domain2.PasswordURLsData
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:  "Valid URLs",
+			login: "Egor",
+			requestData: domain2.PasswordURLsData{
+				PassName: "Email",
+				URLs:     []domain2.LoginURL{{URL: "mail.example.com", Match: domain2.MatchHost}},
+			},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PasswordURLsData) {
+				s.EXPECT().SetPasswordURLs(login, requestData.PassName, requestData.URLs).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:  "Invalid Rule",
+			login: "Egor",
+			requestData: domain2.PasswordURLsData{
+				PassName: "Email",
+				URLs:     []domain2.LoginURL{{URL: "(", Match: domain2.MatchRegex}},
+			},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PasswordURLsData) {
+				s.EXPECT().SetPasswordURLs(login, requestData.PassName, requestData.URLs).Return(domain2.InvalidField("urls", "invalid regex"))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/password-urls", func(w http.ResponseWriter, r *http.Request) {
+						handlers.SetPasswordURLsHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/password-urls", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+			},
+		)
+	}
+}
+
+func TestHandler_MatchPasswordsHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login, url string)
+
+	testCases := []struct {
+		name                 string
+		login                string
+		url                  string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "Matched",
+			login: "Egor",
+			url:   "https://accounts.example.com/login",
+			mockBehavior: func(s *mock_service.MockServices, login, url string) {
+				s.EXPECT().MatchPasswords(login, url).Return(
+					[]domain2.LoginMatch{
+						{PassName: "Example", Rule: domain2.LoginURL{URL: "example.com", Match: domain2.MatchDomain}},
+					}, nil,
+				)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `[{"passName":"Example","rule":{"url":"example.com","match":"domain"}}]`,
+		},
+		{
+			name:  "Invalid URL",
+			login: "Egor",
+			url:   "https://",
+			mockBehavior: func(s *mock_service.MockServices, login, url string) {
+				s.EXPECT().MatchPasswords(login, url).Return(nil, domain2.InvalidField("url", "url has no host"))
+			},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"code":"validation","message":"Ошибка при подборе паролей для сайта","details":{"field":"url","reason":"url has no host"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.url)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/password-match", func(w http.ResponseWriter, r *http.Request) {
+						handlers.MatchPasswordsHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(domain2.MatchData{URL: tc.url})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/password-match", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+			},
+		)
+	}
+}
+
+func TestHandler_GetPasswordEntryHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login, passName string)
+
+	testCases := []struct {
+		name                 string
+		login                string
+		passName             string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:     "With OTP",
+			login:    "Egor",
+			passName: "Email",
+			mockBehavior: func(s *mock_service.MockServices, login, passName string) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().GetPasswordEntry(login, passName).Return(
+					&domain2.PasswordEntry{PassName: passName, Password: "c2VjcmV0", OTP: "b3Rw"}, nil,
+				)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"passName":"Email","password":"c2VjcmV0","otp":"b3Rw"}`,
+		},
+		{
+			name:     "Not Found",
+			login:    "Egor",
+			passName: "Unknown",
+			mockBehavior: func(s *mock_service.MockServices, login, passName string) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().GetPasswordEntry(login, passName).Return(nil, domain2.NotFound("password not found"))
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"code":"not_found","message":"Ошибка при получении пароля","details":{"reason":"password not found"}}`,
+		},
+		{
+			name:     "Unlocked By Another User",
+			login:    "Egor",
+			passName: "Email",
+			mockBehavior: func(s *mock_service.MockServices, login, passName string) {
+				s.EXPECT().ParseUnlockToken("u").Return("Ivan", nil)
+			},
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"code":"forbidden","message":"Требуется ввести пин-код","details":{"reason":"invalid unlock token"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.passName)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/password-entry", func(w http.ResponseWriter, r *http.Request) {
+						handlers.GetPasswordEntryHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(domain2.PassData{PassName: tc.passName})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withUnlock(withLogin(httptest.NewRequest("POST", "/password-entry", bytes.NewBuffer(requestBody)), tc.login), "u")
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+			},
+		)
+	}
+}
+
+func TestHandler_SetPasswordOTPHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.PasswordOTPData)
+
+	testCases := []struct {
+		name               string
+		login              string
+		requestData        domain2.PasswordOTPData
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:        "Attach",
+			login:       "Egor",
+			requestData: domain2.PasswordOTPData{PassName: "Email", OTP: "b3Rw"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PasswordOTPData) {
+				s.EXPECT().SetPasswordOTP(login, requestData.PassName, requestData.OTP).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:        "Not Found",
+			login:       "Egor",
+			requestData: domain2.PasswordOTPData{PassName: "Unknown"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.PasswordOTPData) {
+				s.EXPECT().SetPasswordOTP(login, requestData.PassName, requestData.OTP).Return(domain2.NotFound("password not found"))
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/password-otp", func(w http.ResponseWriter, r *http.Request) {
+						handlers.SetPasswordOTPHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/password-otp", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+			},
+		)
+	}
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	domain2 "github.com/egosha7/goph-keeper/internal/domain"
+	"net/http"
+)
+
+// GetTemplatesHandler обрабатывает запрос на получение встроенных и пользовательских шаблонов записей.
+func (h *Handler) GetTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	templates, err := h.Services.GetTemplates(login)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении списка шаблонов")
+		return
+	}
+
+	h.writeJSON(w, templates)
+}
+
+// AddTemplateHandler обрабатывает запрос на добавление пользовательского шаблона записей.
+func (h *Handler) AddTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.Template
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.AddTemplate(login, requestData); err != nil {
+		h.writeError(w, err, "Ошибка при добавлении шаблона")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// DeleteTemplateHandler обрабатывает запрос на удаление пользовательского шаблона записей.
+func (h *Handler) DeleteTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.TemplateData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.DeleteTemplate(login, requestData.Name); err != nil {
+		h.writeError(w, err, "Ошибка при удалении шаблона")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// AddRecordHandler обрабатывает запрос на добавление новой записи.
+func (h *Handler) AddRecordHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.Record
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.AddRecord(login, requestData); err != nil {
+		h.writeError(w, err, "Ошибка при добавлении новой записи")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetRecordHandler обрабатывает запрос на получение записи.
+func (h *Handler) GetRecordHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok || !h.requireUnlock(w, r, login) {
+		return
+	}
+
+	var requestData domain2.RecordData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	record, err := h.Services.GetRecord(login, requestData.Name)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении записи")
+		return
+	}
+
+	h.writeJSON(w, record)
+}
+
+// GetRecordList обрабатывает запрос на получение списка записей, при необходимости только указанного типа.
+func (h *Handler) GetRecordList(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.RecordListData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	records, err := h.Services.GetRecordList(login, requestData.Type)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении списка записей")
+		return
+	}
+
+	h.writeJSON(w, records)
+}
+
+// UpdateRecordHandler обрабатывает запрос на изменение или переименование записи.
+func (h *Handler) UpdateRecordHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.UpdateRecordData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.UpdateRecord(login, requestData.Name, requestData.NewName, requestData.Fields, requestData.Metadata); err != nil {
+		h.writeError(w, err, "Ошибка при изменении записи")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// DeleteRecordHandler обрабатывает запрос на удаление записи.
+func (h *Handler) DeleteRecordHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.RecordData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.DeleteRecord(login, requestData.Name); err != nil {
+		h.writeError(w, err, "Ошибка при удалении записи")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetRecordHistoryHandler обрабатывает запрос на получение списка ревизий записи.
+func (h *Handler) GetRecordHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.RecordData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	revisions, err := h.Services.GetRecordHistory(login, requestData.Name)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении истории записи")
+		return
+	}
+
+	h.writeJSON(w, revisions)
+}
+
+// GetRecordRevisionHandler обрабатывает запрос на получение записи в указанной ревизии.
+func (h *Handler) GetRecordRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok || !h.requireUnlock(w, r, login) {
+		return
+	}
+
+	var requestData domain2.RecordRevisionData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	revision, err := h.Services.GetRecordRevision(login, requestData.Name, requestData.Revision)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении ревизии записи")
+		return
+	}
+
+	h.writeJSON(w, revision)
+}
+
+// RestoreRecordHandler обрабатывает запрос на восстановление записи из ревизии.
+func (h *Handler) RestoreRecordHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.RecordRevisionData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.RestoreRecord(login, requestData.Name, requestData.Revision); err != nil {
+		h.writeError(w, err, "Ошибка при восстановлении записи")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetRecordTrashHandler обрабатывает запрос на получение записей из корзины.
+func (h *Handler) GetRecordTrashHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.Services.GetRecordTrash(login)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при получении корзины записей")
+		return
+	}
+
+	h.writeJSON(w, items)
+}
+
+// RestoreRecordFromTrashHandler обрабатывает запрос на восстановление записи из корзины.
+func (h *Handler) RestoreRecordFromTrashHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.TrashData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.RestoreRecordFromTrash(login, requestData.ID); err != nil {
+		h.writeError(w, err, "Ошибка при восстановлении записи из корзины")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
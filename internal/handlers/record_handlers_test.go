@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	domain2 "github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/service"
+	mock_service "github.com/egosha7/goph-keeper/internal/service/mocks"
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_AddRecordHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.Record)
+
+	record := domain2.Record{
+		Type: "ssh-key",
+		Name: "Deploy key",
+		Fields: []domain2.Field{
+			{Name: "private-key", Type: domain2.FieldHidden, Value: "c2VjcmV0"},
+		},
+		Metadata: map[string]string{"host": "ci"},
+	}
+
+	testCases := []struct {
+		name                 string
+		login                string
+		requestData          domain2.Record
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:        "Valid Record",
+			login:       "Egor",
+			requestData: record,
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.Record) {
+				s.EXPECT().AddRecord(login, requestData).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:        "Unknown Type",
+			login:       "Egor",
+			requestData: domain2.Record{Type: "unknown", Name: "Deploy key"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.Record) {
+				s.EXPECT().AddRecord(login, requestData).Return(domain2.InvalidField("type", `unknown record type "unknown"`))
+			},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"code":"validation","message":"Ошибка при добавлении новой записи","details":{"field":"type","reason":"unknown record type \"unknown\""}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/add-record", func(w http.ResponseWriter, r *http.Request) {
+						handlers.AddRecordHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/add-record", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+			},
+		)
+	}
+}
+
+func TestHandler_GetRecordHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.RecordData)
+
+	testCases := []struct {
+		name                 string
+		login                string
+		requestData          domain2.RecordData
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:        "Valid Record",
+			login:       "Egor",
+			requestData: domain2.RecordData{Name: "Deploy key"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.RecordData) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().GetRecord(login, requestData.Name).Return(
+					&domain2.Record{
+						Type:   "ssh-key",
+						Name:   requestData.Name,
+						Fields: []domain2.Field{{Name: "private-key", Type: domain2.FieldHidden, Value: "c2VjcmV0"}},
+					}, nil,
+				)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{"type":"ssh-key","name":"Deploy key",` +
+				`"fields":[{"name":"private-key","type":"hidden","value":"c2VjcmV0"}]}`,
+		},
+		{
+			name:        "Not Found",
+			login:       "Egor",
+			requestData: domain2.RecordData{Name: "Unknown"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.RecordData) {
+				s.EXPECT().ParseUnlockToken("u").Return(login, nil)
+				s.EXPECT().GetRecord(login, requestData.Name).Return(nil, domain2.NotFound("record not found"))
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"code":"not_found","message":"Ошибка при получении записи","details":{"reason":"record not found"}}`,
+		},
+		{
+			name:        "Not Unlocked",
+			login:       "Egor",
+			requestData: domain2.RecordData{Name: "Deploy key"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.RecordData) {
+				s.EXPECT().ParseUnlockToken("u").Return("Anna", nil)
+			},
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"code":"forbidden","message":"Требуется ввести пин-код","details":{"reason":"invalid unlock token"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/get-record", func(w http.ResponseWriter, r *http.Request) {
+						handlers.GetRecordHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withUnlock(withLogin(httptest.NewRequest("POST", "/get-record", bytes.NewBuffer(requestBody)), tc.login), "u")
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+			},
+		)
+	}
+}
+
+func TestHandler_UpdateRecordHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, requestData domain2.UpdateRecordData)
+
+	testCases := []struct {
+		name               string
+		login              string
+		requestData        domain2.UpdateRecordData
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:  "Renamed",
+			login: "Egor",
+			requestData: domain2.UpdateRecordData{
+				Name:     "Key",
+				NewName:  "Deploy key",
+				Fields:   []domain2.Field{{Name: "private-key", Type: domain2.FieldHidden, Value: "c2VjcmV0"}},
+				Metadata: map[string]string{"host": "ci"},
+			},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.UpdateRecordData) {
+				s.EXPECT().UpdateRecord(
+					login, requestData.Name, requestData.NewName, requestData.Fields, requestData.Metadata,
+				).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:        "Missing Required Field",
+			login:       "Egor",
+			requestData: domain2.UpdateRecordData{Name: "Deploy key"},
+			mockBehavior: func(s *mock_service.MockServices, login string, requestData domain2.UpdateRecordData) {
+				s.EXPECT().UpdateRecord(
+					login, requestData.Name, requestData.NewName, requestData.Fields, requestData.Metadata,
+				).Return(errors.New(`field "private-key" is required`))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.requestData)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/update-record", func(w http.ResponseWriter, r *http.Request) {
+						handlers.UpdateRecordHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.requestData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/update-record", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+			},
+		)
+	}
+}
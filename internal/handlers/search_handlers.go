@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	domain2 "github.com/egosha7/goph-keeper/internal/domain"
+	"net/http"
+)
+
+// SearchHandler обрабатывает запрос на поиск по элементам хранилища.
+func (h *Handler) SearchHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.SearchQuery
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	result, err := h.Services.SearchItems(login, requestData)
+	if err != nil {
+		h.writeError(w, err, "Ошибка при поиске")
+		return
+	}
+
+	h.writeJSON(w, result)
+}
+
+// SetBlindIndexHandler обрабатывает запрос на замену термов слепого индекса элемента.
+func (h *Handler) SetBlindIndexHandler(w http.ResponseWriter, r *http.Request) {
+	login, ok := h.currentLogin(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData domain2.BlindIndexData
+	if !h.decodeJSON(w, r, &requestData) {
+		return
+	}
+
+	if err := h.Services.SetBlindIndex(login, requestData); err != nil {
+		h.writeError(w, err, "Ошибка при обновлении поискового индекса")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	domain2 "github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/service"
+	mock_service "github.com/egosha7/goph-keeper/internal/service/mocks"
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_SearchHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, query domain2.SearchQuery)
+
+	updated := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name                 string
+		login                string
+		query                domain2.SearchQuery
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "Found",
+			login: "Egor",
+			query: domain2.SearchQuery{Query: "mail", Kinds: []string{domain2.KindPassword}, Limit: 1},
+			mockBehavior: func(s *mock_service.MockServices, login string, query domain2.SearchQuery) {
+				s.EXPECT().SearchItems(login, query).Return(
+					&domain2.SearchResult{
+						Items: []domain2.SearchItem{
+							{Kind: "password", Type: "password", Name: "Gmail", Tags: []string{"work"}, Folder: "Работа", UpdatedAt: updated},
+						},
+						NextCursor: "abc",
+					}, nil,
+				)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"items":[{"kind":"password","type":"password","name":"Gmail","tags":["work"],"folder":"Работа","updatedAt":"2023-10-01T12:00:00Z"}],"nextCursor":"abc"}`,
+		},
+		{
+			name:  "Invalid Query",
+			login: "Egor",
+			query: domain2.SearchQuery{Sort: "size"},
+			mockBehavior: func(s *mock_service.MockServices, login string, query domain2.SearchQuery) {
+				s.EXPECT().SearchItems(login, query).Return(nil, domain2.InvalidField("sort", `unknown sort order "size"`))
+			},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"code":"validation","message":"Ошибка при поиске","details":{"field":"sort","reason":"unknown sort order \"size\""}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.query)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/search", func(w http.ResponseWriter, r *http.Request) {
+						handlers.SearchHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.query)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/search", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+				assert.Equal(t, tc.expectedResponseBody, strings.TrimSpace(w.Body.String()))
+			},
+		)
+	}
+}
+
+func TestHandler_SetBlindIndexHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockServices, login string, index domain2.BlindIndexData)
+
+	term := strings.Repeat("a", 32)
+
+	testCases := []struct {
+		name               string
+		login              string
+		index              domain2.BlindIndexData
+		mockBehavior       mockBehavior
+		expectedStatusCode int
+	}{
+		{
+			name:  "Valid Index",
+			login: "Egor",
+			index: domain2.BlindIndexData{Kind: domain2.KindPassword, Name: "Gmail", Terms: []string{term}},
+			mockBehavior: func(s *mock_service.MockServices, login string, index domain2.BlindIndexData) {
+				s.EXPECT().SetBlindIndex(login, index).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:  "Not Found",
+			login: "Egor",
+			index: domain2.BlindIndexData{Kind: domain2.KindNote, Name: "Unknown", Terms: []string{term}},
+			mockBehavior: func(s *mock_service.MockServices, login string, index domain2.BlindIndexData) {
+				s.EXPECT().SetBlindIndex(login, index).Return(domain2.NotFound("note not found"))
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				auth := mock_service.NewMockServices(ctrl)
+				tc.mockBehavior(auth, tc.login, tc.index)
+				logger := zap.NewExample()
+				userService := &service.Service{Services: auth}
+
+				handlers := NewHandler(userService, logger)
+
+				r := chi.NewRouter()
+				r.Post(
+					"/index/set", func(w http.ResponseWriter, r *http.Request) {
+						handlers.SetBlindIndexHandler(w, r)
+					},
+				)
+
+				requestBody, err := json.Marshal(tc.index)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w := httptest.NewRecorder()
+				req := withLogin(httptest.NewRequest("POST", "/index/set", bytes.NewBuffer(requestBody)), tc.login)
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tc.expectedStatusCode, w.Code)
+			},
+		)
+	}
+}
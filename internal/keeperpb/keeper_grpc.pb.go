@@ -0,0 +1,976 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.1
+// source: keeper.proto
+
+// API хранилища GophKeeper для клиентов gRPC.
+// Секреты шифруются клиентом: сервер принимает и возвращает только шифротекст в base64.
+//
+// Токен доступа передается в метаданных authorization в виде "Bearer <токен>",
+// токен разблокировки после проверки пин-кода - в метаданных x-unlock-token.
+//
+// Код на Go генерируется командой из корня репозитория:
+//
+//	protoc -I api/proto --go_out=. --go_opt=module=github.com/egosha7/goph-keeper \
+//	  --go-grpc_out=. --go-grpc_opt=module=github.com/egosha7/goph-keeper keeper.proto
+
+package keeperpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Keeper_Register_FullMethodName       = "/keeper.v1.Keeper/Register"
+	Keeper_Login_FullMethodName          = "/keeper.v1.Keeper/Login"
+	Keeper_CompleteLogin_FullMethodName  = "/keeper.v1.Keeper/CompleteLogin"
+	Keeper_RefreshTokens_FullMethodName  = "/keeper.v1.Keeper/RefreshTokens"
+	Keeper_GetSalt_FullMethodName        = "/keeper.v1.Keeper/GetSalt"
+	Keeper_CheckPin_FullMethodName       = "/keeper.v1.Keeper/CheckPin"
+	Keeper_AddPassword_FullMethodName    = "/keeper.v1.Keeper/AddPassword"
+	Keeper_GetPassword_FullMethodName    = "/keeper.v1.Keeper/GetPassword"
+	Keeper_ListPasswords_FullMethodName  = "/keeper.v1.Keeper/ListPasswords"
+	Keeper_UpdatePassword_FullMethodName = "/keeper.v1.Keeper/UpdatePassword"
+	Keeper_DeletePassword_FullMethodName = "/keeper.v1.Keeper/DeletePassword"
+	Keeper_AddCard_FullMethodName        = "/keeper.v1.Keeper/AddCard"
+	Keeper_GetCard_FullMethodName        = "/keeper.v1.Keeper/GetCard"
+	Keeper_ListCards_FullMethodName      = "/keeper.v1.Keeper/ListCards"
+	Keeper_UpdateCard_FullMethodName     = "/keeper.v1.Keeper/UpdateCard"
+	Keeper_DeleteCard_FullMethodName     = "/keeper.v1.Keeper/DeleteCard"
+	Keeper_Search_FullMethodName         = "/keeper.v1.Keeper/Search"
+	Keeper_AddRecord_FullMethodName      = "/keeper.v1.Keeper/AddRecord"
+	Keeper_GetRecord_FullMethodName      = "/keeper.v1.Keeper/GetRecord"
+	Keeper_UpdateRecord_FullMethodName   = "/keeper.v1.Keeper/UpdateRecord"
+	Keeper_DeleteRecord_FullMethodName   = "/keeper.v1.Keeper/DeleteRecord"
+	Keeper_WatchChanges_FullMethodName   = "/keeper.v1.Keeper/WatchChanges"
+)
+
+// KeeperClient is the client API for Keeper service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type KeeperClient interface {
+	// Register регистрирует пользователя и выдает пару токенов.
+	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	// Login проверяет логин и пароль. С подключенным вторым фактором вместо токенов
+	// возвращается токен незавершенного входа.
+	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	// CompleteLogin завершает вход кодом второго фактора.
+	CompleteLogin(ctx context.Context, in *CompleteLoginRequest, opts ...grpc.CallOption) (*Tokens, error)
+	// RefreshTokens выпускает новую пару токенов по токену обновления.
+	RefreshTokens(ctx context.Context, in *RefreshTokensRequest, opts ...grpc.CallOption) (*Tokens, error)
+	// GetSalt возвращает соль мастер-ключа текущего пользователя.
+	GetSalt(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SaltResponse, error)
+	// CheckPin проверяет пин-код и выдает разрешение на чтение секретов.
+	CheckPin(ctx context.Context, in *CheckPinRequest, opts ...grpc.CallOption) (*UnlockGrant, error)
+	// AddPassword добавляет пароль.
+	AddPassword(ctx context.Context, in *Password, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// GetPassword возвращает пароль вместе с ключом одноразовых паролей. Требует разблокировки.
+	GetPassword(ctx context.Context, in *ItemRequest, opts ...grpc.CallOption) (*Password, error)
+	// ListPasswords возвращает названия паролей.
+	ListPasswords(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*NameList, error)
+	// UpdatePassword изменяет или переименовывает пароль.
+	UpdatePassword(ctx context.Context, in *UpdatePasswordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// DeletePassword перемещает пароль в корзину.
+	DeletePassword(ctx context.Context, in *ItemRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// AddCard добавляет карту.
+	AddCard(ctx context.Context, in *Card, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// GetCard возвращает реквизиты карты. Требует разблокировки.
+	GetCard(ctx context.Context, in *ItemRequest, opts ...grpc.CallOption) (*Card, error)
+	// ListCards возвращает названия карт.
+	ListCards(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*NameList, error)
+	// UpdateCard изменяет или переименовывает карту.
+	UpdateCard(ctx context.Context, in *UpdateCardRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// DeleteCard перемещает карту в корзину.
+	DeleteCard(ctx context.Context, in *ItemRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Search ищет элементы хранилища любого вида.
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// Записи произвольного типа: новые виды секретов добавляются шаблонами, без новых методов.
+	// AddRecord добавляет запись.
+	AddRecord(ctx context.Context, in *Record, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// GetRecord возвращает запись.
+	GetRecord(ctx context.Context, in *ItemRequest, opts ...grpc.CallOption) (*Record, error)
+	// UpdateRecord изменяет или переименовывает запись.
+	UpdateRecord(ctx context.Context, in *UpdateRecordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// DeleteRecord перемещает запись в корзину.
+	DeleteRecord(ctx context.Context, in *ItemRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// WatchChanges передает изменения хранилища текущего пользователя по мере их появления.
+	// После обрыва соединения вызов повторяется с курсором последнего полученного события.
+	WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (Keeper_WatchChangesClient, error)
+}
+
+type keeperClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKeeperClient(cc grpc.ClientConnInterface) KeeperClient {
+	return &keeperClient{cc}
+}
+
+func (c *keeperClient) Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, Keeper_Register_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, Keeper_Login_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) CompleteLogin(ctx context.Context, in *CompleteLoginRequest, opts ...grpc.CallOption) (*Tokens, error) {
+	out := new(Tokens)
+	err := c.cc.Invoke(ctx, Keeper_CompleteLogin_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) RefreshTokens(ctx context.Context, in *RefreshTokensRequest, opts ...grpc.CallOption) (*Tokens, error) {
+	out := new(Tokens)
+	err := c.cc.Invoke(ctx, Keeper_RefreshTokens_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) GetSalt(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SaltResponse, error) {
+	out := new(SaltResponse)
+	err := c.cc.Invoke(ctx, Keeper_GetSalt_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) CheckPin(ctx context.Context, in *CheckPinRequest, opts ...grpc.CallOption) (*UnlockGrant, error) {
+	out := new(UnlockGrant)
+	err := c.cc.Invoke(ctx, Keeper_CheckPin_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) AddPassword(ctx context.Context, in *Password, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Keeper_AddPassword_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) GetPassword(ctx context.Context, in *ItemRequest, opts ...grpc.CallOption) (*Password, error) {
+	out := new(Password)
+	err := c.cc.Invoke(ctx, Keeper_GetPassword_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) ListPasswords(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*NameList, error) {
+	out := new(NameList)
+	err := c.cc.Invoke(ctx, Keeper_ListPasswords_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) UpdatePassword(ctx context.Context, in *UpdatePasswordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Keeper_UpdatePassword_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) DeletePassword(ctx context.Context, in *ItemRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Keeper_DeletePassword_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) AddCard(ctx context.Context, in *Card, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Keeper_AddCard_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) GetCard(ctx context.Context, in *ItemRequest, opts ...grpc.CallOption) (*Card, error) {
+	out := new(Card)
+	err := c.cc.Invoke(ctx, Keeper_GetCard_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) ListCards(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*NameList, error) {
+	out := new(NameList)
+	err := c.cc.Invoke(ctx, Keeper_ListCards_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) UpdateCard(ctx context.Context, in *UpdateCardRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Keeper_UpdateCard_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) DeleteCard(ctx context.Context, in *ItemRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Keeper_DeleteCard_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, Keeper_Search_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) AddRecord(ctx context.Context, in *Record, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Keeper_AddRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) GetRecord(ctx context.Context, in *ItemRequest, opts ...grpc.CallOption) (*Record, error) {
+	out := new(Record)
+	err := c.cc.Invoke(ctx, Keeper_GetRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) UpdateRecord(ctx context.Context, in *UpdateRecordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Keeper_UpdateRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) DeleteRecord(ctx context.Context, in *ItemRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Keeper_DeleteRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperClient) WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (Keeper_WatchChangesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Keeper_ServiceDesc.Streams[0], Keeper_WatchChanges_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &keeperWatchChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Keeper_WatchChangesClient interface {
+	Recv() (*ChangeEvent, error)
+	grpc.ClientStream
+}
+
+type keeperWatchChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *keeperWatchChangesClient) Recv() (*ChangeEvent, error) {
+	m := new(ChangeEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// KeeperServer is the server API for Keeper service.
+// All implementations must embed UnimplementedKeeperServer
+// for forward compatibility
+type KeeperServer interface {
+	// Register регистрирует пользователя и выдает пару токенов.
+	Register(context.Context, *RegisterRequest) (*LoginResponse, error)
+	// Login проверяет логин и пароль. С подключенным вторым фактором вместо токенов
+	// возвращается токен незавершенного входа.
+	Login(context.Context, *LoginRequest) (*LoginResponse, error)
+	// CompleteLogin завершает вход кодом второго фактора.
+	CompleteLogin(context.Context, *CompleteLoginRequest) (*Tokens, error)
+	// RefreshTokens выпускает новую пару токенов по токену обновления.
+	RefreshTokens(context.Context, *RefreshTokensRequest) (*Tokens, error)
+	// GetSalt возвращает соль мастер-ключа текущего пользователя.
+	GetSalt(context.Context, *emptypb.Empty) (*SaltResponse, error)
+	// CheckPin проверяет пин-код и выдает разрешение на чтение секретов.
+	CheckPin(context.Context, *CheckPinRequest) (*UnlockGrant, error)
+	// AddPassword добавляет пароль.
+	AddPassword(context.Context, *Password) (*emptypb.Empty, error)
+	// GetPassword возвращает пароль вместе с ключом одноразовых паролей. Требует разблокировки.
+	GetPassword(context.Context, *ItemRequest) (*Password, error)
+	// ListPasswords возвращает названия паролей.
+	ListPasswords(context.Context, *emptypb.Empty) (*NameList, error)
+	// UpdatePassword изменяет или переименовывает пароль.
+	UpdatePassword(context.Context, *UpdatePasswordRequest) (*emptypb.Empty, error)
+	// DeletePassword перемещает пароль в корзину.
+	DeletePassword(context.Context, *ItemRequest) (*emptypb.Empty, error)
+	// AddCard добавляет карту.
+	AddCard(context.Context, *Card) (*emptypb.Empty, error)
+	// GetCard возвращает реквизиты карты. Требует разблокировки.
+	GetCard(context.Context, *ItemRequest) (*Card, error)
+	// ListCards возвращает названия карт.
+	ListCards(context.Context, *emptypb.Empty) (*NameList, error)
+	// UpdateCard изменяет или переименовывает карту.
+	UpdateCard(context.Context, *UpdateCardRequest) (*emptypb.Empty, error)
+	// DeleteCard перемещает карту в корзину.
+	DeleteCard(context.Context, *ItemRequest) (*emptypb.Empty, error)
+	// Search ищет элементы хранилища любого вида.
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// Записи произвольного типа: новые виды секретов добавляются шаблонами, без новых методов.
+	// AddRecord добавляет запись.
+	AddRecord(context.Context, *Record) (*emptypb.Empty, error)
+	// GetRecord возвращает запись.
+	GetRecord(context.Context, *ItemRequest) (*Record, error)
+	// UpdateRecord изменяет или переименовывает запись.
+	UpdateRecord(context.Context, *UpdateRecordRequest) (*emptypb.Empty, error)
+	// DeleteRecord перемещает запись в корзину.
+	DeleteRecord(context.Context, *ItemRequest) (*emptypb.Empty, error)
+	// WatchChanges передает изменения хранилища текущего пользователя по мере их появления.
+	// После обрыва соединения вызов повторяется с курсором последнего полученного события.
+	WatchChanges(*WatchChangesRequest, Keeper_WatchChangesServer) error
+	mustEmbedUnimplementedKeeperServer()
+}
+
+// UnimplementedKeeperServer must be embedded to have forward compatible implementations.
+type UnimplementedKeeperServer struct {
+}
+
+func (UnimplementedKeeperServer) Register(context.Context, *RegisterRequest) (*LoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (UnimplementedKeeperServer) Login(context.Context, *LoginRequest) (*LoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
+}
+func (UnimplementedKeeperServer) CompleteLogin(context.Context, *CompleteLoginRequest) (*Tokens, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteLogin not implemented")
+}
+func (UnimplementedKeeperServer) RefreshTokens(context.Context, *RefreshTokensRequest) (*Tokens, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshTokens not implemented")
+}
+func (UnimplementedKeeperServer) GetSalt(context.Context, *emptypb.Empty) (*SaltResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSalt not implemented")
+}
+func (UnimplementedKeeperServer) CheckPin(context.Context, *CheckPinRequest) (*UnlockGrant, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckPin not implemented")
+}
+func (UnimplementedKeeperServer) AddPassword(context.Context, *Password) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddPassword not implemented")
+}
+func (UnimplementedKeeperServer) GetPassword(context.Context, *ItemRequest) (*Password, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPassword not implemented")
+}
+func (UnimplementedKeeperServer) ListPasswords(context.Context, *emptypb.Empty) (*NameList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPasswords not implemented")
+}
+func (UnimplementedKeeperServer) UpdatePassword(context.Context, *UpdatePasswordRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePassword not implemented")
+}
+func (UnimplementedKeeperServer) DeletePassword(context.Context, *ItemRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePassword not implemented")
+}
+func (UnimplementedKeeperServer) AddCard(context.Context, *Card) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddCard not implemented")
+}
+func (UnimplementedKeeperServer) GetCard(context.Context, *ItemRequest) (*Card, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCard not implemented")
+}
+func (UnimplementedKeeperServer) ListCards(context.Context, *emptypb.Empty) (*NameList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCards not implemented")
+}
+func (UnimplementedKeeperServer) UpdateCard(context.Context, *UpdateCardRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCard not implemented")
+}
+func (UnimplementedKeeperServer) DeleteCard(context.Context, *ItemRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCard not implemented")
+}
+func (UnimplementedKeeperServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedKeeperServer) AddRecord(context.Context, *Record) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddRecord not implemented")
+}
+func (UnimplementedKeeperServer) GetRecord(context.Context, *ItemRequest) (*Record, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRecord not implemented")
+}
+func (UnimplementedKeeperServer) UpdateRecord(context.Context, *UpdateRecordRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRecord not implemented")
+}
+func (UnimplementedKeeperServer) DeleteRecord(context.Context, *ItemRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRecord not implemented")
+}
+func (UnimplementedKeeperServer) WatchChanges(*WatchChangesRequest, Keeper_WatchChangesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchChanges not implemented")
+}
+func (UnimplementedKeeperServer) mustEmbedUnimplementedKeeperServer() {}
+
+// UnsafeKeeperServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to KeeperServer will
+// result in compilation errors.
+type UnsafeKeeperServer interface {
+	mustEmbedUnimplementedKeeperServer()
+}
+
+func RegisterKeeperServer(s grpc.ServiceRegistrar, srv KeeperServer) {
+	s.RegisterService(&Keeper_ServiceDesc, srv)
+}
+
+func _Keeper_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_Register_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).Register(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_Login_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).Login(ctx, req.(*LoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_CompleteLogin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteLoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).CompleteLogin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_CompleteLogin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).CompleteLogin(ctx, req.(*CompleteLoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_RefreshTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).RefreshTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_RefreshTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).RefreshTokens(ctx, req.(*RefreshTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_GetSalt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).GetSalt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_GetSalt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).GetSalt(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_CheckPin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckPinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).CheckPin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_CheckPin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).CheckPin(ctx, req.(*CheckPinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_AddPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Password)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).AddPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_AddPassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).AddPassword(ctx, req.(*Password))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_GetPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).GetPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_GetPassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).GetPassword(ctx, req.(*ItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_ListPasswords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).ListPasswords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_ListPasswords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).ListPasswords(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_UpdatePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).UpdatePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_UpdatePassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).UpdatePassword(ctx, req.(*UpdatePasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_DeletePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).DeletePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_DeletePassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).DeletePassword(ctx, req.(*ItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_AddCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Card)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).AddCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_AddCard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).AddCard(ctx, req.(*Card))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_GetCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).GetCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_GetCard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).GetCard(ctx, req.(*ItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_ListCards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).ListCards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_ListCards_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).ListCards(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_UpdateCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).UpdateCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_UpdateCard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).UpdateCard(ctx, req.(*UpdateCardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_DeleteCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).DeleteCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_DeleteCard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).DeleteCard(ctx, req.(*ItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_AddRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Record)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).AddRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_AddRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).AddRecord(ctx, req.(*Record))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_GetRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).GetRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_GetRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).GetRecord(ctx, req.(*ItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_UpdateRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).UpdateRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_UpdateRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).UpdateRecord(ctx, req.(*UpdateRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_DeleteRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServer).DeleteRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keeper_DeleteRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServer).DeleteRecord(ctx, req.(*ItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keeper_WatchChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KeeperServer).WatchChanges(m, &keeperWatchChangesServer{stream})
+}
+
+type Keeper_WatchChangesServer interface {
+	Send(*ChangeEvent) error
+	grpc.ServerStream
+}
+
+type keeperWatchChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *keeperWatchChangesServer) Send(m *ChangeEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Keeper_ServiceDesc is the grpc.ServiceDesc for Keeper service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Keeper_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keeper.v1.Keeper",
+	HandlerType: (*KeeperServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Register",
+			Handler:    _Keeper_Register_Handler,
+		},
+		{
+			MethodName: "Login",
+			Handler:    _Keeper_Login_Handler,
+		},
+		{
+			MethodName: "CompleteLogin",
+			Handler:    _Keeper_CompleteLogin_Handler,
+		},
+		{
+			MethodName: "RefreshTokens",
+			Handler:    _Keeper_RefreshTokens_Handler,
+		},
+		{
+			MethodName: "GetSalt",
+			Handler:    _Keeper_GetSalt_Handler,
+		},
+		{
+			MethodName: "CheckPin",
+			Handler:    _Keeper_CheckPin_Handler,
+		},
+		{
+			MethodName: "AddPassword",
+			Handler:    _Keeper_AddPassword_Handler,
+		},
+		{
+			MethodName: "GetPassword",
+			Handler:    _Keeper_GetPassword_Handler,
+		},
+		{
+			MethodName: "ListPasswords",
+			Handler:    _Keeper_ListPasswords_Handler,
+		},
+		{
+			MethodName: "UpdatePassword",
+			Handler:    _Keeper_UpdatePassword_Handler,
+		},
+		{
+			MethodName: "DeletePassword",
+			Handler:    _Keeper_DeletePassword_Handler,
+		},
+		{
+			MethodName: "AddCard",
+			Handler:    _Keeper_AddCard_Handler,
+		},
+		{
+			MethodName: "GetCard",
+			Handler:    _Keeper_GetCard_Handler,
+		},
+		{
+			MethodName: "ListCards",
+			Handler:    _Keeper_ListCards_Handler,
+		},
+		{
+			MethodName: "UpdateCard",
+			Handler:    _Keeper_UpdateCard_Handler,
+		},
+		{
+			MethodName: "DeleteCard",
+			Handler:    _Keeper_DeleteCard_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _Keeper_Search_Handler,
+		},
+		{
+			MethodName: "AddRecord",
+			Handler:    _Keeper_AddRecord_Handler,
+		},
+		{
+			MethodName: "GetRecord",
+			Handler:    _Keeper_GetRecord_Handler,
+		},
+		{
+			MethodName: "UpdateRecord",
+			Handler:    _Keeper_UpdateRecord_Handler,
+		},
+		{
+			MethodName: "DeleteRecord",
+			Handler:    _Keeper_DeleteRecord_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchChanges",
+			Handler:       _Keeper_WatchChanges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "keeper.proto",
+}
@@ -0,0 +1,61 @@
+// Package keys управляет ключами шифрования ключей (KEK), которыми сервер
+// оборачивает ключи данных пользователей (DEK).
+package keys
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/egosha7/goph-keeper/internal/crypt"
+)
+
+// ErrUnknownVersion возвращается, если у поставщика нет KEK запрошенной версии.
+var ErrUnknownVersion = errors.New("unknown key version")
+
+// KeyProvider оборачивает и разворачивает ключи данных ключом шифрования ключей.
+// Материал KEK не покидает поставщика, что позволяет подключить внешний KMS.
+type KeyProvider interface {
+	// CurrentVersion возвращает версию KEK, которой оборачиваются новые ключи.
+	CurrentVersion(ctx context.Context) (int, error)
+	// WrapKey оборачивает ключ данных текущей версией KEK и возвращает ее номер.
+	WrapKey(ctx context.Context, dek []byte) ([]byte, int, error)
+	// UnwrapKey разворачивает ключ данных KEK указанной версии.
+	UnwrapKey(ctx context.Context, wrapped []byte, version int) ([]byte, error)
+}
+
+// Rotator - поставщик, способный сам создать новую версию KEK.
+type Rotator interface {
+	// Rotate создает новую версию KEK, делает ее текущей и возвращает ее номер.
+	Rotate(ctx context.Context) (int, error)
+}
+
+// NewDataKey генерирует новый ключ данных.
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, crypt.KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// wrap оборачивает ключ данных KEK, привязывая результат к версии KEK.
+func wrap(kek []byte, version int, dek []byte) ([]byte, error) {
+	return crypt.Encrypt(crypt.AES256GCM, kek, dek, wrapAssociatedData(version))
+}
+
+// unwrap разворачивает ключ данных, обернутый функцией wrap.
+func unwrap(kek []byte, version int, wrapped []byte) ([]byte, error) {
+	dek, err := crypt.Decrypt(kek, wrapped, wrapAssociatedData(version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return dek, nil
+}
+
+// wrapAssociatedData привязывает обернутый ключ к версии KEK.
+func wrapAssociatedData(version int) crypt.AssociatedData {
+	return crypt.AssociatedData{Owner: "kek", Record: strconv.Itoa(version)}
+}
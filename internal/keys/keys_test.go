@@ -0,0 +1,119 @@
+package keys
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeys(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(make([]byte, 32))
+
+	keys, err := ParseKeys("1:" + key + ";\n# комментарий\n2:" + key)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	_, err = ParseKeys("1:" + key + ";1:" + key)
+	assert.Error(t, err)
+	_, err = ParseKeys("abc")
+	assert.Error(t, err)
+	_, err = ParseKeys("0:" + key)
+	assert.Error(t, err)
+}
+
+func TestStaticProvider(t *testing.T) {
+	ctx := context.Background()
+	kek1, err := NewDataKey()
+	require.NoError(t, err)
+	kek2, err := NewDataKey()
+	require.NoError(t, err)
+
+	old, err := NewStaticProvider(map[int][]byte{1: kek1})
+	require.NoError(t, err)
+	provider, err := NewStaticProvider(map[int][]byte{1: kek1, 2: kek2})
+	require.NoError(t, err)
+
+	dek, err := NewDataKey()
+	require.NoError(t, err)
+
+	// Ключ, обернутый старой версией, разворачивается после добавления новой
+	wrapped, version, err := old.WrapKey(ctx, dek)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+
+	unwrapped, err := provider.UnwrapKey(ctx, wrapped, version)
+	require.NoError(t, err)
+	assert.Equal(t, dek, unwrapped)
+
+	_, version, err = provider.WrapKey(ctx, dek)
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+
+	// Обернутый ключ привязан к версии KEK
+	_, err = provider.UnwrapKey(ctx, wrapped, 2)
+	assert.Error(t, err)
+	_, err = provider.UnwrapKey(ctx, wrapped, 3)
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+
+	_, err = NewStaticProvider(map[int][]byte{1: []byte("short")})
+	assert.Error(t, err)
+}
+
+func TestLocalKMS(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	kms, err := NewLocalKMS(dir)
+	require.NoError(t, err)
+
+	dek, err := NewDataKey()
+	require.NoError(t, err)
+	wrapped, version, err := kms.WrapKey(ctx, dek)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+
+	newVersion, err := kms.Rotate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, newVersion)
+
+	// Связка ключей переживает перезапуск вместе со старыми версиями
+	reopened, err := NewLocalKMS(dir)
+	require.NoError(t, err)
+	current, err := reopened.CurrentVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, current)
+
+	unwrapped, err := reopened.UnwrapKey(ctx, wrapped, version)
+	require.NoError(t, err)
+	assert.Equal(t, dek, unwrapped)
+}
+
+func TestLocalKMS_SeesRotationFromAnotherProcess(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	server, err := NewLocalKMS(dir)
+	require.NoError(t, err)
+	rotator, err := NewLocalKMS(dir)
+	require.NoError(t, err)
+
+	_, err = rotator.Rotate(ctx)
+	require.NoError(t, err)
+
+	dek, err := NewDataKey()
+	require.NoError(t, err)
+	wrapped, version, err := rotator.WrapKey(ctx, dek)
+	require.NoError(t, err)
+
+	// Сервер разворачивает ключ новой версии и начинает оборачивать ею новые ключи
+	unwrapped, err := server.UnwrapKey(ctx, wrapped, version)
+	require.NoError(t, err)
+	assert.Equal(t, dek, unwrapped)
+
+	current, err := server.CurrentVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, version, current)
+}
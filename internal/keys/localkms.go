@@ -0,0 +1,193 @@
+package keys
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// keyringFile - имя файла связки ключей локального KMS.
+const keyringFile = "keyring.json"
+
+// keyring - содержимое файла связки ключей.
+type keyring struct {
+	Current int            `json:"current"` // Текущая версия KEK
+	Keys    map[int][]byte `json:"keys"`    // Ключи по версиям
+}
+
+// LocalKMS - локальная замена внешнего KMS.
+// Сам генерирует и хранит KEK в каталоге, наружу отдает только операции
+// оборачивания и разворачивания, как это делает внешний сервис.
+//
+// Связка ключей перечитывается с диска при ее изменении, поэтому версия,
+// созданная командой ротации, становится видна работающему серверу без перезапуска.
+type LocalKMS struct {
+	mu      sync.RWMutex
+	dir     string
+	ring    keyring
+	modTime time.Time
+}
+
+// NewLocalKMS открывает связку ключей в каталоге dir.
+// Если связки нет, создает ее с первой версией KEK.
+func NewLocalKMS(dir string) (*LocalKMS, error) {
+	kms := &LocalKMS{dir: dir}
+
+	data, err := os.ReadFile(filepath.Join(dir, keyringFile))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		kms.ring.Keys = make(map[int][]byte)
+		if _, err := kms.Rotate(context.Background()); err != nil {
+			return nil, err
+		}
+		return kms, nil
+	case err != nil:
+		return nil, err
+	}
+
+	if err := kms.load(data); err != nil {
+		return nil, err
+	}
+	return kms, nil
+}
+
+// Rotate создает новую версию KEK и делает ее текущей.
+// Предыдущие версии сохраняются для разворачивания ранее обернутых ключей.
+func (k *LocalKMS) Rotate(context.Context) (int, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	kek, err := NewDataKey()
+	if err != nil {
+		return 0, err
+	}
+
+	ring := keyring{Current: k.ring.Current + 1, Keys: make(map[int][]byte, len(k.ring.Keys)+1)}
+	for version, key := range k.ring.Keys {
+		ring.Keys[version] = key
+	}
+	ring.Keys[ring.Current] = kek
+
+	if err := k.save(ring); err != nil {
+		return 0, err
+	}
+	k.ring = ring
+	k.modTime = k.fileModTime()
+	return ring.Current, nil
+}
+
+// CurrentVersion возвращает текущую версию KEK.
+func (k *LocalKMS) CurrentVersion(context.Context) (int, error) {
+	if err := k.refresh(); err != nil {
+		return 0, err
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.ring.Current, nil
+}
+
+// WrapKey оборачивает ключ данных текущей версией KEK.
+func (k *LocalKMS) WrapKey(_ context.Context, dek []byte) ([]byte, int, error) {
+	if err := k.refresh(); err != nil {
+		return nil, 0, err
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	wrapped, err := wrap(k.ring.Keys[k.ring.Current], k.ring.Current, dek)
+	if err != nil {
+		return nil, 0, err
+	}
+	return wrapped, k.ring.Current, nil
+}
+
+// UnwrapKey разворачивает ключ данных KEK указанной версии.
+func (k *LocalKMS) UnwrapKey(_ context.Context, wrapped []byte, version int) ([]byte, error) {
+	kek, ok := k.key(version)
+	if !ok {
+		// Версия могла появиться после ротации в другом процессе
+		if err := k.refresh(); err != nil {
+			return nil, err
+		}
+		if kek, ok = k.key(version); !ok {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+		}
+	}
+	return unwrap(kek, version, wrapped)
+}
+
+// key возвращает KEK указанной версии.
+func (k *LocalKMS) key(version int) ([]byte, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	kek, ok := k.ring.Keys[version]
+	return kek, ok
+}
+
+// refresh перечитывает связку ключей, если файл изменился с момента последнего чтения.
+func (k *LocalKMS) refresh() error {
+	modTime := k.fileModTime()
+
+	k.mu.RLock()
+	unchanged := modTime.Equal(k.modTime)
+	k.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(k.dir, keyringFile))
+	if err != nil {
+		return err
+	}
+	return k.load(data)
+}
+
+// load разбирает связку ключей и делает ее текущей.
+func (k *LocalKMS) load(data []byte) error {
+	var ring keyring
+	if err := json.Unmarshal(data, &ring); err != nil {
+		return fmt.Errorf("failed to parse keyring: %w", err)
+	}
+	if _, ok := ring.Keys[ring.Current]; !ok {
+		return fmt.Errorf("keyring has no current key version %d", ring.Current)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.ring = ring
+	k.modTime = k.fileModTime()
+	return nil
+}
+
+// fileModTime возвращает время изменения файла связки ключей.
+func (k *LocalKMS) fileModTime() time.Time {
+	info, err := os.Stat(filepath.Join(k.dir, keyringFile))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// save атомарно записывает связку ключей на диск.
+func (k *LocalKMS) save(ring keyring) error {
+	if err := os.MkdirAll(k.dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ring, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(k.dir, keyringFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(k.dir, keyringFile))
+}
@@ -0,0 +1,40 @@
+package keys
+
+import (
+	"fmt"
+
+	"github.com/egosha7/goph-keeper/internal/config"
+)
+
+// Типы поставщиков ключей, задаваемые в конфигурации.
+const (
+	ProviderStatic   = "static"
+	ProviderLocalKMS = "local-kms"
+)
+
+// NewProvider создает поставщика ключей по конфигурации сервера.
+func NewProvider(cfg *config.Config) (KeyProvider, error) {
+	switch cfg.KeyProvider {
+	case ProviderStatic:
+		spec, err := staticKeys(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return NewStaticProvider(spec)
+	case ProviderLocalKMS:
+		return NewLocalKMS(cfg.LocalKMSDir)
+	default:
+		return nil, fmt.Errorf("unknown key provider %q", cfg.KeyProvider)
+	}
+}
+
+// staticKeys читает ключи из переменной окружения или, если она пуста, из файла.
+func staticKeys(cfg *config.Config) (map[int][]byte, error) {
+	if cfg.KEK != "" {
+		return ParseKeys(cfg.KEK)
+	}
+	if cfg.KEKFile != "" {
+		return LoadKeyFile(cfg.KEKFile)
+	}
+	return nil, fmt.Errorf("static key provider requires KEK or KEK_FILE")
+}
@@ -0,0 +1,100 @@
+package keys
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/egosha7/goph-keeper/internal/crypt"
+)
+
+// StaticProvider хранит KEK в памяти процесса.
+// Ключи задаются переменной окружения или файлом для локальных установок.
+type StaticProvider struct {
+	keys    map[int][]byte
+	current int
+}
+
+// NewStaticProvider создает поставщика из набора ключей по версиям.
+// Текущей считается наибольшая версия.
+func NewStaticProvider(keys map[int][]byte) (*StaticProvider, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no key-encryption keys configured")
+	}
+
+	p := &StaticProvider{keys: make(map[int][]byte, len(keys))}
+	for version, key := range keys {
+		if len(key) != crypt.KeySize {
+			return nil, fmt.Errorf("key version %d: invalid key size %d", version, len(key))
+		}
+		p.keys[version] = key
+		if version > p.current {
+			p.current = version
+		}
+	}
+	return p, nil
+}
+
+// ParseKeys разбирает ключи в формате `версия:base64`, разделенные `;` или переводом строки.
+func ParseKeys(spec string) (map[int][]byte, error) {
+	keys := make(map[int][]byte)
+	for _, entry := range strings.FieldsFunc(spec, func(r rune) bool { return r == ';' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		versionPart, keyPart, found := strings.Cut(entry, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid key entry %q: expected version:base64", entry)
+		}
+		version, err := strconv.Atoi(strings.TrimSpace(versionPart))
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid key version %q", versionPart)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(keyPart))
+		if err != nil {
+			return nil, fmt.Errorf("key version %d: %w", version, err)
+		}
+		if _, ok := keys[version]; ok {
+			return nil, fmt.Errorf("duplicate key version %d", version)
+		}
+		keys[version] = key
+	}
+	return keys, nil
+}
+
+// LoadKeyFile читает ключи из файла в формате ParseKeys.
+func LoadKeyFile(path string) (map[int][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeys(string(data))
+}
+
+// CurrentVersion возвращает наибольшую версию KEK.
+func (p *StaticProvider) CurrentVersion(context.Context) (int, error) {
+	return p.current, nil
+}
+
+// WrapKey оборачивает ключ данных текущей версией KEK.
+func (p *StaticProvider) WrapKey(_ context.Context, dek []byte) ([]byte, int, error) {
+	wrapped, err := wrap(p.keys[p.current], p.current, dek)
+	if err != nil {
+		return nil, 0, err
+	}
+	return wrapped, p.current, nil
+}
+
+// UnwrapKey разворачивает ключ данных KEK указанной версии.
+func (p *StaticProvider) UnwrapKey(_ context.Context, wrapped []byte, version int) ([]byte, error) {
+	kek, ok := p.keys[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	return unwrap(kek, version, wrapped)
+}
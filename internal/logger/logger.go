@@ -88,6 +88,14 @@ func (rw *responseWriter) Write(data []byte) (int, error) {
 	return size, err
 }
 
+// Flush отправляет клиенту буферизованные данные, если это поддерживает исходный ResponseWriter.
+// Без него потоковые ответы, например Server-Sent Events, не доходили бы до клиента.
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 // Status возвращает статус код ответа.
 func (rw *responseWriter) Status() int {
 	return rw.statusCode
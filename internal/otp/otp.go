@@ -0,0 +1,260 @@
+// Package otp реализует одноразовые пароли HOTP (RFC 4226) и TOTP (RFC 6238)
+// и разбор ключей в формате otpauth:// из приложений-аутентификаторов.
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Типы одноразовых паролей.
+const (
+	TypeTOTP = "totp" // По времени
+	TypeHOTP = "hotp" // По счетчику
+)
+
+// Алгоритмы HMAC.
+const (
+	SHA1   = "SHA1"
+	SHA256 = "SHA256"
+	SHA512 = "SHA512"
+)
+
+// Значения по умолчанию из формата otpauth.
+const (
+	DefaultDigits = 6
+	DefaultPeriod = 30
+)
+
+// Допустимое количество цифр кода.
+const (
+	MinDigits = 6
+	MaxDigits = 8
+)
+
+// SecretSize - размер секрета новых ключей в байтах, рекомендованный RFC 4226.
+const SecretSize = 20
+
+// Key описывает ключ одноразовых паролей.
+type Key struct {
+	Type      string // totp или hotp
+	Issuer    string // Сервис, выдавший ключ
+	Account   string // Учетная запись в сервисе
+	Secret    []byte // Общий секрет
+	Algorithm string // Алгоритм HMAC
+	Digits    int    // Количество цифр кода
+	Period    int    // Период смены кода TOTP в секундах
+	Counter   uint64 // Счетчик HOTP
+}
+
+// DecodeSecret декодирует секрет в base32. Пробелы, регистр и выравнивание "=" не важны.
+func DecodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+	return base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+}
+
+// Parse разбирает ключ в формате otpauth://totp/Issuer:account?secret=...
+// Строка без схемы otpauth считается секретом TOTP в base32 с параметрами по умолчанию.
+func Parse(value string) (*Key, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(strings.ToLower(value), "otpauth://") {
+		secret, err := DecodeSecret(value)
+		if err != nil || len(secret) == 0 {
+			return nil, fmt.Errorf("secret is not base32")
+		}
+		return &Key{Type: TypeTOTP, Secret: secret, Algorithm: SHA1, Digits: DefaultDigits, Period: DefaultPeriod}, nil
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid otpauth uri: %w", err)
+	}
+	key := &Key{Type: strings.ToLower(u.Host), Algorithm: SHA1, Digits: DefaultDigits, Period: DefaultPeriod}
+	if key.Type != TypeTOTP && key.Type != TypeHOTP {
+		return nil, fmt.Errorf("unknown otp type %q", u.Host)
+	}
+
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		key.Issuer, key.Account = strings.TrimSpace(issuer), strings.TrimSpace(account)
+	} else {
+		key.Account = strings.TrimSpace(label)
+	}
+
+	q := u.Query()
+	if issuer := q.Get("issuer"); issuer != "" {
+		key.Issuer = issuer
+	}
+	if key.Secret, err = DecodeSecret(q.Get("secret")); err != nil || len(key.Secret) == 0 {
+		return nil, fmt.Errorf("secret is missing or not base32")
+	}
+	if algorithm := q.Get("algorithm"); algorithm != "" {
+		key.Algorithm = strings.ToUpper(algorithm)
+	}
+	if digits := q.Get("digits"); digits != "" {
+		if key.Digits, err = strconv.Atoi(digits); err != nil {
+			return nil, fmt.Errorf("invalid digits %q", digits)
+		}
+	}
+	if period := q.Get("period"); period != "" {
+		if key.Period, err = strconv.Atoi(period); err != nil || key.Period <= 0 {
+			return nil, fmt.Errorf("invalid period %q", period)
+		}
+	}
+	if key.Type == TypeHOTP {
+		counter := q.Get("counter")
+		if counter == "" {
+			return nil, fmt.Errorf("hotp counter is missing")
+		}
+		if key.Counter, err = strconv.ParseUint(counter, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid counter %q", counter)
+		}
+	}
+	return key, key.validate()
+}
+
+// URI формирует ключ в формате otpauth.
+func (k *Key) URI() string {
+	label := k.Account
+	if k.Issuer != "" {
+		label = k.Issuer + ":" + k.Account
+	}
+	q := url.Values{}
+	q.Set("secret", base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(k.Secret))
+	if k.Issuer != "" {
+		q.Set("issuer", k.Issuer)
+	}
+	q.Set("algorithm", k.Algorithm)
+	q.Set("digits", strconv.Itoa(k.Digits))
+	if k.Type == TypeHOTP {
+		q.Set("counter", strconv.FormatUint(k.Counter, 10))
+	} else {
+		q.Set("period", strconv.Itoa(k.Period))
+	}
+	u := url.URL{Scheme: "otpauth", Host: k.Type, Path: "/" + label, RawQuery: q.Encode()}
+	return u.String()
+}
+
+// Code вычисляет код на момент at. Для TOTP возвращает также время до смены кода,
+// для HOTP - код для текущего значения счетчика и нулевое время.
+func (k *Key) Code(at time.Time) (string, time.Duration, error) {
+	if err := k.validate(); err != nil {
+		return "", 0, err
+	}
+	if k.Type == TypeHOTP {
+		code, err := HOTP(k.Secret, k.Counter, k.Digits, k.Algorithm)
+		return code, 0, err
+	}
+
+	period := int64(k.Period)
+	seconds := at.Unix()
+	code, err := HOTP(k.Secret, uint64(seconds/period), k.Digits, k.Algorithm)
+	remaining := time.Duration(period-seconds%period) * time.Second
+	return code, remaining, err
+}
+
+// NewTOTP создает ключ TOTP со случайным секретом и параметрами по умолчанию.
+func NewTOTP(issuer, account string) (*Key, error) {
+	secret := make([]byte, SecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return &Key{
+		Type:      TypeTOTP,
+		Issuer:    issuer,
+		Account:   account,
+		Secret:    secret,
+		Algorithm: SHA1,
+		Digits:    DefaultDigits,
+		Period:    DefaultPeriod,
+	}, nil
+}
+
+// Verify проверяет код TOTP на момент at с допуском skew периодов в обе стороны
+// и возвращает номер периода, которому код соответствует.
+// Номер нужен вызывающему, чтобы не принять один и тот же код дважды.
+func (k *Key) Verify(code string, at time.Time, skew int) (uint64, bool) {
+	if k.Type != TypeTOTP || k.validate() != nil || len(code) != k.Digits {
+		return 0, false
+	}
+	current := at.Unix() / int64(k.Period)
+	for i := -skew; i <= skew; i++ {
+		step := current + int64(i)
+		if step < 0 {
+			continue
+		}
+		expected, err := HOTP(k.Secret, uint64(step), k.Digits, k.Algorithm)
+		if err == nil && subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return uint64(step), true
+		}
+	}
+	return 0, false
+}
+
+// HOTP вычисляет код для значения счетчика по RFC 4226.
+func HOTP(secret []byte, counter uint64, digits int, algorithm string) (string, error) {
+	newHash, err := hashFunc(algorithm)
+	if err != nil {
+		return "", err
+	}
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], counter)
+	mac := hmac.New(newHash, secret)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	// Динамическое усечение: 31 бит, начиная со смещения из младших 4 бит последнего байта
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulo := uint32(1)
+	for i := 0; i < digits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%modulo), nil
+}
+
+// validate проверяет параметры ключа.
+func (k *Key) validate() error {
+	if k.Type != TypeTOTP && k.Type != TypeHOTP {
+		return fmt.Errorf("unknown otp type %q", k.Type)
+	}
+	if len(k.Secret) == 0 {
+		return fmt.Errorf("secret is empty")
+	}
+	if _, err := hashFunc(k.Algorithm); err != nil {
+		return err
+	}
+	if k.Digits < MinDigits || k.Digits > MaxDigits {
+		return fmt.Errorf("digits must be from %d to %d", MinDigits, MaxDigits)
+	}
+	if k.Type == TypeTOTP && k.Period <= 0 {
+		return fmt.Errorf("invalid period %d", k.Period)
+	}
+	return nil
+}
+
+// hashFunc возвращает хеш-функцию алгоритма HMAC.
+func hashFunc(algorithm string) (func() hash.Hash, error) {
+	switch algorithm {
+	case SHA1:
+		return sha1.New, nil
+	case SHA256:
+		return sha256.New, nil
+	case SHA512:
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("unknown algorithm %q", algorithm)
+}
@@ -0,0 +1,110 @@
+package otp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Секреты из приложений RFC 4226 и RFC 6238.
+var (
+	secret20 = []byte("12345678901234567890")
+	secret32 = []byte("12345678901234567890123456789012")
+	secret64 = []byte("1234567890123456789012345678901234567890123456789012345678901234")
+)
+
+func TestHOTP(t *testing.T) {
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		got, err := HOTP(secret20, uint64(counter), 6, SHA1)
+		require.NoError(t, err)
+		assert.Equal(t, code, got, "counter %d", counter)
+	}
+}
+
+func TestTOTP(t *testing.T) {
+	tests := []struct {
+		at     int64
+		secret []byte
+		alg    string
+		code   string
+	}{
+		{59, secret20, SHA1, "94287082"},
+		{59, secret32, SHA256, "46119246"},
+		{59, secret64, SHA512, "90693936"},
+		{1111111109, secret20, SHA1, "07081804"},
+		{1234567890, secret32, SHA256, "91819424"},
+		{20000000000, secret64, SHA512, "47863826"},
+	}
+	for _, tc := range tests {
+		key := &Key{Type: TypeTOTP, Secret: tc.secret, Algorithm: tc.alg, Digits: 8, Period: 30}
+		code, remaining, err := key.Code(time.Unix(tc.at, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tc.code, code)
+		assert.Equal(t, time.Duration(30-tc.at%30)*time.Second, remaining)
+	}
+}
+
+func TestParse(t *testing.T) {
+	key, err := Parse("otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example&algorithm=sha256&digits=8&period=60")
+	require.NoError(t, err)
+	assert.Equal(t, TypeTOTP, key.Type)
+	assert.Equal(t, "Example", key.Issuer)
+	assert.Equal(t, "alice@example.com", key.Account)
+	assert.Equal(t, []byte("Hello!\xde\xad\xbe\xef"), key.Secret)
+	assert.Equal(t, SHA256, key.Algorithm)
+	assert.Equal(t, 8, key.Digits)
+	assert.Equal(t, 60, key.Period)
+
+	again, err := Parse(key.URI())
+	require.NoError(t, err)
+	assert.Equal(t, key, again)
+
+	hotp, err := Parse("otpauth://hotp/alice?secret=JBSWY3DPEHPK3PXP&counter=7")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), hotp.Counter)
+	assert.Equal(t, DefaultDigits, hotp.Digits)
+
+	seed, err := Parse("jbsw y3dp ehpk 3pxp")
+	require.NoError(t, err)
+	assert.Equal(t, TypeTOTP, seed.Type)
+	assert.Equal(t, DefaultPeriod, seed.Period)
+
+	for _, invalid := range []string{
+		"otpauth://sms/alice?secret=JBSWY3DPEHPK3PXP",
+		"otpauth://totp/alice",
+		"otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&digits=9",
+		"otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&algorithm=MD5",
+		"otpauth://hotp/alice?secret=JBSWY3DPEHPK3PXP",
+		"not base32!",
+	} {
+		_, err := Parse(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	key, err := NewTOTP("GophKeeper", "Egor")
+	require.NoError(t, err)
+	assert.Len(t, key.Secret, SecretSize)
+	key.Secret = []byte("12345678901234567890")
+
+	at := time.Unix(1700000000, 0)
+	code, _, err := key.Code(at)
+	require.NoError(t, err)
+
+	step, ok := key.Verify(code, at, 1)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(at.Unix()/DefaultPeriod), step)
+
+	// Код предыдущего периода принимается в пределах допуска
+	_, ok = key.Verify(code, at.Add(DefaultPeriod*time.Second), 1)
+	assert.True(t, ok)
+	_, ok = key.Verify(code, at.Add(3*DefaultPeriod*time.Second), 1)
+	assert.False(t, ok)
+
+	_, ok = key.Verify("12345", at, 1)
+	assert.False(t, ok)
+}
@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// maxBuckets - наибольшее количество корзин в памяти. Ключи учетных записей открытых маршрутов
+// задает клиент, поэтому без ограничения перебор случайных логинов занимал бы память без предела.
+const maxBuckets = 100000
+
+// bucket - состояние корзины на момент последнего обращения.
+type bucket struct {
+	key     string
+	tokens  float64
+	updated time.Time
+	policy  Policy
+}
+
+// Memory хранит корзины в памяти процесса. Подходит для одного экземпляра сервера.
+// Корзины упорядочены по времени последнего обращения: заполнившиеся удаляются с конца очереди
+// при каждом обращении, а при превышении maxBuckets вытесняются давно не использованные.
+type Memory struct {
+	mu         sync.Mutex
+	buckets    map[string]*list.Element
+	order      *list.List // Корзины от последней использованной к самой давней
+	maxBuckets int
+	now        func() time.Time
+}
+
+// NewMemory создает новый экземпляр Memory.
+func NewMemory() *Memory {
+	return &Memory{
+		buckets:    make(map[string]*list.Element),
+		order:      list.New(),
+		maxBuckets: maxBuckets,
+		now:        time.Now,
+	}
+}
+
+// Allow расходует маркер из корзины key.
+func (m *Memory) Allow(_ context.Context, key string, policy Policy) (bool, time.Duration, error) {
+	if !policy.Enabled() {
+		return true, 0, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.expire(now)
+
+	var b *bucket
+	if e, ok := m.buckets[key]; ok {
+		b = e.Value.(*bucket)
+		m.order.MoveToFront(e)
+	} else {
+		b = &bucket{key: key}
+		m.buckets[key] = m.order.PushFront(b)
+		m.evict()
+	}
+	if b.policy != policy {
+		b.tokens, b.updated, b.policy = float64(policy.Requests), now, policy
+	}
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	return false, time.Duration((1 - b.tokens) * float64(policy.Per) / float64(policy.Requests)), nil
+}
+
+// refill пополняет корзину пропорционально прошедшему времени.
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated)
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens += float64(elapsed) * float64(b.policy.Requests) / float64(b.policy.Per)
+	if b.tokens > float64(b.policy.Requests) {
+		b.tokens = float64(b.policy.Requests)
+	}
+	b.updated = now
+}
+
+// expire удаляет с конца очереди корзины, к которым не обращались дольше времени их пополнения:
+// они успели заполниться, и их состояние не отличается от новой корзины.
+func (m *Memory) expire(now time.Time) {
+	for e := m.order.Back(); e != nil; e = m.order.Back() {
+		b := e.Value.(*bucket)
+		if now.Sub(b.updated) < b.policy.Per {
+			return
+		}
+		m.remove(e)
+	}
+}
+
+// evict вытесняет давно не использованные корзины сверх maxBuckets.
+func (m *Memory) evict() {
+	for m.order.Len() > m.maxBuckets {
+		m.remove(m.order.Back())
+	}
+}
+
+// remove удаляет корзину из очереди и индекса.
+func (m *Memory) remove(e *list.Element) {
+	m.order.Remove(e)
+	delete(m.buckets, e.Value.(*bucket).key)
+}
@@ -0,0 +1,105 @@
+// Package ratelimit ограничивает частоту запросов алгоритмом маркерной корзины (token bucket).
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Типы хранилищ состояния, задаваемые в конфигурации.
+const (
+	BackendMemory = "memory"
+)
+
+// Policy задает ограничение: не больше Requests запросов за Per.
+// Корзина вмещает Requests маркеров и равномерно пополняется за Per,
+// поэтому после простоя допускается всплеск до Requests запросов подряд.
+// Нулевая политика ограничение отключает.
+type Policy struct {
+	Requests int           // Емкость корзины
+	Per      time.Duration // Время полного пополнения корзины
+}
+
+// ParsePolicy разбирает политику вида "10/1m". Значения "", "0" и "off" отключают ограничение.
+func ParsePolicy(value string) (Policy, error) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "", "0", "off":
+		return Policy{}, nil
+	}
+
+	requests, per, ok := strings.Cut(value, "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("invalid rate limit %q: want requests/duration, e.g. 10/1m", value)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 0 {
+		return Policy{}, fmt.Errorf("invalid rate limit %q: bad request count", value)
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return Policy{}, fmt.Errorf("invalid rate limit %q: bad duration", value)
+	}
+	if n == 0 {
+		return Policy{}, nil
+	}
+	return Policy{Requests: n, Per: d}, nil
+}
+
+// Enabled сообщает, ограничивает ли политика запросы.
+func (p Policy) Enabled() bool {
+	return p.Requests > 0 && p.Per > 0
+}
+
+// String возвращает политику в формате ParsePolicy.
+func (p Policy) String() string {
+	if !p.Enabled() {
+		return "off"
+	}
+	return strconv.Itoa(p.Requests) + "/" + p.Per.String()
+}
+
+// MarshalText кодирует политику для конфигурации.
+func (p Policy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText разбирает политику из переменной окружения или флага.
+func (p *Policy) UnmarshalText(text []byte) error {
+	parsed, err := ParsePolicy(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// AccountKey возвращает ключ корзины учетной записи. Логин открытых маршрутов задает клиент,
+// поэтому в ключ входит его хеш: размер ключа не зависит от длины логина.
+func AccountKey(login string) string {
+	sum := sha256.Sum256([]byte(login))
+	return "account:" + hex.EncodeToString(sum[:])
+}
+
+// Limiter хранит состояние корзин. Общее хранилище (например, Redis) позволяет
+// нескольким экземплярам сервера ограничивать запросы совместно.
+type Limiter interface {
+	// Allow расходует маркер из корзины key. Если маркеров нет, возвращает false
+	// и время, через которое появится следующий.
+	Allow(ctx context.Context, key string, policy Policy) (bool, time.Duration, error)
+}
+
+// New создает хранилище состояния по названию из конфигурации.
+func New(backend string) (Limiter, error) {
+	switch backend {
+	case BackendMemory:
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend %q", backend)
+	}
+}
@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy("10/1m")
+	require.NoError(t, err)
+	assert.Equal(t, Policy{Requests: 10, Per: time.Minute}, policy)
+	assert.Equal(t, "10/1m0s", policy.String())
+
+	for _, disabled := range []string{"", "0", "off", "0/1s"} {
+		policy, err := ParsePolicy(disabled)
+		require.NoError(t, err, disabled)
+		assert.False(t, policy.Enabled(), disabled)
+	}
+
+	for _, invalid := range []string{"10", "x/1m", "-1/1m", "10/soon", "10/0s"} {
+		_, err := ParsePolicy(invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	var fromText Policy
+	require.NoError(t, fromText.UnmarshalText([]byte("5/30s")))
+	assert.Equal(t, Policy{Requests: 5, Per: 30 * time.Second}, fromText)
+}
+
+func TestAccountKey(t *testing.T) {
+	key := AccountKey("Egor")
+	assert.Equal(t, key, AccountKey("Egor"))
+	assert.NotEqual(t, key, AccountKey("egor"))
+	assert.True(t, strings.HasPrefix(key, "account:"))
+
+	// Размер ключа не зависит от длины логина
+	assert.Len(t, AccountKey(strings.Repeat("a", 10000)), len(key))
+}
+
+func TestMemory_Allow(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+	ctx := context.Background()
+	policy := Policy{Requests: 2, Per: time.Minute}
+
+	// Всплеск до емкости корзины
+	for i := 0; i < 2; i++ {
+		ok, _, err := m.Allow(ctx, "ip:1", policy)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+	ok, retryAfter, err := m.Allow(ctx, "ip:1", policy)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 30*time.Second, retryAfter)
+
+	// Другие ключи не затронуты
+	ok, _, _ = m.Allow(ctx, "ip:2", policy)
+	assert.True(t, ok)
+
+	// Маркер появляется через Per/Requests
+	now = now.Add(30 * time.Second)
+	ok, _, _ = m.Allow(ctx, "ip:1", policy)
+	assert.True(t, ok)
+	ok, _, _ = m.Allow(ctx, "ip:1", policy)
+	assert.False(t, ok)
+
+	// Отключенная политика ничего не ограничивает
+	ok, _, _ = m.Allow(ctx, "ip:1", Policy{})
+	assert.True(t, ok)
+}
+
+func TestMemory_Expire(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+	policy := Policy{Requests: 1, Per: time.Second}
+
+	m.Allow(context.Background(), "ip:1", policy)
+	assert.Len(t, m.buckets, 1)
+
+	// Заполнившаяся корзина удаляется при следующем обращении к любому ключу
+	now = now.Add(policy.Per)
+	m.Allow(context.Background(), "ip:2", policy)
+	assert.Len(t, m.buckets, 1)
+	assert.Contains(t, m.buckets, "ip:2")
+	assert.Equal(t, 1, m.order.Len())
+}
+
+func TestMemory_MaxBuckets(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+	m.maxBuckets = 3
+	ctx := context.Background()
+	policy := Policy{Requests: 1, Per: time.Hour}
+
+	m.Allow(ctx, "ip:1", policy)
+	m.Allow(ctx, "ip:2", policy)
+	m.Allow(ctx, "ip:3", policy)
+	// Обращение к ip:1 делает давно не использованной корзину ip:2
+	ok, _, _ := m.Allow(ctx, "ip:1", policy)
+	assert.False(t, ok)
+	m.Allow(ctx, "ip:4", policy)
+	assert.Contains(t, m.buckets, "ip:1")
+	assert.NotContains(t, m.buckets, "ip:2")
+
+	// Перебор новых ключей не увеличивает память сверх предела
+	for i := 0; i < 100; i++ {
+		m.Allow(ctx, "account:"+strconv.Itoa(i), policy)
+		now = now.Add(time.Millisecond)
+	}
+	assert.Len(t, m.buckets, 3)
+	assert.Equal(t, 3, m.order.Len())
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sync"
+
+	"github.com/egosha7/goph-keeper/internal/crypt"
+	"github.com/egosha7/goph-keeper/internal/keys"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// columnCipher шифрует чувствительные столбцы ключом данных (DEK) владельца записи.
+// Ключи данных хранятся в таблице user_keys обернутыми ключом шифрования ключей (KEK).
+type columnCipher struct {
+	pool *pgxpool.Pool
+	keys keys.KeyProvider
+
+	mu    sync.RWMutex
+	cache map[string][]byte // Развернутые ключи данных по логину
+}
+
+// newColumnCipher создает новый экземпляр columnCipher.
+func newColumnCipher(pool *pgxpool.Pool, provider keys.KeyProvider) *columnCipher {
+	return &columnCipher{
+		pool:  pool,
+		keys:  provider,
+		cache: make(map[string][]byte),
+	}
+}
+
+// encrypt шифрует значение столбца и возвращает конверт в base64.
+// Конверт привязан к владельцу и идентификатору поля записи.
+func (c *columnCipher) encrypt(ctx context.Context, login, record, value string) (string, error) {
+	dek, err := c.dataKey(ctx, login)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := crypt.Encrypt(crypt.AES256GCM, dek, []byte(value), crypt.AssociatedData{Owner: login, Record: record})
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// decrypt расшифровывает значение столбца, зашифрованное функцией encrypt.
+func (c *columnCipher) decrypt(ctx context.Context, login, record, value string) (string, error) {
+	dek, err := c.dataKey(ctx, login)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", fmt.Errorf("stored value is not an envelope: %w", err)
+	}
+	plaintext, err := crypt.Decrypt(dek, ciphertext, crypt.AssociatedData{Owner: login, Record: record})
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %w", record, err)
+	}
+	return string(plaintext), nil
+}
+
+// decryptLegacy расшифровывает значение столбца, который до шифрования на сервере хранился открытым.
+// legacy - отметка строки, сохраненной до появления отметки: только у таких строк значение, не являющееся
+// конвертом ключа данных пользователя, возвращается как есть, пока команда encrypt-legacy не зашифрует его.
+// Значения остальных строк расшифровываются как обычно, и поврежденный конверт приводит к ошибке.
+func (c *columnCipher) decryptLegacy(ctx context.Context, login, record, value string, legacy bool) (string, error) {
+	if !legacy {
+		return c.decrypt(ctx, login, record, value)
+	}
+	sealed, err := c.sealed(ctx, login, value)
+	if err != nil {
+		return "", err
+	}
+	if !sealed {
+		return value, nil
+	}
+	return c.decrypt(ctx, login, record, value)
+}
+
+// sealed сообщает, зашифровано ли значение ключом данных пользователя.
+// Старые значения тоже могут быть конвертами в base64, но зашифрованными на клиенте мастер-ключом,
+// поэтому конверт распознается по идентификатору ключа.
+func (c *columnCipher) sealed(ctx context.Context, login, value string) (bool, error) {
+	dek, err := c.dataKey(ctx, login)
+	if err != nil {
+		return false, err
+	}
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return false, nil
+	}
+	envelope, err := crypt.ParseEnvelope(data)
+	if err != nil {
+		return false, nil
+	}
+	return envelope.KeyID == crypt.KeyID(dek), nil
+}
+
+// dataKey возвращает ключ данных пользователя, создавая его при первом обращении.
+func (c *columnCipher) dataKey(ctx context.Context, login string) ([]byte, error) {
+	c.mu.RLock()
+	dek, ok := c.cache[login]
+	c.mu.RUnlock()
+	if ok {
+		return dek, nil
+	}
+
+	dek, err := c.loadDataKey(ctx, login)
+	if err == pgx.ErrNoRows {
+		dek, err = c.createDataKey(ctx, login)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.cache[login] = dek
+	c.mu.Unlock()
+	return dek, nil
+}
+
+// loadDataKey читает и разворачивает ключ данных пользователя.
+func (c *columnCipher) loadDataKey(ctx context.Context, login string) ([]byte, error) {
+	var wrapped []byte
+	var version int
+	query := `SELECT k.wrapped_key, k.kek_version FROM user_keys k JOIN users u ON u.id = k.id_user WHERE u.login = $1`
+	if err := c.pool.QueryRow(ctx, query, login).Scan(&wrapped, &version); err != nil {
+		return nil, err
+	}
+	return c.keys.UnwrapKey(ctx, wrapped, version)
+}
+
+// createDataKey генерирует и сохраняет новый ключ данных пользователя.
+// При одновременном создании побеждает первая запись, остальные читают ее.
+func (c *columnCipher) createDataKey(ctx context.Context, login string) ([]byte, error) {
+	dek, err := keys.NewDataKey()
+	if err != nil {
+		return nil, err
+	}
+	wrapped, version, err := c.keys.WrapKey(ctx, dek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	query := `INSERT INTO user_keys (id_user, wrapped_key, kek_version)
+		SELECT id, $2, $3 FROM users WHERE login = $1
+		ON CONFLICT (id_user) DO NOTHING`
+	tag, err := c.pool.Exec(ctx, query, login, wrapped, version)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return c.loadDataKey(ctx, login)
+	}
+	return dek, nil
+}
+
+// passwordRecord возвращает идентификатор зашифрованного поля пароля.
+func passwordRecord(passName string) string {
+	return "passwords.password/" + passName
+}
+
+// passwordOTPRecord возвращает идентификатор зашифрованного ключа одноразовых паролей пароля.
+func passwordOTPRecord(passName string) string {
+	return "passwords.otp/" + passName
+}
+
+// secondFactorRecord - идентификатор зашифрованного ключа второго фактора пользователя.
+// Подтвержденный и ожидающий подтверждения ключи шифруются с одним идентификатором,
+// чтобы при подтверждении конверт можно было перенести без расшифровки.
+const secondFactorRecord = "users.totp"
+
+// noteRecord возвращает идентификатор зашифрованного текста заметки.
+func noteRecord(title string) string {
+	return "notes.body/" + title
+}
+
+// recordFieldsRecord возвращает идентификатор зашифрованных полей записи произвольного типа.
+func recordFieldsRecord(name string) string {
+	return "records.fields/" + name
+}
+
+// cardColumns - зашифрованные столбцы карты в порядке номер, срок, CVV.
+var cardColumns = []string{"number", "expirydate", "cvv"}
+
+// cardRecord возвращает идентификатор зашифрованного поля карты.
+func cardRecord(cardName, column string) string {
+	return "cards." + column + "/" + cardName
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/egosha7/goph-keeper/internal/crypt"
+	"github.com/egosha7/goph-keeper/internal/keys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnCipher_DecryptLegacy(t *testing.T) {
+	ctx := context.Background()
+	dek, err := keys.NewDataKey()
+	require.NoError(t, err)
+	masterKey, err := keys.NewDataKey()
+	require.NoError(t, err)
+
+	// Ключ данных уже развернут, поэтому база данных не нужна
+	c := &columnCipher{cache: map[string][]byte{"Egor": dek}}
+	record := passwordRecord("Email")
+
+	// До шифрования на сервере хранился шифротекст клиента, а у самых старых записей - открытое значение
+	clientEnvelope, err := crypt.Encrypt(crypt.AES256GCM, masterKey, []byte("secret"), crypt.AssociatedData{Owner: "Egor", Record: "Email"})
+	require.NoError(t, err)
+	legacyCiphertext := base64.StdEncoding.EncodeToString(clientEnvelope)
+
+	sealed, err := c.encrypt(ctx, "Egor", record, legacyCiphertext)
+	require.NoError(t, err)
+
+	// Поврежденный шифротекст внутри корректного конверта: идентификатор ключа сохраняется
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	require.NoError(t, err)
+	data[len(data)-1] ^= 1
+	tampered := base64.StdEncoding.EncodeToString(data)
+	// Поврежденное значение, которое больше не является base64
+	truncated := "*" + sealed[1:len(sealed)-5]
+
+	testCases := []struct {
+		name    string
+		value   string
+		legacy  bool
+		want    string
+		wantErr bool
+	}{
+		{name: "Legacy plaintext", value: "hunter2", legacy: true, want: "hunter2"},
+		{name: "Legacy client ciphertext", value: legacyCiphertext, legacy: true, want: legacyCiphertext},
+		{name: "Legacy sealed", value: sealed, legacy: true, want: legacyCiphertext},
+		{name: "Sealed", value: sealed, want: legacyCiphertext},
+		{name: "Plaintext", value: "hunter2", wantErr: true},
+		{name: "Legacy tampered", value: tampered, legacy: true, wantErr: true},
+		{name: "Tampered", value: tampered, wantErr: true},
+		{name: "Not base64", value: truncated, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				got, err := c.decryptLegacy(ctx, "Egor", record, tc.value, tc.legacy)
+				if tc.wantErr {
+					assert.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			},
+		)
+	}
+
+	// Конверт ключа данных распознается по идентификатору ключа, а не по формату
+	for value, want := range map[string]bool{"hunter2": false, legacyCiphertext: false, sealed: true, tampered: true} {
+		isSealed, err := c.sealed(ctx, "Egor", value)
+		require.NoError(t, err)
+		assert.Equal(t, want, isSealed)
+	}
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+// fileColumns - столбцы метаданных файла в порядке полей domain.FileInfo.
+const fileColumns = `id, name, size, chunk_size, chunks, complete, created_at`
+
+// scanFileInfo читает метаданные файла из строки результата.
+func scanFileInfo(row pgx.Row) (*domain.FileInfo, error) {
+	info := &domain.FileInfo{}
+	err := row.Scan(&info.ID, &info.FileName, &info.Size, &info.ChunkSize, &info.Chunks, &info.Complete, &info.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// CreateFile создает запись о новом файле, загрузка которого еще не завершена.
+func (r *PostgreSQLRepository) CreateFile(login, fileName string, size int64, chunkSize, chunks int) (*domain.FileInfo, error) {
+	query := `INSERT INTO files (id_user, name, size, chunk_size, chunks)
+		VALUES ((SELECT id FROM users WHERE login = $1), $2, $3, $4, $5) RETURNING ` + fileColumns
+	info, err := scanFileInfo(r.pool.QueryRow(context.Background(), query, login, fileName, size, chunkSize, chunks))
+	if err != nil {
+		r.logger.Error("Failed to create file", zap.Error(err))
+		return nil, err
+	}
+	return info, nil
+}
+
+// FindFile возвращает файл пользователя по названию вместе с номерами загруженных частей.
+// Если файла нет, возвращает nil без ошибки.
+func (r *PostgreSQLRepository) FindFile(login, fileName string) (*domain.UploadSession, error) {
+	ctx := context.Background()
+	query := `SELECT ` + fileColumns + ` FROM files WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2`
+	info, err := scanFileInfo(r.pool.QueryRow(ctx, query, login, fileName))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		r.logger.Error("Failed to find file", zap.Error(err))
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT chunk FROM file_chunks WHERE id_file = $1 ORDER BY chunk`, info.ID)
+	if err != nil {
+		r.logger.Error("Failed to get file chunks", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	session := &domain.UploadSession{FileInfo: *info, Uploaded: []int{}}
+	for rows.Next() {
+		var index int
+		if err := rows.Scan(&index); err != nil {
+			r.logger.Error("Failed to scan file chunk row", zap.Error(err))
+			return nil, err
+		}
+		session.Uploaded = append(session.Uploaded, index)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error in file chunk rows", zap.Error(err))
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetFileByID возвращает метаданные файла пользователя по идентификатору.
+func (r *PostgreSQLRepository) GetFileByID(login string, fileID int) (*domain.FileInfo, error) {
+	query := `SELECT ` + fileColumns + ` FROM files WHERE id = $2 AND id_user = (SELECT id FROM users WHERE login = $1)`
+	info, err := scanFileInfo(r.pool.QueryRow(context.Background(), query, login, fileID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.NotFound("file not found")
+		}
+		r.logger.Error("Failed to get file", zap.Error(err))
+		return nil, err
+	}
+	return info, nil
+}
+
+// SaveFileChunk запоминает загруженную часть файла. Повторная загрузка части заменяет прежнюю.
+func (r *PostgreSQLRepository) SaveFileChunk(login string, fileID int, chunk domain.FileChunk) error {
+	query := `INSERT INTO file_chunks (id_file, chunk, size, checksum)
+		SELECT id, $3, $4, $5 FROM files
+		WHERE id = $2 AND id_user = (SELECT id FROM users WHERE login = $1) AND NOT complete
+		ON CONFLICT (id_file, chunk) DO UPDATE SET size = EXCLUDED.size, checksum = EXCLUDED.checksum`
+	tag, err := r.pool.Exec(context.Background(), query, login, fileID, chunk.Index, chunk.Size, chunk.Checksum)
+	if err != nil {
+		r.logger.Error("Failed to save file chunk", zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NotFound("upload not found")
+	}
+	return nil
+}
+
+// CompleteFile отмечает загрузку завершенной, если получены все части файла.
+func (r *PostgreSQLRepository) CompleteFile(login string, fileID int) error {
+	ctx := context.Background()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var chunks, uploaded int
+	query := `SELECT f.chunks, (SELECT count(*) FROM file_chunks c WHERE c.id_file = f.id)
+		FROM files f WHERE f.id = $2 AND f.id_user = (SELECT id FROM users WHERE login = $1) FOR UPDATE`
+	if err := tx.QueryRow(ctx, query, login, fileID).Scan(&chunks, &uploaded); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.NotFound("upload not found")
+		}
+		r.logger.Error("Failed to check upload", zap.Error(err))
+		return err
+	}
+	if uploaded != chunks {
+		return domain.Conflict("upload incomplete: %d of %d chunks received", uploaded, chunks)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE files SET complete = true WHERE id = $1`, fileID); err != nil {
+		r.logger.Error("Failed to complete upload", zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetFileChunk возвращает сведения о части полностью загруженного файла.
+func (r *PostgreSQLRepository) GetFileChunk(login string, fileID, index int) (*domain.FileChunk, error) {
+	chunk := &domain.FileChunk{Index: index}
+	query := `SELECT c.size, c.checksum FROM file_chunks c JOIN files f ON f.id = c.id_file
+		WHERE f.id = $2 AND f.id_user = (SELECT id FROM users WHERE login = $1) AND f.complete AND c.chunk = $3`
+	err := r.pool.QueryRow(context.Background(), query, login, fileID, index).Scan(&chunk.Size, &chunk.Checksum)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.NotFound("chunk not found")
+		}
+		r.logger.Error("Failed to get file chunk", zap.Error(err))
+		return nil, err
+	}
+	return chunk, nil
+}
+
+// GetFileNameList получает список названий полностью загруженных файлов пользователя.
+func (r *PostgreSQLRepository) GetFileNameList(login string) ([]string, error) {
+	query := `SELECT name FROM files WHERE id_user = (SELECT id FROM users WHERE login = $1) AND complete`
+	rows, err := r.pool.Query(context.Background(), query, login)
+	if err != nil {
+		r.logger.Error("Failed to get file list", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fileNames []string
+	for rows.Next() {
+		var fileName string
+		if err := rows.Scan(&fileName); err != nil {
+			r.logger.Error("Failed to scan file list row", zap.Error(err))
+			return nil, err
+		}
+		fileNames = append(fileNames, fileName)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error in file list rows", zap.Error(err))
+		return nil, err
+	}
+	return fileNames, nil
+}
+
+// DeleteFile удаляет запись о файле и его частях и возвращает удаленные метаданные,
+// чтобы вызывающий удалил содержимое из хранилища объектов.
+func (r *PostgreSQLRepository) DeleteFile(login string, fileID int) (*domain.FileInfo, error) {
+	query := `DELETE FROM files WHERE id = $2 AND id_user = (SELECT id FROM users WHERE login = $1) RETURNING ` + fileColumns
+	info, err := scanFileInfo(r.pool.QueryRow(context.Background(), query, login, fileID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.NotFound("file not found")
+		}
+		r.logger.Error("Failed to delete file", zap.Error(err))
+		return nil, err
+	}
+	return info, nil
+}
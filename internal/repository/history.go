@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+// historyTable описывает таблицу записей и таблицу ее ревизий.
+// Ревизия хранит полный снимок столбцов записи, последняя ревизия совпадает с текущим значением.
+type historyTable struct {
+	kind      string // Вид записи для сообщений об ошибках
+	items     string // Таблица записей
+	revisions string // Таблица ревизий
+	ref       string // Столбец ревизии со ссылкой на запись
+	columns   string // Копируемые столбцы записи
+	restored  string // Столбцы, возвращаемые восстановлением ревизии: название и секрет
+	// rename перешифровывает под новым названием зашифрованные столбцы, которые не восстанавливаются
+	rename func(r *PostgreSQLRepository, ctx context.Context, tx pgx.Tx, login, name, newName string) error
+}
+
+var (
+	passwordHistory = historyTable{
+		kind:      "password",
+		items:     "passwords",
+		revisions: "password_revisions",
+		ref:       "id_password",
+		columns:   "name, password, legacy, metadata, tags, folder, name_terms, domain_terms, urls, otp",
+		restored:  "name, password, legacy, name_terms",
+		rename:    (*PostgreSQLRepository).resealPasswordOTP,
+	}
+	cardHistory = historyTable{
+		kind:      "card",
+		items:     "cards",
+		revisions: "card_revisions",
+		ref:       "id_card",
+		columns:   "name, number, expirydate, cvv, legacy, metadata, tags, folder, name_terms",
+		restored:  "name, number, expirydate, cvv, legacy, name_terms",
+	}
+	noteHistory = historyTable{
+		kind:      "note",
+		items:     "notes",
+		revisions: "note_revisions",
+		ref:       "id_note",
+		columns:   "name, body, name_terms",
+		restored:  "name, body, name_terms",
+	}
+	recordHistory = historyTable{
+		kind:      "record",
+		items:     "records",
+		revisions: "record_revisions",
+		ref:       "id_record",
+		columns:   "type, name, fields, metadata, name_terms",
+		restored:  "type, name, fields, metadata, name_terms",
+	}
+)
+
+// appendRevision добавляет ревизию с текущим значением записи.
+func appendRevision(ctx context.Context, tx pgx.Tx, h historyTable, itemID int, login string) error {
+	query := `INSERT INTO ` + h.revisions + ` (` + h.ref + `, revision, ` + h.columns + `, changed_by)
+		SELECT id, COALESCE((SELECT MAX(revision) FROM ` + h.revisions + ` WHERE ` + h.ref + ` = $1), 0) + 1, ` +
+		h.columns + `, $2 FROM ` + h.items + ` WHERE id = $1`
+	_, err := tx.Exec(ctx, query, itemID, login)
+	return err
+}
+
+// listRevisions возвращает ревизии записи пользователя, начиная с последней.
+func (r *PostgreSQLRepository) listRevisions(ctx context.Context, h historyTable, login, name string) ([]domain.Revision, error) {
+	query := `SELECT r.revision, r.name, r.changed_by, r.created_at
+		FROM ` + h.revisions + ` r JOIN ` + h.items + ` i ON i.id = r.` + h.ref + `
+		WHERE i.id_user = (SELECT id FROM users WHERE login = $1) AND i.name = $2 AND i.deleted_at IS NULL
+		ORDER BY r.revision DESC`
+	rows, err := r.pool.Query(ctx, query, login, name)
+	if err != nil {
+		r.logger.Error("Failed to get revisions", zap.String("kind", h.kind), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []domain.Revision
+	for rows.Next() {
+		var revision domain.Revision
+		if err := rows.Scan(&revision.Revision, &revision.Name, &revision.ChangedBy, &revision.CreatedAt); err != nil {
+			r.logger.Error("Failed to scan revision row", zap.String("kind", h.kind), zap.Error(err))
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error in revision rows", zap.String("kind", h.kind), zap.Error(err))
+		return nil, err
+	}
+
+	// У каждой существующей записи есть хотя бы одна ревизия
+	if len(revisions) == 0 {
+		return nil, domain.NotFound("%s not found", h.kind)
+	}
+	return revisions, nil
+}
+
+// restoreRevision возвращает название и секрет записи к значениям из ревизии и добавляет результат новой ревизией.
+// Метаданные, теги, папка, адреса и ключ одноразовых паролей меняются отдельными запросами и остаются текущими.
+func (r *PostgreSQLRepository) restoreRevision(ctx context.Context, h historyTable, login, name string, revision int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var itemID int
+	query := `SELECT id FROM ` + h.items + ` WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL FOR UPDATE`
+	if err := tx.QueryRow(ctx, query, login, name).Scan(&itemID); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.NotFound("%s not found", h.kind)
+		}
+		r.logger.Error("Failed to lock item", zap.String("kind", h.kind), zap.Error(err))
+		return err
+	}
+
+	var revisionName string
+	query = `SELECT name FROM ` + h.revisions + ` WHERE ` + h.ref + ` = $1 AND revision = $2`
+	if err := tx.QueryRow(ctx, query, itemID, revision).Scan(&revisionName); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.NotFound("revision not found")
+		}
+		r.logger.Error("Failed to get revision", zap.String("kind", h.kind), zap.Error(err))
+		return err
+	}
+
+	if revisionName != name {
+		taken, err := nameTaken(ctx, tx, h.items, login, revisionName)
+		if err != nil {
+			r.logger.Error("Failed to check item name", zap.String("kind", h.kind), zap.Error(err))
+			return err
+		}
+		if taken {
+			return domain.Conflict("%s already exists", h.kind)
+		}
+		if h.rename != nil {
+			if err := h.rename(r, ctx, tx, login, name, revisionName); err != nil {
+				return err
+			}
+		}
+	}
+
+	query = `UPDATE ` + h.items + ` SET (` + h.restored + `) =
+		(SELECT ` + h.restored + ` FROM ` + h.revisions + ` WHERE ` + h.ref + ` = $1 AND revision = $2)
+		WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, itemID, revision); err != nil {
+		r.logger.Error("Failed to restore revision", zap.String("kind", h.kind), zap.Error(err))
+		return err
+	}
+	if err := appendRevision(ctx, tx, h, itemID, login); err != nil {
+		r.logger.Error("Failed to append revision", zap.String("kind", h.kind), zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetPasswordHistory возвращает ревизии пароля, начиная с последней.
+func (r *PostgreSQLRepository) GetPasswordHistory(login, passName string) ([]domain.Revision, error) {
+	return r.listRevisions(context.Background(), passwordHistory, login, passName)
+}
+
+// GetPasswordRevision возвращает пароль в указанной ревизии.
+func (r *PostgreSQLRepository) GetPasswordRevision(login, passName string, revision int) (*domain.PasswordRevision, error) {
+	ctx := context.Background()
+	result := &domain.PasswordRevision{}
+	var legacy bool
+	query := `SELECT r.revision, r.name, r.changed_by, r.created_at, r.password, r.legacy
+		FROM password_revisions r JOIN passwords p ON p.id = r.id_password
+		WHERE p.id_user = (SELECT id FROM users WHERE login = $1) AND p.name = $2 AND p.deleted_at IS NULL AND r.revision = $3`
+	err := r.pool.QueryRow(ctx, query, login, passName, revision).Scan(
+		&result.Revision.Revision, &result.Name, &result.ChangedBy, &result.CreatedAt, &result.Password, &legacy,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.NotFound("revision not found")
+		}
+		r.logger.Error("Failed to get password revision", zap.Error(err))
+		return nil, err
+	}
+
+	result.Password, err = r.cipher.decryptLegacy(ctx, login, passwordRecord(result.Name), result.Password, legacy)
+	if err != nil {
+		r.logger.Error("Failed to decrypt password revision", zap.Error(err))
+		return nil, err
+	}
+	return result, nil
+}
+
+// RestorePassword делает указанную ревизию текущим значением пароля.
+func (r *PostgreSQLRepository) RestorePassword(login, passName string, revision int) error {
+	return r.restoreRevision(context.Background(), passwordHistory, login, passName, revision)
+}
+
+// GetCardHistory возвращает ревизии карты, начиная с последней.
+func (r *PostgreSQLRepository) GetCardHistory(login, cardName string) ([]domain.Revision, error) {
+	return r.listRevisions(context.Background(), cardHistory, login, cardName)
+}
+
+// GetCardRevision возвращает реквизиты карты в указанной ревизии.
+func (r *PostgreSQLRepository) GetCardRevision(login, cardName string, revision int) (*domain.CardRevision, error) {
+	ctx := context.Background()
+	result := &domain.CardRevision{}
+	var legacy bool
+	query := `SELECT r.revision, r.name, r.changed_by, r.created_at, r.number, r.expirydate, r.cvv, r.legacy
+		FROM card_revisions r JOIN cards c ON c.id = r.id_card
+		WHERE c.id_user = (SELECT id FROM users WHERE login = $1) AND c.name = $2 AND c.deleted_at IS NULL AND r.revision = $3`
+	err := r.pool.QueryRow(ctx, query, login, cardName, revision).Scan(
+		&result.Revision.Revision, &result.Name, &result.ChangedBy, &result.CreatedAt,
+		&result.Number, &result.ExpiryDate, &result.CVV, &legacy,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.NotFound("revision not found")
+		}
+		r.logger.Error("Failed to get card revision", zap.Error(err))
+		return nil, err
+	}
+
+	decrypted, err := r.decryptCard(ctx, login, result.Name, legacy, result.Number, result.ExpiryDate, result.CVV)
+	if err != nil {
+		r.logger.Error("Failed to decrypt card revision", zap.Error(err))
+		return nil, err
+	}
+	result.Number, result.ExpiryDate, result.CVV = decrypted[0], decrypted[1], decrypted[2]
+	return result, nil
+}
+
+// RestoreCard делает указанную ревизию текущим значением карты.
+func (r *PostgreSQLRepository) RestoreCard(login, cardName string, revision int) error {
+	return r.restoreRevision(context.Background(), cardHistory, login, cardName, revision)
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/db"
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/keys"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestRepository подключается к базе данных из DATABASE_DSN и применяет миграции.
+// Без DATABASE_DSN тест пропускается.
+func newTestRepository(t *testing.T) *PostgreSQLRepository {
+	dsn := os.Getenv("DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("DATABASE_DSN is not set")
+	}
+	ctx := context.Background()
+
+	conn, err := pgx.Connect(ctx, dsn)
+	require.NoError(t, err)
+	defer conn.Close(ctx)
+	require.NoError(t, db.Migrate(ctx, conn))
+
+	pool, err := pgxpool.Connect(ctx, dsn)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	masterKey, err := keys.NewDataKey()
+	require.NoError(t, err)
+	provider, err := keys.NewStaticProvider(map[int][]byte{1: masterKey})
+	require.NoError(t, err)
+
+	repo := NewPostgreSQLRepository(pool, provider, zap.NewNop())
+	return repo.UserRepository.(*PostgreSQLRepository)
+}
+
+func TestRestorePassword_KeepsCurrentDetails(t *testing.T) {
+	repo := newTestRepository(t)
+	login := fmt.Sprintf("history-%d", time.Now().UnixNano())
+	require.NoError(t, repo.Create(&domain.User{Login: login, Password: "hash", Pin: "hash", Salt: "salt"}))
+
+	// Ревизия 1 хранит старое название и пароль, остальные сведения заданы после переименования
+	require.NoError(t, repo.InsertNewPassword(login, "Mail", "v1"))
+	require.NoError(t, repo.UpdatePassword(login, "Mail", "Email", "v2"))
+	meta := domain.ItemMeta{Metadata: map[string]string{"site": "mail.example.com"}, Tags: []string{"work"}, Folder: "Work/Mail"}
+	require.NoError(t, repo.SetPasswordMeta(login, "Email", meta))
+	urls := []domain.LoginURL{{URL: "https://mail.example.com", Match: domain.MatchHost}}
+	require.NoError(t, repo.SetPasswordURLs(login, "Email", urls))
+	require.NoError(t, repo.SetPasswordOTP(login, "Email", "otpauth-secret"))
+
+	require.NoError(t, repo.RestorePassword(login, "Email", 1))
+
+	// Название и пароль вернулись к ревизии
+	password, err := repo.GetPassword(login, "Mail")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", password)
+	_, err = repo.GetPassword(login, "Email")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	// Метаданные, теги, папка и адреса сайтов остались текущими
+	list, err := repo.GetPasswordList(login, domain.ItemFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ItemSummary{{Name: "Mail", ItemMeta: meta}}, list)
+
+	gotURLs, err := repo.GetPasswordURLs(login)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PasswordURLsData{{PassName: "Mail", URLs: urls}}, gotURLs)
+
+	// Ключ одноразовых паролей перешифрован под восстановленное название
+	entry, err := repo.GetPasswordEntry(login, "Mail")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", entry.Password)
+	assert.Equal(t, "otpauth-secret", entry.OTP)
+}
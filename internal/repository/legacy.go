@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+// LegacyEncryption представляет интерфейс для шифрования значений, сохраненных до шифрования на сервере.
+type LegacyEncryption interface {
+	EncryptLegacyValues(ctx context.Context, batchSize int, progress func(LegacyProgress)) (LegacyProgress, error)
+}
+
+// LegacyProgress описывает ход шифрования старых значений.
+type LegacyProgress struct {
+	Items     int // Количество проверенных отмеченных паролей и карт
+	Encrypted int // Количество зашифрованных значений в записях и ревизиях
+}
+
+// legacyTable описывает таблицу, столбцы которой хранились открытыми до шифрования на сервере.
+type legacyTable struct {
+	history historyTable
+	columns []string                         // Зашифрованные столбцы
+	record  func(name, column string) string // Идентификатор поля для конверта
+}
+
+// legacyTables - таблицы, созданные до шифрования на сервере. Заметки, записи, файлы и ключи
+// одноразовых паролей появились позже и всегда хранились зашифрованными.
+var legacyTables = []legacyTable{
+	{
+		history: passwordHistory,
+		columns: []string{"password"},
+		record:  func(name, _ string) string { return passwordRecord(name) },
+	},
+	{
+		history: cardHistory,
+		columns: cardColumns,
+		record:  cardRecord,
+	},
+}
+
+// EncryptLegacyValues шифрует ключом данных владельца значения отмеченных паролей и карт, сохраненные
+// до шифрования на сервере, вместе с их ревизиями и снимает отметку.
+//
+// Записи обрабатываются пачками, каждая пачка фиксируется отдельной транзакцией. Уже зашифрованные
+// значения не меняются, поэтому после сбоя команду достаточно запустить повторно.
+func (r *PostgreSQLRepository) EncryptLegacyValues(
+	ctx context.Context, batchSize int, progress func(LegacyProgress),
+) (LegacyProgress, error) {
+	var state LegacyProgress
+	for _, table := range legacyTables {
+		lastID := 0
+		for {
+			processed, err := r.encryptLegacyBatch(ctx, table, batchSize, &lastID, &state)
+			if err != nil {
+				return state, err
+			}
+			if processed == 0 {
+				break
+			}
+			if progress != nil {
+				progress(state)
+			}
+		}
+	}
+	return state, nil
+}
+
+// encryptLegacyBatch шифрует старые значения одной пачки записей в отдельной транзакции.
+func (r *PostgreSQLRepository) encryptLegacyBatch(
+	ctx context.Context, table legacyTable, batchSize int, lastID *int, state *LegacyProgress,
+) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	h := table.history
+	columns := strings.Join(table.columns, ", ")
+	query := `SELECT t.id, u.login, t.name, t.legacy, ` + columns + ` FROM ` + h.items + ` t JOIN users u ON u.id = t.id_user
+		WHERE t.id > $1 AND (t.legacy OR EXISTS (SELECT 1 FROM ` + h.revisions + ` r WHERE r.` + h.ref + ` = t.id AND r.legacy))
+		ORDER BY t.id LIMIT $2 FOR UPDATE OF t`
+	rows, err := tx.Query(ctx, query, *lastID, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	type legacyItem struct {
+		id     int
+		login  string
+		name   string
+		legacy bool
+		values []string
+	}
+	var batch []legacyItem
+	for rows.Next() {
+		item := legacyItem{values: make([]string, len(table.columns))}
+		dest := []interface{}{&item.id, &item.login, &item.name, &item.legacy}
+		for i := range item.values {
+			dest = append(dest, &item.values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		batch = append(batch, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, item := range batch {
+		*lastID = item.id
+		state.Items++
+
+		if item.legacy {
+			encrypted, err := r.sealLegacy(ctx, tx, table, item.login, item.name, item.values, h.items, "id = $1", item.id)
+			if err != nil {
+				return 0, err
+			}
+			state.Encrypted += encrypted
+		}
+
+		revisions, err := r.encryptLegacyRevisions(ctx, tx, table, item.login, item.id)
+		if err != nil {
+			return 0, err
+		}
+		state.Encrypted += revisions
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
+
+// encryptLegacyRevisions шифрует старые значения в отмеченных ревизиях записи.
+// Ревизия зашифрована под названием, которое запись имела в момент изменения.
+func (r *PostgreSQLRepository) encryptLegacyRevisions(
+	ctx context.Context, tx pgx.Tx, table legacyTable, login string, itemID int,
+) (int, error) {
+	h := table.history
+	query := `SELECT revision, name, ` + strings.Join(table.columns, ", ") + ` FROM ` + h.revisions + `
+		WHERE ` + h.ref + ` = $1 AND legacy ORDER BY revision`
+	rows, err := tx.Query(ctx, query, itemID)
+	if err != nil {
+		return 0, err
+	}
+
+	type legacyRevision struct {
+		revision int
+		name     string
+		values   []string
+	}
+	var revisions []legacyRevision
+	for rows.Next() {
+		rev := legacyRevision{values: make([]string, len(table.columns))}
+		dest := []interface{}{&rev.revision, &rev.name}
+		for i := range rev.values {
+			dest = append(dest, &rev.values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		revisions = append(revisions, rev)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var encrypted int
+	for _, rev := range revisions {
+		n, err := r.sealLegacy(
+			ctx, tx, table, login, rev.name, rev.values, h.revisions, h.ref+" = $1 AND revision = $2", itemID, rev.revision,
+		)
+		if err != nil {
+			return 0, err
+		}
+		encrypted += n
+	}
+	return encrypted, nil
+}
+
+// sealLegacy шифрует незашифрованные значения отмеченной строки, сохраняет их и снимает отметку.
+// Строка выбирается условием where с параметрами args, значения подставляются после них.
+// Возвращает количество зашифрованных значений.
+func (r *PostgreSQLRepository) sealLegacy(
+	ctx context.Context, tx pgx.Tx, table legacyTable, login, name string, values []string,
+	from, where string, args ...interface{},
+) (int, error) {
+	set := []string{"legacy = false"}
+	for i, value := range values {
+		sealed, err := r.cipher.sealed(ctx, login, value)
+		if err != nil {
+			return 0, err
+		}
+		if sealed {
+			continue
+		}
+		encrypted, err := r.cipher.encrypt(ctx, login, table.record(name, table.columns[i]), value)
+		if err != nil {
+			r.logger.Error("Failed to encrypt legacy value", zap.String("table", from), zap.Error(err))
+			return 0, err
+		}
+		args = append(args, encrypted)
+		set = append(set, table.columns[i]+" = $"+strconv.Itoa(len(args)))
+	}
+	query := `UPDATE ` + from + ` SET ` + strings.Join(set, ", ") + ` WHERE ` + where
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		r.logger.Error("Failed to store encrypted legacy value", zap.String("table", from), zap.Error(err))
+		return 0, err
+	}
+	return len(set) - 1, nil
+}
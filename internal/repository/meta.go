@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+// setItemMeta заменяет метаданные, теги и папку записи и добавляет ревизию.
+func (r *PostgreSQLRepository) setItemMeta(ctx context.Context, h historyTable, login, name string, meta domain.ItemMeta) error {
+	if meta.Metadata == nil {
+		meta.Metadata = map[string]string{}
+	}
+	if meta.Tags == nil {
+		meta.Tags = []string{}
+	}
+	metadata, err := json.Marshal(meta.Metadata)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var itemID int
+	query := `UPDATE ` + h.items + ` SET metadata = $3, tags = $4, folder = $5
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL RETURNING id`
+	if err := tx.QueryRow(ctx, query, login, name, metadata, meta.Tags, meta.Folder).Scan(&itemID); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.NotFound("%s not found", h.kind)
+		}
+		r.logger.Error("Failed to update item meta", zap.String("kind", h.kind), zap.Error(err))
+		return err
+	}
+	if err := appendRevision(ctx, tx, h, itemID, login); err != nil {
+		r.logger.Error("Failed to append revision", zap.String("kind", h.kind), zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// listItems возвращает записи пользователя с метаданными, отфильтрованные по тегу и папке.
+// Фильтр по папке включает вложенные папки.
+func (r *PostgreSQLRepository) listItems(ctx context.Context, h historyTable, login string, filter domain.ItemFilter) ([]domain.ItemSummary, error) {
+	query := `SELECT name, metadata, tags, folder FROM ` + h.items + `
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND deleted_at IS NULL
+			AND ($2::text = '' OR $2 = ANY (tags))
+			AND ($3::text = '' OR folder = $3 OR starts_with(folder, $3 || '/'))
+		ORDER BY folder, name`
+	rows, err := r.pool.Query(ctx, query, login, filter.Tag, filter.Folder)
+	if err != nil {
+		r.logger.Error("Failed to get item list", zap.String("kind", h.kind), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []domain.ItemSummary
+	for rows.Next() {
+		var item domain.ItemSummary
+		var metadata []byte
+		if err := rows.Scan(&item.Name, &metadata, &item.Tags, &item.Folder); err != nil {
+			r.logger.Error("Failed to scan item list row", zap.String("kind", h.kind), zap.Error(err))
+			return nil, err
+		}
+		if err := json.Unmarshal(metadata, &item.Metadata); err != nil {
+			return nil, fmt.Errorf("stored %s metadata is corrupted: %w", h.kind, err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error in item list rows", zap.String("kind", h.kind), zap.Error(err))
+		return nil, err
+	}
+	return items, nil
+}
+
+// SetPasswordMeta заменяет метаданные, теги и папку пароля.
+func (r *PostgreSQLRepository) SetPasswordMeta(login, passName string, meta domain.ItemMeta) error {
+	return r.setItemMeta(context.Background(), passwordHistory, login, passName, meta)
+}
+
+// GetPasswordList возвращает пароли пользователя с метаданными, отфильтрованные по тегу и папке.
+func (r *PostgreSQLRepository) GetPasswordList(login string, filter domain.ItemFilter) ([]domain.ItemSummary, error) {
+	return r.listItems(context.Background(), passwordHistory, login, filter)
+}
+
+// SetCardMeta заменяет метаданные, теги и папку карты.
+func (r *PostgreSQLRepository) SetCardMeta(login, cardName string, meta domain.ItemMeta) error {
+	return r.setItemMeta(context.Background(), cardHistory, login, cardName, meta)
+}
+
+// GetCardList возвращает карты пользователя с метаданными, отфильтрованные по тегу и папке.
+func (r *PostgreSQLRepository) GetCardList(login string, filter domain.ItemFilter) ([]domain.ItemSummary, error) {
+	return r.listItems(context.Background(), cardHistory, login, filter)
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+// InsertNewNote вставляет новую заметку в базу данных.
+func (r *PostgreSQLRepository) InsertNewNote(login, title, body string) error {
+	ctx := context.Background()
+	encrypted, err := r.cipher.encrypt(ctx, login, noteRecord(title), body)
+	if err != nil {
+		r.logger.Error("Failed to encrypt note", zap.Error(err))
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	taken, err := nameTaken(ctx, tx, "notes", login, title)
+	if err != nil {
+		r.logger.Error("Failed to check note title", zap.Error(err))
+		return err
+	}
+	if taken {
+		return domain.Conflict("note already exists")
+	}
+
+	var noteID int
+	query := "INSERT INTO notes (id_user, name, body) VALUES ((SELECT id FROM users WHERE login = $1), $2, $3) RETURNING id"
+	if err := tx.QueryRow(ctx, query, login, title, encrypted).Scan(&noteID); err != nil {
+		r.logger.Error("Failed to insert new note", zap.Error(err))
+		return err
+	}
+	if err := appendRevision(ctx, tx, noteHistory, noteID, login); err != nil {
+		r.logger.Error("Failed to append note revision", zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetNote получает текст заметки из базы данных.
+func (r *PostgreSQLRepository) GetNote(login, title string) (string, error) {
+	ctx := context.Background()
+	var body string
+	query := `SELECT body FROM notes WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL`
+	if err := r.pool.QueryRow(ctx, query, login, title).Scan(&body); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", domain.NotFound("note not found")
+		}
+		r.logger.Error("Failed to get note", zap.Error(err))
+		return "", err
+	}
+
+	body, err := r.cipher.decrypt(ctx, login, noteRecord(title), body)
+	if err != nil {
+		r.logger.Error("Failed to decrypt note", zap.Error(err))
+		return "", err
+	}
+	return body, nil
+}
+
+// GetNoteNameList получает список заголовков заметок пользователя из базы данных.
+func (r *PostgreSQLRepository) GetNoteNameList(login string) ([]string, error) {
+	query := `SELECT name FROM notes WHERE id_user = (SELECT id FROM users WHERE login = $1) AND deleted_at IS NULL`
+	rows, err := r.pool.Query(context.Background(), query, login)
+	if err != nil {
+		r.logger.Error("Failed to get note list", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var titles []string
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			r.logger.Error("Failed to scan note list row", zap.Error(err))
+			return nil, err
+		}
+		titles = append(titles, title)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error in note list rows", zap.Error(err))
+		return nil, err
+	}
+	return titles, nil
+}
+
+// UpdateNote изменяет текст заметки и, если задан новый заголовок, переименовывает ее.
+// Прежнее значение остается в истории ревизий.
+func (r *PostgreSQLRepository) UpdateNote(login, title, newTitle, body string) error {
+	ctx := context.Background()
+	encrypted, err := r.cipher.encrypt(ctx, login, noteRecord(newTitle), body)
+	if err != nil {
+		r.logger.Error("Failed to encrypt note", zap.Error(err))
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if newTitle != title {
+		taken, err := nameTaken(ctx, tx, "notes", login, newTitle)
+		if err != nil {
+			r.logger.Error("Failed to check note title", zap.Error(err))
+			return err
+		}
+		if taken {
+			return domain.Conflict("note already exists")
+		}
+	}
+
+	var noteID int
+	query := `UPDATE notes SET name = $3, body = $4
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL RETURNING id`
+	if err := tx.QueryRow(ctx, query, login, title, newTitle, encrypted).Scan(&noteID); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.NotFound("note not found")
+		}
+		r.logger.Error("Failed to update note", zap.Error(err))
+		return err
+	}
+	if err := appendRevision(ctx, tx, noteHistory, noteID, login); err != nil {
+		r.logger.Error("Failed to append note revision", zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DeleteNote перемещает заметку в корзину.
+func (r *PostgreSQLRepository) DeleteNote(login, title string) error {
+	query := `UPDATE notes SET deleted_at = now()
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL`
+	tag, err := r.pool.Exec(context.Background(), query, login, title)
+	if err != nil {
+		r.logger.Error("Failed to delete note", zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NotFound("note not found")
+	}
+	return nil
+}
+
+// GetNoteHistory возвращает ревизии заметки, начиная с последней.
+func (r *PostgreSQLRepository) GetNoteHistory(login, title string) ([]domain.Revision, error) {
+	return r.listRevisions(context.Background(), noteHistory, login, title)
+}
+
+// GetNoteRevision возвращает текст заметки в указанной ревизии.
+func (r *PostgreSQLRepository) GetNoteRevision(login, title string, revision int) (*domain.NoteRevision, error) {
+	ctx := context.Background()
+	result := &domain.NoteRevision{}
+	query := `SELECT r.revision, r.name, r.changed_by, r.created_at, r.body
+		FROM note_revisions r JOIN notes n ON n.id = r.id_note
+		WHERE n.id_user = (SELECT id FROM users WHERE login = $1) AND n.name = $2 AND n.deleted_at IS NULL AND r.revision = $3`
+	err := r.pool.QueryRow(ctx, query, login, title, revision).Scan(
+		&result.Revision.Revision, &result.Name, &result.ChangedBy, &result.CreatedAt, &result.Body,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.NotFound("revision not found")
+		}
+		r.logger.Error("Failed to get note revision", zap.Error(err))
+		return nil, err
+	}
+
+	result.Body, err = r.cipher.decrypt(ctx, login, noteRecord(result.Name), result.Body)
+	if err != nil {
+		r.logger.Error("Failed to decrypt note revision", zap.Error(err))
+		return nil, err
+	}
+	return result, nil
+}
+
+// RestoreNote делает указанную ревизию текущим значением заметки.
+func (r *PostgreSQLRepository) RestoreNote(login, title string, revision int) error {
+	return r.restoreRevision(context.Background(), noteHistory, login, title, revision)
+}
+
+// GetNoteTrash возвращает заметки пользователя из корзины.
+func (r *PostgreSQLRepository) GetNoteTrash(login string) ([]domain.TrashItem, error) {
+	return r.listTrash(context.Background(), noteHistory, login)
+}
+
+// RestoreNoteFromTrash возвращает заметку из корзины.
+func (r *PostgreSQLRepository) RestoreNoteFromTrash(login string, id int) error {
+	return r.restoreFromTrash(context.Background(), noteHistory, login, id)
+}
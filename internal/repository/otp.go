@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+// SetPasswordOTP привязывает к паролю ключ одноразовых паролей и добавляет ревизию.
+// Пустой ключ отвязывает одноразовые пароли.
+func (r *PostgreSQLRepository) SetPasswordOTP(login, passName, otp string) error {
+	ctx := context.Background()
+	if otp != "" {
+		var err error
+		if otp, err = r.cipher.encrypt(ctx, login, passwordOTPRecord(passName), otp); err != nil {
+			r.logger.Error("Failed to encrypt password otp", zap.Error(err))
+			return err
+		}
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var passID int
+	query := `UPDATE passwords SET otp = $3
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL RETURNING id`
+	if err := tx.QueryRow(ctx, query, login, passName, otp).Scan(&passID); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.NotFound("password not found")
+		}
+		r.logger.Error("Failed to update password otp", zap.Error(err))
+		return err
+	}
+	if err := appendRevision(ctx, tx, passwordHistory, passID, login); err != nil {
+		r.logger.Error("Failed to append password revision", zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetPasswordEntry возвращает пароль вместе с привязанным ключом одноразовых паролей.
+func (r *PostgreSQLRepository) GetPasswordEntry(login, passName string) (*domain.PasswordEntry, error) {
+	ctx := context.Background()
+	entry := domain.PasswordEntry{PassName: passName}
+	var legacy bool
+	query := `SELECT password, otp, legacy FROM passwords
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL`
+	if err := r.pool.QueryRow(ctx, query, login, passName).Scan(&entry.Password, &entry.OTP, &legacy); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.NotFound("password not found")
+		}
+		r.logger.Error("Failed to get password entry", zap.Error(err))
+		return nil, err
+	}
+
+	var err error
+	if entry.Password, err = r.cipher.decryptLegacy(ctx, login, passwordRecord(passName), entry.Password, legacy); err != nil {
+		r.logger.Error("Failed to decrypt password", zap.Error(err))
+		return nil, err
+	}
+	if entry.OTP != "" {
+		if entry.OTP, err = r.cipher.decrypt(ctx, login, passwordOTPRecord(passName), entry.OTP); err != nil {
+			r.logger.Error("Failed to decrypt password otp", zap.Error(err))
+			return nil, err
+		}
+	}
+	return &entry, nil
+}
+
+// resealPasswordOTP перешифровывает ключ одноразовых паролей пароля под новым названием,
+// не меняя название самого пароля.
+func (r *PostgreSQLRepository) resealPasswordOTP(ctx context.Context, tx pgx.Tx, login, passName, newPassName string) error {
+	otpValue, err := r.renamePasswordOTP(ctx, tx, login, passName, newPassName)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE passwords SET otp = $3
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL`
+	if _, err := tx.Exec(ctx, query, login, passName, otpValue); err != nil {
+		r.logger.Error("Failed to update password otp", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// renamePasswordOTP возвращает хранимый ключ одноразовых паролей для пароля после переименования.
+// Шифротекст привязан к названию, поэтому при переименовании ключ шифруется заново.
+func (r *PostgreSQLRepository) renamePasswordOTP(ctx context.Context, tx pgx.Tx, login, passName, newPassName string) (string, error) {
+	var stored string
+	query := `SELECT otp FROM passwords
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL FOR UPDATE`
+	if err := tx.QueryRow(ctx, query, login, passName).Scan(&stored); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", domain.NotFound("password not found")
+		}
+		r.logger.Error("Failed to get password otp", zap.Error(err))
+		return "", err
+	}
+	if stored == "" || passName == newPassName {
+		return stored, nil
+	}
+
+	plaintext, err := r.cipher.decrypt(ctx, login, passwordOTPRecord(passName), stored)
+	if err != nil {
+		r.logger.Error("Failed to decrypt password otp", zap.Error(err))
+		return "", err
+	}
+	encrypted, err := r.cipher.encrypt(ctx, login, passwordOTPRecord(newPassName), plaintext)
+	if err != nil {
+		r.logger.Error("Failed to encrypt password otp", zap.Error(err))
+		return "", err
+	}
+	return encrypted, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+// GetPinState возвращает сохраненный пин-код пользователя и состояние защиты от перебора.
+func (r *PostgreSQLRepository) GetPinState(login string) (*domain.PinState, error) {
+	var state domain.PinState
+	query := `SELECT pin, pin_failures, pin_locked_until FROM users WHERE login = $1`
+	err := r.pool.QueryRow(context.Background(), query, login).Scan(&state.Hash, &state.Failures, &state.LockedUntil)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.NotFound("user not found")
+		}
+		r.logger.Error("Failed to get pin state", zap.Error(err))
+		return nil, err
+	}
+	return &state, nil
+}
+
+// SetPinHash заменяет сохраненный пин-код его хешем.
+func (r *PostgreSQLRepository) SetPinHash(login, hash string) error {
+	if _, err := r.pool.Exec(context.Background(), `UPDATE users SET pin = $2 WHERE login = $1`, login, hash); err != nil {
+		r.logger.Error("Failed to set pin hash", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ClaimPinAttempt засчитывает проверку пин-кода как неудачную до сверки пин-кода: увеличивает
+// счетчик с failures до failures+1 и устанавливает блокировку до lockedUntil.
+// Возвращает false, если счетчик уже изменила параллельная проверка, - тогда состояние нужно прочитать заново.
+func (r *PostgreSQLRepository) ClaimPinAttempt(login string, failures int, lockedUntil time.Time) (bool, error) {
+	query := `UPDATE users SET pin_failures = $2 + 1, pin_locked_until = $3 WHERE login = $1 AND pin_failures = $2`
+	tag, err := r.pool.Exec(context.Background(), query, login, failures, lockedUntil)
+	if err != nil {
+		r.logger.Error("Failed to claim pin attempt", zap.Error(err))
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ResetPinFailures сбрасывает счетчик неудачных проверок и блокировку пин-кода.
+func (r *PostgreSQLRepository) ResetPinFailures(login string) error {
+	query := `UPDATE users SET pin_failures = 0, pin_locked_until = 'epoch' WHERE login = $1`
+	if _, err := r.pool.Exec(context.Background(), query, login); err != nil {
+		r.logger.Error("Failed to reset pin failures", zap.Error(err))
+		return err
+	}
+	return nil
+}
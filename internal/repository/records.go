@@ -0,0 +1,360 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+// InsertTemplate сохраняет пользовательский шаблон записей.
+func (r *PostgreSQLRepository) InsertTemplate(login string, template domain.Template) error {
+	fields, err := json.Marshal(template.Fields)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO record_templates (id_user, name, description, fields)
+		VALUES ((SELECT id FROM users WHERE login = $1), $2, $3, $4)
+		ON CONFLICT (id_user, name) DO NOTHING`
+	tag, err := r.pool.Exec(context.Background(), query, login, template.Name, template.Description, fields)
+	if err != nil {
+		r.logger.Error("Failed to insert template", zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.Conflict("template already exists")
+	}
+	return nil
+}
+
+// GetTemplates возвращает пользовательские шаблоны записей.
+func (r *PostgreSQLRepository) GetTemplates(login string) ([]domain.Template, error) {
+	query := `SELECT name, description, fields FROM record_templates
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) ORDER BY name`
+	rows, err := r.pool.Query(context.Background(), query, login)
+	if err != nil {
+		r.logger.Error("Failed to get templates", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []domain.Template
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan template row", zap.Error(err))
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error in template rows", zap.Error(err))
+		return nil, err
+	}
+	return templates, nil
+}
+
+// GetTemplate возвращает пользовательский шаблон по названию или nil, если его нет.
+func (r *PostgreSQLRepository) GetTemplate(login, name string) (*domain.Template, error) {
+	query := `SELECT name, description, fields FROM record_templates
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2`
+	template, err := scanTemplate(r.pool.QueryRow(context.Background(), query, login, name))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error("Failed to get template", zap.Error(err))
+		return nil, err
+	}
+	return template, nil
+}
+
+// DeleteTemplate удаляет пользовательский шаблон, если по нему не создано ни одной записи.
+// Записи в корзине не учитываются: восстановленная запись сохраняет свои поля и без шаблона.
+func (r *PostgreSQLRepository) DeleteTemplate(login, name string) error {
+	ctx := context.Background()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var inUse bool
+	query := `SELECT EXISTS (SELECT 1 FROM records
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND type = $2 AND deleted_at IS NULL)`
+	if err := tx.QueryRow(ctx, query, login, name).Scan(&inUse); err != nil {
+		r.logger.Error("Failed to check template usage", zap.Error(err))
+		return err
+	}
+	if inUse {
+		return domain.Conflict("template is in use")
+	}
+
+	query = `DELETE FROM record_templates WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2`
+	tag, err := tx.Exec(ctx, query, login, name)
+	if err != nil {
+		r.logger.Error("Failed to delete template", zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NotFound("template not found")
+	}
+	return tx.Commit(ctx)
+}
+
+// InsertRecord вставляет новую запись произвольного типа.
+func (r *PostgreSQLRepository) InsertRecord(login string, record domain.Record) error {
+	ctx := context.Background()
+	fields, metadata, err := r.encodeRecord(ctx, login, record)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	taken, err := nameTaken(ctx, tx, "records", login, record.Name)
+	if err != nil {
+		r.logger.Error("Failed to check record name", zap.Error(err))
+		return err
+	}
+	if taken {
+		return domain.Conflict("record already exists")
+	}
+
+	var recordID int
+	query := `INSERT INTO records (id_user, type, name, fields, metadata)
+		VALUES ((SELECT id FROM users WHERE login = $1), $2, $3, $4, $5) RETURNING id`
+	if err := tx.QueryRow(ctx, query, login, record.Type, record.Name, fields, metadata).Scan(&recordID); err != nil {
+		r.logger.Error("Failed to insert new record", zap.Error(err))
+		return err
+	}
+	if err := appendRevision(ctx, tx, recordHistory, recordID, login); err != nil {
+		r.logger.Error("Failed to append record revision", zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetRecord получает запись по названию.
+func (r *PostgreSQLRepository) GetRecord(login, name string) (*domain.Record, error) {
+	ctx := context.Background()
+	var fields string
+	var metadata []byte
+	record := &domain.Record{Name: name}
+	query := `SELECT type, fields, metadata FROM records
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL`
+	if err := r.pool.QueryRow(ctx, query, login, name).Scan(&record.Type, &fields, &metadata); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.NotFound("record not found")
+		}
+		r.logger.Error("Failed to get record", zap.Error(err))
+		return nil, err
+	}
+
+	var err error
+	record.Fields, record.Metadata, err = r.decodeRecord(ctx, login, name, fields, metadata)
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// GetRecordList получает список записей пользователя указанного типа или всех типов, если тип пустой.
+// Время изменения записи - время ее последней ревизии.
+func (r *PostgreSQLRepository) GetRecordList(login, recordType string) ([]domain.RecordSummary, error) {
+	query := `SELECT r.name, r.type, (SELECT MAX(created_at) FROM record_revisions WHERE id_record = r.id)
+		FROM records r
+		WHERE r.id_user = (SELECT id FROM users WHERE login = $1) AND r.deleted_at IS NULL AND ($2::text = '' OR r.type = $2)
+		ORDER BY r.name`
+	rows, err := r.pool.Query(context.Background(), query, login, recordType)
+	if err != nil {
+		r.logger.Error("Failed to get record list", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []domain.RecordSummary
+	for rows.Next() {
+		var record domain.RecordSummary
+		if err := rows.Scan(&record.Name, &record.Type, &record.UpdatedAt); err != nil {
+			r.logger.Error("Failed to scan record list row", zap.Error(err))
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error in record list rows", zap.Error(err))
+		return nil, err
+	}
+	return records, nil
+}
+
+// UpdateRecord изменяет поля и метаданные записи и, если изменилось название, переименовывает ее.
+// Тип записи не меняется. Прежнее значение остается в истории ревизий.
+func (r *PostgreSQLRepository) UpdateRecord(login, name string, record domain.Record) error {
+	ctx := context.Background()
+	fields, metadata, err := r.encodeRecord(ctx, login, record)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if record.Name != name {
+		taken, err := nameTaken(ctx, tx, "records", login, record.Name)
+		if err != nil {
+			r.logger.Error("Failed to check record name", zap.Error(err))
+			return err
+		}
+		if taken {
+			return domain.Conflict("record already exists")
+		}
+	}
+
+	var recordID int
+	query := `UPDATE records SET name = $3, fields = $4, metadata = $5
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL RETURNING id`
+	if err := tx.QueryRow(ctx, query, login, name, record.Name, fields, metadata).Scan(&recordID); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.NotFound("record not found")
+		}
+		r.logger.Error("Failed to update record", zap.Error(err))
+		return err
+	}
+	if err := appendRevision(ctx, tx, recordHistory, recordID, login); err != nil {
+		r.logger.Error("Failed to append record revision", zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DeleteRecord перемещает запись в корзину.
+func (r *PostgreSQLRepository) DeleteRecord(login, name string) error {
+	query := `UPDATE records SET deleted_at = now()
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL`
+	tag, err := r.pool.Exec(context.Background(), query, login, name)
+	if err != nil {
+		r.logger.Error("Failed to delete record", zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NotFound("record not found")
+	}
+	return nil
+}
+
+// GetRecordHistory возвращает ревизии записи, начиная с последней.
+func (r *PostgreSQLRepository) GetRecordHistory(login, name string) ([]domain.Revision, error) {
+	return r.listRevisions(context.Background(), recordHistory, login, name)
+}
+
+// GetRecordRevision возвращает запись в указанной ревизии.
+func (r *PostgreSQLRepository) GetRecordRevision(login, name string, revision int) (*domain.RecordRevision, error) {
+	ctx := context.Background()
+	var fields string
+	var metadata []byte
+	result := &domain.RecordRevision{}
+	query := `SELECT rv.revision, rv.name, rv.changed_by, rv.created_at, rv.type, rv.fields, rv.metadata
+		FROM record_revisions rv JOIN records r ON r.id = rv.id_record
+		WHERE r.id_user = (SELECT id FROM users WHERE login = $1) AND r.name = $2 AND r.deleted_at IS NULL AND rv.revision = $3`
+	err := r.pool.QueryRow(ctx, query, login, name, revision).Scan(
+		&result.Revision.Revision, &result.Name, &result.ChangedBy, &result.CreatedAt, &result.Type, &fields, &metadata,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.NotFound("revision not found")
+		}
+		r.logger.Error("Failed to get record revision", zap.Error(err))
+		return nil, err
+	}
+
+	result.Fields, result.Metadata, err = r.decodeRecord(ctx, login, result.Name, fields, metadata)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RestoreRecord делает указанную ревизию текущим значением записи.
+func (r *PostgreSQLRepository) RestoreRecord(login, name string, revision int) error {
+	return r.restoreRevision(context.Background(), recordHistory, login, name, revision)
+}
+
+// GetRecordTrash возвращает записи пользователя из корзины.
+func (r *PostgreSQLRepository) GetRecordTrash(login string) ([]domain.TrashItem, error) {
+	return r.listTrash(context.Background(), recordHistory, login)
+}
+
+// RestoreRecordFromTrash возвращает запись из корзины.
+func (r *PostgreSQLRepository) RestoreRecordFromTrash(login string, id int) error {
+	return r.restoreFromTrash(context.Background(), recordHistory, login, id)
+}
+
+// encodeRecord шифрует поля записи одним конвертом и кодирует метаданные в JSON.
+func (r *PostgreSQLRepository) encodeRecord(ctx context.Context, login string, record domain.Record) (string, []byte, error) {
+	fields, err := json.Marshal(record.Fields)
+	if err != nil {
+		return "", nil, err
+	}
+	encrypted, err := r.cipher.encrypt(ctx, login, recordFieldsRecord(record.Name), string(fields))
+	if err != nil {
+		r.logger.Error("Failed to encrypt record fields", zap.Error(err))
+		return "", nil, err
+	}
+
+	if record.Metadata == nil {
+		record.Metadata = map[string]string{}
+	}
+	metadata, err := json.Marshal(record.Metadata)
+	if err != nil {
+		return "", nil, err
+	}
+	return encrypted, metadata, nil
+}
+
+// decodeRecord расшифровывает поля записи и разбирает метаданные.
+func (r *PostgreSQLRepository) decodeRecord(ctx context.Context, login, name, encrypted string, rawMetadata []byte) ([]domain.Field, map[string]string, error) {
+	decrypted, err := r.cipher.decrypt(ctx, login, recordFieldsRecord(name), encrypted)
+	if err != nil {
+		r.logger.Error("Failed to decrypt record fields", zap.Error(err))
+		return nil, nil, err
+	}
+
+	var fields []domain.Field
+	if err := json.Unmarshal([]byte(decrypted), &fields); err != nil {
+		return nil, nil, fmt.Errorf("stored record fields are corrupted: %w", err)
+	}
+	var metadata map[string]string
+	if err := json.Unmarshal(rawMetadata, &metadata); err != nil {
+		return nil, nil, fmt.Errorf("stored record metadata is corrupted: %w", err)
+	}
+	return fields, metadata, nil
+}
+
+// scanTemplate сканирует строку с пользовательским шаблоном.
+func scanTemplate(row pgx.Row) (*domain.Template, error) {
+	var template domain.Template
+	var fields []byte
+	if err := row.Scan(&template.Name, &template.Description, &fields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(fields, &template.Fields); err != nil {
+		return nil, fmt.Errorf("stored template fields are corrupted: %w", err)
+	}
+	return &template, nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	rotationPasses     = 5           // Количество проходов по ключам, заблокированным параллельной ротацией
+	rotationRetryDelay = time.Second // Пауза перед повторным проходом
+)
+
+// KeyRotation представляет интерфейс для переоборачивания ключей данных после смены KEK.
+type KeyRotation interface {
+	RewrapDataKeys(ctx context.Context, batchSize int, progress func(RotationProgress)) (RotationProgress, error)
+}
+
+// RotationProgress описывает ход переоборачивания ключей данных.
+type RotationProgress struct {
+	Version int // Версия KEK, которой оборачиваются ключи
+	Total   int // Количество ключей, обернутых устаревшими версиями, на момент запуска
+	Done    int // Количество переобернутых ключей
+	Failed  int // Количество ключей, которые не удалось развернуть
+}
+
+// RewrapDataKeys переоборачивает текущей версией KEK все ключи данных, обернутые устаревшими версиями.
+//
+// Ключи обрабатываются пачками, каждая пачка фиксируется отдельной транзакцией. Сами ключи данных
+// не меняются, поэтому зашифрованные строки остаются читаемыми на протяжении всей ротации,
+// а после сбоя повторный запуск продолжает с оставшихся ключей. Если часть ключей так и осталась
+// заблокированной параллельной ротацией, возвращается ошибка.
+func (r *PostgreSQLRepository) RewrapDataKeys(
+	ctx context.Context, batchSize int, progress func(RotationProgress),
+) (RotationProgress, error) {
+	version, err := r.cipher.keys.CurrentVersion(ctx)
+	if err != nil {
+		return RotationProgress{}, err
+	}
+
+	state := RotationProgress{Version: version}
+	query := "SELECT count(*) FROM user_keys WHERE kek_version <> $1"
+	if err := r.pool.QueryRow(ctx, query, version).Scan(&state.Total); err != nil {
+		return state, err
+	}
+
+	// Ключевая пагинация по id_user исключает повторную выборку ключей в пределах прохода. Строки,
+	// заблокированные параллельным запуском, остаются позади курсора, поэтому после прохода
+	// оставшиеся ключи пересчитываются и при необходимости обрабатываются следующим проходом.
+	failed := make([]int, 0)
+	for pass := 1; ; pass++ {
+		lastID := 0
+		for {
+			processed, err := r.rewrapBatch(ctx, version, batchSize, &lastID, &failed, &state)
+			if err != nil {
+				return state, err
+			}
+			if processed == 0 {
+				break
+			}
+			if progress != nil {
+				progress(state)
+			}
+		}
+
+		var skipped int
+		query := "SELECT count(*) FROM user_keys WHERE kek_version <> $1 AND NOT (id_user = ANY($2))"
+		if err := r.pool.QueryRow(ctx, query, version, failed).Scan(&skipped); err != nil {
+			return state, err
+		}
+		if skipped == 0 {
+			return state, nil
+		}
+		if pass == rotationPasses {
+			return state, fmt.Errorf("%d data keys were locked by another rotation, run the command again", skipped)
+		}
+
+		r.logger.Info("Data keys were locked during rotation, retrying", zap.Int("skipped", skipped))
+		select {
+		case <-ctx.Done():
+			return state, ctx.Err()
+		case <-time.After(rotationRetryDelay):
+		}
+	}
+}
+
+// rewrapBatch переоборачивает одну пачку ключей данных в отдельной транзакции.
+func (r *PostgreSQLRepository) rewrapBatch(
+	ctx context.Context, version, batchSize int, lastID *int, failed *[]int, state *RotationProgress,
+) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Строки, заблокированные параллельным запуском ротации, пропускаются,
+	// ключи, которые не удалось развернуть в предыдущих проходах, не выбираются повторно
+	query := `SELECT id_user, wrapped_key, kek_version FROM user_keys
+		WHERE kek_version <> $1 AND id_user > $2 AND NOT (id_user = ANY($4))
+		ORDER BY id_user LIMIT $3 FOR UPDATE SKIP LOCKED`
+	rows, err := tx.Query(ctx, query, version, *lastID, batchSize, *failed)
+	if err != nil {
+		return 0, err
+	}
+
+	type userKey struct {
+		userID  int
+		wrapped []byte
+		version int
+	}
+	var batch []userKey
+	for rows.Next() {
+		var key userKey
+		if err := rows.Scan(&key.userID, &key.wrapped, &key.version); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		batch = append(batch, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, key := range batch {
+		*lastID = key.userID
+
+		dek, err := r.cipher.keys.UnwrapKey(ctx, key.wrapped, key.version)
+		if err != nil {
+			r.logger.Error("Failed to unwrap data key", zap.Int("user", key.userID), zap.Error(err))
+			state.Failed++
+			*failed = append(*failed, key.userID)
+			continue
+		}
+		wrapped, newVersion, err := r.cipher.keys.WrapKey(ctx, dek)
+		if err != nil {
+			return 0, fmt.Errorf("failed to wrap data key of user %d: %w", key.userID, err)
+		}
+
+		_, err = tx.Exec(
+			ctx, "UPDATE user_keys SET wrapped_key = $1, kek_version = $2 WHERE id_user = $3",
+			wrapped, newVersion, key.userID,
+		)
+		if err != nil {
+			return 0, err
+		}
+		state.Done++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
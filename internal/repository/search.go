@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+// searchCursor - позиция последнего элемента страницы в порядке сортировки.
+// Следующая страница начинается строго после нее, поэтому вставки и удаления не сдвигают выдачу.
+type searchCursor struct {
+	Sort    string    `json:"s"`           // Порядок сортировки, для которого выдан курсор
+	Name    string    `json:"n,omitempty"` // Название в нижнем регистре
+	Updated time.Time `json:"u,omitempty"` // Время изменения
+	Kind    string    `json:"k"`           // Вид элемента
+	ID      int       `json:"i"`           // Идентификатор элемента
+}
+
+// likeEscaper экранирует спецсимволы шаблона LIKE.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchItems ищет активные элементы хранилища пользователя и возвращает страницу результатов.
+// Параметры запроса должны быть проверены и дополнены значениями по умолчанию вызывающим.
+func (r *PostgreSQLRepository) SearchItems(login string, q domain.SearchQuery) (*domain.SearchResult, error) {
+	var cursor *searchCursor
+	if q.Cursor != "" {
+		var err error
+		if cursor, err = decodeSearchCursor(q.Cursor, q.Sort); err != nil {
+			return nil, err
+		}
+	}
+
+	var pattern string
+	if q.Query != "" {
+		pattern = likeEscaper.Replace(strings.ToLower(q.Query)) + "%"
+		if q.Match != domain.MatchPrefix {
+			pattern = "%" + pattern
+		}
+	}
+	if q.Kinds == nil {
+		q.Kinds = []string{}
+	}
+	if q.Types == nil {
+		q.Types = []string{}
+	}
+	if q.Terms == nil {
+		q.Terms = []string{}
+	}
+
+	key, direction, comparison := "lower(name)", "ASC", ">"
+	if q.Sort == domain.SortUpdated || q.Sort == domain.SortUpdatedDesc {
+		key = "updated_at"
+	}
+	if strings.HasPrefix(q.Sort, "-") {
+		direction, comparison = "DESC", "<"
+	}
+
+	args := []interface{}{login, pattern, q.Kinds, q.Types, q.Tag, q.Folder, q.Terms}
+	query := `SELECT kind, id, type, name, lower(name), tags, folder, updated_at FROM vault_search_items
+		WHERE id_user = (SELECT id FROM users WHERE login = $1)
+			AND ($2::text = '' OR lower(name) LIKE $2)
+			AND (cardinality($3::text[]) = 0 OR kind = ANY ($3))
+			AND (cardinality($4::text[]) = 0 OR type = ANY ($4))
+			AND ($5::text = '' OR $5 = ANY (tags))
+			AND ($6::text = '' OR folder = $6 OR starts_with(folder, $6 || '/'))
+			AND (cardinality($7::text[]) = 0 OR blind_terms @> $7)`
+	if cursor != nil {
+		var position interface{} = cursor.Name
+		if key == "updated_at" {
+			position = cursor.Updated
+		}
+		args = append(args, position, cursor.Kind, cursor.ID)
+		query += ` AND (` + key + `, kind, id) ` + comparison + ` ($8, $9, $10)`
+	}
+	args = append(args, q.Limit+1)
+	query += ` ORDER BY ` + key + ` ` + direction + `, kind ` + direction + `, id ` + direction +
+		` LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := r.pool.Query(context.Background(), query, args...)
+	if err != nil {
+		r.logger.Error("Failed to search items", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := &domain.SearchResult{Items: []domain.SearchItem{}}
+	var positions []searchCursor
+	for rows.Next() {
+		var item domain.SearchItem
+		position := searchCursor{Sort: q.Sort}
+		err := rows.Scan(&item.Kind, &position.ID, &item.Type, &item.Name, &position.Name, &item.Tags, &item.Folder, &item.UpdatedAt)
+		if err != nil {
+			r.logger.Error("Failed to scan search row", zap.Error(err))
+			return nil, err
+		}
+		position.Kind, position.Updated = item.Kind, item.UpdatedAt
+		result.Items = append(result.Items, item)
+		positions = append(positions, position)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error in search rows", zap.Error(err))
+		return nil, err
+	}
+
+	// Лишний элемент означает, что есть следующая страница
+	if len(result.Items) > q.Limit {
+		result.Items = result.Items[:q.Limit]
+		result.NextCursor = encodeSearchCursor(positions[q.Limit-1])
+	}
+	return result, nil
+}
+
+// encodeSearchCursor кодирует позицию в непрозрачную для клиента строку.
+func encodeSearchCursor(cursor searchCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSearchCursor разбирает курсор и проверяет, что он выдан для того же порядка сортировки.
+func decodeSearchCursor(value, sort string) (*searchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, domain.InvalidField("cursor", "invalid cursor")
+	}
+	var cursor searchCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, domain.InvalidField("cursor", "invalid cursor")
+	}
+	if cursor.Sort != sort {
+		return nil, domain.InvalidField("cursor", "cursor was issued for another sort order")
+	}
+	return &cursor, nil
+}
+
+// blindIndexTables сопоставляет виду элемента его таблицу и условие, отбирающее активные элементы.
+var blindIndexTables = map[string]struct {
+	history historyTable
+	active  string
+}{
+	domain.KindPassword: {passwordHistory, "deleted_at IS NULL"},
+	domain.KindCard:     {cardHistory, "deleted_at IS NULL"},
+	domain.KindNote:     {noteHistory, "deleted_at IS NULL"},
+	domain.KindRecord:   {recordHistory, "deleted_at IS NULL"},
+	domain.KindFile:     {historyTable{kind: "file", items: "files"}, "complete"},
+}
+
+// SetBlindIndex заменяет термы слепого индекса поля элемента.
+// Термы описывают текущее состояние элемента, поэтому они же записываются в его последнюю ревизию:
+// при восстановлении ревизии вместе с названием восстанавливается и его индекс.
+func (r *PostgreSQLRepository) SetBlindIndex(login, kind, name, field string, terms []string) error {
+	table, ok := blindIndexTables[kind]
+	if !ok {
+		return domain.Invalid("unknown item kind %q", kind)
+	}
+	column := "name_terms"
+	if field == domain.IndexDomain {
+		if kind != domain.KindPassword {
+			return domain.Invalid("%s has no domains", kind)
+		}
+		column = "domain_terms"
+	}
+	if terms == nil {
+		terms = []string{}
+	}
+	h := table.history
+
+	ctx := context.Background()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var itemID int
+	query := `UPDATE ` + h.items + ` SET ` + column + ` = $3
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND ` + table.active + ` RETURNING id`
+	if err := tx.QueryRow(ctx, query, login, name, terms).Scan(&itemID); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.NotFound("%s not found", h.kind)
+		}
+		r.logger.Error("Failed to update blind index", zap.String("kind", h.kind), zap.Error(err))
+		return err
+	}
+	if h.revisions != "" {
+		query = `UPDATE ` + h.revisions + ` SET ` + column + ` = $2
+			WHERE ` + h.ref + ` = $1 AND revision = (SELECT MAX(revision) FROM ` + h.revisions + ` WHERE ` + h.ref + ` = $1)`
+		if _, err := tx.Exec(ctx, query, itemID, terms); err != nil {
+			r.logger.Error("Failed to update revision blind index", zap.String("kind", h.kind), zap.Error(err))
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
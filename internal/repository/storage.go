@@ -2,11 +2,14 @@ package repository
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/keys"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"go.uber.org/zap"
+	"time"
 )
 
 // UserRepository представляет интерфейс для работы с данными пользователей.
@@ -14,33 +17,111 @@ type UserRepository interface {
 	Create(user *domain.User) error
 	GetByUsername(username string) (*domain.User, error)
 	CheckUniqUser(login string) (bool, error)
-	CheckPinCode(login, pin string) (bool, error)
+	GetPinState(login string) (*domain.PinState, error)
+	SetPinHash(login, hash string) error
+	ClaimPinAttempt(login string, failures int, lockedUntil time.Time) (bool, error)
+	ResetPinFailures(login string) error
 	CheckValidUser(login string) (string, error)
+	GetSalt(login string) (string, error)
+	GetSecondFactor(login string) (*domain.SecondFactor, error)
+	SetPendingSecondFactor(login, key string) error
+	EnableSecondFactor(login string, step int64, recoveryHashes []string) error
+	DisableSecondFactor(login string) error
+	UseSecondFactorStep(login string, step int64) (bool, error)
+	UseRecoveryCode(login, codeHash string) (bool, error)
+	ClaimSecondFactorAttempt(login string, failures int, lockedUntil time.Time) (bool, error)
+	ResetSecondFactorFailures(login string) error
 	InsertNewCard(login, cardName, numberCard, expiryDateCard, cvvCard string) error
 	GetCard(login, cardName string) (string, string, string, error)
 	GetCardNameList(login string) ([]string, error)
+	UpdateCard(login, cardName, newCardName, numberCard, expiryDateCard, cvvCard string) error
+	DeleteCard(login, cardName string) error
 	InsertNewPassword(login, passName, password string) error
 	GetPassword(login, passName string) (string, error)
 	GetPasswordNameList(login string) ([]string, error)
+	UpdatePassword(login, passName, newPassName, password string) error
+	DeletePassword(login, passName string) error
+	GetPasswordHistory(login, passName string) ([]domain.Revision, error)
+	GetPasswordRevision(login, passName string, revision int) (*domain.PasswordRevision, error)
+	RestorePassword(login, passName string, revision int) error
+	GetCardHistory(login, cardName string) ([]domain.Revision, error)
+	GetCardRevision(login, cardName string, revision int) (*domain.CardRevision, error)
+	RestoreCard(login, cardName string, revision int) error
+	GetPasswordTrash(login string) ([]domain.TrashItem, error)
+	RestorePasswordFromTrash(login string, id int) error
+	GetCardTrash(login string) ([]domain.TrashItem, error)
+	RestoreCardFromTrash(login string, id int) error
+	InsertNewNote(login, title, body string) error
+	GetNote(login, title string) (string, error)
+	GetNoteNameList(login string) ([]string, error)
+	UpdateNote(login, title, newTitle, body string) error
+	DeleteNote(login, title string) error
+	GetNoteHistory(login, title string) ([]domain.Revision, error)
+	GetNoteRevision(login, title string, revision int) (*domain.NoteRevision, error)
+	RestoreNote(login, title string, revision int) error
+	GetNoteTrash(login string) ([]domain.TrashItem, error)
+	RestoreNoteFromTrash(login string, id int) error
+	CreateFile(login, fileName string, size int64, chunkSize, chunks int) (*domain.FileInfo, error)
+	FindFile(login, fileName string) (*domain.UploadSession, error)
+	GetFileByID(login string, fileID int) (*domain.FileInfo, error)
+	SaveFileChunk(login string, fileID int, chunk domain.FileChunk) error
+	CompleteFile(login string, fileID int) error
+	GetFileChunk(login string, fileID, index int) (*domain.FileChunk, error)
+	GetFileNameList(login string) ([]string, error)
+	DeleteFile(login string, fileID int) (*domain.FileInfo, error)
+	SetPasswordMeta(login, passName string, meta domain.ItemMeta) error
+	GetPasswordList(login string, filter domain.ItemFilter) ([]domain.ItemSummary, error)
+	SetCardMeta(login, cardName string, meta domain.ItemMeta) error
+	GetCardList(login string, filter domain.ItemFilter) ([]domain.ItemSummary, error)
+	SetPasswordURLs(login, passName string, urls []domain.LoginURL) error
+	SetPasswordOTP(login, passName, otp string) error
+	GetPasswordEntry(login, passName string) (*domain.PasswordEntry, error)
+	GetPasswordURLs(login string) ([]domain.PasswordURLsData, error)
+	SearchItems(login string, query domain.SearchQuery) (*domain.SearchResult, error)
+	SetBlindIndex(login, kind, name, field string, terms []string) error
+	InsertTemplate(login string, template domain.Template) error
+	GetTemplates(login string) ([]domain.Template, error)
+	GetTemplate(login, name string) (*domain.Template, error)
+	DeleteTemplate(login, name string) error
+	InsertRecord(login string, record domain.Record) error
+	GetRecord(login, name string) (*domain.Record, error)
+	GetRecordList(login, recordType string) ([]domain.RecordSummary, error)
+	UpdateRecord(login, name string, record domain.Record) error
+	DeleteRecord(login, name string) error
+	GetRecordHistory(login, name string) ([]domain.Revision, error)
+	GetRecordRevision(login, name string, revision int) (*domain.RecordRevision, error)
+	RestoreRecord(login, name string, revision int) error
+	GetRecordTrash(login string) ([]domain.TrashItem, error)
+	RestoreRecordFromTrash(login string, id int) error
 }
 
 type Repository struct {
 	UserRepository
+	KeyRotation
+	LegacyEncryption
+	TrashPurger
 }
 
 // PostgreSQLRepository представляет репозиторий для работы с PostgreSQL.
+// Чувствительные столбцы шифруются ключами данных пользователей.
 type PostgreSQLRepository struct {
 	pool   *pgxpool.Pool
+	cipher *columnCipher
 	logger *zap.Logger
 }
 
 // NewPostgreSQLRepository создает новый экземпляр PostgreSQLRepository.
-func NewPostgreSQLRepository(pool *pgxpool.Pool, logger *zap.Logger) *Repository {
+func NewPostgreSQLRepository(pool *pgxpool.Pool, provider keys.KeyProvider, logger *zap.Logger) *Repository {
+	repo := &PostgreSQLRepository{
+		pool:   pool,
+		cipher: newColumnCipher(pool, provider),
+		logger: logger,
+	}
 	return &Repository{
-		UserRepository: &PostgreSQLRepository{
-			pool:   pool,
-			logger: logger,
-		},
+		UserRepository:   repo,
+		KeyRotation:      repo,
+		LegacyEncryption: repo,
+		TrashPurger:      repo,
 	}
 }
 
@@ -59,6 +140,21 @@ func (r *PostgreSQLRepository) CheckValidUser(login string) (string, error) {
 	return password, nil
 }
 
+// GetSalt возвращает соль мастер-ключа пользователя.
+func (r *PostgreSQLRepository) GetSalt(login string) (string, error) {
+	var salt string
+	query := "SELECT salt FROM users WHERE login = $1"
+	err := r.pool.QueryRow(context.Background(), query, login).Scan(&salt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", domain.NotFound("user not found")
+		}
+		r.logger.Error("Failed to get salt", zap.Error(err))
+		return "", err
+	}
+	return salt, nil
+}
+
 // CheckUniqUser проверяет уникальность логина пользователя.
 func (r *PostgreSQLRepository) CheckUniqUser(login string) (bool, error) {
 	var existingUser string
@@ -76,33 +172,101 @@ func (r *PostgreSQLRepository) CheckUniqUser(login string) (bool, error) {
 
 // InsertNewCard вставляет новую карту в базу данных.
 func (r *PostgreSQLRepository) InsertNewCard(login, cardName, numberCard, expiryDateCard, cvvCard string) error {
-	query := "INSERT INTO cards (number, expirydate, cvv, id_user, name) VALUES ($1, $2, $3, (SELECT id FROM users WHERE login = $4), $5)"
-	_, err := r.pool.Exec(context.Background(), query, numberCard, expiryDateCard, cvvCard, login, cardName)
+	ctx := context.Background()
+	encrypted, err := r.encryptCard(ctx, login, cardName, numberCard, expiryDateCard, cvvCard)
+	if err != nil {
+		r.logger.Error("Failed to encrypt card", zap.Error(err))
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	taken, err := nameTaken(ctx, tx, "cards", login, cardName)
+	if err != nil {
+		r.logger.Error("Failed to check card name", zap.Error(err))
+		return err
+	}
+	if taken {
+		return domain.Conflict("card already exists")
+	}
+
+	var cardID int
+	query := "INSERT INTO cards (number, expirydate, cvv, id_user, name) VALUES ($1, $2, $3, (SELECT id FROM users WHERE login = $4), $5) RETURNING id"
+	err = tx.QueryRow(ctx, query, encrypted[0], encrypted[1], encrypted[2], login, cardName).Scan(&cardID)
 	if err != nil {
+		// Параллельный запрос успел добавить карту с тем же названием
+		if isUniqueViolation(err) {
+			return domain.Conflict("card already exists")
+		}
 		r.logger.Error("Failed to insert new card", zap.Error(err))
 		return err
 	}
-	return nil
+	if err := appendRevision(ctx, tx, cardHistory, cardID, login); err != nil {
+		r.logger.Error("Failed to append card revision", zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 // GetCard получает данные о карте из базы данных.
 func (r *PostgreSQLRepository) GetCard(login, cardName string) (string, string, string, error) {
 	var cardNumber, cardExpiryDate, cardCVV string
-	query := `SELECT number, expirydate, cvv FROM cards WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2`
-	err := r.pool.QueryRow(context.Background(), query, login, cardName).Scan(&cardNumber, &cardExpiryDate, &cardCVV)
+	var legacy bool
+	query := `SELECT number, expirydate, cvv, legacy FROM cards
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL`
+	err := r.pool.QueryRow(context.Background(), query, login, cardName).Scan(&cardNumber, &cardExpiryDate, &cardCVV, &legacy)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return "", "", "", fmt.Errorf("card not found")
+			return "", "", "", domain.NotFound("card not found")
 		}
 		r.logger.Error("Failed to get card", zap.Error(err))
 		return "", "", "", err
 	}
-	return cardNumber, cardExpiryDate, cardCVV, nil
+
+	decrypted, err := r.decryptCard(context.Background(), login, cardName, legacy, cardNumber, cardExpiryDate, cardCVV)
+	if err != nil {
+		r.logger.Error("Failed to decrypt card", zap.Error(err))
+		return "", "", "", err
+	}
+	return decrypted[0], decrypted[1], decrypted[2], nil
+}
+
+// encryptCard шифрует номер, срок действия и CVV карты.
+func (r *PostgreSQLRepository) encryptCard(ctx context.Context, login, cardName string, values ...string) ([]string, error) {
+	encrypted := make([]string, len(values))
+	for i, value := range values {
+		var err error
+		encrypted[i], err = r.cipher.encrypt(ctx, login, cardRecord(cardName, cardColumns[i]), value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return encrypted, nil
+}
+
+// decryptCard расшифровывает номер, срок действия и CVV карты. legacy - отметка строки, сохраненной до шифрования на сервере.
+func (r *PostgreSQLRepository) decryptCard(
+	ctx context.Context, login, cardName string, legacy bool, values ...string,
+) ([]string, error) {
+	decrypted := make([]string, len(values))
+	for i, value := range values {
+		var err error
+		decrypted[i], err = r.cipher.decryptLegacy(ctx, login, cardRecord(cardName, cardColumns[i]), value, legacy)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return decrypted, nil
 }
 
 // GetCardList получает список имен карт пользователя из базы данных.
 func (r *PostgreSQLRepository) GetCardNameList(login string) ([]string, error) {
-	query := `SELECT name FROM cards WHERE id_user = (SELECT id FROM users WHERE login = $1)`
+	query := `SELECT name FROM cards WHERE id_user = (SELECT id FROM users WHERE login = $1) AND deleted_at IS NULL`
 	rows, err := r.pool.Query(context.Background(), query, login)
 	if err != nil {
 		r.logger.Error("Failed to get card list", zap.Error(err))
@@ -126,35 +290,217 @@ func (r *PostgreSQLRepository) GetCardNameList(login string) ([]string, error) {
 	return cardNames, nil
 }
 
+// UpdateCard изменяет реквизиты карты и, если задано новое название, переименовывает ее.
+// Прежнее значение остается в истории ревизий.
+func (r *PostgreSQLRepository) UpdateCard(login, cardName, newCardName, numberCard, expiryDateCard, cvvCard string) error {
+	ctx := context.Background()
+	encrypted, err := r.encryptCard(ctx, login, newCardName, numberCard, expiryDateCard, cvvCard)
+	if err != nil {
+		r.logger.Error("Failed to encrypt card", zap.Error(err))
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if newCardName != cardName {
+		taken, err := nameTaken(ctx, tx, "cards", login, newCardName)
+		if err != nil {
+			r.logger.Error("Failed to check card name", zap.Error(err))
+			return err
+		}
+		if taken {
+			return domain.Conflict("card already exists")
+		}
+	}
+
+	var cardID int
+	query := `UPDATE cards SET name = $3, number = $4, expirydate = $5, cvv = $6, legacy = false
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL RETURNING id`
+	err = tx.QueryRow(ctx, query, login, cardName, newCardName, encrypted[0], encrypted[1], encrypted[2]).Scan(&cardID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.NotFound("card not found")
+		}
+		if isUniqueViolation(err) {
+			return domain.Conflict("card already exists")
+		}
+		r.logger.Error("Failed to update card", zap.Error(err))
+		return err
+	}
+	if err := appendRevision(ctx, tx, cardHistory, cardID, login); err != nil {
+		r.logger.Error("Failed to append card revision", zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DeleteCard перемещает карту в корзину.
+func (r *PostgreSQLRepository) DeleteCard(login, cardName string) error {
+	query := `UPDATE cards SET deleted_at = now()
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL`
+	tag, err := r.pool.Exec(context.Background(), query, login, cardName)
+	if err != nil {
+		r.logger.Error("Failed to delete card", zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NotFound("card not found")
+	}
+	return nil
+}
+
 // InsertNewPassword вставляет новый пароль в базу данных.
 func (r *PostgreSQLRepository) InsertNewPassword(login, passName, password string) error {
-	query := "INSERT INTO passwords (id_user, name, password) VALUES ((SELECT id FROM users WHERE login = $1), $2, $3)"
-	_, err := r.pool.Exec(context.Background(), query, login, passName, password)
+	ctx := context.Background()
+	encrypted, err := r.cipher.encrypt(ctx, login, passwordRecord(passName), password)
+	if err != nil {
+		r.logger.Error("Failed to encrypt password", zap.Error(err))
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	taken, err := nameTaken(ctx, tx, "passwords", login, passName)
 	if err != nil {
+		r.logger.Error("Failed to check password name", zap.Error(err))
+		return err
+	}
+	if taken {
+		return domain.Conflict("password already exists")
+	}
+
+	var passID int
+	query := "INSERT INTO passwords (id_user, name, password) VALUES ((SELECT id FROM users WHERE login = $1), $2, $3) RETURNING id"
+	err = tx.QueryRow(ctx, query, login, passName, encrypted).Scan(&passID)
+	if err != nil {
+		// Параллельный запрос успел добавить пароль с тем же названием
+		if isUniqueViolation(err) {
+			return domain.Conflict("password already exists")
+		}
 		r.logger.Error("Failed to insert new password", zap.Error(err))
 		return err
 	}
-	return nil
+	if err := appendRevision(ctx, tx, passwordHistory, passID, login); err != nil {
+		r.logger.Error("Failed to append password revision", zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 // GetPassword получает пароль из базы данных.
 func (r *PostgreSQLRepository) GetPassword(login, passName string) (string, error) {
 	var password string
-	query := `SELECT password FROM passwords WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2`
-	err := r.pool.QueryRow(context.Background(), query, login, passName).Scan(&password)
+	var legacy bool
+	query := `SELECT password, legacy FROM passwords
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL`
+	err := r.pool.QueryRow(context.Background(), query, login, passName).Scan(&password, &legacy)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return "", fmt.Errorf("password not found")
+			return "", domain.NotFound("password not found")
 		}
 		r.logger.Error("Failed to get password", zap.Error(err))
 		return "", err
 	}
+
+	password, err = r.cipher.decryptLegacy(context.Background(), login, passwordRecord(passName), password, legacy)
+	if err != nil {
+		r.logger.Error("Failed to decrypt password", zap.Error(err))
+		return "", err
+	}
 	return password, nil
 }
 
+// UpdatePassword изменяет пароль и, если задано новое название, переименовывает его.
+// Прежнее значение остается в истории ревизий.
+func (r *PostgreSQLRepository) UpdatePassword(login, passName, newPassName, password string) error {
+	ctx := context.Background()
+	encrypted, err := r.cipher.encrypt(ctx, login, passwordRecord(newPassName), password)
+	if err != nil {
+		r.logger.Error("Failed to encrypt password", zap.Error(err))
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if newPassName != passName {
+		taken, err := nameTaken(ctx, tx, "passwords", login, newPassName)
+		if err != nil {
+			r.logger.Error("Failed to check password name", zap.Error(err))
+			return err
+		}
+		if taken {
+			return domain.Conflict("password already exists")
+		}
+	}
+
+	otpValue, err := r.renamePasswordOTP(ctx, tx, login, passName, newPassName)
+	if err != nil {
+		return err
+	}
+
+	var passID int
+	query := `UPDATE passwords SET name = $3, password = $4, otp = $5, legacy = false
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL RETURNING id`
+	err = tx.QueryRow(ctx, query, login, passName, newPassName, encrypted, otpValue).Scan(&passID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.NotFound("password not found")
+		}
+		if isUniqueViolation(err) {
+			return domain.Conflict("password already exists")
+		}
+		r.logger.Error("Failed to update password", zap.Error(err))
+		return err
+	}
+	if err := appendRevision(ctx, tx, passwordHistory, passID, login); err != nil {
+		r.logger.Error("Failed to append password revision", zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DeletePassword перемещает пароль в корзину.
+func (r *PostgreSQLRepository) DeletePassword(login, passName string) error {
+	query := `UPDATE passwords SET deleted_at = now()
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL`
+	tag, err := r.pool.Exec(context.Background(), query, login, passName)
+	if err != nil {
+		r.logger.Error("Failed to delete password", zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NotFound("password not found")
+	}
+	return nil
+}
+
+// nameTaken проверяет, есть ли у пользователя запись с таким названием в указанной таблице.
+// Записи в корзине не учитываются.
+func nameTaken(ctx context.Context, tx pgx.Tx, table, login, name string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM ` + table + ` WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL)`
+	err := tx.QueryRow(ctx, query, login, name).Scan(&exists)
+	return exists, err
+}
+
 // GetPasswordNameList получает список имен паролей пользователя из базы данных.
 func (r *PostgreSQLRepository) GetPasswordNameList(login string) ([]string, error) {
-	query := `SELECT name FROM passwords WHERE id_user = (SELECT id FROM users WHERE login = $1)`
+	query := `SELECT name FROM passwords WHERE id_user = (SELECT id FROM users WHERE login = $1) AND deleted_at IS NULL`
 	rows, err := r.pool.Query(context.Background(), query, login)
 	if err != nil {
 		r.logger.Error("Failed to get password name list", zap.Error(err))
@@ -178,27 +524,27 @@ func (r *PostgreSQLRepository) GetPasswordNameList(login string) ([]string, erro
 	return passNames, nil
 }
 
-// CheckPinCode проверяет пин-код пользователя.
-func (r *PostgreSQLRepository) CheckPinCode(login, pin string) (bool, error) {
-	var valid bool
-	query := `SELECT EXISTS (SELECT * FROM users WHERE login = $1 AND pin = $2)`
-	err := r.pool.QueryRow(context.Background(), query, login, pin).Scan(&valid)
-	if err != nil {
-		r.logger.Error("Failed to check pin code", zap.Error(err))
-		return false, err
-	}
-	return valid, nil
-}
-
 // Create создает нового пользователя в базе данных.
 func (r *PostgreSQLRepository) Create(user *domain.User) error {
 	_, err := r.pool.Exec(
-		context.Background(), "INSERT INTO users (login, password, pin) VALUES ($1, $2, $3)", user.Login, user.Password,
-		user.Pin,
+		context.Background(), "INSERT INTO users (login, password, pin, salt) VALUES ($1, $2, $3, $4)", user.Login,
+		user.Password, user.Pin, user.Salt,
 	)
+	if isUniqueViolation(err) {
+		return domain.Conflict("user %q already exists", user.Login)
+	}
 	return err
 }
 
+// uniqueViolation - код ошибки PostgreSQL о нарушении ограничения уникальности.
+const uniqueViolation = "23505"
+
+// isUniqueViolation сообщает, нарушает ли запрос ограничение уникальности.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
 // GetByUsername возвращает пользователя из базы данных по его логину.
 func (r *PostgreSQLRepository) GetByUsername(username string) (*domain.User, error) {
 	row := r.pool.QueryRow(context.Background(), "SELECT password FROM users WHERE username = $1", username)
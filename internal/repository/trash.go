@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+// TrashPurger окончательно удаляет записи из корзины.
+type TrashPurger interface {
+	// PurgeTrash удаляет записи, перемещенные в корзину раньше указанного времени,
+	// вместе с их историей и возвращает количество удаленных записей.
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+}
+
+// listTrash возвращает записи пользователя из корзины, начиная с последней удаленной.
+func (r *PostgreSQLRepository) listTrash(ctx context.Context, h historyTable, login string) ([]domain.TrashItem, error) {
+	query := `SELECT id, name, deleted_at FROM ` + h.items + `
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC`
+	rows, err := r.pool.Query(ctx, query, login)
+	if err != nil {
+		r.logger.Error("Failed to get trash", zap.String("kind", h.kind), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []domain.TrashItem
+	for rows.Next() {
+		var item domain.TrashItem
+		if err := rows.Scan(&item.ID, &item.Name, &item.DeletedAt); err != nil {
+			r.logger.Error("Failed to scan trash row", zap.String("kind", h.kind), zap.Error(err))
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error in trash rows", zap.String("kind", h.kind), zap.Error(err))
+		return nil, err
+	}
+	return items, nil
+}
+
+// restoreFromTrash возвращает запись из корзины, если ее название не занято другой записью.
+func (r *PostgreSQLRepository) restoreFromTrash(ctx context.Context, h historyTable, login string, id int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var name string
+	query := `SELECT name FROM ` + h.items + `
+		WHERE id = $2 AND id_user = (SELECT id FROM users WHERE login = $1) AND deleted_at IS NOT NULL FOR UPDATE`
+	if err := tx.QueryRow(ctx, query, login, id).Scan(&name); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.NotFound("%s not found in trash", h.kind)
+		}
+		r.logger.Error("Failed to get trash item", zap.String("kind", h.kind), zap.Error(err))
+		return err
+	}
+
+	taken, err := nameTaken(ctx, tx, h.items, login, name)
+	if err != nil {
+		r.logger.Error("Failed to check item name", zap.String("kind", h.kind), zap.Error(err))
+		return err
+	}
+	if taken {
+		return domain.Conflict("%s already exists", h.kind)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE `+h.items+` SET deleted_at = NULL WHERE id = $1`, id); err != nil {
+		r.logger.Error("Failed to restore trash item", zap.String("kind", h.kind), zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetPasswordTrash возвращает пароли пользователя из корзины.
+func (r *PostgreSQLRepository) GetPasswordTrash(login string) ([]domain.TrashItem, error) {
+	return r.listTrash(context.Background(), passwordHistory, login)
+}
+
+// RestorePasswordFromTrash возвращает пароль из корзины.
+func (r *PostgreSQLRepository) RestorePasswordFromTrash(login string, id int) error {
+	return r.restoreFromTrash(context.Background(), passwordHistory, login, id)
+}
+
+// GetCardTrash возвращает карты пользователя из корзины.
+func (r *PostgreSQLRepository) GetCardTrash(login string) ([]domain.TrashItem, error) {
+	return r.listTrash(context.Background(), cardHistory, login)
+}
+
+// RestoreCardFromTrash возвращает карту из корзины.
+func (r *PostgreSQLRepository) RestoreCardFromTrash(login string, id int) error {
+	return r.restoreFromTrash(context.Background(), cardHistory, login, id)
+}
+
+// PurgeTrash окончательно удаляет пароли, карты, заметки и записи, перемещенные в корзину раньше указанного времени.
+// История ревизий удаляется каскадно.
+func (r *PostgreSQLRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	for _, h := range []historyTable{passwordHistory, cardHistory, noteHistory, recordHistory} {
+		tag, err := r.pool.Exec(ctx, `DELETE FROM `+h.items+` WHERE deleted_at < $1`, before)
+		if err != nil {
+			r.logger.Error("Failed to purge trash", zap.String("kind", h.kind), zap.Error(err))
+			return purged, err
+		}
+		purged += tag.RowsAffected()
+	}
+	return purged, nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+// GetSecondFactor возвращает состояние второго фактора пользователя с расшифрованными ключами.
+func (r *PostgreSQLRepository) GetSecondFactor(login string) (*domain.SecondFactor, error) {
+	ctx := context.Background()
+	var sf domain.SecondFactor
+	query := `SELECT totp_enabled, totp_key, totp_pending, totp_last_step, totp_failures, totp_locked_until
+		FROM users WHERE login = $1`
+	err := r.pool.QueryRow(ctx, query, login).Scan(
+		&sf.Enabled, &sf.Key, &sf.Pending, &sf.LastStep, &sf.Failures, &sf.LockedUntil,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.NotFound("user not found")
+		}
+		r.logger.Error("Failed to get second factor", zap.Error(err))
+		return nil, err
+	}
+
+	for _, key := range []*string{&sf.Key, &sf.Pending} {
+		if *key == "" {
+			continue
+		}
+		var err error
+		if *key, err = r.cipher.decrypt(ctx, login, secondFactorRecord, *key); err != nil {
+			r.logger.Error("Failed to decrypt second factor key", zap.Error(err))
+			return nil, err
+		}
+	}
+	return &sf, nil
+}
+
+// SetPendingSecondFactor сохраняет ключ TOTP, который еще нужно подтвердить кодом.
+// Повторное подключение заменяет прежний неподтвержденный ключ.
+func (r *PostgreSQLRepository) SetPendingSecondFactor(login, key string) error {
+	ctx := context.Background()
+	encrypted, err := r.cipher.encrypt(ctx, login, secondFactorRecord, key)
+	if err != nil {
+		r.logger.Error("Failed to encrypt second factor key", zap.Error(err))
+		return err
+	}
+
+	tag, err := r.pool.Exec(ctx, `UPDATE users SET totp_pending = $2 WHERE login = $1`, login, encrypted)
+	if err != nil {
+		r.logger.Error("Failed to set pending second factor", zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NotFound("user not found")
+	}
+	return nil
+}
+
+// EnableSecondFactor делает ожидающий ключ действующим и заменяет коды восстановления.
+// step - номер периода кода, которым подтверждено подключение: повторно этот код не принимается.
+func (r *PostgreSQLRepository) EnableSecondFactor(login string, step int64, recoveryHashes []string) error {
+	ctx := context.Background()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var userID int
+	query := `UPDATE users SET totp_enabled = true, totp_key = totp_pending, totp_pending = '', totp_last_step = $2
+		WHERE login = $1 AND totp_pending <> '' RETURNING id`
+	if err := tx.QueryRow(ctx, query, login, step).Scan(&userID); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.Conflict("second factor enrollment not started")
+		}
+		r.logger.Error("Failed to enable second factor", zap.Error(err))
+		return err
+	}
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryHashes); err != nil {
+		r.logger.Error("Failed to save recovery codes", zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DisableSecondFactor отключает второй фактор и удаляет ключи и коды восстановления.
+func (r *PostgreSQLRepository) DisableSecondFactor(login string) error {
+	ctx := context.Background()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var userID int
+	query := `UPDATE users SET totp_enabled = false, totp_key = '', totp_pending = '', totp_last_step = 0
+		WHERE login = $1 RETURNING id`
+	if err := tx.QueryRow(ctx, query, login).Scan(&userID); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.NotFound("user not found")
+		}
+		r.logger.Error("Failed to disable second factor", zap.Error(err))
+		return err
+	}
+	if err := replaceRecoveryCodes(ctx, tx, userID, nil); err != nil {
+		r.logger.Error("Failed to delete recovery codes", zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// UseSecondFactorStep отмечает период принятого кода TOTP.
+// Возвращает false, если код этого или более позднего периода уже принимался.
+func (r *PostgreSQLRepository) UseSecondFactorStep(login string, step int64) (bool, error) {
+	query := `UPDATE users SET totp_last_step = $2 WHERE login = $1 AND totp_enabled AND totp_last_step < $2`
+	tag, err := r.pool.Exec(context.Background(), query, login, step)
+	if err != nil {
+		r.logger.Error("Failed to use second factor step", zap.Error(err))
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ClaimSecondFactorAttempt засчитывает проверку кода второго фактора как неудачную до сверки кода:
+// увеличивает счетчик с failures до failures+1 и устанавливает блокировку до lockedUntil.
+// Возвращает false, если счетчик уже изменила параллельная проверка, - тогда состояние нужно прочитать заново.
+func (r *PostgreSQLRepository) ClaimSecondFactorAttempt(login string, failures int, lockedUntil time.Time) (bool, error) {
+	query := `UPDATE users SET totp_failures = $2 + 1, totp_locked_until = $3 WHERE login = $1 AND totp_failures = $2`
+	tag, err := r.pool.Exec(context.Background(), query, login, failures, lockedUntil)
+	if err != nil {
+		r.logger.Error("Failed to claim second factor attempt", zap.Error(err))
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ResetSecondFactorFailures сбрасывает счетчик неудачных проверок и блокировку второго фактора.
+func (r *PostgreSQLRepository) ResetSecondFactorFailures(login string) error {
+	query := `UPDATE users SET totp_failures = 0, totp_locked_until = 'epoch' WHERE login = $1`
+	if _, err := r.pool.Exec(context.Background(), query, login); err != nil {
+		r.logger.Error("Failed to reset second factor failures", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UseRecoveryCode удаляет код восстановления по его хешу.
+// Возвращает false, если такого кода нет или он уже использован.
+func (r *PostgreSQLRepository) UseRecoveryCode(login, codeHash string) (bool, error) {
+	query := `DELETE FROM recovery_codes WHERE id_user = (SELECT id FROM users WHERE login = $1) AND code_hash = $2`
+	tag, err := r.pool.Exec(context.Background(), query, login, codeHash)
+	if err != nil {
+		r.logger.Error("Failed to use recovery code", zap.Error(err))
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// replaceRecoveryCodes заменяет коды восстановления пользователя в транзакции.
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID int, hashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE id_user = $1`, userID); err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		if _, err := tx.Exec(ctx, `INSERT INTO recovery_codes (id_user, code_hash) VALUES ($1, $2)`, userID, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+// SetPasswordURLs заменяет адреса сайтов пароля и добавляет ревизию.
+func (r *PostgreSQLRepository) SetPasswordURLs(login, passName string, urls []domain.LoginURL) error {
+	if urls == nil {
+		urls = []domain.LoginURL{}
+	}
+	encoded, err := json.Marshal(urls)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var passID int
+	query := `UPDATE passwords SET urls = $3
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND name = $2 AND deleted_at IS NULL RETURNING id`
+	if err := tx.QueryRow(ctx, query, login, passName, encoded).Scan(&passID); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.NotFound("password not found")
+		}
+		r.logger.Error("Failed to update password urls", zap.Error(err))
+		return err
+	}
+	if err := appendRevision(ctx, tx, passwordHistory, passID, login); err != nil {
+		r.logger.Error("Failed to append password revision", zap.Error(err))
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetPasswordURLs возвращает адреса сайтов всех паролей пользователя, у которых они указаны.
+func (r *PostgreSQLRepository) GetPasswordURLs(login string) ([]domain.PasswordURLsData, error) {
+	query := `SELECT name, urls FROM passwords
+		WHERE id_user = (SELECT id FROM users WHERE login = $1) AND deleted_at IS NULL AND urls <> '[]'
+		ORDER BY name`
+	rows, err := r.pool.Query(context.Background(), query, login)
+	if err != nil {
+		r.logger.Error("Failed to get password urls", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var passwords []domain.PasswordURLsData
+	for rows.Next() {
+		var password domain.PasswordURLsData
+		var urls []byte
+		if err := rows.Scan(&password.PassName, &urls); err != nil {
+			r.logger.Error("Failed to scan password urls row", zap.Error(err))
+			return nil, err
+		}
+		if err := json.Unmarshal(urls, &password.URLs); err != nil {
+			return nil, fmt.Errorf("stored password urls are corrupted: %w", err)
+		}
+		passwords = append(passwords, password)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error in password urls rows", zap.Error(err))
+		return nil, err
+	}
+	return passwords, nil
+}
@@ -0,0 +1,158 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/egosha7/goph-keeper/internal/auth"
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/handlers"
+	"github.com/egosha7/goph-keeper/internal/ratelimit"
+	"github.com/egosha7/goph-keeper/internal/service"
+	"go.uber.org/zap"
+)
+
+// maxAccountPeek - сколько байт тела открытого запроса читается в поисках логина.
+const maxAccountPeek = 64 << 10
+
+// AuthMiddleware - это промежуточное ПО, определяющее пользователя по токену доступа.
+type AuthMiddleware struct {
+	Services *service.Service
+	Logger   *zap.Logger
+}
+
+// Apply - это метод, который применяет AuthMiddleware к следующему обработчику HTTP.
+// Логин владельца токена из заголовка Authorization сохраняется в контексте запроса.
+func (m *AuthMiddleware) Apply(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			// Ожидаем заголовок вида `Authorization: Bearer <token>`.
+			header := r.Header.Get("Authorization")
+			token, found := strings.CutPrefix(header, "Bearer ")
+			if !found || token == "" {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				handlers.WriteError(
+					w, http.StatusUnauthorized,
+					domain.ErrorResponse{Code: domain.CodeUnauthorized, Message: "Требуется токен доступа"},
+				)
+				return
+			}
+
+			login, err := m.Services.ParseAccessToken(token)
+			if err != nil {
+				m.Logger.Info("Недействительный токен доступа", zap.Error(err))
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				handlers.WriteError(
+					w, http.StatusUnauthorized,
+					domain.ErrorResponse{Code: domain.CodeUnauthorized, Message: "Недействительный токен доступа"},
+				)
+				return
+			}
+
+			// Передаем управление следующему обработчику с логином в контексте.
+			next.ServeHTTP(w, r.WithContext(auth.WithLogin(r.Context(), login)))
+		},
+	)
+}
+
+// RateLimitMiddleware - это промежуточное ПО, ограничивающее частоту запросов группы маршрутов
+// отдельно для каждого IP-адреса и для каждой учетной записи.
+type RateLimitMiddleware struct {
+	Limiter ratelimit.Limiter
+	Group   string           // Название группы маршрутов: у каждой группы свои корзины
+	IP      ratelimit.Policy // Ограничение для одного IP-адреса
+	Account ratelimit.Policy // Ограничение для одной учетной записи
+	Logger  *zap.Logger
+}
+
+// Apply - это метод, который применяет RateLimitMiddleware к следующему обработчику HTTP.
+// Учетная запись берется из контекста аутентифицированного запроса, а для открытых маршрутов -
+// из поля login тела запроса, поэтому подбор пароля ограничивается и при смене IP-адресов.
+func (m *RateLimitMiddleware) Apply(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if !m.allow(w, r, "ip:"+clientIP(r), m.IP) {
+				return
+			}
+			if m.Account.Enabled() {
+				if login := accountOf(r); login != "" && !m.allow(w, r, ratelimit.AccountKey(login), m.Account) {
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		},
+	)
+}
+
+// allow расходует маркер корзины и, если лимит исчерпан, отправляет статус Too Many Requests.
+// При недоступности хранилища состояния запрос пропускается, чтобы сбой не останавливал сервис.
+func (m *RateLimitMiddleware) allow(w http.ResponseWriter, r *http.Request, key string, policy ratelimit.Policy) bool {
+	ok, retryAfter, err := m.Limiter.Allow(r.Context(), m.Group+":"+key, policy)
+	if err != nil {
+		m.Logger.Warn("Ошибка хранилища ограничений частоты запросов", zap.Error(err))
+		return true
+	}
+	if ok {
+		return true
+	}
+
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	m.Logger.Info("Превышено ограничение частоты запросов", zap.String("group", m.Group), zap.String("key", key))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	handlers.WriteError(
+		w, http.StatusTooManyRequests,
+		domain.ErrorResponse{
+			Code:    domain.CodeTooManyRequests,
+			Message: "Слишком много запросов, повторите позже",
+			Details: map[string]string{"retryAfter": strconv.Itoa(seconds)},
+		},
+	)
+	return false
+}
+
+// clientIP возвращает IP-адрес клиента из адреса соединения.
+// Заголовкам прокси не доверяем: их может подставить сам клиент.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// accountOf возвращает логин, к которому относится запрос.
+// Прочитанная часть тела возвращается в запрос, чтобы обработчик получил его целиком.
+func accountOf(r *http.Request) string {
+	if login, ok := auth.LoginFromContext(r.Context()); ok {
+		return login
+	}
+	if r.Body == nil {
+		return ""
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxAccountPeek))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+	if err != nil {
+		return ""
+	}
+
+	var body struct {
+		Login string `json:"login"`
+	}
+	if json.Unmarshal(data, &body) != nil {
+		return ""
+	}
+	return body.Login
+}
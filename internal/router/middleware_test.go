@@ -0,0 +1,77 @@
+package routes
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/auth"
+	"github.com/egosha7/goph-keeper/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	m := RateLimitMiddleware{
+		Limiter: ratelimit.NewMemory(),
+		Group:   "auth",
+		IP:      ratelimit.Policy{Requests: 3, Per: time.Minute},
+		Account: ratelimit.Policy{Requests: 1, Per: time.Minute},
+		Logger:  zap.NewNop(),
+	}
+	var bodies []string
+	handler := m.Apply(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+			},
+		),
+	)
+
+	send := func(remoteAddr, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/auth", bytes.NewBufferString(body))
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// Обработчик получает тело целиком, хотя логин из него уже прочитан
+	w := send("10.0.0.1:5000", `{"login":"Egor","password":"parol"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{`{"login":"Egor","password":"parol"}`}, bodies)
+
+	// Учетная запись ограничивается и при смене IP-адреса
+	w = send("10.0.0.2:5000", `{"login":"Egor","password":"other"}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	// IP-адрес ограничивается независимо от логина
+	assert.Equal(t, http.StatusOK, send("10.0.0.1:5001", `{"login":"Ivan"}`).Code)
+	assert.Equal(t, http.StatusOK, send("10.0.0.1:5002", `{"login":"Anna"}`).Code)
+	assert.Equal(t, http.StatusTooManyRequests, send("10.0.0.1:5003", `{"login":"Olga"}`).Code)
+
+	// Длинный логин, например адрес почты, ограничивается так же, как короткий
+	long := strings.Repeat("a", 200) + "@example.com"
+	assert.Equal(t, http.StatusOK, send("10.0.0.3:5000", `{"login":"`+long+`"}`).Code)
+	assert.Equal(t, http.StatusTooManyRequests, send("10.0.0.4:5000", `{"login":"`+long+`"}`).Code)
+}
+
+func TestAccountOf(t *testing.T) {
+	req := httptest.NewRequest("POST", "/pincheck", bytes.NewBufferString(`{"login":"Ivan"}`))
+	req = req.WithContext(auth.WithLogin(req.Context(), "Egor"))
+	assert.Equal(t, "Egor", accountOf(req))
+
+	req = httptest.NewRequest("POST", "/auth/refresh", bytes.NewBufferString(`{"refreshToken":"r"}`))
+	assert.Equal(t, "", accountOf(req))
+	body, _ := io.ReadAll(req.Body)
+	assert.Equal(t, `{"refreshToken":"r"}`, string(body))
+
+	req = httptest.NewRequest("POST", "/auth", bytes.NewBufferString(`{"login":"Ivan"}`))
+	assert.Equal(t, "Ivan", accountOf(req))
+}
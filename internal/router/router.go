@@ -1,40 +1,21 @@
 package routes
 
 import (
-	"context"
 	"github.com/egosha7/goph-keeper/internal/compress"
 	"github.com/egosha7/goph-keeper/internal/config"
 	"github.com/egosha7/goph-keeper/internal/handlers"
-	"github.com/egosha7/goph-keeper/internal/repository"
+	"github.com/egosha7/goph-keeper/internal/ratelimit"
 	"github.com/egosha7/goph-keeper/internal/service"
 	"net/http"
 
 	"github.com/go-chi/chi"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"go.uber.org/zap"
 )
 
 // SetupRoutes настраивает и возвращает обработчик HTTP-маршрутов.
-func SetupRoutes(cfg *config.Config, conn *pgx.Conn, logger *zap.Logger) http.Handler {
-	// Парсинг конфигурации для пула подключений
-	config, err := pgxpool.ParseConfig(cfg.DataBase)
-	if err != nil {
-		logger.Error("Error parse config", zap.Error(err))
-	}
-
-	// Установка максимального количества соединений в пуле
-	config.MaxConns = 1000
-
-	// Создание пула подключений
-	pool, err := pgxpool.ConnectConfig(context.Background(), config)
-	if err != nil {
-		logger.Error("Error connect config", zap.Error(err))
-	}
-
-	// Создание хранилища
-	repo := repository.NewPostgreSQLRepository(pool, logger)
-	services := service.NewUserService(repo, logger)
+func SetupRoutes(
+	cfg *config.Config, services *service.Service, limiter ratelimit.Limiter, logger *zap.Logger,
+) http.Handler {
 	h := handlers.NewHandler(services, logger)
 
 	// Создание роутера
@@ -43,13 +24,27 @@ func SetupRoutes(cfg *config.Config, conn *pgx.Conn, logger *zap.Logger) http.Ha
 	// Middleware для сжатия ответа
 	gzipMiddleware := compress.GzipMiddleware{}
 
-	// Группа роутов
+	// Middleware для аутентификации по токену доступа
+	authMiddleware := AuthMiddleware{Services: services, Logger: logger}
+
+	// Ограничения частоты запросов для групп маршрутов
+	authLimit := RateLimitMiddleware{
+		Limiter: limiter, Group: "auth", IP: cfg.AuthRateIP, Account: cfg.AuthRateAccount, Logger: logger,
+	}
+	pinLimit := RateLimitMiddleware{
+		Limiter: limiter, Group: "pin", IP: cfg.PinRateIP, Account: cfg.PinRateAccount, Logger: logger,
+	}
+	apiLimit := RateLimitMiddleware{
+		Limiter: limiter, Group: "api", IP: cfg.APIRateIP, Account: cfg.APIRateAccount, Logger: logger,
+	}
+
+	// Группа открытых роутов
 	r.Group(
 		func(route chi.Router) {
 			route.Use(gzipMiddleware.Apply)
+			route.Use(authLimit.Apply)
 
 			// Регистрация обработчиков для различных маршрутов
-			route.Delete("/", func(w http.ResponseWriter, r *http.Request) {})
 			route.Post(
 				"/auth", func(w http.ResponseWriter, r *http.Request) {
 					h.AuthUser(w, r)
@@ -60,6 +55,46 @@ func SetupRoutes(cfg *config.Config, conn *pgx.Conn, logger *zap.Logger) http.Ha
 					h.RegisterUser(w, r)
 				},
 			)
+			route.Post(
+				"/auth/refresh", func(w http.ResponseWriter, r *http.Request) {
+					h.RefreshTokens(w, r)
+				},
+			)
+			route.Post(
+				"/auth/2fa", func(w http.ResponseWriter, r *http.Request) {
+					h.CompleteLoginHandler(w, r)
+				},
+			)
+		},
+	)
+
+	// Группа роутов, требующих аутентификации
+	r.Group(
+		func(route chi.Router) {
+			route.Use(gzipMiddleware.Apply)
+			route.Use(authMiddleware.Apply)
+			route.Use(apiLimit.Apply)
+
+			route.Post(
+				"/auth/salt", func(w http.ResponseWriter, r *http.Request) {
+					h.GetSaltHandler(w, r)
+				},
+			)
+			route.Post(
+				"/auth/2fa/enroll", func(w http.ResponseWriter, r *http.Request) {
+					h.EnrollSecondFactorHandler(w, r)
+				},
+			)
+			route.Post(
+				"/auth/2fa/confirm", func(w http.ResponseWriter, r *http.Request) {
+					h.ConfirmSecondFactorHandler(w, r)
+				},
+			)
+			route.Post(
+				"/auth/2fa/disable", func(w http.ResponseWriter, r *http.Request) {
+					h.DisableSecondFactorHandler(w, r)
+				},
+			)
 			route.Post(
 				"/pass/namelist", func(w http.ResponseWriter, r *http.Request) {
 					h.GetPasswordNameList(w, r)
@@ -70,7 +105,7 @@ func SetupRoutes(cfg *config.Config, conn *pgx.Conn, logger *zap.Logger) http.Ha
 					h.GetCardList(w, r)
 				},
 			)
-			route.Post(
+			route.With(pinLimit.Apply).Post(
 				"/pincheck", func(w http.ResponseWriter, r *http.Request) {
 					h.CheckPinCodeHandler(w, r)
 				},
@@ -95,6 +130,286 @@ func SetupRoutes(cfg *config.Config, conn *pgx.Conn, logger *zap.Logger) http.Ha
 					h.AddCardHandler(w, r)
 				},
 			)
+			route.Post(
+				"/password/update", func(w http.ResponseWriter, r *http.Request) {
+					h.UpdatePasswordHandler(w, r)
+				},
+			)
+			route.Post(
+				"/password/delete", func(w http.ResponseWriter, r *http.Request) {
+					h.DeletePasswordHandler(w, r)
+				},
+			)
+			route.Post(
+				"/card/update", func(w http.ResponseWriter, r *http.Request) {
+					h.UpdateCardHandler(w, r)
+				},
+			)
+			route.Post(
+				"/card/delete", func(w http.ResponseWriter, r *http.Request) {
+					h.DeleteCardHandler(w, r)
+				},
+			)
+			route.Post(
+				"/password/history", func(w http.ResponseWriter, r *http.Request) {
+					h.GetPasswordHistoryHandler(w, r)
+				},
+			)
+			route.Post(
+				"/password/revision", func(w http.ResponseWriter, r *http.Request) {
+					h.GetPasswordRevisionHandler(w, r)
+				},
+			)
+			route.Post(
+				"/password/restore", func(w http.ResponseWriter, r *http.Request) {
+					h.RestorePasswordHandler(w, r)
+				},
+			)
+			route.Post(
+				"/card/history", func(w http.ResponseWriter, r *http.Request) {
+					h.GetCardHistoryHandler(w, r)
+				},
+			)
+			route.Post(
+				"/card/revision", func(w http.ResponseWriter, r *http.Request) {
+					h.GetCardRevisionHandler(w, r)
+				},
+			)
+			route.Post(
+				"/card/restore", func(w http.ResponseWriter, r *http.Request) {
+					h.RestoreCardHandler(w, r)
+				},
+			)
+			route.Post(
+				"/password/trash", func(w http.ResponseWriter, r *http.Request) {
+					h.GetPasswordTrashHandler(w, r)
+				},
+			)
+			route.Post(
+				"/password/trash/restore", func(w http.ResponseWriter, r *http.Request) {
+					h.RestorePasswordFromTrashHandler(w, r)
+				},
+			)
+			route.Post(
+				"/card/trash", func(w http.ResponseWriter, r *http.Request) {
+					h.GetCardTrashHandler(w, r)
+				},
+			)
+			route.Post(
+				"/card/trash/restore", func(w http.ResponseWriter, r *http.Request) {
+					h.RestoreCardFromTrashHandler(w, r)
+				},
+			)
+			route.Post(
+				"/password/list", func(w http.ResponseWriter, r *http.Request) {
+					h.GetPasswordListHandler(w, r)
+				},
+			)
+			route.Post(
+				"/password/meta", func(w http.ResponseWriter, r *http.Request) {
+					h.SetPasswordMetaHandler(w, r)
+				},
+			)
+			route.Post(
+				"/password/urls", func(w http.ResponseWriter, r *http.Request) {
+					h.SetPasswordURLsHandler(w, r)
+				},
+			)
+			route.Post(
+				"/password/urls/list", func(w http.ResponseWriter, r *http.Request) {
+					h.GetPasswordURLsHandler(w, r)
+				},
+			)
+			route.Post(
+				"/password/match", func(w http.ResponseWriter, r *http.Request) {
+					h.MatchPasswordsHandler(w, r)
+				},
+			)
+			route.Post(
+				"/password/otp", func(w http.ResponseWriter, r *http.Request) {
+					h.SetPasswordOTPHandler(w, r)
+				},
+			)
+			route.Post(
+				"/password/entry", func(w http.ResponseWriter, r *http.Request) {
+					h.GetPasswordEntryHandler(w, r)
+				},
+			)
+			route.Post(
+				"/card/list", func(w http.ResponseWriter, r *http.Request) {
+					h.GetCardListHandler(w, r)
+				},
+			)
+			route.Post(
+				"/card/meta", func(w http.ResponseWriter, r *http.Request) {
+					h.SetCardMetaHandler(w, r)
+				},
+			)
+			route.Post(
+				"/search", func(w http.ResponseWriter, r *http.Request) {
+					h.SearchHandler(w, r)
+				},
+			)
+			route.Post(
+				"/index/set", func(w http.ResponseWriter, r *http.Request) {
+					h.SetBlindIndexHandler(w, r)
+				},
+			)
+			route.Get(
+				"/events", func(w http.ResponseWriter, r *http.Request) {
+					h.WatchChangesHandler(w, r)
+				},
+			)
+			route.Post(
+				"/note/namelist", func(w http.ResponseWriter, r *http.Request) {
+					h.GetNoteNameList(w, r)
+				},
+			)
+			route.Post(
+				"/note/get", func(w http.ResponseWriter, r *http.Request) {
+					h.GetNoteHandler(w, r)
+				},
+			)
+			route.Post(
+				"/note/add", func(w http.ResponseWriter, r *http.Request) {
+					h.AddNoteHandler(w, r)
+				},
+			)
+			route.Post(
+				"/note/update", func(w http.ResponseWriter, r *http.Request) {
+					h.UpdateNoteHandler(w, r)
+				},
+			)
+			route.Post(
+				"/note/delete", func(w http.ResponseWriter, r *http.Request) {
+					h.DeleteNoteHandler(w, r)
+				},
+			)
+			route.Post(
+				"/note/history", func(w http.ResponseWriter, r *http.Request) {
+					h.GetNoteHistoryHandler(w, r)
+				},
+			)
+			route.Post(
+				"/note/revision", func(w http.ResponseWriter, r *http.Request) {
+					h.GetNoteRevisionHandler(w, r)
+				},
+			)
+			route.Post(
+				"/note/restore", func(w http.ResponseWriter, r *http.Request) {
+					h.RestoreNoteHandler(w, r)
+				},
+			)
+			route.Post(
+				"/note/trash", func(w http.ResponseWriter, r *http.Request) {
+					h.GetNoteTrashHandler(w, r)
+				},
+			)
+			route.Post(
+				"/note/trash/restore", func(w http.ResponseWriter, r *http.Request) {
+					h.RestoreNoteFromTrashHandler(w, r)
+				},
+			)
+			route.Post(
+				"/file/namelist", func(w http.ResponseWriter, r *http.Request) {
+					h.GetFileNameList(w, r)
+				},
+			)
+			route.Post(
+				"/file/upload", func(w http.ResponseWriter, r *http.Request) {
+					h.StartUploadHandler(w, r)
+				},
+			)
+			route.Put(
+				"/file/chunk", func(w http.ResponseWriter, r *http.Request) {
+					h.UploadChunkHandler(w, r)
+				},
+			)
+			route.Post(
+				"/file/complete", func(w http.ResponseWriter, r *http.Request) {
+					h.CompleteUploadHandler(w, r)
+				},
+			)
+			route.Post(
+				"/file/info", func(w http.ResponseWriter, r *http.Request) {
+					h.GetFileInfoHandler(w, r)
+				},
+			)
+			route.Get(
+				"/file/chunk", func(w http.ResponseWriter, r *http.Request) {
+					h.DownloadChunkHandler(w, r)
+				},
+			)
+			route.Post(
+				"/file/delete", func(w http.ResponseWriter, r *http.Request) {
+					h.DeleteFileHandler(w, r)
+				},
+			)
+			route.Post(
+				"/template/list", func(w http.ResponseWriter, r *http.Request) {
+					h.GetTemplatesHandler(w, r)
+				},
+			)
+			route.Post(
+				"/template/add", func(w http.ResponseWriter, r *http.Request) {
+					h.AddTemplateHandler(w, r)
+				},
+			)
+			route.Post(
+				"/template/delete", func(w http.ResponseWriter, r *http.Request) {
+					h.DeleteTemplateHandler(w, r)
+				},
+			)
+			route.Post(
+				"/record/list", func(w http.ResponseWriter, r *http.Request) {
+					h.GetRecordList(w, r)
+				},
+			)
+			route.Post(
+				"/record/get", func(w http.ResponseWriter, r *http.Request) {
+					h.GetRecordHandler(w, r)
+				},
+			)
+			route.Post(
+				"/record/add", func(w http.ResponseWriter, r *http.Request) {
+					h.AddRecordHandler(w, r)
+				},
+			)
+			route.Post(
+				"/record/update", func(w http.ResponseWriter, r *http.Request) {
+					h.UpdateRecordHandler(w, r)
+				},
+			)
+			route.Post(
+				"/record/delete", func(w http.ResponseWriter, r *http.Request) {
+					h.DeleteRecordHandler(w, r)
+				},
+			)
+			route.Post(
+				"/record/history", func(w http.ResponseWriter, r *http.Request) {
+					h.GetRecordHistoryHandler(w, r)
+				},
+			)
+			route.Post(
+				"/record/revision", func(w http.ResponseWriter, r *http.Request) {
+					h.GetRecordRevisionHandler(w, r)
+				},
+			)
+			route.Post(
+				"/record/restore", func(w http.ResponseWriter, r *http.Request) {
+					h.RestoreRecordHandler(w, r)
+				},
+			)
+			route.Post(
+				"/record/trash", func(w http.ResponseWriter, r *http.Request) {
+					h.GetRecordTrashHandler(w, r)
+				},
+			)
+			route.Post(
+				"/record/trash/restore", func(w http.ResponseWriter, r *http.Request) {
+					h.RestoreRecordFromTrashHandler(w, r)
+				},
+			)
 		},
 	)
 
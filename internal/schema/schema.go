@@ -0,0 +1,228 @@
+// Package schema описывает шаблоны записей хранилища и проверяет записи на соответствие им.
+package schema
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/otp"
+)
+
+// DateLayout - формат значений полей типа date.
+const DateLayout = "2006-01-02"
+
+// templateName ограничивает названия пользовательских шаблонов: строчные латинские буквы, цифры и дефис.
+var templateName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// builtIn - встроенные шаблоны, доступные всем пользователям.
+var builtIn = []domain.Template{
+	{
+		Name:        "login",
+		Description: "Учетная запись на сайте или в сервисе",
+		Fields: []domain.FieldSpec{
+			{Name: "username", Type: domain.FieldText},
+			{Name: "password", Type: domain.FieldHidden, Required: true},
+			{Name: "url", Type: domain.FieldURL},
+			{Name: "otp", Type: domain.FieldOTPSeed},
+			{Name: "notes", Type: domain.FieldText, Multiline: true},
+		},
+	},
+	{
+		Name:        "card",
+		Description: "Банковская карта",
+		Fields: []domain.FieldSpec{
+			{Name: "cardholder", Type: domain.FieldText},
+			{Name: "number", Type: domain.FieldHidden, Required: true},
+			{Name: "expiry", Type: domain.FieldText, Required: true},
+			{Name: "cvv", Type: domain.FieldHidden},
+			{Name: "pin", Type: domain.FieldHidden},
+		},
+	},
+	{
+		Name:        "note",
+		Description: "Текстовая заметка",
+		Fields: []domain.FieldSpec{
+			{Name: "body", Type: domain.FieldHidden, Required: true, Multiline: true},
+		},
+	},
+	{
+		Name:        "ssh-key",
+		Description: "Ключ SSH",
+		Fields: []domain.FieldSpec{
+			{Name: "private-key", Type: domain.FieldHidden, Required: true, Multiline: true},
+			{Name: "public-key", Type: domain.FieldText, Multiline: true},
+			{Name: "passphrase", Type: domain.FieldHidden},
+			{Name: "comment", Type: domain.FieldText},
+		},
+	},
+	{
+		Name:        "otp",
+		Description: "Ключ одноразовых паролей (TOTP или HOTP)",
+		Fields: []domain.FieldSpec{
+			{Name: "secret", Type: domain.FieldOTPSeed, Required: true},
+			{Name: "notes", Type: domain.FieldText, Multiline: true},
+		},
+	},
+	{
+		Name:        "identity",
+		Description: "Личные данные и документы",
+		Fields: []domain.FieldSpec{
+			{Name: "full-name", Type: domain.FieldText, Required: true},
+			{Name: "birth-date", Type: domain.FieldDate},
+			{Name: "email", Type: domain.FieldEmail},
+			{Name: "phone", Type: domain.FieldText},
+			{Name: "address", Type: domain.FieldText, Multiline: true},
+			{Name: "document", Type: domain.FieldHidden},
+		},
+	},
+}
+
+// BuiltIn возвращает копию встроенных шаблонов.
+func BuiltIn() []domain.Template {
+	templates := make([]domain.Template, len(builtIn))
+	for i, t := range builtIn {
+		templates[i] = copyTemplate(t)
+	}
+	return templates
+}
+
+// LookupBuiltIn возвращает встроенный шаблон по названию.
+func LookupBuiltIn(name string) (domain.Template, bool) {
+	for _, t := range builtIn {
+		if t.Name == name {
+			return copyTemplate(t), true
+		}
+	}
+	return domain.Template{}, false
+}
+
+// KnownType сообщает, поддерживается ли тип поля.
+func KnownType(fieldType domain.FieldType) bool {
+	switch fieldType {
+	case domain.FieldText, domain.FieldHidden, domain.FieldURL, domain.FieldEmail, domain.FieldDate, domain.FieldOTPSeed:
+		return true
+	default:
+		return false
+	}
+}
+
+// ValidateTemplate проверяет пользовательский шаблон.
+// Название не должно совпадать со встроенным шаблоном, поля должны иметь уникальные названия и известные типы.
+func ValidateTemplate(t domain.Template) error {
+	if !templateName.MatchString(t.Name) {
+		return fmt.Errorf("invalid template name %q", t.Name)
+	}
+	if _, ok := LookupBuiltIn(t.Name); ok {
+		return fmt.Errorf("template %q is built in", t.Name)
+	}
+	if len(t.Fields) == 0 {
+		return fmt.Errorf("template %q has no fields", t.Name)
+	}
+
+	seen := make(map[string]bool, len(t.Fields))
+	for _, f := range t.Fields {
+		if err := checkField(f.Name, f.Type, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate проверяет, что запись соответствует шаблону своего типа.
+// Поля шаблона должны иметь объявленный тип, обязательные поля - непустое значение.
+// Поля, которых нет в шаблоне, считаются собственными полями пользователя и допускаются с любым известным типом.
+// Значения зашифрованы клиентом, поэтому их формат здесь не проверяется, см. ValidateValue.
+func Validate(t domain.Template, r domain.Record) error {
+	if r.Type != t.Name {
+		return fmt.Errorf("record type %q does not match template %q", r.Type, t.Name)
+	}
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("record name is empty")
+	}
+
+	fields := make(map[string]domain.Field, len(r.Fields))
+	seen := make(map[string]bool, len(r.Fields))
+	for _, f := range r.Fields {
+		if err := checkField(f.Name, f.Type, seen); err != nil {
+			return err
+		}
+		fields[f.Name] = f
+	}
+
+	for _, spec := range t.Fields {
+		f, ok := fields[spec.Name]
+		if ok && f.Type != spec.Type {
+			return fmt.Errorf("field %q must have type %q", spec.Name, spec.Type)
+		}
+		if spec.Required && f.Value == "" {
+			return fmt.Errorf("field %q is required", spec.Name)
+		}
+	}
+
+	for key := range r.Metadata {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("metadata key is empty")
+		}
+	}
+	return nil
+}
+
+// ValidateValue проверяет формат открытого значения поля указанного типа.
+// Вызывается клиентом перед шифрованием значения.
+func ValidateValue(fieldType domain.FieldType, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	switch fieldType {
+	case domain.FieldText, domain.FieldHidden:
+		return nil
+	case domain.FieldURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%q is not an absolute URL", value)
+		}
+	case domain.FieldEmail:
+		if _, err := mail.ParseAddress(value); err != nil {
+			return fmt.Errorf("%q is not an email address", value)
+		}
+	case domain.FieldDate:
+		if _, err := time.Parse(DateLayout, value); err != nil {
+			return fmt.Errorf("%q is not a date in format %s", value, DateLayout)
+		}
+	case domain.FieldOTPSeed:
+		if _, err := otp.Parse(value); err != nil {
+			return fmt.Errorf("%q is not an otpauth uri or base32 secret: %v", value, err)
+		}
+	default:
+		return fmt.Errorf("unknown field type %q", fieldType)
+	}
+	return nil
+}
+
+// checkField проверяет название и тип поля и запоминает название в seen.
+func checkField(name string, fieldType domain.FieldType, seen map[string]bool) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("field name is empty")
+	}
+	if seen[name] {
+		return fmt.Errorf("duplicate field %q", name)
+	}
+	if !KnownType(fieldType) {
+		return fmt.Errorf("unknown type %q of field %q", fieldType, name)
+	}
+	seen[name] = true
+	return nil
+}
+
+// copyTemplate копирует встроенный шаблон, чтобы вызывающий не мог изменить его поля.
+func copyTemplate(t domain.Template) domain.Template {
+	t.Fields = append([]domain.FieldSpec(nil), t.Fields...)
+	t.BuiltIn = true
+	return t
+}
@@ -0,0 +1,120 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltIn(t *testing.T) {
+	for _, tpl := range BuiltIn() {
+		assert.True(t, tpl.BuiltIn, tpl.Name)
+		assert.NotEmpty(t, tpl.Fields, tpl.Name)
+	}
+
+	login, ok := LookupBuiltIn("login")
+	require.True(t, ok)
+	login.Fields[0].Name = "changed"
+	login, _ = LookupBuiltIn("login")
+	assert.Equal(t, "username", login.Fields[0].Name)
+
+	_, ok = LookupBuiltIn("unknown")
+	assert.False(t, ok)
+}
+
+func TestValidateTemplate(t *testing.T) {
+	valid := domain.Template{Name: "wifi", Fields: []domain.FieldSpec{{Name: "ssid", Type: domain.FieldText}}}
+	assert.NoError(t, ValidateTemplate(valid))
+
+	testCases := []struct {
+		name     string
+		template domain.Template
+	}{
+		{name: "Built In Name", template: domain.Template{Name: "login", Fields: valid.Fields}},
+		{name: "Bad Name", template: domain.Template{Name: "Wi Fi", Fields: valid.Fields}},
+		{name: "No Fields", template: domain.Template{Name: "wifi"}},
+		{name: "Unknown Type", template: domain.Template{Name: "wifi", Fields: []domain.FieldSpec{{Name: "ssid", Type: "blob"}}}},
+		{
+			name: "Duplicate Field",
+			template: domain.Template{Name: "wifi", Fields: []domain.FieldSpec{
+				{Name: "ssid", Type: domain.FieldText}, {Name: "ssid", Type: domain.FieldHidden},
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				assert.Error(t, ValidateTemplate(tc.template))
+			},
+		)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	login, _ := LookupBuiltIn("login")
+
+	testCases := []struct {
+		name    string
+		record  domain.Record
+		wantErr bool
+	}{
+		{
+			name: "Valid With Custom Field",
+			record: domain.Record{Type: "login", Name: "mail", Fields: []domain.Field{
+				{Name: "password", Type: domain.FieldHidden, Value: "c2VjcmV0"},
+				{Name: "recovery", Type: domain.FieldHidden, Value: "cmVjb3Zlcnk="},
+			}},
+		},
+		{
+			name:    "Missing Required",
+			record:  domain.Record{Type: "login", Name: "mail", Fields: []domain.Field{{Name: "username", Type: domain.FieldText, Value: "dXNlcg=="}}},
+			wantErr: true,
+		},
+		{
+			name:    "Wrong Type",
+			record:  domain.Record{Type: "login", Name: "mail", Fields: []domain.Field{{Name: "password", Type: domain.FieldText, Value: "c2VjcmV0"}}},
+			wantErr: true,
+		},
+		{
+			name:    "Other Template",
+			record:  domain.Record{Type: "card", Name: "visa"},
+			wantErr: true,
+		},
+		{
+			name:    "Empty Name",
+			record:  domain.Record{Type: "login", Fields: []domain.Field{{Name: "password", Type: domain.FieldHidden, Value: "c2VjcmV0"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				err := Validate(login, tc.record)
+				if tc.wantErr {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+				}
+			},
+		)
+	}
+}
+
+func TestValidateValue(t *testing.T) {
+	assert.NoError(t, ValidateValue(domain.FieldURL, "https://example.com/login"))
+	assert.Error(t, ValidateValue(domain.FieldURL, "example.com"))
+	assert.NoError(t, ValidateValue(domain.FieldEmail, "user@example.com"))
+	assert.Error(t, ValidateValue(domain.FieldEmail, "user"))
+	assert.NoError(t, ValidateValue(domain.FieldDate, "1990-05-17"))
+	assert.Error(t, ValidateValue(domain.FieldDate, "17.05.1990"))
+	assert.NoError(t, ValidateValue(domain.FieldOTPSeed, "jbsw y3dp ehpk 3pxp"))
+	assert.Error(t, ValidateValue(domain.FieldOTPSeed, "not base32!"))
+	assert.NoError(t, ValidateValue(domain.FieldOTPSeed, "otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP&digits=8"))
+	assert.Error(t, ValidateValue(domain.FieldOTPSeed, "otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP&digits=10"))
+	assert.NoError(t, ValidateValue(domain.FieldHidden, "anything"))
+	assert.Error(t, ValidateValue("blob", "value"))
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/events"
+)
+
+// WatchChanges подписывает на изменения хранилища пользователя и возвращает события после курсора,
+// пропущенные клиентом. Подписку нужно закрыть после использования.
+func (s *UserServiceImpl) WatchChanges(login, cursor string) (*events.Subscription, []domain.ChangeEvent, error) {
+	if s.Events == nil {
+		return nil, nil, fmt.Errorf("change feed is disabled")
+	}
+	sub, backlog := s.Events.Subscribe(login, cursor)
+	return sub, backlog, nil
+}
+
+// publish сообщает подписчикам пользователя об изменении элемента хранилища.
+func (s *UserServiceImpl) publish(login string, event domain.ChangeEvent) {
+	if s.Events != nil {
+		s.Events.Publish(login, event)
+	}
+}
+
+// restoreFromTrash возвращает элемент из корзины и публикует событие с его названием,
+// которое можно узнать только до восстановления.
+func (s *UserServiceImpl) restoreFromTrash(
+	login, kind string, id int,
+	trash func(login string) ([]domain.TrashItem, error),
+	restore func(login string, id int) error,
+) error {
+	items, err := trash(login)
+	if err != nil {
+		return err
+	}
+	if err := restore(login, id); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.ID == id {
+			s.publish(login, domain.ChangeEvent{Action: domain.ActionCreated, Kind: kind, Name: item.Name})
+			break
+		}
+	}
+	return nil
+}
+
+// renamed возвращает новое название элемента, если оно отличается от прежнего.
+func renamed(name, newName string) string {
+	if newName == name {
+		return ""
+	}
+	return newName
+}
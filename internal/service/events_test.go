@@ -0,0 +1,43 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreFromTrash(t *testing.T) {
+	s := &UserServiceImpl{Events: events.NewBus(10)}
+	sub, _, err := s.WatchChanges("Egor", "")
+	require.NoError(t, err)
+	defer sub.Close()
+
+	trash := func(string) ([]domain.TrashItem, error) {
+		return []domain.TrashItem{{ID: 1, Name: "Email"}, {ID: 2, Name: "Bank"}}, nil
+	}
+	restored := func(string, int) error { return nil }
+	failed := func(string, int) error { return errors.New("not in trash") }
+
+	require.Error(t, s.restoreFromTrash("Egor", domain.KindPassword, 2, trash, failed))
+	require.NoError(t, s.restoreFromTrash("Egor", domain.KindPassword, 2, trash, restored))
+
+	// Неудачное восстановление событий не порождает
+	event := <-sub.Events()
+	assert.Equal(t, domain.ActionCreated, event.Action)
+	assert.Equal(t, domain.KindPassword, event.Kind)
+	assert.Equal(t, "Bank", event.Name)
+	assert.Empty(t, sub.Events())
+}
+
+func TestWatchChangesDisabled(t *testing.T) {
+	s := &UserServiceImpl{}
+	_, _, err := s.WatchChanges("Egor", "")
+	assert.Error(t, err)
+
+	// Без шины изменения сохраняются, но не публикуются
+	s.publish("Egor", domain.ChangeEvent{Action: domain.ActionDeleted})
+}
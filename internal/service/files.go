@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"go.uber.org/zap"
+)
+
+// chunkOverhead - запас сверх размера исходной части на заголовок конверта и тег аутентификации,
+// которыми клиент дополняет каждую часть при шифровании.
+const chunkOverhead = 1024
+
+var (
+	// ErrChunkTooLarge возвращается, если загружаемая часть больше допустимого размера.
+	ErrChunkTooLarge = domain.InvalidField("chunk", "chunk too large")
+	// ErrChecksumMismatch возвращается, если контрольная сумма загруженной части не совпала с заявленной.
+	// Часть могла повредиться при передаче, поэтому клиент отправляет ее повторно.
+	ErrChecksumMismatch = domain.InvalidField("checksum", "chunk checksum mismatch")
+)
+
+// StartUpload начинает загрузку файла или возобновляет незавершенную загрузку файла с тем же названием и размером.
+// Незавершенная загрузка с другим размером отменяется.
+func (s *UserServiceImpl) StartUpload(login, fileName string, size int64) (*domain.UploadSession, error) {
+	if fileName == "" {
+		return nil, domain.InvalidField("fileName", "empty file name")
+	}
+	if size < 0 {
+		return nil, domain.InvalidField("size", "invalid file size")
+	}
+
+	existing, err := s.Repository.FindFile(login, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.Complete {
+			return nil, domain.Conflict("file already exists")
+		}
+		if existing.Size == size {
+			return existing, nil
+		}
+		if err := s.removeFile(login, existing.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	info, err := s.Repository.CreateFile(login, fileName, size, s.ChunkSize, chunkCount(size, s.ChunkSize))
+	if err != nil {
+		return nil, err
+	}
+	return &domain.UploadSession{FileInfo: *info, Uploaded: []int{}}, nil
+}
+
+// UploadChunk сохраняет часть файла, проверяя ее размер и контрольную сумму SHA-256 при чтении.
+// Часть, не прошедшая проверку, в хранилище не попадает.
+func (s *UserServiceImpl) UploadChunk(login string, fileID, index int, checksum string, r io.Reader) error {
+	info, err := s.Repository.GetFileByID(login, fileID)
+	if err != nil {
+		return err
+	}
+	if info.Complete {
+		return domain.Conflict("upload already complete")
+	}
+	if index < 0 || index >= info.Chunks {
+		return domain.InvalidField("index", "invalid chunk index")
+	}
+	checksum = strings.ToLower(checksum)
+	if decoded, err := hex.DecodeString(checksum); err != nil || len(decoded) != sha256.Size {
+		return domain.InvalidField("checksum", "invalid chunk checksum")
+	}
+
+	verified := &verifyingReader{
+		r:     r,
+		hash:  sha256.New(),
+		limit: plainChunkSize(info, index) + chunkOverhead,
+		want:  checksum,
+	}
+	size, err := s.Blobs.Put(context.Background(), chunkKey(fileID, index), verified)
+	if err != nil {
+		return err
+	}
+	return s.Repository.SaveFileChunk(login, fileID, domain.FileChunk{Index: index, Size: size, Checksum: checksum})
+}
+
+// CompleteUpload завершает загрузку файла после получения всех частей.
+func (s *UserServiceImpl) CompleteUpload(login string, fileID int) error {
+	if err := s.Repository.CompleteFile(login, fileID); err != nil {
+		return err
+	}
+	if info, err := s.Repository.GetFileByID(login, fileID); err == nil {
+		s.publish(login, domain.ChangeEvent{Action: domain.ActionCreated, Kind: domain.KindFile, Name: info.FileName})
+	}
+	return nil
+}
+
+// GetFileInfo возвращает метаданные полностью загруженного файла.
+func (s *UserServiceImpl) GetFileInfo(login, fileName string) (*domain.FileInfo, error) {
+	existing, err := s.Repository.FindFile(login, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil || !existing.Complete {
+		return nil, domain.NotFound("file not found")
+	}
+	return &existing.FileInfo, nil
+}
+
+// OpenFileChunk открывает часть файла для потокового чтения.
+// Вызывающий должен закрыть возвращенный поток.
+func (s *UserServiceImpl) OpenFileChunk(login string, fileID, index int) (io.ReadCloser, *domain.FileChunk, error) {
+	chunk, err := s.Repository.GetFileChunk(login, fileID, index)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := s.Blobs.Get(context.Background(), chunkKey(fileID, index))
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, chunk, nil
+}
+
+// GetFileNameList возвращает список названий файлов для указанного пользователя.
+func (s *UserServiceImpl) GetFileNameList(login string) ([]string, error) {
+	// Здесь может быть ваша бизнес-логика
+	return s.Repository.GetFileNameList(login)
+}
+
+// DeleteFile удаляет файл вместе с содержимым.
+func (s *UserServiceImpl) DeleteFile(login, fileName string) error {
+	existing, err := s.Repository.FindFile(login, fileName)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return domain.NotFound("file not found")
+	}
+	if err := s.removeFile(login, existing.ID); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionDeleted, Kind: domain.KindFile, Name: fileName})
+	return nil
+}
+
+// removeFile удаляет запись о файле, а затем его части из хранилища.
+// Запись удаляется первой, чтобы сбой не оставил файл с недостающими частями.
+func (s *UserServiceImpl) removeFile(login string, fileID int) error {
+	info, err := s.Repository.DeleteFile(login, fileID)
+	if err != nil {
+		return err
+	}
+	for index := 0; index < info.Chunks; index++ {
+		if err := s.Blobs.Delete(context.Background(), chunkKey(fileID, index)); err != nil {
+			s.Logger.Warn("Failed to delete file chunk", zap.Int("file", fileID), zap.Int("chunk", index), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// chunkKey возвращает ключ части файла в хранилище объектов.
+func chunkKey(fileID, index int) string {
+	return fmt.Sprintf("files/%d/%d", fileID, index)
+}
+
+// chunkCount возвращает количество частей файла. Пустой файл состоит из одной пустой части,
+// чтобы клиент все равно получил шифротекст, подтверждающий целостность.
+func chunkCount(size int64, chunkSize int) int {
+	if size == 0 {
+		return 1
+	}
+	return int((size + int64(chunkSize) - 1) / int64(chunkSize))
+}
+
+// plainChunkSize возвращает размер исходной части файла с указанным номером.
+func plainChunkSize(info *domain.FileInfo, index int) int64 {
+	rest := info.Size - int64(index)*int64(info.ChunkSize)
+	if rest > int64(info.ChunkSize) {
+		return int64(info.ChunkSize)
+	}
+	return rest
+}
+
+// verifyingReader ограничивает размер потока и сверяет его SHA-256 с ожидаемой при достижении конца.
+type verifyingReader struct {
+	r     io.Reader
+	hash  hash.Hash
+	n     int64
+	limit int64
+	want  string
+}
+
+// Read читает данные, возвращая ошибку вместо io.EOF, если поток не прошел проверку.
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	v.n += int64(n)
+	v.hash.Write(p[:n])
+	if v.n > v.limit {
+		return n, ErrChunkTooLarge
+	}
+	if err == io.EOF && hex.EncodeToString(v.hash.Sum(nil)) != v.want {
+		return n, ErrChecksumMismatch
+	}
+	return n, err
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyingReader(t *testing.T) {
+	sum := sha256.Sum256([]byte("chunk data"))
+	checksum := hex.EncodeToString(sum[:])
+
+	testCases := []struct {
+		name  string
+		data  string
+		limit int64
+		want  string
+		err   error
+	}{
+		{name: "Valid", data: "chunk data", limit: 10, want: checksum},
+		{name: "Checksum Mismatch", data: "chunk dat4", limit: 10, want: checksum, err: ErrChecksumMismatch},
+		{name: "Too Large", data: "chunk data!", limit: 10, want: checksum, err: ErrChunkTooLarge},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				r := &verifyingReader{r: strings.NewReader(tc.data), hash: sha256.New(), limit: tc.limit, want: tc.want}
+				_, err := io.ReadAll(r)
+				assert.ErrorIs(t, err, tc.err)
+			},
+		)
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+)
+
+// SetPasswordMeta заменяет метаданные, теги и папку пароля.
+func (s *UserServiceImpl) SetPasswordMeta(login, passName string, meta domain.ItemMeta) error {
+	meta, err := normalizeMeta(meta)
+	if err != nil {
+		return err
+	}
+	if err := s.Repository.SetPasswordMeta(login, passName, meta); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionUpdated, Kind: domain.KindPassword, Name: passName})
+	return nil
+}
+
+// GetPasswordList возвращает пароли с метаданными, отфильтрованные по тегу и папке.
+func (s *UserServiceImpl) GetPasswordList(login string, filter domain.ItemFilter) ([]domain.ItemSummary, error) {
+	return s.Repository.GetPasswordList(login, normalizeFilter(filter))
+}
+
+// SetCardMeta заменяет метаданные, теги и папку карты.
+func (s *UserServiceImpl) SetCardMeta(login, cardName string, meta domain.ItemMeta) error {
+	meta, err := normalizeMeta(meta)
+	if err != nil {
+		return err
+	}
+	if err := s.Repository.SetCardMeta(login, cardName, meta); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionUpdated, Kind: domain.KindCard, Name: cardName})
+	return nil
+}
+
+// GetCardList возвращает карты с метаданными, отфильтрованные по тегу и папке.
+func (s *UserServiceImpl) GetCardList(login string, filter domain.ItemFilter) ([]domain.ItemSummary, error) {
+	return s.Repository.GetCardList(login, normalizeFilter(filter))
+}
+
+// normalizeMeta приводит метаданные к виду, в котором они хранятся:
+// ключи и значения без крайних пробелов, теги без повторов и по алфавиту, путь папки без пустых сегментов.
+func normalizeMeta(meta domain.ItemMeta) (domain.ItemMeta, error) {
+	metadata := make(map[string]string, len(meta.Metadata))
+	for key, value := range meta.Metadata {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return domain.ItemMeta{}, domain.InvalidField("metadata", "metadata key is empty")
+		}
+		metadata[key] = strings.TrimSpace(value)
+	}
+
+	seen := make(map[string]bool, len(meta.Tags))
+	tags := make([]string, 0, len(meta.Tags))
+	for _, tag := range meta.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	return domain.ItemMeta{Metadata: metadata, Tags: tags, Folder: normalizeFolder(meta.Folder)}, nil
+}
+
+// normalizeFilter приводит фильтр к виду хранимых тегов и папок.
+func normalizeFilter(filter domain.ItemFilter) domain.ItemFilter {
+	return domain.ItemFilter{Tag: strings.TrimSpace(filter.Tag), Folder: normalizeFolder(filter.Folder)}
+}
+
+// normalizeFolder убирает из пути папки пустые сегменты и крайние пробелы сегментов,
+// например " Работа // Проект/ " становится "Работа/Проект".
+func normalizeFolder(folder string) string {
+	var segments []string
+	for _, segment := range strings.Split(folder, "/") {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeMeta(t *testing.T) {
+	meta, err := normalizeMeta(domain.ItemMeta{
+		Metadata: map[string]string{" bank ": " Tinkoff "},
+		Tags:     []string{"work", " personal", "work", ""},
+		Folder:   " Работа // Проект/ ",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"bank": "Tinkoff"}, meta.Metadata)
+	assert.Equal(t, []string{"personal", "work"}, meta.Tags)
+	assert.Equal(t, "Работа/Проект", meta.Folder)
+
+	_, err = normalizeMeta(domain.ItemMeta{Metadata: map[string]string{" ": "value"}})
+	assert.Error(t, err)
+}
@@ -5,9 +5,11 @@
 package mock_service
 
 import (
-	"github.com/egosha7/goph-keeper/internal/domain"
+	io "io"
 	reflect "reflect"
 
+	domain "github.com/egosha7/goph-keeper/internal/domain"
+	events "github.com/egosha7/goph-keeper/internal/events"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -48,6 +50,20 @@ func (mr *MockServicesMockRecorder) AddCard(login, cardName, numberCard, expiryD
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCard", reflect.TypeOf((*MockServices)(nil).AddCard), login, cardName, numberCard, expiryDateCard, cvvCard)
 }
 
+// AddNote mocks base method.
+func (m *MockServices) AddNote(login, title, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddNote", login, title, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddNote indicates an expected call of AddNote.
+func (mr *MockServicesMockRecorder) AddNote(login, title, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNote", reflect.TypeOf((*MockServices)(nil).AddNote), login, title, body)
+}
+
 // AddPassword mocks base method.
 func (m *MockServices) AddPassword(login, passName, password string) error {
 	m.ctrl.T.Helper()
@@ -62,6 +78,34 @@ func (mr *MockServicesMockRecorder) AddPassword(login, passName, password interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPassword", reflect.TypeOf((*MockServices)(nil).AddPassword), login, passName, password)
 }
 
+// AddRecord mocks base method.
+func (m *MockServices) AddRecord(login string, record domain.Record) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRecord", login, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRecord indicates an expected call of AddRecord.
+func (mr *MockServicesMockRecorder) AddRecord(login, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRecord", reflect.TypeOf((*MockServices)(nil).AddRecord), login, record)
+}
+
+// AddTemplate mocks base method.
+func (m *MockServices) AddTemplate(login string, template domain.Template) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTemplate", login, template)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTemplate indicates an expected call of AddTemplate.
+func (mr *MockServicesMockRecorder) AddTemplate(login, template interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTemplate", reflect.TypeOf((*MockServices)(nil).AddTemplate), login, template)
+}
+
 // AuthenticateUser mocks base method.
 func (m *MockServices) AuthenticateUser(user *domain.User) error {
 	m.ctrl.T.Helper()
@@ -91,6 +135,163 @@ func (mr *MockServicesMockRecorder) CheckPinCode(login, pin interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPinCode", reflect.TypeOf((*MockServices)(nil).CheckPinCode), login, pin)
 }
 
+// CompleteLogin mocks base method.
+func (m *MockServices) CompleteLogin(challenge, code string) (*domain.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteLogin", challenge, code)
+	ret0, _ := ret[0].(*domain.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteLogin indicates an expected call of CompleteLogin.
+func (mr *MockServicesMockRecorder) CompleteLogin(challenge, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteLogin", reflect.TypeOf((*MockServices)(nil).CompleteLogin), challenge, code)
+}
+
+// CompleteUpload mocks base method.
+func (m *MockServices) CompleteUpload(login string, fileID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteUpload", login, fileID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteUpload indicates an expected call of CompleteUpload.
+func (mr *MockServicesMockRecorder) CompleteUpload(login, fileID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteUpload", reflect.TypeOf((*MockServices)(nil).CompleteUpload), login, fileID)
+}
+
+// ConfirmSecondFactor mocks base method.
+func (m *MockServices) ConfirmSecondFactor(login, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmSecondFactor", login, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmSecondFactor indicates an expected call of ConfirmSecondFactor.
+func (mr *MockServicesMockRecorder) ConfirmSecondFactor(login, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmSecondFactor", reflect.TypeOf((*MockServices)(nil).ConfirmSecondFactor), login, code)
+}
+
+// DeleteCard mocks base method.
+func (m *MockServices) DeleteCard(login, cardName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCard", login, cardName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCard indicates an expected call of DeleteCard.
+func (mr *MockServicesMockRecorder) DeleteCard(login, cardName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCard", reflect.TypeOf((*MockServices)(nil).DeleteCard), login, cardName)
+}
+
+// DeleteFile mocks base method.
+func (m *MockServices) DeleteFile(login, fileName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFile", login, fileName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFile indicates an expected call of DeleteFile.
+func (mr *MockServicesMockRecorder) DeleteFile(login, fileName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFile", reflect.TypeOf((*MockServices)(nil).DeleteFile), login, fileName)
+}
+
+// DeleteNote mocks base method.
+func (m *MockServices) DeleteNote(login, title string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNote", login, title)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNote indicates an expected call of DeleteNote.
+func (mr *MockServicesMockRecorder) DeleteNote(login, title interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNote", reflect.TypeOf((*MockServices)(nil).DeleteNote), login, title)
+}
+
+// DeletePassword mocks base method.
+func (m *MockServices) DeletePassword(login, passName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePassword", login, passName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePassword indicates an expected call of DeletePassword.
+func (mr *MockServicesMockRecorder) DeletePassword(login, passName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePassword", reflect.TypeOf((*MockServices)(nil).DeletePassword), login, passName)
+}
+
+// DeleteRecord mocks base method.
+func (m *MockServices) DeleteRecord(login, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecord", login, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecord indicates an expected call of DeleteRecord.
+func (mr *MockServicesMockRecorder) DeleteRecord(login, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecord", reflect.TypeOf((*MockServices)(nil).DeleteRecord), login, name)
+}
+
+// DeleteTemplate mocks base method.
+func (m *MockServices) DeleteTemplate(login, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTemplate", login, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTemplate indicates an expected call of DeleteTemplate.
+func (mr *MockServicesMockRecorder) DeleteTemplate(login, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTemplate", reflect.TypeOf((*MockServices)(nil).DeleteTemplate), login, name)
+}
+
+// DisableSecondFactor mocks base method.
+func (m *MockServices) DisableSecondFactor(login, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableSecondFactor", login, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableSecondFactor indicates an expected call of DisableSecondFactor.
+func (mr *MockServicesMockRecorder) DisableSecondFactor(login, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableSecondFactor", reflect.TypeOf((*MockServices)(nil).DisableSecondFactor), login, code)
+}
+
+// EnrollSecondFactor mocks base method.
+func (m *MockServices) EnrollSecondFactor(login string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollSecondFactor", login)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollSecondFactor indicates an expected call of EnrollSecondFactor.
+func (mr *MockServicesMockRecorder) EnrollSecondFactor(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollSecondFactor", reflect.TypeOf((*MockServices)(nil).EnrollSecondFactor), login)
+}
+
 // GetCard mocks base method.
 func (m *MockServices) GetCard(login, cardName string) (string, string, string, error) {
 	m.ctrl.T.Helper()
@@ -108,6 +309,36 @@ func (mr *MockServicesMockRecorder) GetCard(login, cardName interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCard", reflect.TypeOf((*MockServices)(nil).GetCard), login, cardName)
 }
 
+// GetCardHistory mocks base method.
+func (m *MockServices) GetCardHistory(login, cardName string) ([]domain.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardHistory", login, cardName)
+	ret0, _ := ret[0].([]domain.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCardHistory indicates an expected call of GetCardHistory.
+func (mr *MockServicesMockRecorder) GetCardHistory(login, cardName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardHistory", reflect.TypeOf((*MockServices)(nil).GetCardHistory), login, cardName)
+}
+
+// GetCardList mocks base method.
+func (m *MockServices) GetCardList(login string, filter domain.ItemFilter) ([]domain.ItemSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardList", login, filter)
+	ret0, _ := ret[0].([]domain.ItemSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCardList indicates an expected call of GetCardList.
+func (mr *MockServicesMockRecorder) GetCardList(login, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardList", reflect.TypeOf((*MockServices)(nil).GetCardList), login, filter)
+}
+
 // GetCardNameList mocks base method.
 func (m *MockServices) GetCardNameList(login string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +354,141 @@ func (mr *MockServicesMockRecorder) GetCardNameList(login interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardNameList", reflect.TypeOf((*MockServices)(nil).GetCardNameList), login)
 }
 
+// GetCardRevision mocks base method.
+func (m *MockServices) GetCardRevision(login, cardName string, revision int) (*domain.CardRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardRevision", login, cardName, revision)
+	ret0, _ := ret[0].(*domain.CardRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCardRevision indicates an expected call of GetCardRevision.
+func (mr *MockServicesMockRecorder) GetCardRevision(login, cardName, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardRevision", reflect.TypeOf((*MockServices)(nil).GetCardRevision), login, cardName, revision)
+}
+
+// GetCardTrash mocks base method.
+func (m *MockServices) GetCardTrash(login string) ([]domain.TrashItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardTrash", login)
+	ret0, _ := ret[0].([]domain.TrashItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCardTrash indicates an expected call of GetCardTrash.
+func (mr *MockServicesMockRecorder) GetCardTrash(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardTrash", reflect.TypeOf((*MockServices)(nil).GetCardTrash), login)
+}
+
+// GetFileInfo mocks base method.
+func (m *MockServices) GetFileInfo(login, fileName string) (*domain.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileInfo", login, fileName)
+	ret0, _ := ret[0].(*domain.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileInfo indicates an expected call of GetFileInfo.
+func (mr *MockServicesMockRecorder) GetFileInfo(login, fileName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileInfo", reflect.TypeOf((*MockServices)(nil).GetFileInfo), login, fileName)
+}
+
+// GetFileNameList mocks base method.
+func (m *MockServices) GetFileNameList(login string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileNameList", login)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileNameList indicates an expected call of GetFileNameList.
+func (mr *MockServicesMockRecorder) GetFileNameList(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileNameList", reflect.TypeOf((*MockServices)(nil).GetFileNameList), login)
+}
+
+// GetNote mocks base method.
+func (m *MockServices) GetNote(login, title string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNote", login, title)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNote indicates an expected call of GetNote.
+func (mr *MockServicesMockRecorder) GetNote(login, title interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNote", reflect.TypeOf((*MockServices)(nil).GetNote), login, title)
+}
+
+// GetNoteHistory mocks base method.
+func (m *MockServices) GetNoteHistory(login, title string) ([]domain.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNoteHistory", login, title)
+	ret0, _ := ret[0].([]domain.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNoteHistory indicates an expected call of GetNoteHistory.
+func (mr *MockServicesMockRecorder) GetNoteHistory(login, title interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNoteHistory", reflect.TypeOf((*MockServices)(nil).GetNoteHistory), login, title)
+}
+
+// GetNoteNameList mocks base method.
+func (m *MockServices) GetNoteNameList(login string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNoteNameList", login)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNoteNameList indicates an expected call of GetNoteNameList.
+func (mr *MockServicesMockRecorder) GetNoteNameList(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNoteNameList", reflect.TypeOf((*MockServices)(nil).GetNoteNameList), login)
+}
+
+// GetNoteRevision mocks base method.
+func (m *MockServices) GetNoteRevision(login, title string, revision int) (*domain.NoteRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNoteRevision", login, title, revision)
+	ret0, _ := ret[0].(*domain.NoteRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNoteRevision indicates an expected call of GetNoteRevision.
+func (mr *MockServicesMockRecorder) GetNoteRevision(login, title, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNoteRevision", reflect.TypeOf((*MockServices)(nil).GetNoteRevision), login, title, revision)
+}
+
+// GetNoteTrash mocks base method.
+func (m *MockServices) GetNoteTrash(login string) ([]domain.TrashItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNoteTrash", login)
+	ret0, _ := ret[0].([]domain.TrashItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNoteTrash indicates an expected call of GetNoteTrash.
+func (mr *MockServicesMockRecorder) GetNoteTrash(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNoteTrash", reflect.TypeOf((*MockServices)(nil).GetNoteTrash), login)
+}
+
 // GetPassword mocks base method.
 func (m *MockServices) GetPassword(login, passName string) (string, error) {
 	m.ctrl.T.Helper()
@@ -138,6 +504,51 @@ func (mr *MockServicesMockRecorder) GetPassword(login, passName interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPassword", reflect.TypeOf((*MockServices)(nil).GetPassword), login, passName)
 }
 
+// GetPasswordEntry mocks base method.
+func (m *MockServices) GetPasswordEntry(login, passName string) (*domain.PasswordEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordEntry", login, passName)
+	ret0, _ := ret[0].(*domain.PasswordEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordEntry indicates an expected call of GetPasswordEntry.
+func (mr *MockServicesMockRecorder) GetPasswordEntry(login, passName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordEntry", reflect.TypeOf((*MockServices)(nil).GetPasswordEntry), login, passName)
+}
+
+// GetPasswordHistory mocks base method.
+func (m *MockServices) GetPasswordHistory(login, passName string) ([]domain.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordHistory", login, passName)
+	ret0, _ := ret[0].([]domain.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordHistory indicates an expected call of GetPasswordHistory.
+func (mr *MockServicesMockRecorder) GetPasswordHistory(login, passName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordHistory", reflect.TypeOf((*MockServices)(nil).GetPasswordHistory), login, passName)
+}
+
+// GetPasswordList mocks base method.
+func (m *MockServices) GetPasswordList(login string, filter domain.ItemFilter) ([]domain.ItemSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordList", login, filter)
+	ret0, _ := ret[0].([]domain.ItemSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordList indicates an expected call of GetPasswordList.
+func (mr *MockServicesMockRecorder) GetPasswordList(login, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordList", reflect.TypeOf((*MockServices)(nil).GetPasswordList), login, filter)
+}
+
 // GetPasswordNameList mocks base method.
 func (m *MockServices) GetPasswordNameList(login string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -153,16 +564,585 @@ func (mr *MockServicesMockRecorder) GetPasswordNameList(login interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordNameList", reflect.TypeOf((*MockServices)(nil).GetPasswordNameList), login)
 }
 
-// RegisterUser mocks base method.
-func (m *MockServices) RegisterUser(user *domain.User) error {
+// GetPasswordRevision mocks base method.
+func (m *MockServices) GetPasswordRevision(login, passName string, revision int) (*domain.PasswordRevision, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RegisterUser", user)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetPasswordRevision", login, passName, revision)
+	ret0, _ := ret[0].(*domain.PasswordRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// RegisterUser indicates an expected call of RegisterUser.
-func (mr *MockServicesMockRecorder) RegisterUser(user interface{}) *gomock.Call {
+// GetPasswordRevision indicates an expected call of GetPasswordRevision.
+func (mr *MockServicesMockRecorder) GetPasswordRevision(login, passName, revision interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockServices)(nil).RegisterUser), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordRevision", reflect.TypeOf((*MockServices)(nil).GetPasswordRevision), login, passName, revision)
+}
+
+// GetPasswordTrash mocks base method.
+func (m *MockServices) GetPasswordTrash(login string) ([]domain.TrashItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordTrash", login)
+	ret0, _ := ret[0].([]domain.TrashItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordTrash indicates an expected call of GetPasswordTrash.
+func (mr *MockServicesMockRecorder) GetPasswordTrash(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordTrash", reflect.TypeOf((*MockServices)(nil).GetPasswordTrash), login)
+}
+
+// GetPasswordURLs mocks base method.
+func (m *MockServices) GetPasswordURLs(login string) ([]domain.PasswordURLsData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordURLs", login)
+	ret0, _ := ret[0].([]domain.PasswordURLsData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordURLs indicates an expected call of GetPasswordURLs.
+func (mr *MockServicesMockRecorder) GetPasswordURLs(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordURLs", reflect.TypeOf((*MockServices)(nil).GetPasswordURLs), login)
+}
+
+// GetRecord mocks base method.
+func (m *MockServices) GetRecord(login, name string) (*domain.Record, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecord", login, name)
+	ret0, _ := ret[0].(*domain.Record)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecord indicates an expected call of GetRecord.
+func (mr *MockServicesMockRecorder) GetRecord(login, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecord", reflect.TypeOf((*MockServices)(nil).GetRecord), login, name)
+}
+
+// GetRecordHistory mocks base method.
+func (m *MockServices) GetRecordHistory(login, name string) ([]domain.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecordHistory", login, name)
+	ret0, _ := ret[0].([]domain.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecordHistory indicates an expected call of GetRecordHistory.
+func (mr *MockServicesMockRecorder) GetRecordHistory(login, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecordHistory", reflect.TypeOf((*MockServices)(nil).GetRecordHistory), login, name)
+}
+
+// GetRecordList mocks base method.
+func (m *MockServices) GetRecordList(login, recordType string) ([]domain.RecordSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecordList", login, recordType)
+	ret0, _ := ret[0].([]domain.RecordSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecordList indicates an expected call of GetRecordList.
+func (mr *MockServicesMockRecorder) GetRecordList(login, recordType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecordList", reflect.TypeOf((*MockServices)(nil).GetRecordList), login, recordType)
+}
+
+// GetRecordRevision mocks base method.
+func (m *MockServices) GetRecordRevision(login, name string, revision int) (*domain.RecordRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecordRevision", login, name, revision)
+	ret0, _ := ret[0].(*domain.RecordRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecordRevision indicates an expected call of GetRecordRevision.
+func (mr *MockServicesMockRecorder) GetRecordRevision(login, name, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecordRevision", reflect.TypeOf((*MockServices)(nil).GetRecordRevision), login, name, revision)
+}
+
+// GetRecordTrash mocks base method.
+func (m *MockServices) GetRecordTrash(login string) ([]domain.TrashItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecordTrash", login)
+	ret0, _ := ret[0].([]domain.TrashItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecordTrash indicates an expected call of GetRecordTrash.
+func (mr *MockServicesMockRecorder) GetRecordTrash(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecordTrash", reflect.TypeOf((*MockServices)(nil).GetRecordTrash), login)
+}
+
+// GetSalt mocks base method.
+func (m *MockServices) GetSalt(login string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSalt", login)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSalt indicates an expected call of GetSalt.
+func (mr *MockServicesMockRecorder) GetSalt(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSalt", reflect.TypeOf((*MockServices)(nil).GetSalt), login)
+}
+
+// GetTemplates mocks base method.
+func (m *MockServices) GetTemplates(login string) ([]domain.Template, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplates", login)
+	ret0, _ := ret[0].([]domain.Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplates indicates an expected call of GetTemplates.
+func (mr *MockServicesMockRecorder) GetTemplates(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplates", reflect.TypeOf((*MockServices)(nil).GetTemplates), login)
+}
+
+// IssueTokens mocks base method.
+func (m *MockServices) IssueTokens(login string) (*domain.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueTokens", login)
+	ret0, _ := ret[0].(*domain.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueTokens indicates an expected call of IssueTokens.
+func (mr *MockServicesMockRecorder) IssueTokens(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueTokens", reflect.TypeOf((*MockServices)(nil).IssueTokens), login)
+}
+
+// IssueUnlock mocks base method.
+func (m *MockServices) IssueUnlock(login string) (*domain.UnlockGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueUnlock", login)
+	ret0, _ := ret[0].(*domain.UnlockGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueUnlock indicates an expected call of IssueUnlock.
+func (mr *MockServicesMockRecorder) IssueUnlock(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueUnlock", reflect.TypeOf((*MockServices)(nil).IssueUnlock), login)
+}
+
+// LoginChallenge mocks base method.
+func (m *MockServices) LoginChallenge(login string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginChallenge", login)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginChallenge indicates an expected call of LoginChallenge.
+func (mr *MockServicesMockRecorder) LoginChallenge(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginChallenge", reflect.TypeOf((*MockServices)(nil).LoginChallenge), login)
+}
+
+// MatchPasswords mocks base method.
+func (m *MockServices) MatchPasswords(login, page string) ([]domain.LoginMatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MatchPasswords", login, page)
+	ret0, _ := ret[0].([]domain.LoginMatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MatchPasswords indicates an expected call of MatchPasswords.
+func (mr *MockServicesMockRecorder) MatchPasswords(login, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MatchPasswords", reflect.TypeOf((*MockServices)(nil).MatchPasswords), login, page)
+}
+
+// OpenFileChunk mocks base method.
+func (m *MockServices) OpenFileChunk(login string, fileID, index int) (io.ReadCloser, *domain.FileChunk, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenFileChunk", login, fileID, index)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(*domain.FileChunk)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// OpenFileChunk indicates an expected call of OpenFileChunk.
+func (mr *MockServicesMockRecorder) OpenFileChunk(login, fileID, index interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenFileChunk", reflect.TypeOf((*MockServices)(nil).OpenFileChunk), login, fileID, index)
+}
+
+// ParseAccessToken mocks base method.
+func (m *MockServices) ParseAccessToken(accessToken string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseAccessToken", accessToken)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseAccessToken indicates an expected call of ParseAccessToken.
+func (mr *MockServicesMockRecorder) ParseAccessToken(accessToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseAccessToken", reflect.TypeOf((*MockServices)(nil).ParseAccessToken), accessToken)
+}
+
+// ParseUnlockToken mocks base method.
+func (m *MockServices) ParseUnlockToken(unlockToken string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseUnlockToken", unlockToken)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseUnlockToken indicates an expected call of ParseUnlockToken.
+func (mr *MockServicesMockRecorder) ParseUnlockToken(unlockToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseUnlockToken", reflect.TypeOf((*MockServices)(nil).ParseUnlockToken), unlockToken)
+}
+
+// RefreshTokens mocks base method.
+func (m *MockServices) RefreshTokens(refreshToken string) (*domain.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshTokens", refreshToken)
+	ret0, _ := ret[0].(*domain.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshTokens indicates an expected call of RefreshTokens.
+func (mr *MockServicesMockRecorder) RefreshTokens(refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTokens", reflect.TypeOf((*MockServices)(nil).RefreshTokens), refreshToken)
+}
+
+// RegisterUser mocks base method.
+func (m *MockServices) RegisterUser(user *domain.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterUser", user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterUser indicates an expected call of RegisterUser.
+func (mr *MockServicesMockRecorder) RegisterUser(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockServices)(nil).RegisterUser), user)
+}
+
+// RestoreCard mocks base method.
+func (m *MockServices) RestoreCard(login, cardName string, revision int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCard", login, cardName, revision)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreCard indicates an expected call of RestoreCard.
+func (mr *MockServicesMockRecorder) RestoreCard(login, cardName, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCard", reflect.TypeOf((*MockServices)(nil).RestoreCard), login, cardName, revision)
+}
+
+// RestoreCardFromTrash mocks base method.
+func (m *MockServices) RestoreCardFromTrash(login string, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCardFromTrash", login, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreCardFromTrash indicates an expected call of RestoreCardFromTrash.
+func (mr *MockServicesMockRecorder) RestoreCardFromTrash(login, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCardFromTrash", reflect.TypeOf((*MockServices)(nil).RestoreCardFromTrash), login, id)
+}
+
+// RestoreNote mocks base method.
+func (m *MockServices) RestoreNote(login, title string, revision int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreNote", login, title, revision)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreNote indicates an expected call of RestoreNote.
+func (mr *MockServicesMockRecorder) RestoreNote(login, title, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreNote", reflect.TypeOf((*MockServices)(nil).RestoreNote), login, title, revision)
+}
+
+// RestoreNoteFromTrash mocks base method.
+func (m *MockServices) RestoreNoteFromTrash(login string, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreNoteFromTrash", login, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreNoteFromTrash indicates an expected call of RestoreNoteFromTrash.
+func (mr *MockServicesMockRecorder) RestoreNoteFromTrash(login, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreNoteFromTrash", reflect.TypeOf((*MockServices)(nil).RestoreNoteFromTrash), login, id)
+}
+
+// RestorePassword mocks base method.
+func (m *MockServices) RestorePassword(login, passName string, revision int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestorePassword", login, passName, revision)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestorePassword indicates an expected call of RestorePassword.
+func (mr *MockServicesMockRecorder) RestorePassword(login, passName, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestorePassword", reflect.TypeOf((*MockServices)(nil).RestorePassword), login, passName, revision)
+}
+
+// RestorePasswordFromTrash mocks base method.
+func (m *MockServices) RestorePasswordFromTrash(login string, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestorePasswordFromTrash", login, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestorePasswordFromTrash indicates an expected call of RestorePasswordFromTrash.
+func (mr *MockServicesMockRecorder) RestorePasswordFromTrash(login, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestorePasswordFromTrash", reflect.TypeOf((*MockServices)(nil).RestorePasswordFromTrash), login, id)
+}
+
+// RestoreRecord mocks base method.
+func (m *MockServices) RestoreRecord(login, name string, revision int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreRecord", login, name, revision)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreRecord indicates an expected call of RestoreRecord.
+func (mr *MockServicesMockRecorder) RestoreRecord(login, name, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreRecord", reflect.TypeOf((*MockServices)(nil).RestoreRecord), login, name, revision)
+}
+
+// RestoreRecordFromTrash mocks base method.
+func (m *MockServices) RestoreRecordFromTrash(login string, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreRecordFromTrash", login, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreRecordFromTrash indicates an expected call of RestoreRecordFromTrash.
+func (mr *MockServicesMockRecorder) RestoreRecordFromTrash(login, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreRecordFromTrash", reflect.TypeOf((*MockServices)(nil).RestoreRecordFromTrash), login, id)
+}
+
+// SearchItems mocks base method.
+func (m *MockServices) SearchItems(login string, query domain.SearchQuery) (*domain.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchItems", login, query)
+	ret0, _ := ret[0].(*domain.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchItems indicates an expected call of SearchItems.
+func (mr *MockServicesMockRecorder) SearchItems(login, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchItems", reflect.TypeOf((*MockServices)(nil).SearchItems), login, query)
+}
+
+// SetBlindIndex mocks base method.
+func (m *MockServices) SetBlindIndex(login string, index domain.BlindIndexData) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBlindIndex", login, index)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBlindIndex indicates an expected call of SetBlindIndex.
+func (mr *MockServicesMockRecorder) SetBlindIndex(login, index interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBlindIndex", reflect.TypeOf((*MockServices)(nil).SetBlindIndex), login, index)
+}
+
+// SetCardMeta mocks base method.
+func (m *MockServices) SetCardMeta(login, cardName string, meta domain.ItemMeta) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCardMeta", login, cardName, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCardMeta indicates an expected call of SetCardMeta.
+func (mr *MockServicesMockRecorder) SetCardMeta(login, cardName, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCardMeta", reflect.TypeOf((*MockServices)(nil).SetCardMeta), login, cardName, meta)
+}
+
+// SetPasswordMeta mocks base method.
+func (m *MockServices) SetPasswordMeta(login, passName string, meta domain.ItemMeta) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPasswordMeta", login, passName, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPasswordMeta indicates an expected call of SetPasswordMeta.
+func (mr *MockServicesMockRecorder) SetPasswordMeta(login, passName, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPasswordMeta", reflect.TypeOf((*MockServices)(nil).SetPasswordMeta), login, passName, meta)
+}
+
+// SetPasswordOTP mocks base method.
+func (m *MockServices) SetPasswordOTP(login, passName, otp string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPasswordOTP", login, passName, otp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPasswordOTP indicates an expected call of SetPasswordOTP.
+func (mr *MockServicesMockRecorder) SetPasswordOTP(login, passName, otp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPasswordOTP", reflect.TypeOf((*MockServices)(nil).SetPasswordOTP), login, passName, otp)
+}
+
+// SetPasswordURLs mocks base method.
+func (m *MockServices) SetPasswordURLs(login, passName string, urls []domain.LoginURL) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPasswordURLs", login, passName, urls)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPasswordURLs indicates an expected call of SetPasswordURLs.
+func (mr *MockServicesMockRecorder) SetPasswordURLs(login, passName, urls interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPasswordURLs", reflect.TypeOf((*MockServices)(nil).SetPasswordURLs), login, passName, urls)
+}
+
+// StartUpload mocks base method.
+func (m *MockServices) StartUpload(login, fileName string, size int64) (*domain.UploadSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartUpload", login, fileName, size)
+	ret0, _ := ret[0].(*domain.UploadSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartUpload indicates an expected call of StartUpload.
+func (mr *MockServicesMockRecorder) StartUpload(login, fileName, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartUpload", reflect.TypeOf((*MockServices)(nil).StartUpload), login, fileName, size)
+}
+
+// UpdateCard mocks base method.
+func (m *MockServices) UpdateCard(login, cardName, newCardName, numberCard, expiryDateCard, cvvCard string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCard", login, cardName, newCardName, numberCard, expiryDateCard, cvvCard)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCard indicates an expected call of UpdateCard.
+func (mr *MockServicesMockRecorder) UpdateCard(login, cardName, newCardName, numberCard, expiryDateCard, cvvCard interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCard", reflect.TypeOf((*MockServices)(nil).UpdateCard), login, cardName, newCardName, numberCard, expiryDateCard, cvvCard)
+}
+
+// UpdateNote mocks base method.
+func (m *MockServices) UpdateNote(login, title, newTitle, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNote", login, title, newTitle, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNote indicates an expected call of UpdateNote.
+func (mr *MockServicesMockRecorder) UpdateNote(login, title, newTitle, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNote", reflect.TypeOf((*MockServices)(nil).UpdateNote), login, title, newTitle, body)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockServices) UpdatePassword(login, passName, newPassName, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", login, passName, newPassName, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockServicesMockRecorder) UpdatePassword(login, passName, newPassName, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockServices)(nil).UpdatePassword), login, passName, newPassName, password)
+}
+
+// UpdateRecord mocks base method.
+func (m *MockServices) UpdateRecord(login, name, newName string, fields []domain.Field, metadata map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRecord", login, name, newName, fields, metadata)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRecord indicates an expected call of UpdateRecord.
+func (mr *MockServicesMockRecorder) UpdateRecord(login, name, newName, fields, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRecord", reflect.TypeOf((*MockServices)(nil).UpdateRecord), login, name, newName, fields, metadata)
+}
+
+// UploadChunk mocks base method.
+func (m *MockServices) UploadChunk(login string, fileID, index int, checksum string, r io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadChunk", login, fileID, index, checksum, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UploadChunk indicates an expected call of UploadChunk.
+func (mr *MockServicesMockRecorder) UploadChunk(login, fileID, index, checksum, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadChunk", reflect.TypeOf((*MockServices)(nil).UploadChunk), login, fileID, index, checksum, r)
+}
+
+// WatchChanges mocks base method.
+func (m *MockServices) WatchChanges(login, cursor string) (*events.Subscription, []domain.ChangeEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchChanges", login, cursor)
+	ret0, _ := ret[0].(*events.Subscription)
+	ret1, _ := ret[1].([]domain.ChangeEvent)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// WatchChanges indicates an expected call of WatchChanges.
+func (mr *MockServicesMockRecorder) WatchChanges(login, cursor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchChanges", reflect.TypeOf((*MockServices)(nil).WatchChanges), login, cursor)
 }
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"github.com/egosha7/goph-keeper/internal/domain"
+)
+
+// maxOTPLength ограничивает размер зашифрованного ключа одноразовых паролей.
+const maxOTPLength = 4096
+
+// SetPasswordOTP привязывает к паролю зашифрованный клиентом ключ одноразовых паролей.
+// Пустой ключ отвязывает одноразовые пароли.
+func (s *UserServiceImpl) SetPasswordOTP(login, passName, otp string) error {
+	if len(otp) > maxOTPLength {
+		return domain.InvalidField("otp", "otp is too long")
+	}
+	if err := s.Repository.SetPasswordOTP(login, passName, otp); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionUpdated, Kind: domain.KindPassword, Name: passName})
+	return nil
+}
+
+// GetPasswordEntry возвращает пароль вместе с привязанным ключом одноразовых паролей.
+func (s *UserServiceImpl) GetPasswordEntry(login, passName string) (*domain.PasswordEntry, error) {
+	// Здесь может быть ваша бизнес-логика
+	return s.Repository.GetPasswordEntry(login, passName)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	pinFreeAttempts = 3                // Неудачных попыток без блокировки
+	pinBaseDelay    = 30 * time.Second // Блокировка после первой попытки сверх бесплатных
+	pinMaxDelay     = time.Hour        // Наибольшая блокировка
+	pinClaimRetries = 5                // Попыток засчитать проверку при параллельных запросах
+)
+
+// PinLockedError возвращается, если проверка пин-кода временно заблокирована после неудачных попыток.
+type PinLockedError struct {
+	Until time.Time // Время, после которого можно повторить попытку
+}
+
+// Error возвращает описание ошибки.
+func (e *PinLockedError) Error() string {
+	return fmt.Sprintf("pin check is locked until %s", e.Until.Format(time.RFC3339))
+}
+
+// Is относит ошибку к виду domain.ErrLocked.
+func (e *PinLockedError) Is(target error) bool {
+	return target == domain.ErrLocked
+}
+
+// CheckPinCode проверяет пин-код для указанного пользователя.
+// После нескольких неудачных попыток подряд проверка блокируется на время, растущее вдвое с каждой новой ошибкой;
+// во время блокировки пин-код не сверяется и возвращается PinLockedError.
+func (s *UserServiceImpl) CheckPinCode(login, pin string) (bool, error) {
+	state, until, err := s.claimPinAttempt(login)
+	if err != nil {
+		return false, err
+	}
+
+	if !matchPin(state.Hash, pin) {
+		if time.Now().Before(until) {
+			return false, &PinLockedError{Until: until}
+		}
+		return false, nil
+	}
+
+	if err := s.Repository.ResetPinFailures(login); err != nil {
+		return false, err
+	}
+	// Пин-коды, сохраненные до хеширования, заменяются хешем при первой успешной проверке
+	if !isPinHash(state.Hash) {
+		hash, err := hashPin(pin)
+		if err != nil {
+			return false, err
+		}
+		if err := s.Repository.SetPinHash(login, hash); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// claimPinAttempt засчитывает проверку как неудачную еще до сверки пин-кода и вместе со счетчиком
+// устанавливает блокировку, которая наступит при ошибке. Счетчик меняется сравнением с прочитанным значением,
+// поэтому параллельные запросы не могут сверить больше пин-кодов, чем разрешено до блокировки.
+// Возвращает прочитанное состояние и время блокировки на случай неверного пин-кода.
+func (s *UserServiceImpl) claimPinAttempt(login string) (*domain.PinState, time.Time, error) {
+	for i := 0; i < pinClaimRetries; i++ {
+		state, err := s.Repository.GetPinState(login)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		now := time.Now()
+		if now.Before(state.LockedUntil) {
+			return nil, time.Time{}, &PinLockedError{Until: state.LockedUntil}
+		}
+
+		until := state.LockedUntil
+		if delay := pinLockDelay(state.Failures + 1); delay > 0 {
+			until = now.Add(delay)
+		}
+		claimed, err := s.Repository.ClaimPinAttempt(login, state.Failures, until)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if claimed {
+			return state, until, nil
+		}
+	}
+	return nil, time.Time{}, domain.Conflict("too many concurrent pin checks")
+}
+
+// pinLockDelay возвращает время блокировки после failures неудачных попыток подряд.
+func pinLockDelay(failures int) time.Duration {
+	if failures < pinFreeAttempts {
+		return 0
+	}
+	delay := pinBaseDelay
+	for i := pinFreeAttempts; i < failures && delay < pinMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > pinMaxDelay {
+		delay = pinMaxDelay
+	}
+	return delay
+}
+
+// hashPin вычисляет медленный хеш пин-кода для хранения.
+func hashPin(pin string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// matchPin сверяет пин-код с сохраненным хешем или, для старых записей, с открытым значением.
+func matchPin(stored, pin string) bool {
+	if isPinHash(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(pin)) == nil
+	}
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(pin)) == 1
+}
+
+// isPinHash сообщает, является ли сохраненное значение хешем bcrypt.
+func isPinHash(stored string) bool {
+	return strings.HasPrefix(stored, "$2")
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pinRepository хранит состояние пин-кода одного пользователя в памяти
+// и меняет его так же, как условные запросы PostgreSQLRepository.
+type pinRepository struct {
+	repository.UserRepository
+
+	mu     sync.Mutex
+	state  domain.PinState
+	claims int
+}
+
+func (r *pinRepository) GetPinState(string) (*domain.PinState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state := r.state
+	return &state, nil
+}
+
+func (r *pinRepository) ClaimPinAttempt(_ string, failures int, lockedUntil time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.state.Failures != failures {
+		return false, nil
+	}
+	r.state.Failures, r.state.LockedUntil = failures+1, lockedUntil
+	r.claims++
+	return true, nil
+}
+
+func (r *pinRepository) ResetPinFailures(string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state.Failures, r.state.LockedUntil = 0, time.Unix(0, 0)
+	return nil
+}
+
+func TestPinLockDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), pinLockDelay(1))
+	assert.Equal(t, time.Duration(0), pinLockDelay(pinFreeAttempts-1))
+	assert.Equal(t, pinBaseDelay, pinLockDelay(pinFreeAttempts))
+	assert.Equal(t, 2*pinBaseDelay, pinLockDelay(pinFreeAttempts+1))
+	assert.Equal(t, 4*pinBaseDelay, pinLockDelay(pinFreeAttempts+2))
+	assert.Equal(t, pinMaxDelay, pinLockDelay(pinFreeAttempts+100))
+}
+
+func TestMatchPin(t *testing.T) {
+	hash, err := hashPin("1234")
+	require.NoError(t, err)
+	assert.True(t, isPinHash(hash))
+	assert.NotContains(t, hash, "1234")
+
+	assert.True(t, matchPin(hash, "1234"))
+	assert.False(t, matchPin(hash, "0000"))
+
+	// Пин-коды, сохраненные до хеширования
+	assert.False(t, isPinHash("1234"))
+	assert.True(t, matchPin("1234", "1234"))
+	assert.False(t, matchPin("1234", "12345"))
+}
+
+func TestCheckPinCode_ParallelFailures(t *testing.T) {
+	hash, err := hashPin("1234")
+	require.NoError(t, err)
+	repo := &pinRepository{state: domain.PinState{Hash: hash, LockedUntil: time.Unix(0, 0)}}
+	s := &UserServiceImpl{Repository: repo}
+
+	const attempts = 20
+	var (
+		wg      sync.WaitGroup
+		start   = make(chan struct{})
+		results = make(chan error, attempts)
+	)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			valid, err := s.CheckPinCode("Egor", "0000")
+			assert.False(t, valid)
+			results <- err
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(results)
+
+	// Параллельные запросы сверяют не больше пин-кодов, чем разрешено до блокировки
+	assert.Equal(t, pinFreeAttempts, repo.claims)
+	assert.Equal(t, pinFreeAttempts, repo.state.Failures)
+	assert.True(t, repo.state.LockedUntil.After(time.Now()))
+
+	var rejected int
+	for err := range results {
+		if err != nil {
+			assert.True(t, errors.Is(err, domain.ErrLocked) || errors.Is(err, domain.ErrConflict), err)
+			rejected++
+		}
+	}
+	assert.Equal(t, attempts-pinFreeAttempts+1, rejected)
+
+	// Верный пин-код во время блокировки не сверяется
+	valid, err := s.CheckPinCode("Egor", "1234")
+	assert.False(t, valid)
+	assert.ErrorIs(t, err, domain.ErrLocked)
+
+	repo.state.LockedUntil = time.Now().Add(-time.Second)
+	valid, err = s.CheckPinCode("Egor", "1234")
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, 0, repo.state.Failures)
+}
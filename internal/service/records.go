@@ -0,0 +1,140 @@
+package service
+
+import (
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/schema"
+)
+
+// GetTemplates возвращает встроенные шаблоны и шаблоны пользователя.
+func (s *UserServiceImpl) GetTemplates(login string) ([]domain.Template, error) {
+	templates, err := s.Repository.GetTemplates(login)
+	if err != nil {
+		return nil, err
+	}
+	return append(schema.BuiltIn(), templates...), nil
+}
+
+// AddTemplate добавляет пользовательский шаблон записей.
+func (s *UserServiceImpl) AddTemplate(login string, template domain.Template) error {
+	if err := schema.ValidateTemplate(template); err != nil {
+		return err
+	}
+	template.BuiltIn = false
+	return s.Repository.InsertTemplate(login, template)
+}
+
+// DeleteTemplate удаляет пользовательский шаблон. Встроенные шаблоны удалить нельзя.
+func (s *UserServiceImpl) DeleteTemplate(login, name string) error {
+	if _, ok := schema.LookupBuiltIn(name); ok {
+		return domain.Conflict("template %q is built in", name)
+	}
+	return s.Repository.DeleteTemplate(login, name)
+}
+
+// AddRecord добавляет запись, предварительно проверив ее по шаблону типа.
+func (s *UserServiceImpl) AddRecord(login string, record domain.Record) error {
+	if err := s.validateRecord(login, record); err != nil {
+		return err
+	}
+	if err := s.Repository.InsertRecord(login, record); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionCreated, Kind: domain.KindRecord, Name: record.Name})
+	return nil
+}
+
+// GetRecord возвращает запись по ее названию.
+func (s *UserServiceImpl) GetRecord(login, name string) (*domain.Record, error) {
+	// Здесь может быть ваша бизнес-логика
+	return s.Repository.GetRecord(login, name)
+}
+
+// GetRecordList возвращает список записей указанного типа или всех типов, если тип пустой.
+func (s *UserServiceImpl) GetRecordList(login, recordType string) ([]domain.RecordSummary, error) {
+	// Здесь может быть ваша бизнес-логика
+	return s.Repository.GetRecordList(login, recordType)
+}
+
+// UpdateRecord изменяет поля и метаданные записи. Пустое новое название оставляет прежнее.
+// Тип записи не меняется, новые поля проверяются по его шаблону.
+func (s *UserServiceImpl) UpdateRecord(login, name, newName string, fields []domain.Field, metadata map[string]string) error {
+	current, err := s.Repository.GetRecord(login, name)
+	if err != nil {
+		return err
+	}
+	if newName == "" {
+		newName = name
+	}
+
+	record := domain.Record{Type: current.Type, Name: newName, Fields: fields, Metadata: metadata}
+	if err := s.validateRecord(login, record); err != nil {
+		return err
+	}
+	if err := s.Repository.UpdateRecord(login, name, record); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionUpdated, Kind: domain.KindRecord, Name: name, NewName: renamed(name, newName)})
+	return nil
+}
+
+// DeleteRecord перемещает запись в корзину.
+func (s *UserServiceImpl) DeleteRecord(login, name string) error {
+	if err := s.Repository.DeleteRecord(login, name); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionDeleted, Kind: domain.KindRecord, Name: name})
+	return nil
+}
+
+// GetRecordHistory возвращает ревизии записи, начиная с последней.
+func (s *UserServiceImpl) GetRecordHistory(login, name string) ([]domain.Revision, error) {
+	// Здесь может быть ваша бизнес-логика
+	return s.Repository.GetRecordHistory(login, name)
+}
+
+// GetRecordRevision возвращает запись в указанной ревизии.
+func (s *UserServiceImpl) GetRecordRevision(login, name string, revision int) (*domain.RecordRevision, error) {
+	if revision < 1 {
+		return nil, domain.InvalidField("revision", "invalid revision")
+	}
+	return s.Repository.GetRecordRevision(login, name, revision)
+}
+
+// RestoreRecord делает указанную ревизию текущим значением записи.
+func (s *UserServiceImpl) RestoreRecord(login, name string, revision int) error {
+	if revision < 1 {
+		return domain.InvalidField("revision", "invalid revision")
+	}
+	if err := s.Repository.RestoreRecord(login, name, revision); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionUpdated, Kind: domain.KindRecord, Name: name})
+	return nil
+}
+
+// GetRecordTrash возвращает записи пользователя из корзины.
+func (s *UserServiceImpl) GetRecordTrash(login string) ([]domain.TrashItem, error) {
+	// Здесь может быть ваша бизнес-логика
+	return s.Repository.GetRecordTrash(login)
+}
+
+// RestoreRecordFromTrash возвращает запись из корзины.
+func (s *UserServiceImpl) RestoreRecordFromTrash(login string, id int) error {
+	return s.restoreFromTrash(login, domain.KindRecord, id, s.Repository.GetRecordTrash, s.Repository.RestoreRecordFromTrash)
+}
+
+// validateRecord находит шаблон типа записи среди встроенных и пользовательских и проверяет запись по нему.
+func (s *UserServiceImpl) validateRecord(login string, record domain.Record) error {
+	template, ok := schema.LookupBuiltIn(record.Type)
+	if !ok {
+		custom, err := s.Repository.GetTemplate(login, record.Type)
+		if err != nil {
+			return err
+		}
+		if custom == nil {
+			return domain.InvalidField("type", "unknown record type %q", record.Type)
+		}
+		template = *custom
+	}
+	return schema.Validate(template, record)
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/egosha7/goph-keeper/internal/blindindex"
+	"github.com/egosha7/goph-keeper/internal/domain"
+)
+
+// Размер страницы результатов поиска.
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// maxBlindTerms ограничивает количество термов слепого индекса одного поля или запроса.
+const maxBlindTerms = 256
+
+// SearchItems ищет элементы хранилища по названию, виду, типу, тегу и папке.
+func (s *UserServiceImpl) SearchItems(login string, query domain.SearchQuery) (*domain.SearchResult, error) {
+	query, err := normalizeSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return s.Repository.SearchItems(login, query)
+}
+
+// normalizeSearchQuery проверяет параметры поиска и подставляет значения по умолчанию.
+func normalizeSearchQuery(query domain.SearchQuery) (domain.SearchQuery, error) {
+	query.Query = strings.TrimSpace(query.Query)
+	query.Tag = strings.TrimSpace(query.Tag)
+	query.Folder = normalizeFolder(query.Folder)
+
+	switch query.Match {
+	case "":
+		query.Match = domain.MatchSubstring
+	case domain.MatchSubstring, domain.MatchPrefix:
+	default:
+		return query, domain.InvalidField("match", "unknown match mode %q", query.Match)
+	}
+
+	switch query.Sort {
+	case "":
+		query.Sort = domain.SortName
+	case domain.SortName, domain.SortNameDesc, domain.SortUpdated, domain.SortUpdatedDesc:
+	default:
+		return query, domain.InvalidField("sort", "unknown sort order %q", query.Sort)
+	}
+
+	switch {
+	case query.Limit < 0:
+		return query, domain.InvalidField("limit", "invalid limit")
+	case query.Limit == 0:
+		query.Limit = defaultSearchLimit
+	case query.Limit > maxSearchLimit:
+		query.Limit = maxSearchLimit
+	}
+
+	for _, kind := range query.Kinds {
+		if !knownKind(kind) {
+			return query, domain.InvalidField("kinds", "unknown item kind %q", kind)
+		}
+	}
+
+	terms, err := normalizeTerms(query.Terms)
+	if err != nil {
+		return query, err
+	}
+	query.Terms = terms
+	return query, nil
+}
+
+// SetBlindIndex заменяет термы слепого индекса названия или доменов элемента.
+func (s *UserServiceImpl) SetBlindIndex(login string, index domain.BlindIndexData) error {
+	if !knownKind(index.Kind) {
+		return domain.InvalidField("kind", "unknown item kind %q", index.Kind)
+	}
+	switch index.Field {
+	case "":
+		index.Field = domain.IndexName
+	case domain.IndexName, domain.IndexDomain:
+	default:
+		return domain.InvalidField("field", "unknown index field %q", index.Field)
+	}
+
+	terms, err := normalizeTerms(index.Terms)
+	if err != nil {
+		return err
+	}
+	return s.Repository.SetBlindIndex(login, index.Kind, index.Name, index.Field, terms)
+}
+
+// knownKind проверяет, что вид элемента хранилища известен.
+func knownKind(kind string) bool {
+	switch kind {
+	case domain.KindPassword, domain.KindCard, domain.KindNote, domain.KindFile, domain.KindRecord:
+		return true
+	}
+	return false
+}
+
+// normalizeTerms проверяет формат термов слепого индекса, убирает повторы и сортирует их.
+func normalizeTerms(terms []string) ([]string, error) {
+	if len(terms) > maxBlindTerms {
+		return nil, domain.InvalidField("terms", "too many index terms")
+	}
+	seen := make(map[string]bool, len(terms))
+	normalized := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if !blindindex.ValidTerm(term) {
+			return nil, domain.InvalidField("terms", "invalid index term %q", term)
+		}
+		if !seen[term] {
+			seen[term] = true
+			normalized = append(normalized, term)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/egosha7/goph-keeper/internal/blindindex"
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeSearchQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   domain.SearchQuery
+		want    domain.SearchQuery
+		wantErr bool
+	}{
+		{
+			name:  "Defaults",
+			query: domain.SearchQuery{Query: "  mail ", Folder: " Работа // Почта/"},
+			want: domain.SearchQuery{
+				Query:  "mail",
+				Match:  domain.MatchSubstring,
+				Folder: "Работа/Почта",
+				Sort:   domain.SortName,
+				Limit:  defaultSearchLimit,
+				Terms:  []string{},
+			},
+		},
+		{
+			name:  "Limit is capped",
+			query: domain.SearchQuery{Match: domain.MatchPrefix, Sort: domain.SortUpdatedDesc, Limit: 1000, Kinds: []string{domain.KindNote}},
+			want: domain.SearchQuery{
+				Match: domain.MatchPrefix,
+				Kinds: []string{domain.KindNote},
+				Sort:  domain.SortUpdatedDesc,
+				Limit: maxSearchLimit,
+				Terms: []string{},
+			},
+		},
+		{name: "Unknown match", query: domain.SearchQuery{Match: "regex"}, wantErr: true},
+		{name: "Unknown sort", query: domain.SearchQuery{Sort: "size"}, wantErr: true},
+		{name: "Negative limit", query: domain.SearchQuery{Limit: -1}, wantErr: true},
+		{name: "Unknown kind", query: domain.SearchQuery{Kinds: []string{"photo"}}, wantErr: true},
+		{name: "Invalid term", query: domain.SearchQuery{Terms: []string{"Gmail"}}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := normalizeSearchQuery(tc.query)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNormalizeTerms(t *testing.T) {
+	a := strings.Repeat("a", blindindex.TermLength)
+	b := strings.Repeat("b", blindindex.TermLength)
+
+	terms, err := normalizeTerms([]string{b, a, b})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{a, b}, terms)
+
+	_, err = normalizeTerms([]string{"not a term"})
+	assert.Error(t, err)
+
+	_, err = normalizeTerms(make([]string, maxBlindTerms+1))
+	assert.Error(t, err)
+}
@@ -1,10 +1,16 @@
 package service
 
 import (
+	"encoding/base64"
+	"github.com/egosha7/goph-keeper/internal/auth"
+	"github.com/egosha7/goph-keeper/internal/blob"
+	"github.com/egosha7/goph-keeper/internal/crypt"
 	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/events"
 	"github.com/egosha7/goph-keeper/internal/repository"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
+	"io"
 )
 
 // Services представляет сервис для работы с пользователями.
@@ -18,8 +24,75 @@ type Services interface {
 	GetCard(login, cardName string) (string, string, string, error)
 	GetPasswordNameList(login string) ([]string, error)
 	GetCardNameList(login string) ([]string, error)
+	UpdatePassword(login, passName, newPassName, password string) error
+	DeletePassword(login, passName string) error
+	UpdateCard(login, cardName, newCardName, numberCard, expiryDateCard, cvvCard string) error
+	DeleteCard(login, cardName string) error
+	GetPasswordHistory(login, passName string) ([]domain.Revision, error)
+	GetPasswordRevision(login, passName string, revision int) (*domain.PasswordRevision, error)
+	RestorePassword(login, passName string, revision int) error
+	GetCardHistory(login, cardName string) ([]domain.Revision, error)
+	GetCardRevision(login, cardName string, revision int) (*domain.CardRevision, error)
+	RestoreCard(login, cardName string, revision int) error
+	GetPasswordTrash(login string) ([]domain.TrashItem, error)
+	RestorePasswordFromTrash(login string, id int) error
+	GetCardTrash(login string) ([]domain.TrashItem, error)
+	RestoreCardFromTrash(login string, id int) error
+	AddNote(login, title, body string) error
+	GetNote(login, title string) (string, error)
+	GetNoteNameList(login string) ([]string, error)
+	UpdateNote(login, title, newTitle, body string) error
+	DeleteNote(login, title string) error
+	GetNoteHistory(login, title string) ([]domain.Revision, error)
+	GetNoteRevision(login, title string, revision int) (*domain.NoteRevision, error)
+	RestoreNote(login, title string, revision int) error
+	GetNoteTrash(login string) ([]domain.TrashItem, error)
+	RestoreNoteFromTrash(login string, id int) error
+	StartUpload(login, fileName string, size int64) (*domain.UploadSession, error)
+	UploadChunk(login string, fileID, index int, checksum string, r io.Reader) error
+	CompleteUpload(login string, fileID int) error
+	GetFileInfo(login, fileName string) (*domain.FileInfo, error)
+	OpenFileChunk(login string, fileID, index int) (io.ReadCloser, *domain.FileChunk, error)
+	GetFileNameList(login string) ([]string, error)
+	DeleteFile(login, fileName string) error
+	SetPasswordMeta(login, passName string, meta domain.ItemMeta) error
+	GetPasswordList(login string, filter domain.ItemFilter) ([]domain.ItemSummary, error)
+	SetCardMeta(login, cardName string, meta domain.ItemMeta) error
+	GetCardList(login string, filter domain.ItemFilter) ([]domain.ItemSummary, error)
+	SetPasswordURLs(login, passName string, urls []domain.LoginURL) error
+	SetPasswordOTP(login, passName, otp string) error
+	GetPasswordEntry(login, passName string) (*domain.PasswordEntry, error)
+	GetPasswordURLs(login string) ([]domain.PasswordURLsData, error)
+	MatchPasswords(login, page string) ([]domain.LoginMatch, error)
+	SearchItems(login string, query domain.SearchQuery) (*domain.SearchResult, error)
+	SetBlindIndex(login string, index domain.BlindIndexData) error
+	GetTemplates(login string) ([]domain.Template, error)
+	AddTemplate(login string, template domain.Template) error
+	DeleteTemplate(login, name string) error
+	AddRecord(login string, record domain.Record) error
+	GetRecord(login, name string) (*domain.Record, error)
+	GetRecordList(login, recordType string) ([]domain.RecordSummary, error)
+	UpdateRecord(login, name, newName string, fields []domain.Field, metadata map[string]string) error
+	DeleteRecord(login, name string) error
+	GetRecordHistory(login, name string) ([]domain.Revision, error)
+	GetRecordRevision(login, name string, revision int) (*domain.RecordRevision, error)
+	RestoreRecord(login, name string, revision int) error
+	GetRecordTrash(login string) ([]domain.TrashItem, error)
+	RestoreRecordFromTrash(login string, id int) error
 	RegisterUser(user *domain.User) error
 	AuthenticateUser(user *domain.User) error
+	GetSalt(login string) (string, error)
+	IssueTokens(login string) (*domain.Tokens, error)
+	RefreshTokens(refreshToken string) (*domain.Tokens, error)
+	ParseAccessToken(accessToken string) (string, error)
+	IssueUnlock(login string) (*domain.UnlockGrant, error)
+	ParseUnlockToken(unlockToken string) (string, error)
+	LoginChallenge(login string) (string, error)
+	CompleteLogin(challenge, code string) (*domain.Tokens, error)
+	EnrollSecondFactor(login string) (string, error)
+	ConfirmSecondFactor(login, code string) ([]string, error)
+	DisableSecondFactor(login, code string) error
+	WatchChanges(login, cursor string) (*events.Subscription, []domain.ChangeEvent, error)
 }
 
 type Service struct {
@@ -29,29 +102,37 @@ type Service struct {
 // UserServiceImpl представляет реализацию UserService.
 type UserServiceImpl struct {
 	Repository repository.UserRepository
+	Tokens     *auth.TokenManager
+	Blobs      blob.Store  // Хранилище содержимого файлов
+	Events     *events.Bus // Шина событий изменения хранилища
+	ChunkSize  int         // Размер части новых файлов в байтах
 	Logger     *zap.Logger
 }
 
 // NewUserService создает новый экземпляр UserService.
-func NewUserService(repository *repository.Repository, logger *zap.Logger) *Service {
+func NewUserService(
+	repository *repository.Repository, tokens *auth.TokenManager, blobs blob.Store, bus *events.Bus, chunkSize int,
+	logger *zap.Logger,
+) *Service {
 	return &Service{
 		Services: &UserServiceImpl{
 			Repository: repository,
+			Tokens:     tokens,
+			Blobs:      blobs,
+			Events:     bus,
+			ChunkSize:  chunkSize,
 			Logger:     logger,
 		},
 	}
 }
 
-// CheckPinCode проверяет пин-код для указанного пользователя.
-func (s *UserServiceImpl) CheckPinCode(login, pin string) (bool, error) {
-	// Здесь может быть ваша бизнес-логика
-	return s.Repository.CheckPinCode(login, pin)
-}
-
 // AddPassword добавляет новый пароль.
 func (s *UserServiceImpl) AddPassword(login, passName, password string) error {
-	// Здесь может быть ваша бизнес-логика
-	return s.Repository.InsertNewPassword(login, passName, password)
+	if err := s.Repository.InsertNewPassword(login, passName, password); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionCreated, Kind: domain.KindPassword, Name: passName})
+	return nil
 }
 
 // GetPassword возвращает пароль по его имени.
@@ -62,8 +143,11 @@ func (s *UserServiceImpl) GetPassword(login, passName string) (string, error) {
 
 // AddCard добавляет новую карту.
 func (s *UserServiceImpl) AddCard(login, cardName, numberCard, expiryDateCard, cvvCard string) error {
-	// Здесь может быть ваша бизнес-логика
-	return s.Repository.InsertNewCard(login, cardName, numberCard, expiryDateCard, cvvCard)
+	if err := s.Repository.InsertNewCard(login, cardName, numberCard, expiryDateCard, cvvCard); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionCreated, Kind: domain.KindCard, Name: cardName})
+	return nil
 }
 
 // GetCard возвращает информацию о карте по ее имени.
@@ -84,13 +168,220 @@ func (s *UserServiceImpl) GetCardNameList(login string) ([]string, error) {
 	return s.Repository.GetCardNameList(login)
 }
 
+// UpdatePassword изменяет пароль. Пустое новое название оставляет прежнее.
+func (s *UserServiceImpl) UpdatePassword(login, passName, newPassName, password string) error {
+	if newPassName == "" {
+		newPassName = passName
+	}
+	if err := s.Repository.UpdatePassword(login, passName, newPassName, password); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionUpdated, Kind: domain.KindPassword, Name: passName, NewName: renamed(passName, newPassName)})
+	return nil
+}
+
+// DeletePassword перемещает пароль в корзину.
+func (s *UserServiceImpl) DeletePassword(login, passName string) error {
+	if err := s.Repository.DeletePassword(login, passName); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionDeleted, Kind: domain.KindPassword, Name: passName})
+	return nil
+}
+
+// UpdateCard изменяет реквизиты карты. Пустое новое название оставляет прежнее.
+func (s *UserServiceImpl) UpdateCard(login, cardName, newCardName, numberCard, expiryDateCard, cvvCard string) error {
+	if newCardName == "" {
+		newCardName = cardName
+	}
+	if err := s.Repository.UpdateCard(login, cardName, newCardName, numberCard, expiryDateCard, cvvCard); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionUpdated, Kind: domain.KindCard, Name: cardName, NewName: renamed(cardName, newCardName)})
+	return nil
+}
+
+// DeleteCard перемещает карту в корзину.
+func (s *UserServiceImpl) DeleteCard(login, cardName string) error {
+	if err := s.Repository.DeleteCard(login, cardName); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionDeleted, Kind: domain.KindCard, Name: cardName})
+	return nil
+}
+
+// GetPasswordHistory возвращает ревизии пароля, начиная с последней.
+func (s *UserServiceImpl) GetPasswordHistory(login, passName string) ([]domain.Revision, error) {
+	// Здесь может быть ваша бизнес-логика
+	return s.Repository.GetPasswordHistory(login, passName)
+}
+
+// GetPasswordRevision возвращает пароль в указанной ревизии.
+func (s *UserServiceImpl) GetPasswordRevision(login, passName string, revision int) (*domain.PasswordRevision, error) {
+	if revision < 1 {
+		return nil, domain.InvalidField("revision", "invalid revision")
+	}
+	return s.Repository.GetPasswordRevision(login, passName, revision)
+}
+
+// RestorePassword делает указанную ревизию текущим значением пароля.
+func (s *UserServiceImpl) RestorePassword(login, passName string, revision int) error {
+	if revision < 1 {
+		return domain.InvalidField("revision", "invalid revision")
+	}
+	if err := s.Repository.RestorePassword(login, passName, revision); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionUpdated, Kind: domain.KindPassword, Name: passName})
+	return nil
+}
+
+// GetCardHistory возвращает ревизии карты, начиная с последней.
+func (s *UserServiceImpl) GetCardHistory(login, cardName string) ([]domain.Revision, error) {
+	// Здесь может быть ваша бизнес-логика
+	return s.Repository.GetCardHistory(login, cardName)
+}
+
+// GetCardRevision возвращает реквизиты карты в указанной ревизии.
+func (s *UserServiceImpl) GetCardRevision(login, cardName string, revision int) (*domain.CardRevision, error) {
+	if revision < 1 {
+		return nil, domain.InvalidField("revision", "invalid revision")
+	}
+	return s.Repository.GetCardRevision(login, cardName, revision)
+}
+
+// RestoreCard делает указанную ревизию текущим значением карты.
+func (s *UserServiceImpl) RestoreCard(login, cardName string, revision int) error {
+	if revision < 1 {
+		return domain.InvalidField("revision", "invalid revision")
+	}
+	if err := s.Repository.RestoreCard(login, cardName, revision); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionUpdated, Kind: domain.KindCard, Name: cardName})
+	return nil
+}
+
+// GetPasswordTrash возвращает пароли пользователя из корзины.
+func (s *UserServiceImpl) GetPasswordTrash(login string) ([]domain.TrashItem, error) {
+	// Здесь может быть ваша бизнес-логика
+	return s.Repository.GetPasswordTrash(login)
+}
+
+// RestorePasswordFromTrash возвращает пароль из корзины.
+func (s *UserServiceImpl) RestorePasswordFromTrash(login string, id int) error {
+	return s.restoreFromTrash(login, domain.KindPassword, id, s.Repository.GetPasswordTrash, s.Repository.RestorePasswordFromTrash)
+}
+
+// GetCardTrash возвращает карты пользователя из корзины.
+func (s *UserServiceImpl) GetCardTrash(login string) ([]domain.TrashItem, error) {
+	// Здесь может быть ваша бизнес-логика
+	return s.Repository.GetCardTrash(login)
+}
+
+// RestoreCardFromTrash возвращает карту из корзины.
+func (s *UserServiceImpl) RestoreCardFromTrash(login string, id int) error {
+	return s.restoreFromTrash(login, domain.KindCard, id, s.Repository.GetCardTrash, s.Repository.RestoreCardFromTrash)
+}
+
+// AddNote добавляет новую заметку.
+func (s *UserServiceImpl) AddNote(login, title, body string) error {
+	if title == "" {
+		return domain.InvalidField("title", "empty note title")
+	}
+	if err := s.Repository.InsertNewNote(login, title, body); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionCreated, Kind: domain.KindNote, Name: title})
+	return nil
+}
+
+// GetNote возвращает текст заметки по ее заголовку.
+func (s *UserServiceImpl) GetNote(login, title string) (string, error) {
+	// Здесь может быть ваша бизнес-логика
+	return s.Repository.GetNote(login, title)
+}
+
+// GetNoteNameList возвращает список заголовков заметок для указанного пользователя.
+func (s *UserServiceImpl) GetNoteNameList(login string) ([]string, error) {
+	// Здесь может быть ваша бизнес-логика
+	return s.Repository.GetNoteNameList(login)
+}
+
+// UpdateNote изменяет заметку. Пустой новый заголовок оставляет прежний.
+func (s *UserServiceImpl) UpdateNote(login, title, newTitle, body string) error {
+	if newTitle == "" {
+		newTitle = title
+	}
+	if err := s.Repository.UpdateNote(login, title, newTitle, body); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionUpdated, Kind: domain.KindNote, Name: title, NewName: renamed(title, newTitle)})
+	return nil
+}
+
+// DeleteNote перемещает заметку в корзину.
+func (s *UserServiceImpl) DeleteNote(login, title string) error {
+	if err := s.Repository.DeleteNote(login, title); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionDeleted, Kind: domain.KindNote, Name: title})
+	return nil
+}
+
+// GetNoteHistory возвращает ревизии заметки, начиная с последней.
+func (s *UserServiceImpl) GetNoteHistory(login, title string) ([]domain.Revision, error) {
+	// Здесь может быть ваша бизнес-логика
+	return s.Repository.GetNoteHistory(login, title)
+}
+
+// GetNoteRevision возвращает заметку в указанной ревизии.
+func (s *UserServiceImpl) GetNoteRevision(login, title string, revision int) (*domain.NoteRevision, error) {
+	if revision < 1 {
+		return nil, domain.InvalidField("revision", "invalid revision")
+	}
+	return s.Repository.GetNoteRevision(login, title, revision)
+}
+
+// RestoreNote делает указанную ревизию текущим значением заметки.
+func (s *UserServiceImpl) RestoreNote(login, title string, revision int) error {
+	if revision < 1 {
+		return domain.InvalidField("revision", "invalid revision")
+	}
+	if err := s.Repository.RestoreNote(login, title, revision); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionUpdated, Kind: domain.KindNote, Name: title})
+	return nil
+}
+
+// GetNoteTrash возвращает заметки пользователя из корзины.
+func (s *UserServiceImpl) GetNoteTrash(login string) ([]domain.TrashItem, error) {
+	// Здесь может быть ваша бизнес-логика
+	return s.Repository.GetNoteTrash(login)
+}
+
+// RestoreNoteFromTrash возвращает заметку из корзины.
+func (s *UserServiceImpl) RestoreNoteFromTrash(login string, id int) error {
+	return s.restoreFromTrash(login, domain.KindNote, id, s.Repository.GetNoteTrash, s.Repository.RestoreNoteFromTrash)
+}
+
 // RegisterUser регистрирует нового пользователя.
 func (s *UserServiceImpl) RegisterUser(user *domain.User) error {
+	// Без соли клиент не сможет получить мастер-ключ при следующем входе
+	salt, err := base64.StdEncoding.DecodeString(user.Salt)
+	if err != nil || len(salt) < crypt.SaltSize {
+		return domain.InvalidField("salt", "invalid salt")
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
 	user.Password = string(hashedPassword)
+	if user.Pin, err = hashPin(user.Pin); err != nil {
+		return err
+	}
 	return s.Repository.Create(user)
 }
 
@@ -100,5 +391,60 @@ func (s *UserServiceImpl) AuthenticateUser(user *domain.User) error {
 	if err != nil {
 		return err
 	}
-	return bcrypt.CompareHashAndPassword([]byte(storedUser), []byte(user.Password))
+	// Для неизвестного логина хеш пустой и сравнение тоже не проходит
+	if err := bcrypt.CompareHashAndPassword([]byte(storedUser), []byte(user.Password)); err != nil {
+		return domain.Unauthorized("invalid login or password")
+	}
+	return nil
+}
+
+// GetSalt возвращает соль мастер-ключа пользователя.
+func (s *UserServiceImpl) GetSalt(login string) (string, error) {
+	return s.Repository.GetSalt(login)
+}
+
+// IssueTokens выпускает пару токенов для аутентифицированного пользователя.
+func (s *UserServiceImpl) IssueTokens(login string) (*domain.Tokens, error) {
+	accessToken, refreshToken, err := s.Tokens.Issue(login)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Tokens{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// RefreshTokens выпускает новую пару токенов по действующему токену обновления.
+func (s *UserServiceImpl) RefreshTokens(refreshToken string) (*domain.Tokens, error) {
+	login, err := s.Tokens.ParseRefresh(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Пользователь мог быть удален после выдачи токена
+	exists, err := s.Repository.CheckUniqUser(login)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, auth.ErrInvalidToken
+	}
+	return s.IssueTokens(login)
+}
+
+// ParseAccessToken проверяет токен доступа и возвращает логин его владельца.
+func (s *UserServiceImpl) ParseAccessToken(accessToken string) (string, error) {
+	return s.Tokens.ParseAccess(accessToken)
+}
+
+// IssueUnlock выдает разрешение на чтение секретов после успешной проверки пин-кода.
+func (s *UserServiceImpl) IssueUnlock(login string) (*domain.UnlockGrant, error) {
+	token, expiresAt, err := s.Tokens.IssueUnlock(login, auth.ScopeSecrets)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.UnlockGrant{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// ParseUnlockToken проверяет токен разблокировки и возвращает логин его владельца.
+func (s *UserServiceImpl) ParseUnlockToken(unlockToken string) (string, error) {
+	return s.Tokens.ParseUnlock(unlockToken, auth.ScopeSecrets)
 }
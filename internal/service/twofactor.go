@@ -0,0 +1,243 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/otp"
+)
+
+const (
+	secondFactorIssuer = "GophKeeper" // Сервис в ключе TOTP, который показывает аутентификатор
+	secondFactorSkew   = 1            // Допуск расхождения часов в периодах TOTP
+	recoveryCodeCount  = 10           // Количество выдаваемых кодов восстановления
+	recoveryCodeSize   = 10           // Размер кода восстановления в байтах
+
+	secondFactorFreeAttempts = 5                // Неудачных проверок кода без блокировки
+	secondFactorLockout      = 15 * time.Minute // Блокировка после каждой проверки сверх бесплатных
+	secondFactorClaimRetries = 5                // Попыток засчитать проверку при параллельных запросах
+)
+
+// recoveryEncoding кодирует коды восстановления без выравнивания.
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// SecondFactorLockedError возвращается, если проверка кода второго фактора временно заблокирована
+// после неудачных попыток.
+type SecondFactorLockedError struct {
+	Until time.Time // Время, после которого можно повторить попытку
+}
+
+// Error возвращает описание ошибки.
+func (e *SecondFactorLockedError) Error() string {
+	return fmt.Sprintf("second factor check is locked until %s", e.Until.Format(time.RFC3339))
+}
+
+// Is относит ошибку к виду domain.ErrLocked.
+func (e *SecondFactorLockedError) Is(target error) bool {
+	return target == domain.ErrLocked
+}
+
+// LoginChallenge возвращает токен незавершенного входа, если у пользователя подключен второй фактор.
+// Без второго фактора возвращает пустую строку: токены можно выдавать сразу.
+func (s *UserServiceImpl) LoginChallenge(login string) (string, error) {
+	sf, err := s.Repository.GetSecondFactor(login)
+	if err != nil {
+		return "", err
+	}
+	if !sf.Enabled {
+		return "", nil
+	}
+	return s.Tokens.IssueChallenge(login)
+}
+
+// CompleteLogin завершает вход кодом TOTP или кодом восстановления и выпускает пару токенов.
+// Токен входа можно предъявлять повторно до истечения срока, поэтому перебор кодов ограничивается
+// счетчиком неудачных проверок пользователя, а не токеном.
+func (s *UserServiceImpl) CompleteLogin(challenge, code string) (*domain.Tokens, error) {
+	login, err := s.Tokens.ParseChallenge(challenge)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifySecondFactor(login, code); err != nil {
+		// При входе неверный код означает неудачную аутентификацию, а не ошибку в запросе
+		if errors.Is(err, domain.ErrValidation) {
+			return nil, domain.Unauthorized("%v", err)
+		}
+		return nil, err
+	}
+	return s.IssueTokens(login)
+}
+
+// EnrollSecondFactor выпускает новый ключ TOTP и возвращает его в формате otpauth://.
+// Ключ начинает действовать после подтверждения кодом из аутентификатора.
+func (s *UserServiceImpl) EnrollSecondFactor(login string) (string, error) {
+	sf, err := s.Repository.GetSecondFactor(login)
+	if err != nil {
+		return "", err
+	}
+	if sf.Enabled {
+		return "", domain.Conflict("second factor is already enabled")
+	}
+
+	key, err := otp.NewTOTP(secondFactorIssuer, login)
+	if err != nil {
+		return "", err
+	}
+	uri := key.URI()
+	if err := s.Repository.SetPendingSecondFactor(login, uri); err != nil {
+		return "", err
+	}
+	return uri, nil
+}
+
+// ConfirmSecondFactor подключает второй фактор, если код соответствует выданному ключу,
+// и возвращает коды восстановления. Сервер хранит только их хеши, поэтому показать коды повторно нельзя.
+func (s *UserServiceImpl) ConfirmSecondFactor(login, code string) ([]string, error) {
+	sf, err := s.Repository.GetSecondFactor(login)
+	if err != nil {
+		return nil, err
+	}
+	if sf.Pending == "" {
+		return nil, domain.Conflict("second factor enrollment not started")
+	}
+	key, err := otp.Parse(sf.Pending)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := key.Verify(normalizeCode(code), time.Now(), secondFactorSkew)
+	if !ok {
+		return nil, domain.InvalidField("code", "invalid second factor code")
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = newRecoveryCode(); err != nil {
+			return nil, err
+		}
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	if err := s.Repository.EnableSecondFactor(login, int64(step), hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableSecondFactor отключает второй фактор после проверки кода TOTP или кода восстановления.
+func (s *UserServiceImpl) DisableSecondFactor(login, code string) error {
+	if err := s.verifySecondFactor(login, code); err != nil {
+		return err
+	}
+	return s.Repository.DisableSecondFactor(login)
+}
+
+// verifySecondFactor проверяет код TOTP, а если он не подошел - код восстановления.
+// Каждый код принимается только один раз. После нескольких неудачных проверок подряд проверка блокируется,
+// и во время блокировки код не сверяется, а возвращается SecondFactorLockedError.
+func (s *UserServiceImpl) verifySecondFactor(login, code string) error {
+	sf, until, err := s.claimSecondFactorAttempt(login)
+	if err != nil {
+		return err
+	}
+	if err := s.matchSecondFactor(login, sf, code); err != nil {
+		if errors.Is(err, domain.ErrValidation) && time.Now().Before(until) {
+			return &SecondFactorLockedError{Until: until}
+		}
+		return err
+	}
+	return s.Repository.ResetSecondFactorFailures(login)
+}
+
+// claimSecondFactorAttempt засчитывает проверку как неудачную еще до сверки кода и вместе со счетчиком
+// устанавливает блокировку, которая наступит при ошибке, - так же, как claimPinAttempt.
+// Возвращает прочитанное состояние и время блокировки на случай неверного кода.
+func (s *UserServiceImpl) claimSecondFactorAttempt(login string) (*domain.SecondFactor, time.Time, error) {
+	for i := 0; i < secondFactorClaimRetries; i++ {
+		sf, err := s.Repository.GetSecondFactor(login)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if !sf.Enabled {
+			return nil, time.Time{}, domain.Conflict("second factor is not enabled")
+		}
+		now := time.Now()
+		if now.Before(sf.LockedUntil) {
+			return nil, time.Time{}, &SecondFactorLockedError{Until: sf.LockedUntil}
+		}
+
+		until := sf.LockedUntil
+		if sf.Failures+1 >= secondFactorFreeAttempts {
+			until = now.Add(secondFactorLockout)
+		}
+		claimed, err := s.Repository.ClaimSecondFactorAttempt(login, sf.Failures, until)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if claimed {
+			return sf, until, nil
+		}
+	}
+	return nil, time.Time{}, domain.Conflict("too many concurrent second factor checks")
+}
+
+// matchSecondFactor сверяет код TOTP, а если он не подошел - код восстановления, и отмечает принятый код.
+func (s *UserServiceImpl) matchSecondFactor(login string, sf *domain.SecondFactor, code string) error {
+	key, err := otp.Parse(sf.Key)
+	if err != nil {
+		return err
+	}
+	code = normalizeCode(code)
+	if step, ok := key.Verify(code, time.Now(), secondFactorSkew); ok {
+		used, err := s.Repository.UseSecondFactorStep(login, int64(step))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return domain.InvalidField("code", "second factor code has already been used")
+		}
+		return nil
+	}
+
+	used, err := s.Repository.UseRecoveryCode(login, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return domain.InvalidField("code", "invalid second factor code")
+	}
+	return nil
+}
+
+// newRecoveryCode генерирует случайный код восстановления вида XXXX-XXXX-XXXX-XXXX.
+func newRecoveryCode() (string, error) {
+	b := make([]byte, recoveryCodeSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := recoveryEncoding.EncodeToString(b)
+	var groups []string
+	for len(code) > 4 {
+		groups = append(groups, code[:4])
+		code = code[4:]
+	}
+	return strings.Join(append(groups, code), "-"), nil
+}
+
+// normalizeCode убирает из введенного кода пробелы и дефисы и приводит его к верхнему регистру.
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+// hashRecoveryCode возвращает хеш кода восстановления для хранения и поиска.
+// Коды случайные и длинные, поэтому медленное хеширование не требуется.
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(normalizeCode(code)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/auth"
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/otp"
+	"github.com/egosha7/goph-keeper/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveryCodes(t *testing.T) {
+	code, err := newRecoveryCode()
+	require.NoError(t, err)
+	assert.Regexp(t, `^[A-Z2-7]{4}-[A-Z2-7]{4}-[A-Z2-7]{4}-[A-Z2-7]{4}$`, code)
+
+	other, err := newRecoveryCode()
+	require.NoError(t, err)
+	assert.NotEqual(t, code, other)
+
+	// Код можно ввести без дефисов и в нижнем регистре
+	loose := strings.ToLower(normalizeCode(code))
+	assert.Equal(t, hashRecoveryCode(code), hashRecoveryCode(" "+loose[:8]+" "+loose[8:]))
+	assert.NotEqual(t, hashRecoveryCode(code), hashRecoveryCode(other))
+}
+
+// secondFactorRepository хранит второй фактор одного пользователя в памяти
+// и меняет его так же, как условные запросы PostgreSQLRepository.
+type secondFactorRepository struct {
+	repository.UserRepository
+
+	mu sync.Mutex
+	sf domain.SecondFactor
+}
+
+func (r *secondFactorRepository) GetSecondFactor(string) (*domain.SecondFactor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sf := r.sf
+	return &sf, nil
+}
+
+func (r *secondFactorRepository) ClaimSecondFactorAttempt(_ string, failures int, lockedUntil time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sf.Failures != failures {
+		return false, nil
+	}
+	r.sf.Failures, r.sf.LockedUntil = failures+1, lockedUntil
+	return true, nil
+}
+
+func (r *secondFactorRepository) ResetSecondFactorFailures(string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sf.Failures, r.sf.LockedUntil = 0, time.Unix(0, 0)
+	return nil
+}
+
+func (r *secondFactorRepository) UseSecondFactorStep(_ string, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sf.LastStep >= step {
+		return false, nil
+	}
+	r.sf.LastStep = step
+	return true, nil
+}
+
+func (r *secondFactorRepository) UseRecoveryCode(string, string) (bool, error) {
+	return false, nil
+}
+
+func TestCompleteLogin_Lockout(t *testing.T) {
+	key, err := otp.NewTOTP(secondFactorIssuer, "Egor")
+	require.NoError(t, err)
+	repo := &secondFactorRepository{sf: domain.SecondFactor{Enabled: true, Key: key.URI(), LockedUntil: time.Unix(0, 0)}}
+	s := &UserServiceImpl{Repository: repo, Tokens: auth.NewTokenManager("secret", time.Minute, time.Hour, time.Minute)}
+
+	challenge, err := s.Tokens.IssueChallenge("Egor")
+	require.NoError(t, err)
+	code, _, err := key.Code(time.Now())
+	require.NoError(t, err)
+	// Неверный код не должен совпасть с кодом соседних периодов
+	wrong := "000000"
+	for d := '1'; ; d++ {
+		if _, ok := key.Verify(wrong, time.Now(), secondFactorSkew); !ok {
+			break
+		}
+		wrong = strings.Repeat(string(d), 6)
+	}
+
+	// Один и тот же токен входа предъявляется повторно: неудачные проверки считаются по пользователю
+	for i := 1; i < secondFactorFreeAttempts; i++ {
+		_, err := s.CompleteLogin(challenge, wrong)
+		assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	}
+	_, err = s.CompleteLogin(challenge, wrong)
+	var locked *SecondFactorLockedError
+	require.ErrorAs(t, err, &locked)
+	assert.True(t, locked.Until.After(time.Now()))
+
+	// Верный код во время блокировки не сверяется
+	_, err = s.CompleteLogin(challenge, code)
+	assert.ErrorIs(t, err, domain.ErrLocked)
+	assert.Equal(t, secondFactorFreeAttempts, repo.sf.Failures)
+	assert.Zero(t, repo.sf.LastStep)
+
+	repo.sf.LockedUntil = time.Now().Add(-time.Second)
+	tokens, err := s.CompleteLogin(challenge, code)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.Equal(t, 0, repo.sf.Failures)
+}
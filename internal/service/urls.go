@@ -0,0 +1,62 @@
+package service
+
+import (
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/egosha7/goph-keeper/internal/urlmatch"
+)
+
+// maxPasswordURLs ограничивает количество адресов сайтов одного пароля.
+const maxPasswordURLs = 32
+
+// SetPasswordURLs проверяет правила сопоставления и заменяет адреса сайтов пароля.
+func (s *UserServiceImpl) SetPasswordURLs(login, passName string, urls []domain.LoginURL) error {
+	if len(urls) > maxPasswordURLs {
+		return domain.InvalidField("urls", "too many urls")
+	}
+	normalized := make([]domain.LoginURL, 0, len(urls))
+	seen := make(map[domain.LoginURL]bool, len(urls))
+	for _, u := range urls {
+		u, err := urlmatch.Normalize(u)
+		if err != nil {
+			return domain.InvalidField("urls", "%v", err)
+		}
+		if !seen[u] {
+			seen[u] = true
+			normalized = append(normalized, u)
+		}
+	}
+	if err := s.Repository.SetPasswordURLs(login, passName, normalized); err != nil {
+		return err
+	}
+	s.publish(login, domain.ChangeEvent{Action: domain.ActionUpdated, Kind: domain.KindPassword, Name: passName})
+	return nil
+}
+
+// GetPasswordURLs возвращает адреса сайтов паролей пользователя.
+func (s *UserServiceImpl) GetPasswordURLs(login string) ([]domain.PasswordURLsData, error) {
+	// Здесь может быть ваша бизнес-логика
+	return s.Repository.GetPasswordURLs(login)
+}
+
+// MatchPasswords возвращает пароли, один из адресов которых подходит для адреса страницы.
+// Для каждого пароля указывается первое сработавшее правило.
+func (s *UserServiceImpl) MatchPasswords(login, page string) ([]domain.LoginMatch, error) {
+	if _, err := urlmatch.Parse(page); err != nil {
+		return nil, domain.InvalidField("url", "%v", err)
+	}
+	passwords, err := s.Repository.GetPasswordURLs(login)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []domain.LoginMatch{}
+	for _, password := range passwords {
+		for _, rule := range password.URLs {
+			if urlmatch.Match(rule, page) {
+				matches = append(matches, domain.LoginMatch{PassName: password.PassName, Rule: rule})
+				break
+			}
+		}
+	}
+	return matches, nil
+}
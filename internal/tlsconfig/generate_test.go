@@ -0,0 +1,55 @@
+package tlsconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	ca, err := NewCA("GophKeeper Dev CA", time.Hour)
+	require.NoError(t, err)
+	server, err := ca.IssueServer([]string{"localhost", "127.0.0.1"}, time.Hour)
+	require.NoError(t, err)
+	device, err := ca.IssueClient("laptop", time.Hour)
+	require.NoError(t, err)
+
+	assert.True(t, ca.Cert.IsCA)
+	assert.Equal(t, []string{"localhost"}, server.Cert.DNSNames)
+	assert.Len(t, server.Cert.IPAddresses, 1)
+	assert.Equal(t, "laptop", device.Cert.Subject.CommonName)
+
+	require.NoError(t, ca.Write(path("ca.crt"), path("ca.key")))
+	require.NoError(t, server.Write(path("server.crt"), path("server.key")))
+	require.NoError(t, device.Write(path("clients/laptop.crt"), path("clients/laptop.key")))
+
+	info, err := os.Stat(path("server.key"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Сгенерированные файлы подходят для сервера с mTLS и клиента с закрепленным отпечатком
+	reloader, err := NewReloader(path("server.crt"), path("server.key"), path("ca.crt"), zap.NewNop())
+	require.NoError(t, err)
+	client, err := NewClientConfig(
+		ClientOptions{
+			Pin:      Fingerprint(server.Cert),
+			CertFile: path("clients/laptop.crt"),
+			KeyFile:  path("clients/laptop.key"),
+		},
+	)
+	require.NoError(t, err)
+	assert.NoError(t, handshake(t, reloader.ServerConfig(ClientAuthRequire), client))
+
+	_, err = ca.IssueServer(nil, time.Hour)
+	assert.Error(t, err)
+	_, err = NewCA("CA", 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,191 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Reloader хранит сертификат сервера и сертификаты УЦ клиентов и перечитывает их,
+// когда файлы меняются, поэтому обновленный сертификат применяется без перезапуска сервера.
+// Уже установленные соединения продолжают работать со старым сертификатом.
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	logger       *zap.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	stamps    []fileStamp
+}
+
+// fileStamp - время изменения и размер файла, по которым замечается обновление.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader загружает сертификат и ключ сервера и, если задан файл, сертификаты УЦ клиентов.
+func NewReloader(certFile, keyFile, clientCAFile string, logger *zap.Logger) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, clientCAFile: clientCAFile, logger: logger}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload перечитывает файлы, если они изменились с прошлой загрузки, и сообщает, были ли изменения.
+// При ошибке остаются прежние сертификаты.
+func (r *Reloader) Reload() (bool, error) {
+	stamps, err := r.stat()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := sameStamps(stamps, r.stamps)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("load server certificate: %w", err)
+	}
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		if clientCAs, err = loadPool(r.clientCAFile); err != nil {
+			return false, err
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.stamps = stamps
+	r.mu.Unlock()
+	return true, nil
+}
+
+// Run проверяет файлы с интервалом interval, пока не отменен ctx.
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				r.logger.Warn("Ошибка обновления сертификатов TLS, используются прежние", zap.Error(err))
+				continue
+			}
+			if reloaded {
+				r.logger.Info("Сертификаты TLS обновлены")
+			}
+		}
+	}
+}
+
+// ServerConfig возвращает конфигурацию TLS сервера с текущим сертификатом и проверкой клиентов.
+// Клиентский сертификат проверяется по сертификатам УЦ, загруженным на момент подключения.
+func (r *Reloader) ServerConfig(clientAuth ClientAuth) *tls.Config {
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.getCertificate,
+	}
+
+	switch clientAuth {
+	case ClientAuthOptional:
+		config.ClientAuth = tls.RequestClientCert
+	case ClientAuthRequire:
+		config.ClientAuth = tls.RequireAnyClientCert
+	default:
+		return config
+	}
+	config.VerifyConnection = r.verifyClient
+	return config
+}
+
+// getCertificate возвращает текущий сертификат сервера.
+func (r *Reloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// verifyClient проверяет цепочку предъявленного клиентского сертификата.
+// Отсутствие сертификата допускается, если его не требует режим ClientAuth.
+func (r *Reloader) verifyClient(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return nil
+	}
+
+	r.mu.RLock()
+	roots := r.clientCAs
+	r.mu.RUnlock()
+	if roots == nil {
+		return fmt.Errorf("client certificates are not trusted: no client ca configured")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(
+		x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("verify client certificate: %w", err)
+	}
+	return nil
+}
+
+// stat возвращает отметки всех отслеживаемых файлов.
+func (r *Reloader) stat() ([]fileStamp, error) {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+
+	stamps := make([]fileStamp, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		stamps = append(stamps, fileStamp{modTime: info.ModTime(), size: info.Size()})
+	}
+	return stamps, nil
+}
+
+// sameStamps сообщает, совпадают ли отметки файлов.
+func sameStamps(a, b []fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].modTime.Equal(b[i].modTime) || a[i].size != b[i].size {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,272 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testCert - сертификат и ключ, сохраненные в файлы.
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// newTestCert выпускает сертификат, подписанный parent, или самоподписанный УЦ, если parent не задан.
+func newTestCert(t *testing.T, dir, name string, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		template.ExtKeyUsage = nil
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	tc := &testCert{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".crt"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+	require.NoError(t, os.WriteFile(tc.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(tc.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return tc
+}
+
+// handshake устанавливает TLS-соединение и возвращает ошибку рукопожатия с любой стороны.
+func handshake(t *testing.T, server, client *tls.Config) error {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			done <- err
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		conn = tls.Server(conn, server)
+		err = conn.(*tls.Conn).Handshake()
+		if err == nil {
+			// В TLS 1.3 сервер проверяет сертификат клиента после завершения рукопожатия клиентом,
+			// поэтому клиент узнает об отказе только при первом чтении
+			_, err = conn.Write([]byte{1})
+		}
+		done <- err
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	client.ServerName = "localhost"
+	clientConn := tls.Client(conn, client)
+	clientErr := clientConn.Handshake()
+	if clientErr == nil {
+		_, clientErr = clientConn.Read(make([]byte, 1))
+	}
+	if serverErr := <-done; serverErr != nil {
+		return serverErr
+	}
+	return clientErr
+}
+
+func TestClientAuth_UnmarshalText(t *testing.T) {
+	var mode ClientAuth
+	require.NoError(t, mode.UnmarshalText([]byte("require")))
+	assert.Equal(t, ClientAuthRequire, mode)
+
+	text, err := ClientAuthOptional.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "optional", string(text))
+
+	assert.Error(t, mode.UnmarshalText([]byte("always")))
+}
+
+func TestParseFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, dir, "ca", nil, 0)
+
+	fingerprint := Fingerprint(ca.cert)
+	withColons, err := ParseFingerprint(fingerprint)
+	require.NoError(t, err)
+	plain, err := ParseFingerprint(strings.ToLower(strings.ReplaceAll(fingerprint, ":", "")))
+	require.NoError(t, err)
+	assert.Equal(t, withColons, plain)
+
+	_, err = ParseFingerprint("AB:CD")
+	assert.Error(t, err)
+}
+
+func TestReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, dir, "ca", nil, 0)
+	first := newTestCert(t, dir, "server", ca, x509.ExtKeyUsageServerAuth)
+
+	reloader, err := NewReloader(first.certFile, first.keyFile, "", zap.NewNop())
+	require.NoError(t, err)
+	current, err := reloader.getCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, first.cert.Raw, current.Certificate[0])
+
+	reloaded, err := reloader.Reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded, "файлы не менялись")
+
+	// Новый сертификат записывается на место старого
+	second := newTestCert(t, t.TempDir(), "server", ca, x509.ExtKeyUsageServerAuth)
+	for src, dst := range map[string]string{second.certFile: first.certFile, second.keyFile: first.keyFile} {
+		data, err := os.ReadFile(src)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(dst, data, 0o600))
+		future := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(dst, future, future))
+	}
+
+	reloaded, err = reloader.Reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	current, err = reloader.getCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second.cert.Raw, current.Certificate[0])
+
+	// Поврежденный файл не заменяет рабочий сертификат
+	require.NoError(t, os.WriteFile(first.keyFile, []byte("broken"), 0o600))
+	_, err = reloader.Reload()
+	assert.Error(t, err)
+	current, err = reloader.getCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second.cert.Raw, current.Certificate[0])
+}
+
+func TestServerConfig_ClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, dir, "ca", nil, 0)
+	server := newTestCert(t, dir, "server", ca, x509.ExtKeyUsageServerAuth)
+	device := newTestCert(t, dir, "device", ca, x509.ExtKeyUsageClientAuth)
+	otherCA := newTestCert(t, dir, "other-ca", nil, 0)
+	stranger := newTestCert(t, dir, "stranger", otherCA, x509.ExtKeyUsageClientAuth)
+
+	reloader, err := NewReloader(server.certFile, server.keyFile, ca.certFile, zap.NewNop())
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		mode    ClientAuth
+		client  *testCert
+		wantErr bool
+	}{
+		{name: "Off without certificate", mode: ClientAuthOff},
+		{name: "Optional without certificate", mode: ClientAuthOptional},
+		{name: "Optional with trusted certificate", mode: ClientAuthOptional, client: device},
+		{name: "Optional with untrusted certificate", mode: ClientAuthOptional, client: stranger, wantErr: true},
+		{name: "Require without certificate", mode: ClientAuthRequire, wantErr: true},
+		{name: "Require with trusted certificate", mode: ClientAuthRequire, client: device},
+		{name: "Require with untrusted certificate", mode: ClientAuthRequire, client: stranger, wantErr: true},
+		{name: "Server certificate as client", mode: ClientAuthRequire, client: server, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				opts := ClientOptions{CAFile: ca.certFile}
+				if tc.client != nil {
+					opts.CertFile, opts.KeyFile = tc.client.certFile, tc.client.keyFile
+				}
+				client, err := NewClientConfig(opts)
+				require.NoError(t, err)
+
+				err = handshake(t, reloader.ServerConfig(tc.mode), client)
+				if tc.wantErr {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+				}
+			},
+		)
+	}
+}
+
+func TestNewClientConfig_Pin(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, dir, "ca", nil, 0)
+	server := newTestCert(t, dir, "server", ca, x509.ExtKeyUsageServerAuth)
+	other := newTestCert(t, dir, "other", ca, x509.ExtKeyUsageServerAuth)
+
+	reloader, err := NewReloader(server.certFile, server.keyFile, "", zap.NewNop())
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		opts    ClientOptions
+		wantErr bool
+	}{
+		{name: "Pin without CA", opts: ClientOptions{Pin: Fingerprint(server.cert)}},
+		{name: "Pin with CA", opts: ClientOptions{CAFile: ca.certFile, Pin: Fingerprint(server.cert)}},
+		{name: "Pin mismatch", opts: ClientOptions{Pin: Fingerprint(other.cert)}, wantErr: true},
+		{name: "Untrusted without pin", opts: ClientOptions{}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(
+			tc.name, func(t *testing.T) {
+				client, err := NewClientConfig(tc.opts)
+				require.NoError(t, err)
+
+				err = handshake(t, reloader.ServerConfig(ClientAuthOff), client)
+				if tc.wantErr {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,127 @@
+// Package urlmatch сопоставляет адреса сайтов паролей с адресом открытой страницы.
+package urlmatch
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"golang.org/x/net/publicsuffix"
+)
+
+// maxPatternLength ограничивает длину адреса и регулярного выражения правила.
+const maxPatternLength = 2048
+
+// Normalize проверяет правило и приводит его к хранимому виду: правило по умолчанию - domain,
+// адрес без крайних пробелов. Регулярное выражение должно компилироваться.
+func Normalize(rule domain.LoginURL) (domain.LoginURL, error) {
+	rule.URL = strings.TrimSpace(rule.URL)
+	if rule.URL == "" {
+		return rule, fmt.Errorf("url is empty")
+	}
+	if len(rule.URL) > maxPatternLength {
+		return rule, fmt.Errorf("url is too long")
+	}
+
+	switch rule.Match {
+	case "":
+		rule.Match = domain.MatchDomain
+		fallthrough
+	case domain.MatchDomain, domain.MatchHost:
+		if _, err := Parse(rule.URL); err != nil {
+			return rule, err
+		}
+	case domain.MatchStarts:
+	case domain.MatchRegex:
+		if _, err := regexp.Compile(rule.URL); err != nil {
+			return rule, fmt.Errorf("invalid regex: %w", err)
+		}
+	default:
+		return rule, fmt.Errorf("unknown match rule %q", rule.Match)
+	}
+	return rule, nil
+}
+
+// Parse разбирает адрес. Адрес без схемы считается адресом https, например "example.com/login".
+func Parse(address string) (*url.URL, error) {
+	address = strings.TrimSpace(address)
+	if !strings.Contains(address, "://") {
+		address = "https://" + address
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("url %q has no host", address)
+	}
+	return u, nil
+}
+
+// Match проверяет, подходит ли правило для адреса страницы.
+// Неразбираемые адреса и некорректные правила ни с чем не совпадают.
+func Match(rule domain.LoginURL, page string) bool {
+	switch rule.Match {
+	case domain.MatchStarts:
+		return strings.HasPrefix(page, rule.URL)
+	case domain.MatchRegex:
+		re, err := regexp.Compile(rule.URL)
+		return err == nil && re.MatchString(page)
+	}
+
+	target, err := Parse(page)
+	if err != nil {
+		return false
+	}
+	site, err := Parse(rule.URL)
+	if err != nil {
+		return false
+	}
+
+	switch rule.Match {
+	case domain.MatchHost:
+		if host(site) != host(target) {
+			return false
+		}
+		return site.Port() == "" || site.Port() == port(target)
+	case domain.MatchDomain, "":
+		return BaseDomain(host(site)) == BaseDomain(host(target))
+	}
+	return false
+}
+
+// BaseDomain возвращает домен, зарегистрированный владельцем сайта, по списку публичных суффиксов:
+// для accounts.example.co.uk это example.co.uk. IP-адреса и имена без публичного суффикса,
+// например localhost, возвращаются без изменений.
+func BaseDomain(hostname string) string {
+	if net.ParseIP(hostname) != nil {
+		return hostname
+	}
+	base, err := publicsuffix.EffectiveTLDPlusOne(hostname)
+	if err != nil {
+		return hostname
+	}
+	return base
+}
+
+// host возвращает имя хоста адреса в нижнем регистре без завершающей точки.
+func host(u *url.URL) string {
+	return strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+}
+
+// port возвращает порт адреса, подставляя стандартный порт схемы.
+func port(u *url.URL) string {
+	if p := u.Port(); p != "" {
+		return p
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package urlmatch
+
+import (
+	"testing"
+
+	"github.com/egosha7/goph-keeper/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	const page = "https://accounts.example.co.uk/login?next=/"
+
+	tests := []struct {
+		name string
+		rule domain.LoginURL
+		want bool
+	}{
+		{"Base domain", domain.LoginURL{URL: "example.co.uk", Match: domain.MatchDomain}, true},
+		{"Base domain of subdomain", domain.LoginURL{URL: "https://www.example.co.uk"}, true},
+		{"Public suffix is not a base domain", domain.LoginURL{URL: "other.co.uk", Match: domain.MatchDomain}, false},
+		{"Exact host", domain.LoginURL{URL: "https://Accounts.Example.co.uk/", Match: domain.MatchHost}, true},
+		{"Other host", domain.LoginURL{URL: "example.co.uk", Match: domain.MatchHost}, false},
+		{"Host with default port", domain.LoginURL{URL: "accounts.example.co.uk:443", Match: domain.MatchHost}, true},
+		{"Host with other port", domain.LoginURL{URL: "accounts.example.co.uk:8443", Match: domain.MatchHost}, false},
+		{"Starts with", domain.LoginURL{URL: "https://accounts.example.co.uk/login", Match: domain.MatchStarts}, true},
+		{"Starts with other path", domain.LoginURL{URL: "https://accounts.example.co.uk/admin", Match: domain.MatchStarts}, false},
+		{"Regex", domain.LoginURL{URL: `^https://[a-z]+\.example\.co\.uk/`, Match: domain.MatchRegex}, true},
+		{"Regex mismatch", domain.LoginURL{URL: `^http://`, Match: domain.MatchRegex}, false},
+		{"Invalid regex", domain.LoginURL{URL: `(`, Match: domain.MatchRegex}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Match(tc.rule, page))
+		})
+	}
+}
+
+func TestBaseDomain(t *testing.T) {
+	assert.Equal(t, "example.com", BaseDomain("mail.example.com"))
+	assert.Equal(t, "example.co.uk", BaseDomain("a.b.example.co.uk"))
+	assert.Equal(t, "localhost", BaseDomain("localhost"))
+	assert.Equal(t, "192.168.0.1", BaseDomain("192.168.0.1"))
+}
+
+func TestNormalize(t *testing.T) {
+	rule, err := Normalize(domain.LoginURL{URL: "  example.com "})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.LoginURL{URL: "example.com", Match: domain.MatchDomain}, rule)
+
+	for _, rule := range []domain.LoginURL{
+		{URL: ""},
+		{URL: "example.com", Match: "fuzzy"},
+		{URL: "(", Match: domain.MatchRegex},
+		{URL: "https://", Match: domain.MatchHost},
+	} {
+		_, err := Normalize(rule)
+		assert.Error(t, err, rule)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/config"
+	"github.com/egosha7/goph-keeper/internal/tlsconfig"
+)
+
+// genCertsCommand - имя подкоманды выпуска сертификатов для разработки.
+const genCertsCommand = "gen-certs"
+
+// clientNamePattern - допустимое имя устройства, из него получается имя файла.
+var clientNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// runGenCerts создает собственный УЦ, сертификат сервера и сертификаты устройств для mTLS.
+//
+// Файлы записываются по путям TLS_CERT, TLS_KEY и TLS_CLIENT_CA из конфигурации, а если они
+// не заданы - в каталог -dir. Существующие файлы не перезаписываются без флага -force, так как
+// новый УЦ делает недействительными все выпущенные ранее сертификаты устройств.
+// Отпечатки выводятся в формате флага клиента -pin-sha256.
+func runGenCerts(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet(genCertsCommand, flag.ContinueOnError)
+	dir := flags.String("dir", "certs", "Каталог для файлов, пути к которым не заданы в конфигурации")
+	hosts := flags.String("hosts", defaultHosts(cfg.Addr), "Имена хостов и IP-адреса сервера через запятую")
+	clients := flags.String("clients", "", "Имена устройств через запятую, для которых выпускаются сертификаты mTLS")
+	validity := flags.Duration("validity", 365*24*time.Hour, "Срок действия сертификатов сервера и устройств")
+	caValidity := flags.Duration("ca-validity", 10*365*24*time.Hour, "Срок действия УЦ")
+	force := flags.Bool("force", false, "Перезаписать существующие файлы")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	hostList := splitList(*hosts)
+	if len(hostList) == 0 {
+		return fmt.Errorf("at least one host is required")
+	}
+	clientList := splitList(*clients)
+	for _, name := range clientList {
+		if !clientNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid client name %q", name)
+		}
+	}
+
+	// Пути из конфигурации, чтобы сервер подхватил файлы без дополнительной настройки
+	caCert := pathOr(cfg.TLSClientCA, filepath.Join(*dir, "ca.crt"))
+	caKey := strings.TrimSuffix(caCert, filepath.Ext(caCert)) + ".key"
+	serverCert := pathOr(cfg.TLSCert, filepath.Join(*dir, "server.crt"))
+	serverKey := pathOr(cfg.TLSKey, filepath.Join(*dir, "server.key"))
+	clientsDir := filepath.Join(filepath.Dir(caCert), "clients")
+
+	files := []string{caCert, caKey, serverCert, serverKey}
+	for _, name := range clientList {
+		files = append(files, filepath.Join(clientsDir, name+".crt"), filepath.Join(clientsDir, name+".key"))
+	}
+	if !*force {
+		if err := ensureAbsent(files); err != nil {
+			return err
+		}
+	}
+
+	ca, err := tlsconfig.NewCA("GophKeeper Dev CA", *caValidity)
+	if err != nil {
+		return err
+	}
+	if err := ca.Write(caCert, caKey); err != nil {
+		return err
+	}
+	server, err := ca.IssueServer(hostList, *validity)
+	if err != nil {
+		return err
+	}
+	if err := server.Write(serverCert, serverKey); err != nil {
+		return err
+	}
+
+	fmt.Printf("УЦ:      %s\n  SHA-256 %s\n", caCert, tlsconfig.Fingerprint(ca.Cert))
+	fmt.Printf("Сервер:  %s (%s)\n  SHA-256 %s\n", serverCert, strings.Join(hostList, ", "), tlsconfig.Fingerprint(server.Cert))
+
+	for _, name := range clientList {
+		client, err := ca.IssueClient(name, *validity)
+		if err != nil {
+			return err
+		}
+		certFile := filepath.Join(clientsDir, name+".crt")
+		if err := client.Write(certFile, filepath.Join(clientsDir, name+".key")); err != nil {
+			return err
+		}
+		fmt.Printf("Устройство %s: %s\n  SHA-256 %s\n", name, certFile, tlsconfig.Fingerprint(client.Cert))
+	}
+
+	fmt.Println()
+	fmt.Printf("Сервер: TLS_CERT=%s TLS_KEY=%s", serverCert, serverKey)
+	if len(clientList) > 0 {
+		fmt.Printf(" TLS_CLIENT_CA=%s TLS_CLIENT_AUTH=require", caCert)
+	}
+	fmt.Println()
+	fmt.Printf("Клиент: -server https://%s -pin-sha256 %s\n", net.JoinHostPort(hostList[0], port(cfg.Addr)), tlsconfig.Fingerprint(server.Cert))
+	return nil
+}
+
+// defaultHosts возвращает хост из адреса сервера вместе с адресами локальной машины.
+func defaultHosts(addr string) string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	if host, _, err := net.SplitHostPort(addr); err == nil && host != "" {
+		found := false
+		for _, h := range hosts {
+			found = found || h == host
+		}
+		if !found {
+			hosts = append([]string{host}, hosts...)
+		}
+	}
+	return strings.Join(hosts, ",")
+}
+
+// port возвращает порт из адреса сервера.
+func port(addr string) string {
+	_, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "8080"
+	}
+	return p
+}
+
+// splitList разбирает список через запятую, пропуская пустые элементы.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// pathOr возвращает путь из конфигурации или путь по умолчанию.
+func pathOr(configured, fallback string) string {
+	if configured != "" {
+		return configured
+	}
+	return fallback
+}
+
+// ensureAbsent возвращает ошибку, если какой-либо из файлов уже существует.
+func ensureAbsent(files []string) error {
+	var existing []string
+	for _, file := range files {
+		_, err := os.Stat(file)
+		switch {
+		case err == nil:
+			existing = append(existing, file)
+		case !errors.Is(err, fs.ErrNotExist):
+			return err
+		}
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("files already exist, use -force to overwrite: %s", strings.Join(existing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/egosha7/goph-keeper/internal/config"
+	"github.com/egosha7/goph-keeper/internal/db"
+	"github.com/egosha7/goph-keeper/internal/keys"
+	"github.com/egosha7/goph-keeper/internal/repository"
+	"go.uber.org/zap"
+)
+
+// encryptLegacyCommand - имя подкоманды шифрования значений, сохраненных до шифрования на сервере.
+const encryptLegacyCommand = "encrypt-legacy"
+
+// runEncryptLegacy шифрует ключами данных пользователей пароли и карты, сохраненные открытыми
+// до появления шифрования столбцов.
+//
+// Сервер читает такие значения и без этой команды, но хранит их открытыми, пока они не изменятся.
+// Команду можно выполнять на работающем сервисе и запускать повторно после сбоя.
+func runEncryptLegacy(cfg *config.Config, provider keys.KeyProvider, args []string, logger *zap.Logger) error {
+	flags := flag.NewFlagSet(encryptLegacyCommand, flag.ContinueOnError)
+	batchSize := flags.Int("batch", 100, "Количество записей, обрабатываемых в одной транзакции")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive")
+	}
+
+	pool, err := db.NewPool(cfg)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	repo := repository.NewPostgreSQLRepository(pool, provider, logger)
+	state, err := repo.EncryptLegacyValues(
+		context.Background(), *batchSize, func(p repository.LegacyProgress) {
+			logger.Info("Шифрование старых значений", zap.Int("items", p.Items), zap.Int("encrypted", p.Encrypted))
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Шифрование старых значений завершено", zap.Int("items", state.Items), zap.Int("encrypted", state.Encrypted))
+	return nil
+}
@@ -2,12 +2,26 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"flag"
 	"fmt"
+	"github.com/egosha7/goph-keeper/internal/auth"
+	"github.com/egosha7/goph-keeper/internal/blob"
 	"github.com/egosha7/goph-keeper/internal/config"
 	"github.com/egosha7/goph-keeper/internal/db"
+	"github.com/egosha7/goph-keeper/internal/events"
+	"github.com/egosha7/goph-keeper/internal/grpcserver"
+	"github.com/egosha7/goph-keeper/internal/keys"
 	loger "github.com/egosha7/goph-keeper/internal/logger"
+	"github.com/egosha7/goph-keeper/internal/ratelimit"
+	"github.com/egosha7/goph-keeper/internal/repository"
 	"github.com/egosha7/goph-keeper/internal/router"
+	"github.com/egosha7/goph-keeper/internal/service"
+	"github.com/egosha7/goph-keeper/internal/tlsconfig"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -42,6 +56,15 @@ func main() {
 	// Проверка конфигурации из флагов и переменных окружения.
 	cfg := config.OnFlag(logger)
 
+	// Выпуск сертификатов не требует базы данных, поэтому выполняется до подключения к ней.
+	if flag.Arg(0) == genCertsCommand {
+		if err := runGenCerts(cfg, flag.Args()[1:]); err != nil {
+			logger.Error("Ошибка выпуска сертификатов", zap.Error(err))
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Подключение к базе данных.
 	conn, err := db.ConnectToDB(cfg)
 	if err != nil {
@@ -50,8 +73,106 @@ func main() {
 	}
 	defer conn.Close(context.Background())
 
+	// Обновление схемы базы данных.
+	if err := db.Migrate(context.Background(), conn); err != nil {
+		logger.Error("Ошибка обновления схемы базы данных", zap.Error(err))
+		os.Exit(1)
+	}
+
+	// Поставщик ключей для шифрования данных в базе.
+	provider, err := keys.NewProvider(cfg)
+	if err != nil {
+		logger.Error("Ошибка инициализации поставщика ключей", zap.Error(err))
+		os.Exit(1)
+	}
+
+	// Подкоманды выполняются вместо запуска сервера.
+	if flag.Arg(0) == rotateKeysCommand {
+		if err := runRotateKeys(cfg, provider, flag.Args()[1:], logger); err != nil {
+			logger.Error("Ошибка ротации ключей", zap.Error(err))
+			os.Exit(1)
+		}
+		return
+	}
+	if flag.Arg(0) == encryptLegacyCommand {
+		if err := runEncryptLegacy(cfg, provider, flag.Args()[1:], logger); err != nil {
+			logger.Error("Ошибка шифрования старых значений", zap.Error(err))
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Пул подключений к базе данных.
+	pool, err := db.NewPool(cfg)
+	if err != nil {
+		logger.Error("Ошибка создания пула подключений", zap.Error(err))
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	// Создание хранилища.
+	repo := repository.NewPostgreSQLRepository(pool, provider, logger)
+
+	// Хранилище содержимого файлов.
+	blobs, err := blob.NewStore(cfg)
+	if err != nil {
+		logger.Error("Ошибка инициализации хранилища файлов", zap.Error(err))
+		os.Exit(1)
+	}
+
+	// Хранилище состояния ограничений частоты запросов.
+	limiter, err := ratelimit.New(cfg.RateLimitBackend)
+	if err != nil {
+		logger.Error("Ошибка инициализации ограничений частоты запросов", zap.Error(err))
+		os.Exit(1)
+	}
+
+	// Фоновая очистка корзины.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runTrashPurge(ctx, cfg, repo, logger)
+
+	// Сервисы общие для REST API и gRPC.
+	tokens := auth.NewTokenManager(cfg.TokenSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL, cfg.UnlockTTL)
+	bus := events.NewBus(events.DefaultHistorySize)
+	services := service.NewUserService(repo, tokens, blobs, bus, cfg.FileChunkSize, logger)
+
 	// Настройка маршрутов для приложения.
-	r := routes.SetupRoutes(cfg, conn, logger)
+	r := routes.SetupRoutes(cfg, services, limiter, logger)
+
+	// Сертификаты TLS перечитываются при обновлении файлов, перезапуск сервера не нужен.
+	var tlsConfig *tls.Config
+	if cfg.TLSEnabled() {
+		reloader, err := tlsconfig.NewReloader(cfg.TLSCert, cfg.TLSKey, cfg.TLSClientCA, logger)
+		if err != nil {
+			logger.Error("Ошибка загрузки сертификатов TLS", zap.Error(err))
+			os.Exit(1)
+		}
+		go reloader.Run(ctx, cfg.TLSReloadInterval)
+		tlsConfig = reloader.ServerConfig(cfg.TLSClientAuth)
+	} else {
+		logger.Warn("TLS не настроен, соединения не шифруются")
+	}
+
+	// Запуск gRPC-сервера на отдельном порту.
+	if cfg.GRPCAddr != "" {
+		listener, err := net.Listen("tcp", cfg.GRPCAddr)
+		if err != nil {
+			logger.Error("Ошибка запуска gRPC сервера", zap.Error(err))
+			os.Exit(1)
+		}
+		var opts []grpc.ServerOption
+		if tlsConfig != nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+		grpcServer := grpcserver.New(cfg, services, limiter, logger, opts...)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				logger.Error("Ошибка работы gRPC сервера", zap.Error(err))
+				os.Exit(1)
+			}
+		}()
+	}
 
 	// Настройка обработки сигналов для грациозного завершения.
 	signalCh := make(chan os.Signal, 1)
@@ -71,7 +192,13 @@ func main() {
 		os.Exit(0)
 	}()
 
-	if err := http.ListenAndServe(cfg.Addr, loger.LogMiddleware(logger, r)); err != nil {
+	server := &http.Server{Addr: cfg.Addr, Handler: loger.LogMiddleware(logger, r), TLSConfig: tlsConfig}
+	if tlsConfig != nil {
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != nil {
 		logger.Error("Ошибка запуска HTTP сервера", zap.Error(err))
 		os.Exit(1)
 	}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/egosha7/goph-keeper/internal/config"
+	"github.com/egosha7/goph-keeper/internal/repository"
+	"go.uber.org/zap"
+)
+
+// runTrashPurge периодически удаляет из корзины записи, срок хранения которых истек.
+// Работает до отмены контекста; при нулевом интервале очистка отключена.
+func runTrashPurge(ctx context.Context, cfg *config.Config, purger repository.TrashPurger, logger *zap.Logger) {
+	if cfg.TrashPurgeInterval <= 0 {
+		logger.Info("Очистка корзины отключена")
+		return
+	}
+
+	ticker := time.NewTicker(cfg.TrashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := purger.PurgeTrash(ctx, time.Now().Add(-cfg.TrashRetention))
+		if err != nil {
+			logger.Error("Ошибка очистки корзины", zap.Error(err))
+		} else if purged > 0 {
+			logger.Info("Корзина очищена", zap.Int64("purged", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/egosha7/goph-keeper/internal/config"
+	"github.com/egosha7/goph-keeper/internal/db"
+	"github.com/egosha7/goph-keeper/internal/keys"
+	"github.com/egosha7/goph-keeper/internal/repository"
+	"go.uber.org/zap"
+)
+
+// rotateKeysCommand - имя подкоманды ротации ключа шифрования ключей.
+const rotateKeysCommand = "rotate-keys"
+
+// runRotateKeys переоборачивает ключи данных пользователей текущей версией KEK.
+//
+// Ротацию можно выполнять на работающем сервисе: ключи данных не меняются, поэтому
+// записи остаются читаемыми, а после сбоя команду достаточно запустить повторно.
+// Для поставщика static новая версия KEK должна быть заранее добавлена в конфигурацию
+// всех экземпляров сервера, для local-kms ее можно создать флагом -generate.
+func runRotateKeys(cfg *config.Config, provider keys.KeyProvider, args []string, logger *zap.Logger) error {
+	flags := flag.NewFlagSet(rotateKeysCommand, flag.ContinueOnError)
+	generate := flags.Bool("generate", false, "Создать новую версию KEK перед ротацией (только local-kms)")
+	batchSize := flags.Int("batch", 100, "Количество ключей, переоборачиваемых в одной транзакции")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive")
+	}
+
+	ctx := context.Background()
+	if *generate {
+		rotator, ok := provider.(keys.Rotator)
+		if !ok {
+			return fmt.Errorf("key provider %q cannot generate keys", cfg.KeyProvider)
+		}
+		version, err := rotator.Rotate(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to generate key: %w", err)
+		}
+		logger.Info("Создана новая версия KEK", zap.Int("version", version))
+	}
+
+	pool, err := db.NewPool(cfg)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	repo := repository.NewPostgreSQLRepository(pool, provider, logger)
+	state, err := repo.RewrapDataKeys(
+		ctx, *batchSize, func(p repository.RotationProgress) {
+			logger.Info(
+				"Ротация ключей",
+				zap.Int("version", p.Version),
+				zap.Int("done", p.Done),
+				zap.Int("failed", p.Failed),
+				zap.Int("total", p.Total),
+			)
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	logger.Info(
+		"Ротация ключей завершена",
+		zap.Int("version", state.Version),
+		zap.Int("done", state.Done),
+		zap.Int("failed", state.Failed),
+	)
+	if state.Failed > 0 {
+		return fmt.Errorf("%d data keys could not be unwrapped", state.Failed)
+	}
+	return nil
+}